@@ -0,0 +1,92 @@
+package aicache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DefaultMaxEntries - сколько ответов хранится в кеше по умолчанию
+const DefaultMaxEntries = 10000
+
+type entry struct {
+	reply     string
+	expiresAt time.Time
+}
+
+// Cache хранит ответы ассистента по ключу (тест, вопрос, модель, нормализованный запрос) с TTL
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]entry
+}
+
+func New(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]entry),
+	}
+}
+
+// Key собирает ключ кеша; запросы, отличающиеся регистром, пробелами и пунктуацией, совпадают
+func Key(testID, questionID uint64, model, prompt string) string {
+	return fmt.Sprintf("%d:%d:%s:%s", testID, questionID, model, Normalize(prompt))
+}
+
+// Normalize приводит запрос к нижнему регистру, убирает пунктуацию и лишние пробелы
+func Normalize(prompt string) string {
+	fields := strings.FieldsFunc(strings.ToLower(prompt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Get возвращает ответ, если он есть и не истек
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return e.reply, true
+}
+
+// Set сохраняет ответ на ttl. При переполнении сначала удаляются истекшие записи,
+// затем произвольные - кеш лишь экономит запросы, потеря записи не страшна
+func (c *Cache) Set(key, reply string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = entry{reply: reply, expiresAt: time.Now().Add(ttl)}
+}
+
+// evict освобождает место в кеше, вызывается под блокировкой
+func (c *Cache) evict() {
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
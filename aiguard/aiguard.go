@@ -0,0 +1,120 @@
+package aiguard
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Уровни строгости проверки
+const (
+	StrictnessOff    = "off"    // проверка отключена
+	StrictnessLow    = "low"    // только прямые требования решить задание
+	StrictnessMedium = "medium" // плюс вопросы про готовый ответ
+	StrictnessHigh   = "high"   // плюс вопрос, скопированный в чат почти целиком
+)
+
+// DefaultStrictness - строгость, если тест не задает свою
+const DefaultStrictness = StrictnessLow
+
+// copiedQuestionShare - доля слов вопроса в сообщении, при которой считаем, что вопрос просто скопирован
+const copiedQuestionShare = 0.8
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var lowRules = []rule{
+	{"solve_for_me", regexp.MustCompile(`(?i)(реши|решите|сделай|выполни)\s+(это\s+|задани\S*\s+|задачу\s+)?(за\s+меня|вместо\s+меня)`)},
+	{"give_answer", regexp.MustCompile(`(?i)(дай|дайте|напиши|напишите|скажи|скажите|назови|назовите)\s+(мне\s+)?(сразу\s+|просто\s+)?(готовый\s+|правильный\s+|верный\s+)?ответ`)},
+	{"solve_for_me", regexp.MustCompile(`(?i)(solve|do)\s+(it|this|the\s+(task|question|problem))\s+for\s+me`)},
+	{"give_answer", regexp.MustCompile(`(?i)(just\s+)?(give|tell)\s+me\s+the\s+(correct\s+|right\s+|final\s+)?answer`)},
+}
+
+var mediumRules = []rule{
+	{"ask_answer", regexp.MustCompile(`(?i)как(ой|ая|ое)\s+(здесь\s+|тут\s+)?(правильн\S*|верн\S*)\s+ответ`)},
+	{"ask_answer", regexp.MustCompile(`(?i)ответ\s+на\s+(этот\s+|данный\s+)?вопрос`)},
+	{"full_solution", regexp.MustCompile(`(?i)(полное|готовое|подробное)\s+решение`)},
+	{"ask_answer", regexp.MustCompile(`(?i)what\s+is\s+the\s+(correct\s+|right\s+)?answer`)},
+	{"full_solution", regexp.MustCompile(`(?i)(full|complete|step[-\s]by[-\s]step)\s+solution`)},
+	{"answer_only", regexp.MustCompile(`(?i)(только\s+ответ|answer\s+only|only\s+the\s+answer)`)},
+}
+
+// Verdict - результат проверки сообщения
+type Verdict struct {
+	Refused bool   `json:"refused"`
+	Rule    string `json:"rule,omitempty"` // сработавшее правило
+}
+
+// ValidStrictness проверяет допустимость уровня строгости (пустая строка - значение по умолчанию)
+func ValidStrictness(strictness string) bool {
+	switch strictness {
+	case "", StrictnessOff, StrictnessLow, StrictnessMedium, StrictnessHigh:
+		return true
+	}
+	return false
+}
+
+// Check проверяет, не просит ли студент решить вопрос за него
+func Check(message, questionText, strictness string) Verdict {
+	if strictness == "" {
+		strictness = DefaultStrictness
+	}
+	if strictness == StrictnessOff {
+		return Verdict{}
+	}
+
+	rules := lowRules
+	if strictness == StrictnessMedium || strictness == StrictnessHigh {
+		rules = append(append([]rule{}, lowRules...), mediumRules...)
+	}
+	for _, r := range rules {
+		if r.pattern.MatchString(message) {
+			return Verdict{Refused: true, Rule: r.name}
+		}
+	}
+
+	if strictness == StrictnessHigh && copiesQuestion(message, questionText) {
+		return Verdict{Refused: true, Rule: "copied_question"}
+	}
+
+	return Verdict{}
+}
+
+// copiesQuestion проверяет, что сообщение содержит почти все слова вопроса
+func copiesQuestion(message, questionText string) bool {
+	questionWords := words(questionText)
+	if len(questionWords) == 0 {
+		return false
+	}
+
+	messageWords := make(map[string]bool)
+	for _, w := range words(message) {
+		messageWords[w] = true
+	}
+
+	found := 0
+	for _, w := range questionWords {
+		if messageWords[w] {
+			found++
+		}
+	}
+
+	return float64(found)/float64(len(questionWords)) >= copiedQuestionShare
+}
+
+// words разбивает текст на слова в нижнем регистре, отбрасывая короткие
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) > 2 {
+			result = append(result, f)
+		}
+	}
+	return result
+}
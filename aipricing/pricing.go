@@ -0,0 +1,99 @@
+package aipricing
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Price - цена модели в долларах за миллион токенов
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Table - тарифы моделей. Модель ищется по самому длинному совпадающему префиксу имени,
+// поэтому "gpt-4o-mini-2024-07-18" тарифицируется как "gpt-4o-mini"
+type Table struct {
+	Models   map[string]Price `json:"models"`
+	Fallback Price            `json:"fallback"` // для неизвестных моделей и ответов без имени модели
+}
+
+// Default возвращает тарифы по умолчанию. Неизвестные модели считаются по цене gpt-4o,
+// чтобы оценка расхода не была заниженной
+func Default() *Table {
+	return &Table{
+		Models: map[string]Price{
+			"gpt-4o":            {Prompt: 2.50, Completion: 10.00},
+			"gpt-4o-mini":       {Prompt: 0.15, Completion: 0.60},
+			"gpt-4.1":           {Prompt: 2.00, Completion: 8.00},
+			"gpt-4.1-mini":      {Prompt: 0.40, Completion: 1.60},
+			"gpt-4.1-nano":      {Prompt: 0.10, Completion: 0.40},
+			"gpt-4-turbo":       {Prompt: 10.00, Completion: 30.00},
+			"gpt-3.5-turbo":     {Prompt: 0.50, Completion: 1.50},
+			"claude-3-5-sonnet": {Prompt: 3.00, Completion: 15.00},
+			"claude-sonnet-4":   {Prompt: 3.00, Completion: 15.00},
+			"claude-3-5-haiku":  {Prompt: 0.80, Completion: 4.00},
+			"claude-3-opus":     {Prompt: 15.00, Completion: 75.00},
+			"claude-opus-4":     {Prompt: 15.00, Completion: 75.00},
+			"fake":              {},
+		},
+		Fallback: Price{Prompt: 2.50, Completion: 10.00},
+	}
+}
+
+// Load читает тарифы из JSON-файла вида {"models": {"gpt-4o": {"prompt": 2.5, "completion": 10}}, "fallback": {...}}.
+// Модели из файла дополняют и переопределяют тарифы по умолчанию
+func Load(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Models   map[string]Price `json:"models"`
+		Fallback *Price           `json:"fallback"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	table := Default()
+	for model, price := range file.Models {
+		if price.Prompt < 0 || price.Completion < 0 {
+			return nil, errors.New("price of " + model + " must not be negative")
+		}
+		table.Models[strings.ToLower(model)] = price
+	}
+	if file.Fallback != nil {
+		if file.Fallback.Prompt < 0 || file.Fallback.Completion < 0 {
+			return nil, errors.New("fallback price must not be negative")
+		}
+		table.Fallback = *file.Fallback
+	}
+
+	return table, nil
+}
+
+// Price возвращает тариф модели
+func (t *Table) Price(model string) Price {
+	model = strings.ToLower(model)
+
+	best, found := "", false
+	for prefix := range t.Models {
+		if strings.HasPrefix(model, prefix) && len(prefix) >= len(best) {
+			best, found = prefix, true
+		}
+	}
+	if !found {
+		return t.Fallback
+	}
+	return t.Models[best]
+}
+
+// Cost оценивает стоимость ответа в долларах
+func (t *Table) Cost(model string, promptTokens, completionTokens int) float64 {
+	price := t.Price(model)
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6
+}
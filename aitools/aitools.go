@@ -0,0 +1,147 @@
+package aitools
+
+import (
+	"GEEK_back/client/llm"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Имена серверных функций ассистента
+const (
+	ToolCalculator = "calculator" // вычисление арифметических выражений
+	ToolMaterials  = "materials"  // поиск по материалам вопроса
+)
+
+// ToolsOff отключает функции, унаследованные от настроек теста
+const ToolsOff = "off"
+
+// maxMaterialResults - сколько фрагментов материалов возвращает поиск
+const maxMaterialResults = 3
+
+// Parse разбирает список функций через запятую
+func Parse(names string) ([]string, error) {
+	if names == "" || names == ToolsOff {
+		return nil, nil
+	}
+
+	var result []string
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case ToolCalculator, ToolMaterials:
+			result = append(result, name)
+		default:
+			return nil, errors.New("unknown tool: " + name)
+		}
+	}
+	return result, nil
+}
+
+// Build собирает функции ассистента по списку имен. Поиск видит только переданные материалы вопроса
+func Build(names string, materials []string) []llm.Tool {
+	parsed, err := Parse(names)
+	if err != nil {
+		return nil
+	}
+
+	tools := make([]llm.Tool, 0, len(parsed))
+	for _, name := range parsed {
+		switch name {
+		case ToolCalculator:
+			tools = append(tools, Calculator())
+		case ToolMaterials:
+			tools = append(tools, Materials(materials))
+		}
+	}
+	return tools
+}
+
+// Calculator вычисляет арифметическое выражение: + - * / ^ и скобки
+func Calculator() llm.Tool {
+	return llm.Tool{
+		Name:        ToolCalculator,
+		Description: "Evaluates an arithmetic expression with + - * / ^ and parentheses",
+		Parameters: json.RawMessage(`{"type":"object","properties":{"expression":{"type":"string",` +
+			`"description":"Arithmetic expression, e.g. (2+3)*4"}},"required":["expression"]}`),
+		Handler: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			var args struct {
+				Expression string `json:"expression"`
+			}
+			if err := json.Unmarshal(arguments, &args); err != nil {
+				return "", errors.New("invalid arguments")
+			}
+
+			value, err := Evaluate(args.Expression)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatFloat(value, 'g', -1, 64), nil
+		},
+	}
+}
+
+// Materials ищет фрагменты справочных материалов вопроса, содержащие слова запроса
+func Materials(materials []string) llm.Tool {
+	return llm.Tool{
+		Name:        ToolMaterials,
+		Description: "Searches the reference materials attached to the current question",
+		Parameters: json.RawMessage(`{"type":"object","properties":{"query":{"type":"string",` +
+			`"description":"Words to search for"}},"required":["query"]}`),
+		Handler: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			var args struct {
+				Query string `json:"query"`
+			}
+			if err := json.Unmarshal(arguments, &args); err != nil {
+				return "", errors.New("invalid arguments")
+			}
+
+			found := searchMaterials(materials, args.Query)
+			if len(found) == 0 {
+				return "no matching materials", nil
+			}
+			return strings.Join(found, "\n---\n"), nil
+		},
+	}
+}
+
+// searchMaterials возвращает материалы, в которых встречается больше всего слов запроса
+func searchMaterials(materials []string, query string) []string {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	type match struct {
+		text  string
+		score int
+	}
+	var matches []match
+	for _, material := range materials {
+		lower := strings.ToLower(material)
+		score := 0
+		for _, word := range words {
+			if strings.Contains(lower, word) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{material, score})
+		}
+	}
+
+	// Материалов у вопроса немного, сортировка вставками сохраняет их исходный порядок при равенстве
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].score > matches[j-1].score; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	result := make([]string, 0, maxMaterialResults)
+	for i := 0; i < len(matches) && i < maxMaterialResults; i++ {
+		result = append(result, matches[i].text)
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package aitools
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxExpressionLength ограничивает размер выражения калькулятора
+const maxExpressionLength = 256
+
+// Evaluate вычисляет арифметическое выражение с операциями + - * / ^ и скобками
+func Evaluate(expression string) (float64, error) {
+	if len(expression) > maxExpressionLength {
+		return 0, errors.New("expression is too long")
+	}
+
+	p := &parser{input: []rune(expression)}
+	value, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, errors.New("unexpected character: " + string(p.input[p.pos]))
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, errors.New("result is not a finite number")
+	}
+	return value, nil
+}
+
+// parser - разбор выражения рекурсивным спуском
+type parser struct {
+	input []rune
+	pos   int
+	depth int
+}
+
+// maxExpressionDepth ограничивает вложенность скобок
+const maxExpressionDepth = 32
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek возвращает следующий значимый символ или 0 в конце выражения
+func (p *parser) peek() rune {
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// expr = term { (+|-) term }
+func (p *parser) expr() (float64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			value += right
+		case '-':
+			p.pos++
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			value -= right
+		default:
+			return value, nil
+		}
+	}
+}
+
+// term = power { (*|/) power }
+func (p *parser) term() (float64, error) {
+	value, err := p.power()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		switch p.peek() {
+		case '*':
+			p.pos++
+			right, err := p.power()
+			if err != nil {
+				return 0, err
+			}
+			value *= right
+		case '/':
+			p.pos++
+			right, err := p.power()
+			if err != nil {
+				return 0, err
+			}
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			value /= right
+		default:
+			return value, nil
+		}
+	}
+}
+
+// power = unary [ ^ power ], возведение в степень правоассоциативно
+func (p *parser) power() (float64, error) {
+	base, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+
+	exponent, err := p.power()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+// unary = [ - | + ] unary | primary
+func (p *parser) unary() (float64, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		value, err := p.unary()
+		return -value, err
+	case '+':
+		p.pos++
+		return p.unary()
+	}
+	return p.primary()
+}
+
+// primary = число | ( expr )
+func (p *parser) primary() (float64, error) {
+	if p.peek() == '(' {
+		p.depth++
+		if p.depth > maxExpressionDepth {
+			return 0, errors.New("expression is nested too deeply")
+		}
+		p.pos++
+
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		p.depth--
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.' || p.input[p.pos] == ',') {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.pos == len(p.input) {
+			return 0, errors.New("unexpected end of expression")
+		}
+		return 0, errors.New("unexpected character: " + string(p.input[p.pos]))
+	}
+
+	// Десятичная запятая, как пишут в русскоязычных заданиях
+	number := strings.ReplaceAll(string(p.input[start:p.pos]), ",", ".")
+	return strconv.ParseFloat(number, 64)
+}
@@ -2,11 +2,21 @@ package apiutils
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
+// requestIDHeader - заголовок, в который middleware.RequestID кладет ID запроса
+const requestIDHeader = "X-Request-ID"
+
+// languageHeader - заголовок, в который middleware.Language кладет язык сообщений
+const languageHeader = "Content-Language"
+
 func WriteJSON(w http.ResponseWriter, code int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -15,3 +25,51 @@ func WriteJSON(w http.ResponseWriter, code int, v interface{}) {
 		log.Error().Err(err).Msg("json encode error")
 	}
 }
+
+// IsHTTPS сообщает, пришел ли запрос по HTTPS. X-Forwarded-Proto учитывается,
+// только если сервер стоит за доверенным прокси (TRUST_PROXY_HEADERS=true)
+func IsHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		return strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+	}
+	return false
+}
+
+// ClientIP возвращает IP клиента. Заголовок X-Forwarded-For учитывается,
+// только если сервер стоит за доверенным прокси (TRUST_PROXY_HEADERS=true).
+// Левые записи заголовка присылает сам клиент, поэтому берется запись, которую добавил
+// первый доверенный прокси: TRUSTED_PROXY_HOPS - сколько доверенных прокси стоит перед сервером
+// (по умолчанию 1, то есть самая правая запись)
+func ClientIP(r *http.Request) string {
+	if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		var entries []string
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			for _, entry := range strings.Split(value, ",") {
+				if entry = strings.TrimSpace(entry); entry != "" {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		if len(entries) > 0 {
+			return entries[max(len(entries)-trustedProxyHops(), 0)]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// trustedProxyHops читает TRUSTED_PROXY_HOPS, неверное значение заменяется на 1
+func trustedProxyHops() int {
+	hops, err := strconv.Atoi(os.Getenv("TRUSTED_PROXY_HOPS"))
+	if err != nil || hops < 1 {
+		return 1
+	}
+	return hops
+}
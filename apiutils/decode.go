@@ -0,0 +1,84 @@
+package apiutils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// errTrailingData - после JSON-значения в теле есть что-то еще
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// DecodeJSON строго читает JSON-тело запроса в dst: неизвестные поля и данные после
+// JSON-значения считаются ошибкой, чтобы опечатки в именах полей не проходили молча.
+// Пустое тело возвращает io.EOF
+func DecodeJSON(r *http.Request, dst any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return err
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+	return nil
+}
+
+// decodeErrorDetails - подробности ошибки разбора: поле, к которому она относится
+type decodeErrorDetails struct {
+	Field string `json:"field"`
+}
+
+// WriteDecodeError отвечает на ошибку DecodeJSON: 413 на слишком большое тело,
+// 400 invalid_json с позицией или именем поля на остальные
+func WriteDecodeError(w http.ResponseWriter, err error) {
+	var (
+		tooLarge  *http.MaxBytesError
+		syntax    *json.SyntaxError
+		typeError *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		WriteError(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge,
+			fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit), nil)
+	case errors.Is(err, io.EOF):
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON, "request body is empty", nil)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON, "request body contains truncated json", nil)
+	case errors.As(err, &syntax):
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON, fmt.Sprintf("malformed json at position %d", syntax.Offset), nil)
+	case errors.As(err, &typeError) && typeError.Field != "":
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON,
+			fmt.Sprintf("field %s must be %s", typeError.Field, jsonTypeName(typeError.Type)),
+			decodeErrorDetails{Field: typeError.Field})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не экспортирует тип этой ошибки
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON, fmt.Sprintf("unknown field %s", field), decodeErrorDetails{Field: field})
+	case errors.Is(err, errTrailingData):
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON, err.Error(), nil)
+	default:
+		WriteError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json: "+strings.TrimPrefix(err.Error(), "json: "), nil)
+	}
+}
+
+// jsonTypeName называет ожидаемый тип так, как его видит клиент
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
@@ -0,0 +1,88 @@
+package apiutils
+
+import (
+	"GEEK_back/i18n"
+	"net/http"
+)
+
+// ErrorResponse - единый формат ошибки API. Code - стабильный машиночитаемый код,
+// по которому фронтенд выбирает поведение; Message - текст для человека на языке из Accept-Language
+type ErrorResponse struct {
+	Code      string `json:"code" example:"not_found"`
+	Message   string `json:"message" example:"attempt not found"`
+	Details   any    `json:"details,omitempty" swaggertype:"object"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Общие коды ошибок. Коды предметной области объявлены рядом с обработчиками
+const (
+	CodeBadRequest           = "bad_request"
+	CodeInvalidJSON          = "invalid_json"
+	CodeInvalidParameter     = "invalid_parameter"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeCSRFInvalid          = "csrf_invalid"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+	CodeNotImplemented       = "not_implemented"
+	CodeUnavailable          = "service_unavailable"
+)
+
+// StatusCode возвращает общий код ошибки для HTTP-статуса
+func StatusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// WriteError пишет ошибку в едином формате. Пустой code заменяется общим кодом статуса,
+// request_id берется из заголовка ответа, который выставил middleware.RequestID.
+// message - исходный текст на английском, он переводится на язык из заголовка middleware.Language
+func WriteError(w http.ResponseWriter, status int, code, message string, details any) {
+	if code == "" {
+		code = StatusCode(status)
+	}
+	WriteJSON(w, status, NewError(w, code, message, details))
+}
+
+// NewError собирает тело ошибки, например для события error в потоке SSE
+func NewError(w http.ResponseWriter, code, message string, details any) ErrorResponse {
+	lang := i18n.Supported(w.Header().Get(languageHeader))
+	if validation, ok := details.(ValidationDetails); ok {
+		details = validation.translate(lang)
+	}
+	return ErrorResponse{
+		Code:      code,
+		Message:   i18n.Error(lang, code, message),
+		Details:   details,
+		RequestID: w.Header().Get(requestIDHeader),
+	}
+}
@@ -0,0 +1,211 @@
+package apiutils
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Размер страницы списков по умолчанию и максимальный
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// Page - единый формат ответа списков. NextCursor пуст на последней странице
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ParamError - неверный параметр списка (limit, cursor, sort или фильтр)
+type ParamError struct {
+	Param string
+}
+
+func (e *ParamError) Error() string {
+	return "invalid " + e.Param
+}
+
+// ListParams - параметры страницы из query: limit и offset или cursor, sort.
+// Sort - имя поля, по убыванию при префиксе "-" (sort=-created_at); пусто = порядок хранилища
+type ListParams struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+}
+
+// Sorts - поля, по которым можно сортировать список, и функции сравнения как у cmp.Compare
+type Sorts[T any] map[string]func(a, b T) int
+
+// ParseListParams читает limit, offset, cursor и sort. Курсор важнее offset
+func ParseListParams[T any](r *http.Request, sorts Sorts[T]) (ListParams, error) {
+	query := r.URL.Query()
+	params := ListParams{Limit: DefaultPageLimit}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return params, &ParamError{Param: "limit"}
+		}
+		params.Limit = min(limit, MaxPageLimit)
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		offset, err := decodeCursor(v)
+		if err != nil {
+			return params, &ParamError{Param: "cursor"}
+		}
+		params.Offset = offset
+	} else if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return params, &ParamError{Param: "offset"}
+		}
+		params.Offset = offset
+	}
+
+	if v := query.Get("sort"); v != "" {
+		field, desc := strings.CutPrefix(v, "-")
+		if _, ok := sorts[field]; !ok {
+			return params, &ParamError{Param: "sort"}
+		}
+		params.Sort, params.Desc = field, desc
+	}
+
+	return params, nil
+}
+
+// Paginate сортирует список по params.Sort и возвращает страницу. Сортировка стабильна:
+// при равных значениях сохраняется порядок хранилища, поэтому страницы не пересекаются
+func Paginate[T any](items []T, params ListParams, sorts Sorts[T]) Page[T] {
+	if compare, ok := sorts[params.Sort]; ok {
+		items = slices.Clone(items)
+		slices.SortStableFunc(items, func(a, b T) int {
+			if params.Desc {
+				return compare(b, a)
+			}
+			return compare(a, b)
+		})
+	}
+
+	total := len(items)
+	page := Page[T]{Items: []T{}, Total: total}
+	if params.Offset >= total {
+		return page
+	}
+
+	end := total
+	if params.Limit > 0 && params.Offset+params.Limit < total {
+		end = params.Offset + params.Limit
+		page.NextCursor = encodeCursor(end)
+	}
+	page.Items = items[params.Offset:end]
+	return page
+}
+
+// encodeCursor прячет смещение следующей страницы в непрозрачную строку,
+// чтобы клиенты не строили на нем свою логику
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := strings.CutPrefix(string(raw), "o:")
+	if !ok {
+		return 0, strconv.ErrSyntax
+	}
+	offset, err := strconv.Atoi(v)
+	if err != nil || offset < 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return offset, nil
+}
+
+// Filters читает фильтры списка из query. Первая ошибка разбора запоминается и
+// возвращается из Err, поэтому фильтры можно читать подряд без проверок после каждого
+type Filters struct {
+	query url.Values
+	err   error
+}
+
+// NewFilters создает читатель фильтров запроса
+func NewFilters(r *http.Request) *Filters {
+	return &Filters{query: r.URL.Query()}
+}
+
+// Err возвращает первую ошибку разбора фильтров
+func (f *Filters) Err() error {
+	return f.err
+}
+
+func (f *Filters) fail(name string) {
+	if f.err == nil {
+		f.err = &ParamError{Param: name}
+	}
+}
+
+// String возвращает значение фильтра как есть, пусто = не задан
+func (f *Filters) String(name string) string {
+	return strings.TrimSpace(f.query.Get(name))
+}
+
+// OneOf возвращает значение фильтра из допустимого набора, пусто = не задан
+func (f *Filters) OneOf(name string, allowed ...string) string {
+	v := f.String(name)
+	if v != "" && !slices.Contains(allowed, v) {
+		f.fail(name)
+		return ""
+	}
+	return v
+}
+
+// Uint возвращает числовой фильтр, 0 = не задан
+func (f *Filters) Uint(name string) uint64 {
+	v := f.query.Get(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		f.fail(name)
+	}
+	return n
+}
+
+// Bool возвращает логический фильтр, nil = не задан
+func (f *Filters) Bool(name string) *bool {
+	v := f.query.Get(name)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		f.fail(name)
+		return nil
+	}
+	return &b
+}
+
+// Time возвращает фильтр по времени в RFC3339, нулевое время = не задан
+func (f *Filters) Time(name string) time.Time {
+	v := f.query.Get(name)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		f.fail(name)
+	}
+	return t
+}
@@ -0,0 +1,175 @@
+package apiutils
+
+import (
+	"GEEK_back/i18n"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError - ошибка одного поля запроса. Field - имя поля в JSON (для вложенных - путь
+// вида answers[0].text), Rule - нарушенное правило из тега validate
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"required"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message" example:"email is required"`
+}
+
+// ValidationDetails - подробности ошибки validation_failed: все нарушения сразу
+type ValidationDetails struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// translate возвращает копию подробностей с текстами нарушений на языке lang
+func (d ValidationDetails) translate(lang string) ValidationDetails {
+	fields := make([]FieldError, len(d.Fields))
+	for i, field := range d.Fields {
+		field.Message = i18n.Translate(lang, field.Message)
+		fields[i] = field
+	}
+	return ValidationDetails{Fields: fields}
+}
+
+// validate - общий валидатор; кеширует разобранные теги по типу, поэтому создается один раз
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// В ошибках используем имена полей из JSON, а не из Go
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Validate проверяет структуру по тегам validate и возвращает все нарушения или nil
+func Validate(v any) []FieldError {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		// nil или не структура - ошибка вызывающего кода, а не клиента
+		return []FieldError{{Rule: "invalid", Message: err.Error()}}
+	}
+
+	fields := make([]FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		field := fieldPath(fe.Namespace())
+		param := fe.Param()
+		if crossFieldRules[fe.Tag()] {
+			param = jsonFieldName(reflect.TypeOf(v), fe.StructNamespace(), param)
+		}
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   param,
+			Message: fieldMessage(field, fe.Tag(), param, fe.Kind()),
+		})
+	}
+	return fields
+}
+
+// crossFieldRules - правила, параметр которых - имя соседнего поля в Go
+var crossFieldRules = map[string]bool{
+	"eqfield":          true,
+	"ltefield":         true,
+	"required_without": true,
+}
+
+// jsonFieldName переводит имя соседнего поля из Go в JSON, чтобы клиент видел его так же, как в запросе.
+// structNamespace - путь к проверенному полю в именах Go, начиная с корневой структуры
+func jsonFieldName(root reflect.Type, structNamespace, goName string) string {
+	parent := root
+	path := strings.Split(structNamespace, ".")
+	for _, name := range path[1 : len(path)-1] {
+		parent = elemType(parent)
+		field, ok := parent.FieldByName(strings.SplitN(name, "[", 2)[0])
+		if !ok {
+			return goName
+		}
+		parent = field.Type
+	}
+	parent = elemType(parent)
+	field, ok := parent.FieldByName(goName)
+	if !ok {
+		return goName
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return goName
+}
+
+// elemType снимает указатели и срезы до типа элементов
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t
+}
+
+// fieldPath убирает имя корневой структуры из пути поля
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// fieldMessage формирует понятный текст нарушения для правил, которые используют DTO
+func fieldMessage(field, rule, param string, kind reflect.Kind) string {
+	switch rule {
+	case "required":
+		return field + " is required"
+	case "required_without":
+		return fmt.Sprintf("%s is required when %s is empty", field, param)
+	case "email":
+		return field + " must be a valid email"
+	case "eqfield":
+		return fmt.Sprintf("%s must match %s", field, param)
+	case "ltefield":
+		return fmt.Sprintf("%s must not be greater than %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	case "min", "gte":
+		if unit := lengthUnit(kind); unit != "" {
+			return fmt.Sprintf("%s must contain at least %s %s", field, param, unit)
+		}
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "max", "lte":
+		if unit := lengthUnit(kind); unit != "" {
+			return fmt.Sprintf("%s must contain at most %s %s", field, param, unit)
+		}
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "unique":
+		return field + " must not contain duplicates"
+	}
+	return fmt.Sprintf("%s failed %s validation", field, rule)
+}
+
+// lengthUnit возвращает, в чем min/max измеряют длину поля, или пусто, если проверяется значение
+func lengthUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	}
+	return ""
+}
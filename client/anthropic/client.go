@@ -0,0 +1,322 @@
+package anthropic
+
+import (
+	"GEEK_back/client/llm"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// дефолтные настройки
+const DefaultBaseURL = "https://api.anthropic.com/v1"
+const DefaultTimeout = 60 * time.Second
+const DefaultMaxTokens = 1024
+const APIVersion = "2023-06-01"
+
+// maxStreamLineSize - максимальный размер одной строки SSE
+const maxStreamLineSize = 1 << 20
+
+// Client — клиент Anthropic Messages API. В отличие от Assistants API у Anthropic нет
+// серверных тредов, поэтому история диалогов хранится в памяти (llm.History)
+type Client struct {
+	APIKey       string
+	Model        string
+	SystemPrompt string
+	MaxTokens    int
+	BaseURL      string
+	HTTP         *http.Client
+	StreamHTTP   *http.Client // без общего таймаута, для стриминга ответов
+	Retry        llm.RetryPolicy
+
+	history *llm.History
+}
+
+var _ llm.Provider = (*Client)(nil)
+
+type messagesRequest struct {
+	Model       string    `json:"model"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	System      string    `json:"system,omitempty"`
+	Messages    []message `json:"messages"`
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+// message - сообщение запроса. Content - строка или, если приложены изображения, список блоков
+type message struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+type contentBlock struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	Source *imageSource `json:"source,omitempty"`
+}
+
+type imageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type messagesResponse struct {
+	ID      string `json:"id"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage usage `json:"usage"`
+}
+
+// streamEvent - событие стриминга Messages API (нужные нам поля)
+type streamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string `json:"id"`
+		Usage usage  `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage usage `json:"usage"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewClient(apiKey, model, systemPrompt string) *Client {
+	return &Client{
+		APIKey:       apiKey,
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		MaxTokens:    DefaultMaxTokens,
+		BaseURL:      DefaultBaseURL,
+		HTTP:         &http.Client{Timeout: DefaultTimeout},
+		StreamHTTP:   &http.Client{},
+		Retry:        llm.DefaultRetryPolicy(),
+		history:      llm.NewHistory(),
+	}
+}
+
+// Name возвращает имя провайдера
+func (c *Client) Name() string {
+	return "anthropic"
+}
+
+// CreateThread создает пустой диалог в памяти
+func (c *Client) CreateThread(ctx context.Context) (string, error) {
+	return c.history.Create()
+}
+
+// GetThread возвращает метаданные диалога из истории
+func (c *Client) GetThread(ctx context.Context, threadID string) (*llm.Thread, error) {
+	return c.history.Get(threadID)
+}
+
+// DeleteThread удаляет историю диалога
+func (c *Client) DeleteThread(ctx context.Context, threadID string) error {
+	return c.history.Delete(threadID)
+}
+
+// Send отправляет сообщение с историей диалога и возвращает полный ответ
+func (c *Client) Send(ctx context.Context, threadID, content string, opts llm.Options) (*llm.Reply, error) {
+	history, err := c.history.With(threadID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, c.HTTP, messagesRequest{
+		Model:       c.model(opts),
+		MaxTokens:   c.MaxTokens,
+		Temperature: opts.Temperature,
+		System:      c.system(opts),
+		Messages:    messages(history, opts.Images),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out messagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	c.history.Remember(threadID, llm.HistoryText(content, opts.Images), text.String())
+
+	return &llm.Reply{Text: text.String(), RunID: out.ID, Model: c.model(opts), Usage: out.Usage.toLLM()}, nil
+}
+
+// Stream отправляет сообщение и передает ответ в onDelta по мере генерации
+func (c *Client) Stream(ctx context.Context, threadID, content string, opts llm.Options, onDelta func(text string) error) (*llm.Reply, error) {
+	history, err := c.history.With(threadID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, c.StreamHTTP, messagesRequest{
+		Model:       c.model(opts),
+		MaxTokens:   c.MaxTokens,
+		Temperature: opts.Temperature,
+		System:      c.system(opts),
+		Messages:    messages(history, opts.Images),
+		Stream:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	var (
+		text    strings.Builder
+		id      string
+		tokens  usage
+		stopped bool
+	)
+	for !stopped && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return nil, err
+		}
+
+		switch event.Type {
+		case "message_start":
+			id = event.Message.ID
+			tokens.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				continue
+			}
+			text.WriteString(event.Delta.Text)
+			if err := onDelta(event.Delta.Text); err != nil {
+				return nil, err
+			}
+		case "message_delta":
+			tokens.OutputTokens = event.Usage.OutputTokens
+		case "message_stop":
+			stopped = true
+		case "error":
+			return nil, fmt.Errorf("anthropic stream error: %s", event.Error.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !stopped {
+		return nil, fmt.Errorf("stream ended before message completed")
+	}
+
+	c.history.Remember(threadID, llm.HistoryText(content, opts.Images), text.String())
+
+	return &llm.Reply{Text: text.String(), RunID: id, Model: c.model(opts), Usage: tokens.toLLM()}, nil
+}
+
+// system возвращает системную инструкцию вместе с контекстом диалога
+func (c *Client) system(opts llm.Options) string {
+	switch {
+	case opts.Context == "":
+		return c.SystemPrompt
+	case c.SystemPrompt == "":
+		return opts.Context
+	default:
+		return c.SystemPrompt + "\n\n" + opts.Context
+	}
+}
+
+// model возвращает модель теста или модель по умолчанию
+func (c *Client) model(opts llm.Options) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return c.Model
+}
+
+func (c *Client) do(ctx context.Context, client *http.Client, payload messagesRequest) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-api-key", c.APIKey)
+	req.Header.Set("anthropic-version", APIVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Retry.Do(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("anthropic http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	return resp, nil
+}
+
+func (u usage) toLLM() *llm.Usage {
+	return &llm.Usage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.InputTokens + u.OutputTokens,
+	}
+}
+
+// messages переводит историю в сообщения запроса и прикладывает изображения к последнему сообщению
+func messages(history []llm.Message, images []llm.Image) []message {
+	result := make([]message, len(history))
+	for i, m := range history {
+		result[i] = message{Role: m.Role, Content: m.Content}
+	}
+
+	if len(images) > 0 && len(result) > 0 {
+		last := &result[len(result)-1]
+		blocks := make([]contentBlock, 0, len(images)+1)
+		for _, image := range images {
+			blocks = append(blocks, contentBlock{
+				Type:   "image",
+				Source: &imageSource{Type: "base64", MediaType: image.MIMEType, Data: image.Base64()},
+			})
+		}
+		if text := history[len(history)-1].Content; text != "" {
+			blocks = append(blocks, contentBlock{Type: "text", Text: text})
+		}
+		last.Content = blocks
+	}
+
+	return result
+}
@@ -0,0 +1,176 @@
+package fake
+
+import (
+	"GEEK_back/client/llm"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// EchoPrefix - начало ответа в режиме эха
+const EchoPrefix = "echo: "
+
+// Model - имя модели в ответах, по нему расход фейкового провайдера не тарифицируется
+const Model = "fake"
+
+// Response - заготовленный ответ: возвращается, если сообщение содержит Match (без учета регистра).
+// Пустой Match подходит к любому сообщению
+type Response struct {
+	Match string `json:"match"`
+	Reply string `json:"reply"`
+}
+
+// Client - детерминированный провайдер для разработки и интеграционных тестов без ключа и сети.
+// Отвечает мгновенно первым подходящим заготовленным ответом, а если такого нет - эхом сообщения
+// (с числом приложенных изображений).
+// История диалогов хранится в памяти, как у провайдеров без серверных тредов
+type Client struct {
+	Responses []Response
+
+	history *llm.History
+	runs    atomic.Uint64
+}
+
+var _ llm.Provider = (*Client)(nil)
+
+func NewClient(responses []Response) *Client {
+	return &Client{
+		Responses: responses,
+		history:   llm.NewHistory(),
+	}
+}
+
+// LoadResponses читает заготовленные ответы из JSON-файла вида [{"match": "...", "reply": "..."}]
+func LoadResponses(path string) ([]Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []Response
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, err
+	}
+	for i, response := range responses {
+		if response.Reply == "" {
+			return nil, fmt.Errorf("response %d: reply is empty", i)
+		}
+	}
+
+	return responses, nil
+}
+
+// Name возвращает имя провайдера
+func (c *Client) Name() string {
+	return "fake"
+}
+
+// CreateThread создает пустой диалог в памяти
+func (c *Client) CreateThread(ctx context.Context) (string, error) {
+	return c.history.Create()
+}
+
+// GetThread возвращает метаданные диалога из истории
+func (c *Client) GetThread(ctx context.Context, threadID string) (*llm.Thread, error) {
+	return c.history.Get(threadID)
+}
+
+// DeleteThread удаляет историю диалога
+func (c *Client) DeleteThread(ctx context.Context, threadID string) error {
+	return c.history.Delete(threadID)
+}
+
+// Send сразу возвращает ответ на сообщение
+func (c *Client) Send(ctx context.Context, threadID, content string, opts llm.Options) (*llm.Reply, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	history, err := c.history.With(threadID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	content = llm.HistoryText(content, opts.Images)
+	text := c.reply(content)
+	c.history.Remember(threadID, content, text)
+
+	return &llm.Reply{
+		Text:  text,
+		RunID: fmt.Sprintf("run_fake_%d", c.runs.Add(1)),
+		Model: Model,
+		Usage: usage(opts.Context, history, text),
+	}, nil
+}
+
+// Stream передает ответ в onDelta по словам
+func (c *Client) Stream(ctx context.Context, threadID, content string, opts llm.Options, onDelta func(text string) error) (*llm.Reply, error) {
+	history, err := c.history.With(threadID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	content = llm.HistoryText(content, opts.Images)
+	text := c.reply(content)
+	for _, delta := range splitWords(text) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	c.history.Remember(threadID, content, text)
+
+	return &llm.Reply{
+		Text:  text,
+		RunID: fmt.Sprintf("run_fake_%d", c.runs.Add(1)),
+		Model: Model,
+		Usage: usage(opts.Context, history, text),
+	}, nil
+}
+
+// reply выбирает заготовленный ответ или возвращает эхо
+func (c *Client) reply(content string) string {
+	lower := strings.ToLower(content)
+	for _, response := range c.Responses {
+		if strings.Contains(lower, strings.ToLower(response.Match)) {
+			return response.Reply
+		}
+	}
+	return EchoPrefix + content
+}
+
+// splitWords режет текст на куски по словам, сохраняя пробелы: склеенные куски дают исходный текст
+func splitWords(text string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i] == ' ' && text[i-1] != ' ' {
+			parts = append(parts, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		parts = append(parts, text[start:])
+	}
+	return parts
+}
+
+// usage считает токены как слова, чтобы квоты и отчеты о расходе работали и без настоящей модели
+func usage(system string, history []llm.Message, reply string) *llm.Usage {
+	prompt := len(strings.Fields(system))
+	for _, message := range history {
+		prompt += len(strings.Fields(message.Content))
+	}
+	completion := len(strings.Fields(reply))
+
+	return &llm.Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
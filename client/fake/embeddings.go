@@ -0,0 +1,33 @@
+package fake
+
+import (
+	"GEEK_back/client/llm"
+	"context"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// embeddingSize - размерность фейковых эмбеддингов
+const embeddingSize = 256
+
+var _ llm.Embedder = (*Client)(nil)
+
+// Embed строит детерминированные эмбеддинги по словам текста: тексты с одинаковыми словами
+// близки независимо от порядка и регистра, тексты без общих слов - нет
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector := make([]float64, embeddingSize)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(word))
+			vector[h.Sum32()%embeddingSize]++
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
@@ -0,0 +1,242 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable возвращается, пока предохранитель разомкнут после серии сбоев провайдера
+var ErrUnavailable = errors.New("assistant temporarily unavailable")
+
+// Состояния предохранителя
+const (
+	BreakerClosed   = "closed"    // запросы идут к провайдеру
+	BreakerOpen     = "open"      // провайдер считается недоступным, запросы сразу отклоняются
+	BreakerHalfOpen = "half_open" // пропускается один пробный запрос
+)
+
+// Настройки предохранителя по умолчанию
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// BreakerStatus - текущее состояние предохранителя
+type BreakerStatus struct {
+	State      string        `json:"state"`
+	Failures   int           `json:"failures"`
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Breaker - предохранитель вокруг провайдера: после Threshold сбоев подряд размыкается на Cooldown
+// и сразу возвращает ErrUnavailable, чтобы запросы не висели до таймаута. Затем пропускает
+// один пробный запрос и замыкается, если он успешен
+type Breaker struct {
+	Provider  Provider
+	Threshold int
+	Cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+var _ Provider = (*Breaker)(nil)
+var _ FileStore = (*Breaker)(nil)
+var _ Embedder = (*Breaker)(nil)
+
+func NewBreaker(p Provider, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		Provider:  p,
+		Threshold: threshold,
+		Cooldown:  cooldown,
+	}
+}
+
+// Name возвращает имя обернутого провайдера
+func (b *Breaker) Name() string {
+	return b.Provider.Name()
+}
+
+// Status возвращает состояние предохранителя
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state(), Failures: b.failures}
+	if status.State == BreakerOpen {
+		status.RetryAfter = time.Until(b.openUntil)
+	}
+	return status
+}
+
+func (b *Breaker) CreateThread(ctx context.Context) (string, error) {
+	if err := b.allow(); err != nil {
+		return "", err
+	}
+	threadID, err := b.Provider.CreateThread(ctx)
+	b.record(ctx, err)
+	return threadID, err
+}
+
+func (b *Breaker) Send(ctx context.Context, threadID, message string, opts Options) (*Reply, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	reply, err := b.Provider.Send(ctx, threadID, message, opts)
+	b.record(ctx, err)
+	return reply, err
+}
+
+func (b *Breaker) Stream(ctx context.Context, threadID, message string, opts Options, onDelta func(text string) error) (*Reply, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+
+	// Ошибка записи клиенту - не сбой провайдера
+	var deltaErr error
+	reply, err := b.Provider.Stream(ctx, threadID, message, opts, func(text string) error {
+		deltaErr = onDelta(text)
+		return deltaErr
+	})
+	if err != nil && deltaErr != nil && errors.Is(err, deltaErr) {
+		b.record(ctx, nil)
+		return reply, err
+	}
+
+	b.record(ctx, err)
+	return reply, err
+}
+
+func (b *Breaker) GetThread(ctx context.Context, threadID string) (*Thread, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	thread, err := b.Provider.GetThread(ctx, threadID)
+	b.record(ctx, err)
+	return thread, err
+}
+
+func (b *Breaker) DeleteThread(ctx context.Context, threadID string) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := b.Provider.DeleteThread(ctx, threadID)
+	b.record(ctx, err)
+	return err
+}
+
+// state возвращает состояние, вызывается под блокировкой
+func (b *Breaker) state() string {
+	switch {
+	case b.failures < b.Threshold:
+		return BreakerClosed
+	case time.Now().Before(b.openUntil):
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// allow решает, пропустить ли запрос к провайдеру
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state() {
+	case BreakerOpen:
+		return ErrUnavailable
+	case BreakerHalfOpen:
+		// Пока идет пробный запрос, остальные отклоняем
+		if b.probing {
+			return ErrUnavailable
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// record учитывает результат запроса
+func (b *Breaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	switch {
+	case err == nil, errors.Is(err, ErrThreadNotFound):
+		b.failures = 0
+	case ctx.Err() != nil && errors.Is(err, context.Canceled):
+		// Клиент ушел сам - провайдер тут ни при чем
+	default:
+		b.failures++
+		if b.failures >= b.Threshold {
+			b.openUntil = time.Now().Add(b.Cooldown)
+		}
+	}
+}
+
+// fileStore возвращает хранилище файлов обернутого провайдера
+func (b *Breaker) fileStore() (FileStore, error) {
+	files, ok := b.Provider.(FileStore)
+	if !ok {
+		return nil, ErrFilesNotSupported
+	}
+	return files, nil
+}
+
+func (b *Breaker) UploadAttachment(ctx context.Context, filename, contentType string, data []byte) (string, error) {
+	files, err := b.fileStore()
+	if err != nil {
+		return "", err
+	}
+	if err := b.allow(); err != nil {
+		return "", err
+	}
+	fileID, err := files.UploadAttachment(ctx, filename, contentType, data)
+	b.record(ctx, err)
+	return fileID, err
+}
+
+func (b *Breaker) AttachFile(ctx context.Context, threadID, fileID, filename string) error {
+	files, err := b.fileStore()
+	if err != nil {
+		return err
+	}
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err = files.AttachFile(ctx, threadID, fileID, filename)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) DeleteFile(ctx context.Context, fileID string) error {
+	files, err := b.fileStore()
+	if err != nil {
+		return err
+	}
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err = files.DeleteFile(ctx, fileID)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	embedder, ok := b.Provider.(Embedder)
+	if !ok {
+		return nil, ErrEmbeddingsNotSupported
+	}
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	b.record(ctx, err)
+	return vectors, err
+}
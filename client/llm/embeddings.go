@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// ErrEmbeddingsNotSupported возвращается, если провайдер не умеет строить эмбеддинги текста
+var ErrEmbeddingsNotSupported = errors.New("provider does not support embeddings")
+
+// Embedder - провайдер, превращающий тексты в векторы для сравнения по смыслу
+type Embedder interface {
+	// Embed возвращает по вектору на каждый текст в том же порядке
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// CosineSimilarity возвращает косинусную близость векторов: 1 - совпадают по направлению,
+// 0 - не связаны. Для векторов разной длины или нулевых возвращает 0
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
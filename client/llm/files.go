@@ -0,0 +1,19 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrFilesNotSupported возвращается, если провайдер не хранит файлы и не умеет прикреплять их к диалогу
+var ErrFilesNotSupported = errors.New("provider does not support file attachments")
+
+// FileStore - провайдер с хранилищем файлов, которые можно прикреплять к диалогам (OpenAI Assistants)
+type FileStore interface {
+	// UploadAttachment загружает файл и возвращает его ID у провайдера
+	UploadAttachment(ctx context.Context, filename, contentType string, data []byte) (string, error)
+	// AttachFile прикрепляет загруженный файл к диалогу
+	AttachFile(ctx context.Context, threadID, fileID, filename string) error
+	// DeleteFile удаляет файл у провайдера
+	DeleteFile(ctx context.Context, fileID string) error
+}
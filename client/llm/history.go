@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Message - сообщение диалога для провайдеров без серверных тредов
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// History хранит историю диалогов в памяти для провайдеров, у которых нет серверных тредов
+// (Chat Completions, Anthropic Messages): каждый запрос отправляет историю целиком
+type History struct {
+	mu      sync.Mutex
+	threads map[string][]Message
+}
+
+func NewHistory() *History {
+	return &History{threads: make(map[string][]Message)}
+}
+
+// Create создает пустой диалог и возвращает его ID
+func (h *History) Create() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	threadID := "thread_" + hex.EncodeToString(b)
+
+	h.mu.Lock()
+	h.threads[threadID] = make([]Message, 0)
+	h.mu.Unlock()
+
+	return threadID, nil
+}
+
+// With возвращает копию истории диалога с новым сообщением пользователя в конце
+func (h *History) With(threadID, content string) ([]Message, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	thread, ok := h.threads[threadID]
+	if !ok {
+		return nil, ErrThreadNotFound
+	}
+
+	history := make([]Message, len(thread), len(thread)+1)
+	copy(history, thread)
+
+	return append(history, Message{Role: "user", Content: content}), nil
+}
+
+// Remember сохраняет обмен сообщениями в истории. Неудачные запросы в историю не попадают,
+// чтобы в ней не было двух сообщений пользователя подряд
+func (h *History) Remember(threadID, content, reply string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.threads[threadID] = append(h.threads[threadID],
+		Message{Role: "user", Content: content},
+		Message{Role: "assistant", Content: reply},
+	)
+}
+
+// Get возвращает метаданные диалога
+func (h *History) Get(threadID string) (*Thread, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	thread, ok := h.threads[threadID]
+	if !ok {
+		return nil, ErrThreadNotFound
+	}
+
+	return &Thread{ID: threadID, Messages: len(thread)}, nil
+}
+
+// Delete удаляет диалог из истории
+func (h *History) Delete(threadID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.threads[threadID]; !ok {
+		return ErrThreadNotFound
+	}
+	delete(h.threads, threadID)
+
+	return nil
+}
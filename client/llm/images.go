@@ -0,0 +1,31 @@
+package llm
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// Image - изображение, приложенное к сообщению пользователя (например, фото решения)
+type Image struct {
+	MIMEType string
+	Data     []byte
+}
+
+// DataURL возвращает изображение в виде data URL для провайдеров, принимающих картинки в теле запроса
+func (i Image) DataURL() string {
+	return "data:" + i.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(i.Data)
+}
+
+// Base64 возвращает содержимое изображения в base64
+func (i Image) Base64() string {
+	return base64.StdEncoding.EncodeToString(i.Data)
+}
+
+// HistoryText возвращает текст сообщения для истории диалога. Сами изображения в историю не попадают,
+// чтобы не отправлять их заново с каждым запросом, но модель видит, что они были
+func HistoryText(content string, images []Image) string {
+	if len(images) == 0 {
+		return content
+	}
+	return content + "\n[приложено изображений: " + strconv.Itoa(len(images)) + "]"
+}
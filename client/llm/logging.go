@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// maxLoggedBody - сколько байт тела запроса и ответа попадает в лог
+const maxLoggedBody = 4 << 10
+
+// RequestLog - запись о запросе к провайдеру. Ключи и персональные данные вырезаны
+type RequestLog struct {
+	Time         time.Time `json:"time"`
+	Provider     string    `json:"provider"`
+	Method       string    `json:"method"`
+	URL          string    `json:"url"`
+	Status       int       `json:"status,omitempty"`
+	LatencyMs    int64     `json:"latency_ms"`
+	Error        string    `json:"error,omitempty"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+}
+
+// RequestLogger пишет запросы к провайдерам в лог и, если задан Sink, сохраняет их построчно в JSON
+type RequestLogger struct {
+	Bodies bool      // логировать тела запросов и ответов (после вырезания секретов)
+	Sink   io.Writer // куда сохранять записи, nil = только лог
+
+	mu sync.Mutex
+}
+
+// Instrument подключает логирование ко всем запросам HTTP-клиента
+func (l *RequestLogger) Instrument(client *http.Client, provider string) {
+	if l == nil || client == nil {
+		return
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &loggingTransport{base: base, provider: provider, logger: l}
+}
+
+type loggingTransport struct {
+	base     http.RoundTripper
+	provider string
+	logger   *RequestLogger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := RequestLog{
+		Time:     time.Now().UTC(),
+		Provider: t.provider,
+		Method:   req.Method,
+		URL:      Redact(req.URL.Redacted()),
+	}
+
+	if t.logger.Bodies && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(io.LimitReader(body, maxLoggedBody))
+			body.Close()
+			entry.RequestBody = Redact(string(b))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	entry.LatencyMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		entry.Error = err.Error()
+		t.logger.write(entry)
+		return nil, err
+	}
+	entry.Status = resp.StatusCode
+
+	// Тело ошибки читаем всегда - именно в нем причина 4xx/5xx. Стримы не трогаем
+	streaming := strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+	if !streaming && (t.logger.Bodies || resp.StatusCode >= 400) {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(b), resp.Body), resp.Body}
+		entry.ResponseBody = Redact(string(b))
+	}
+
+	t.logger.write(entry)
+	return resp, nil
+}
+
+// readCloser возвращает прочитанное начало тела и закрывает исходное тело
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// write пишет запись в лог и в Sink
+func (l *RequestLogger) write(entry RequestLog) {
+	var event *zerolog.Event
+	switch {
+	case entry.Error != "" || entry.Status >= 400:
+		event = log.Warn()
+	default:
+		event = log.Debug()
+	}
+	event = event.Str("provider", entry.Provider).
+		Str("method", entry.Method).
+		Str("url", entry.URL).
+		Int("status", entry.Status).
+		Int64("latency_ms", entry.LatencyMs)
+	if entry.Error != "" {
+		event = event.Str("error", entry.Error)
+	}
+	if entry.RequestBody != "" {
+		event = event.Str("request_body", entry.RequestBody)
+	}
+	if entry.ResponseBody != "" {
+		event = event.Str("response_body", entry.ResponseBody)
+	}
+	event.Msg("ai provider request")
+
+	if l.Sink == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.Sink.Write(append(line, '\n')); err != nil {
+		log.Warn().Err(err).Msg("failed to persist ai request log")
+	}
+}
+
+// Правила вырезания секретов и персональных данных из логов
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)"(api[_-]?key|authorization|x-api-key|password|token)"\s*:\s*"[^"]*"`), `"$1":"***"`},
+	{regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]+`), "Bearer ***"},
+	{regexp.MustCompile(`sk-[A-Za-z0-9_\-]{8,}`), "sk-***"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), "***@***"},
+	{regexp.MustCompile(`\+?[78][\s\-(]*\d{3}[\s\-)]*\d{3}[\s\-]*\d{2}[\s\-]*\d{2}\b`), "***phone***"},
+}
+
+// Redact вырезает из текста ключи API, токены, email и телефоны
+func Redact(s string) string {
+	// Обрезанное тело может закончиться посреди символа UTF-8
+	s = strings.ToValidUTF8(s, "")
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// EndpointPath заменяет ID в пути запроса к провайдеру (thread_abc123, run_abc123, file-abc123) на :id,
+// чтобы путь можно было использовать как метку метрик и имя span
+func EndpointPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 3 && strings.ContainsAny(segment, "0123456789") {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
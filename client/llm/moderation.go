@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// ModerationResult - результат проверки сообщения
+type ModerationResult struct {
+	Flagged    bool     `json:"flagged"`
+	Categories []string `json:"categories,omitempty"`
+	Source     string   `json:"source"` // какой модератор пометил сообщение
+}
+
+// Moderator проверяет сообщения студента до отправки ассистенту
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (*ModerationResult, error)
+}
+
+// DefaultBlockedPatterns - правила по умолчанию: попытки вытащить эталонные ответы и инструкции ассистента
+var DefaultBlockedPatterns = []string{
+	`(?i)эталонн\S* ответ`,
+	`(?i)ключ\S* (к )?ответ`,
+	`(?i)answer\s*key`,
+	`(?i)(системн\S*|твои|свои) (промпт|инструкци)`,
+	`(?i)system\s*prompt`,
+	`(?i)ignore (all |the )?(previous|above) instructions`,
+	`(?i)игнорируй (все )?(предыдущие|прошлые) инструкции`,
+}
+
+// KeywordModerator помечает сообщения, совпадающие с регулярными выражениями
+type KeywordModerator struct {
+	patterns []*regexp.Regexp
+}
+
+// NewKeywordModerator компилирует правила модерации
+func NewKeywordModerator(patterns []string) (*KeywordModerator, error) {
+	m := &KeywordModerator{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+func (m *KeywordModerator) Moderate(ctx context.Context, text string) (*ModerationResult, error) {
+	result := &ModerationResult{Source: "keywords"}
+	for _, re := range m.patterns {
+		if re.MatchString(text) {
+			result.Flagged = true
+			result.Categories = append(result.Categories, re.String())
+		}
+	}
+	return result, nil
+}
+
+// ModeratorChain проверяет сообщение всеми модераторами по очереди до первого срабатывания
+type ModeratorChain []Moderator
+
+func (c ModeratorChain) Moderate(ctx context.Context, text string) (*ModerationResult, error) {
+	for _, m := range c {
+		result, err := m.Moderate(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		if result.Flagged {
+			return result, nil
+		}
+	}
+	return &ModerationResult{}, nil
+}
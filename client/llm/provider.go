@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrThreadNotFound возвращается провайдером, если диалог с таким ID ему неизвестен
+var ErrThreadNotFound = errors.New("thread not found")
+
+// Usage - расход токенов на один ответ модели
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Reply - ответ модели на сообщение пользователя
+type Reply struct {
+	Text  string `json:"text"`
+	RunID string `json:"run_id,omitempty"` // идентификатор запуска у провайдера, если он есть
+	Model string `json:"model,omitempty"`  // модель, которая ответила, если провайдер ее сообщает
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// Thread - метаданные диалога у провайдера
+type Thread struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Messages  int       `json:"messages,omitempty"` // число сообщений, если провайдер его знает
+}
+
+// Options - настройки ассистента для конкретного теста или вопроса. Пустые поля означают
+// настройки провайдера по умолчанию
+type Options struct {
+	AssistantID string   // ассистент OpenAI Assistants, для остальных провайдеров игнорируется
+	Model       string   // модель
+	Temperature *float64 // температура выборки
+	Context     string   // контекст диалога (вопрос и правила помощи), передается как системная инструкция
+	Tools       []Tool   // серверные функции ассистента, поддерживаются только OpenAI Assistants
+	Images      []Image  // изображения, приложенные к сообщению
+}
+
+// Provider - бэкенд диалога с ассистентом (OpenAI Assistants, Anthropic и т.д.)
+type Provider interface {
+	// Name возвращает имя провайдера для логов
+	Name() string
+	// CreateThread создает новый диалог и возвращает его ID
+	CreateThread(ctx context.Context) (string, error)
+	// Send отправляет сообщение в диалог и дожидается полного ответа
+	Send(ctx context.Context, threadID, message string, opts Options) (*Reply, error)
+	// Stream отправляет сообщение и передает ответ в onDelta по мере генерации.
+	// Ошибка onDelta прерывает генерацию
+	Stream(ctx context.Context, threadID, message string, opts Options, onDelta func(text string) error) (*Reply, error)
+	// GetThread возвращает метаданные диалога. Для неизвестного диалога возвращает ErrThreadNotFound
+	GetThread(ctx context.Context, threadID string) (*Thread, error)
+	// DeleteThread удаляет диалог у провайдера. Для неизвестного диалога возвращает ErrThreadNotFound
+	DeleteThread(ctx context.Context, threadID string) error
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Настройки повторов по умолчанию
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 8 * time.Second
+)
+
+// RetryPolicy - повторы запросов к провайдеру при временных сбоях: сетевых ошибках, 429 и 5xx.
+// Пауза растет экспоненциально со случайным разбросом, заголовок Retry-After имеет приоритет
+type RetryPolicy struct {
+	MaxRetries int           // сколько раз повторять после первой попытки, 0 = не повторять
+	BaseDelay  time.Duration // пауза перед первым повтором
+	MaxDelay   time.Duration // максимальная пауза; если Retry-After больше, не повторяем
+}
+
+// DefaultRetryPolicy возвращает политику повторов по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// Do выполняет запрос с повторами. Тело запроса перечитывается через req.GetBody,
+// поэтому запрос должен быть создан из bytes.Reader или без тела
+func (p RetryPolicy) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := client.Do(req)
+		if attempt >= p.MaxRetries || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		delay := p.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if retryAfter > p.MaxDelay {
+					// Провайдер просит ждать дольше, чем мы готовы держать запрос студента
+					return resp, nil
+				}
+				delay = retryAfter
+			}
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff возвращает паузу перед повтором с полным случайным разбросом
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(delay)) + 1)
+}
+
+// retryable определяет, имеет ли смысл повторять запрос
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Отмена запроса клиентом или истекший дедлайн повторять бессмысленно
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolHandler выполняет функцию с аргументами от модели и возвращает результат текстом
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// Tool - серверная функция, которую ассистент может вызвать во время ответа
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage // JSON Schema аргументов
+	Handler     ToolHandler
+}
+
+// ToolCall - вызов функции, запрошенный моделью
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+// CallTool выполняет вызов модели. Неизвестная функция и ошибка обработчика возвращаются
+// модели текстом, чтобы она могла продолжить ответ
+func CallTool(ctx context.Context, tools []Tool, call ToolCall) string {
+	for _, tool := range tools {
+		if tool.Name != call.Name {
+			continue
+		}
+
+		output, err := tool.Handler(ctx, call.Arguments)
+		if err != nil {
+			return fmt.Sprintf("error: %s", err)
+		}
+		return output
+	}
+
+	return fmt.Sprintf("error: unknown function %q", call.Name)
+}
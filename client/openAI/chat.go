@@ -0,0 +1,277 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ChatClient — провайдер на стандартном Chat Completions API. Не зависит от беты Assistants v2:
+// история диалогов хранится на нашем сервере и отправляется целиком в каждом запросе
+type ChatClient struct {
+	APIKey       string
+	Model        string
+	SystemPrompt string
+	BaseURL      string
+	HTTP         *http.Client
+	StreamHTTP   *http.Client // без общего таймаута, для стриминга ответов
+	Retry        llm.RetryPolicy
+
+	EmbeddingModel string // модель для Embed, пусто = DefaultEmbeddingModel
+
+	history *llm.History
+}
+
+var _ llm.Provider = (*ChatClient)(nil)
+
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// chatMessage - сообщение запроса. Content - строка или, если приложены изображения, список частей
+type chatMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatResponse struct {
+	ID      string `json:"id"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+}
+
+func NewChatClient(apiKey, model, systemPrompt string) *ChatClient {
+	return &ChatClient{
+		APIKey:       apiKey,
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		BaseURL:      DefaultBaseURL,
+		HTTP:         &http.Client{Timeout: runWaitTimeout},
+		StreamHTTP:   &http.Client{},
+		Retry:        llm.DefaultRetryPolicy(),
+		history:      llm.NewHistory(),
+	}
+}
+
+// Name возвращает имя провайдера
+func (c *ChatClient) Name() string {
+	return "openai-chat"
+}
+
+// CreateThread создает пустой диалог на нашем сервере
+func (c *ChatClient) CreateThread(ctx context.Context) (string, error) {
+	return c.history.Create()
+}
+
+// GetThread возвращает метаданные диалога из истории
+func (c *ChatClient) GetThread(ctx context.Context, threadID string) (*llm.Thread, error) {
+	return c.history.Get(threadID)
+}
+
+// DeleteThread удаляет историю диалога
+func (c *ChatClient) DeleteThread(ctx context.Context, threadID string) error {
+	return c.history.Delete(threadID)
+}
+
+// Send отправляет историю диалога с новым сообщением и возвращает ответ модели
+func (c *ChatClient) Send(ctx context.Context, threadID, content string, opts llm.Options) (*llm.Reply, error) {
+	messages, err := c.messages(threadID, content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, c.HTTP, chatRequest{
+		Model:       c.model(opts),
+		Messages:    messages,
+		Temperature: opts.Temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Choices) == 0 {
+		return nil, errors.New("no response from model")
+	}
+
+	text := out.Choices[0].Message.Content
+	c.history.Remember(threadID, llm.HistoryText(content, opts.Images), text)
+
+	return &llm.Reply{Text: text, RunID: out.ID, Model: c.model(opts), Usage: out.Usage.toLLM()}, nil
+}
+
+// Stream отправляет историю диалога и передает ответ в onDelta по мере генерации
+func (c *ChatClient) Stream(ctx context.Context, threadID, content string, opts llm.Options, onDelta func(text string) error) (*llm.Reply, error) {
+	messages, err := c.messages(threadID, content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, c.StreamHTTP, chatRequest{
+		Model:         c.model(opts),
+		Messages:      messages,
+		Temperature:   opts.Temperature,
+		Stream:        true,
+		StreamOptions: &streamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	var (
+		text  strings.Builder
+		id    string
+		usage *Usage
+		done  bool
+	)
+	for !done && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+
+		var chunk chatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, err
+		}
+		id = chunk.ID
+		// Расход токенов приходит отдельным последним чанком без choices
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			text.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !done {
+		return nil, errors.New("stream ended before completion finished")
+	}
+
+	c.history.Remember(threadID, llm.HistoryText(content, opts.Images), text.String())
+
+	return &llm.Reply{Text: text.String(), RunID: id, Model: c.model(opts), Usage: usage.toLLM()}, nil
+}
+
+// model возвращает модель теста или модель по умолчанию
+func (c *ChatClient) model(opts llm.Options) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return c.Model
+}
+
+// messages собирает запрос: системная инструкция, контекст диалога, история и новое сообщение
+// с приложенными изображениями
+func (c *ChatClient) messages(threadID, content string, opts llm.Options) ([]chatMessage, error) {
+	history, err := c.history.With(threadID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]chatMessage, 0, len(history)+2)
+	if c.SystemPrompt != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: c.SystemPrompt})
+	}
+	if opts.Context != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: opts.Context})
+	}
+	for _, message := range history {
+		messages = append(messages, chatMessage{Role: message.Role, Content: message.Content})
+	}
+
+	if len(opts.Images) > 0 {
+		parts := make([]contentPart, 0, len(opts.Images)+1)
+		if content != "" {
+			parts = append(parts, contentPart{Type: "text", Text: content})
+		}
+		for _, image := range opts.Images {
+			parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: image.DataURL()}})
+		}
+		messages[len(messages)-1].Content = parts
+	}
+
+	return messages, nil
+}
+
+func (c *ChatClient) do(ctx context.Context, client *http.Client, payload chatRequest) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Retry.Do(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	return resp, nil
+}
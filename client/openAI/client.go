@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"GEEK_back/client/llm"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -21,6 +22,10 @@ type Client struct {
 	AssistantID string
 	BaseURL     string
 	HTTP        *http.Client
+	StreamHTTP  *http.Client // без общего таймаута, для стриминга ответов
+	Retry       llm.RetryPolicy
+
+	EmbeddingModel string // модель для Embed, пусто = DefaultEmbeddingModel
 }
 
 // Message представляет сообщение в треде
@@ -44,10 +49,20 @@ type Text struct {
 
 // Run представляет запуск ассистента
 type Run struct {
-	ID          string `json:"id"`
-	Status      string `json:"status"`
-	ThreadID    string `json:"thread_id"`
-	AssistantID string `json:"assistant_id"`
+	ID             string          `json:"id"`
+	Status         string          `json:"status"`
+	ThreadID       string          `json:"thread_id"`
+	AssistantID    string          `json:"assistant_id"`
+	Model          string          `json:"model,omitempty"`
+	Usage          *Usage          `json:"usage,omitempty"`           // заполняется после завершения run
+	RequiredAction *RequiredAction `json:"required_action,omitempty"` // заполняется в статусе requires_action
+}
+
+// Usage представляет расход токенов за run
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 func NewClient(apiKey, assistantID string) *Client {
@@ -56,6 +71,8 @@ func NewClient(apiKey, assistantID string) *Client {
 		AssistantID: assistantID,
 		BaseURL:     DefaultBaseURL,
 		HTTP:        &http.Client{Timeout: DefaultTimeout},
+		StreamHTTP:  &http.Client{},
+		Retry:       llm.DefaultRetryPolicy(),
 	}
 }
 
@@ -71,7 +88,7 @@ func (c *Client) CreateThread(ctx context.Context) (string, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.Retry.Do(c.HTTP, req)
 	if err != nil {
 		return "", err
 	}
@@ -91,12 +108,85 @@ func (c *Client) CreateThread(ctx context.Context) (string, error) {
 	return out.ID, nil
 }
 
-func (c *Client) AddMessage(ctx context.Context, threadID, content string) error {
-	payload := map[string]interface{}{
-		"role":    "user",
-		"content": content,
+// GetThread возвращает метаданные треда
+func (c *Client) GetThread(ctx context.Context, threadID string) (*llm.Thread, error) {
+	url := fmt.Sprintf("%s/threads/%s", c.BaseURL, threadID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
+
+	resp, err := c.Retry.Do(c.HTTP, req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, llm.ErrThreadNotFound
+	}
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	var out struct {
+		ID        string `json:"id"`
+		CreatedAt int64  `json:"created_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	return &llm.Thread{ID: out.ID, CreatedAt: time.Unix(out.CreatedAt, 0).UTC()}, nil
+}
+
+// DeleteThread удаляет тред вместе с сообщениями на стороне OpenAI
+func (c *Client) DeleteThread(ctx context.Context, threadID string) error {
+	url := fmt.Sprintf("%s/threads/%s", c.BaseURL, threadID)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
+
+	resp, err := c.Retry.Do(c.HTTP, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return llm.ErrThreadNotFound
+	}
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	return nil
+}
+
+// AddMessage добавляет сообщение пользователя в тред. Изображения предварительно загружаются в файлы OpenAI
+func (c *Client) AddMessage(ctx context.Context, threadID, content string, images ...llm.Image) error {
+	messageContent, err := c.messageContent(ctx, content, images)
+	if err != nil {
+		return err
+	}
+
+	return c.postMessage(ctx, threadID, map[string]interface{}{
+		"role":    "user",
+		"content": messageContent,
+	})
+}
+
+// postMessage создает сообщение в треде
+func (c *Client) postMessage(ctx context.Context, threadID string, payload map[string]interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -112,7 +202,7 @@ func (c *Client) AddMessage(ctx context.Context, threadID, content string) error
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.Retry.Do(c.HTTP, req)
 	if err != nil {
 		return err
 	}
@@ -126,10 +216,33 @@ func (c *Client) AddMessage(ctx context.Context, threadID, content string) error
 	return nil
 }
 
-func (c *Client) RunAssistant(ctx context.Context, threadID string) (*Run, error) {
+// runPayload собирает тело запуска run с переопределениями ассистента, модели и температуры,
+// контекстом диалога в additional_instructions и серверными функциями
+func (c *Client) runPayload(opts llm.Options) map[string]interface{} {
 	payload := map[string]interface{}{
 		"assistant_id": c.AssistantID,
 	}
+	if opts.AssistantID != "" {
+		payload["assistant_id"] = opts.AssistantID
+	}
+	if opts.Model != "" {
+		payload["model"] = opts.Model
+	}
+	if opts.Temperature != nil {
+		payload["temperature"] = *opts.Temperature
+	}
+	if opts.Context != "" {
+		payload["additional_instructions"] = opts.Context
+	}
+	if len(opts.Tools) > 0 {
+		payload["tools"] = toolDefinitions(opts.Tools)
+	}
+
+	return payload
+}
+
+func (c *Client) RunAssistant(ctx context.Context, threadID string, opts llm.Options) (*Run, error) {
+	payload := c.runPayload(opts)
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -146,7 +259,7 @@ func (c *Client) RunAssistant(ctx context.Context, threadID string) (*Run, error
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.Retry.Do(c.HTTP, req)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +288,7 @@ func (c *Client) GetRunStatus(ctx context.Context, threadID, runID string) (*Run
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.Retry.Do(c.HTTP, req)
 	if err != nil {
 		return nil, err
 	}
@@ -194,39 +307,6 @@ func (c *Client) GetRunStatus(ctx context.Context, threadID, runID string) (*Run
 	return &run, nil
 }
 
-func (c *Client) WaitForCompletion(ctx context.Context, threadID, runID string, maxWaitTime time.Duration) error {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	timeout := time.After(maxWaitTime)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for assistant completion")
-		case <-ticker.C:
-			run, err := c.GetRunStatus(ctx, threadID, runID)
-			if err != nil {
-				return err
-			}
-
-			switch run.Status {
-			case "completed":
-				return nil
-			case "failed", "cancelled", "expired":
-				return fmt.Errorf("run failed with status: %s", run.Status)
-			case "queued", "in_progress", "cancelling":
-				// продолжаем ждать
-				continue
-			default:
-				return fmt.Errorf("unknown run status: %s", run.Status)
-			}
-		}
-	}
-}
-
 func (c *Client) GetMessages(ctx context.Context, threadID string, limit int) ([]Message, error) {
 	url := fmt.Sprintf("%s/threads/%s/messages?limit=%d&order=desc", c.BaseURL, threadID, limit)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -237,7 +317,7 @@ func (c *Client) GetMessages(ctx context.Context, threadID string, limit int) ([
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.Retry.Do(c.HTTP, req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultEmbeddingModel - модель эмбеддингов по умолчанию
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+var (
+	_ llm.Embedder = (*Client)(nil)
+	_ llm.Embedder = (*ChatClient)(nil)
+)
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed возвращает эмбеддинги текстов через Embeddings API
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return embed(ctx, c.HTTP, c.Retry, c.BaseURL, c.APIKey, c.EmbeddingModel, texts)
+}
+
+// Embed возвращает эмбеддинги текстов через Embeddings API
+func (c *ChatClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return embed(ctx, c.HTTP, c.Retry, c.BaseURL, c.APIKey, c.EmbeddingModel, texts)
+}
+
+// embed выполняет запрос к /embeddings, общий для обоих режимов API
+func embed(ctx context.Context, client *http.Client, retry llm.RetryPolicy, baseURL, apiKey, model string, texts []string) ([][]float64, error) {
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+
+	body, err := json.Marshal(embeddingRequest{Model: model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := retry.Do(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	var result embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	// Порядок в ответе задается полем index, а не позицией в массиве
+	vectors := make([][]float64, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("openai embeddings: unexpected index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("openai embeddings: missing embedding for input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
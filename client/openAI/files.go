@@ -0,0 +1,150 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// Назначения загружаемых файлов
+const (
+	FilePurposeVision     = "vision"     // изображения для сообщений ассистенту
+	FilePurposeAssistants = "assistants" // файлы, прикрепляемые к тредам
+)
+
+var _ llm.FileStore = (*Client)(nil)
+
+// File - загруженный файл
+type File struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Bytes    int    `json:"bytes"`
+	Purpose  string `json:"purpose"`
+}
+
+// UploadFile загружает файл в хранилище OpenAI и возвращает его описание
+func (c *Client) UploadFile(ctx context.Context, filename, contentType string, data []byte, purpose string) (*File, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	if err := form.WriteField("purpose", purpose); err != nil {
+		return nil, err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/files", bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := c.Retry.Do(c.HTTP, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	var file File
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// messageContent возвращает содержимое сообщения: строку или текст с файлами изображений.
+// Assistants API не принимает картинки в data URL, поэтому они загружаются как файлы
+func (c *Client) messageContent(ctx context.Context, content string, images []llm.Image) (interface{}, error) {
+	if len(images) == 0 {
+		return content, nil
+	}
+
+	parts := make([]map[string]interface{}, 0, len(images)+1)
+	if content != "" {
+		parts = append(parts, map[string]interface{}{"type": "text", "text": content})
+	}
+	for i, image := range images {
+		filename := fmt.Sprintf("image-%d.%s", i+1, strings.TrimPrefix(image.MIMEType, "image/"))
+		file, err := c.UploadFile(ctx, filename, image.MIMEType, image.Data, FilePurposeVision)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, map[string]interface{}{
+			"type":       "image_file",
+			"image_file": map[string]string{"file_id": file.ID},
+		})
+	}
+
+	return parts, nil
+}
+
+// UploadAttachment загружает файл для прикрепления к тредам
+func (c *Client) UploadAttachment(ctx context.Context, filename, contentType string, data []byte) (string, error) {
+	file, err := c.UploadFile(ctx, filename, contentType, data, FilePurposeAssistants)
+	if err != nil {
+		return "", err
+	}
+	return file.ID, nil
+}
+
+// AttachFile прикрепляет файл к треду служебным сообщением. Файл доступен ассистенту
+// через поиск по файлам и интерпретатор кода
+func (c *Client) AttachFile(ctx context.Context, threadID, fileID, filename string) error {
+	return c.postMessage(ctx, threadID, map[string]interface{}{
+		"role":    "user",
+		"content": "Прикреплен файл " + filename + ", он относится к вопросу.",
+		"attachments": []map[string]interface{}{{
+			"file_id": fileID,
+			"tools":   []map[string]string{{"type": "file_search"}, {"type": "code_interpreter"}},
+		}},
+	})
+}
+
+// DeleteFile удаляет файл из хранилища OpenAI. Уже удаленный файл - не ошибка
+func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.BaseURL+"/files/"+fileID, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.Retry.Do(c.HTTP, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// DefaultModerationModel - модель модерации по умолчанию
+const DefaultModerationModel = "omni-moderation-latest"
+
+// Moderator проверяет сообщения через OpenAI Moderation API
+type Moderator struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+	HTTP    *http.Client
+	Retry   llm.RetryPolicy
+}
+
+var _ llm.Moderator = (*Moderator)(nil)
+
+func NewModerator(apiKey string) *Moderator {
+	return &Moderator{
+		APIKey:  apiKey,
+		Model:   DefaultModerationModel,
+		BaseURL: DefaultBaseURL,
+		HTTP:    &http.Client{Timeout: DefaultTimeout},
+		Retry:   llm.DefaultRetryPolicy(),
+	}
+}
+
+func (m *Moderator) Moderate(ctx context.Context, text string) (*llm.ModerationResult, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": m.Model,
+		"input": text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.BaseURL+"/moderations", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+m.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.Retry.Do(m.HTTP, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	var out struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	result := &llm.ModerationResult{Source: "openai"}
+	for _, r := range out.Results {
+		result.Flagged = result.Flagged || r.Flagged
+		for category, flagged := range r.Categories {
+			if flagged {
+				result.Categories = append(result.Categories, category)
+			}
+		}
+	}
+	sort.Strings(result.Categories)
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"context"
+	"errors"
+	"time"
+)
+
+// runWaitTimeout - сколько ждем завершения run в блокирующем режиме
+const runWaitTimeout = 30 * time.Second
+
+var _ llm.Provider = (*Client)(nil)
+
+// Name возвращает имя провайдера
+func (c *Client) Name() string {
+	return "openai"
+}
+
+// Send добавляет сообщение в тред, запускает ассистента и ждет его ответа.
+// Run читается из стрима событий: ответ приходит сразу по готовности, без опроса статуса
+func (c *Client) Send(ctx context.Context, threadID, message string, opts llm.Options) (*llm.Reply, error) {
+	ctx, cancel := context.WithTimeout(ctx, runWaitTimeout)
+	defer cancel()
+
+	reply, err := c.Stream(ctx, threadID, message, opts, func(string) error { return nil })
+	if err != nil {
+		return nil, err
+	}
+	if reply.Text != "" {
+		return reply, nil
+	}
+
+	// Ответ без текстовых фрагментов в стриме забираем из треда
+	messages, err := c.GetMessages(ctx, threadID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("no response from assistant")
+	}
+	if len(messages[0].Content) > 0 && messages[0].Content[0].Text != nil {
+		reply.Text = messages[0].Content[0].Text.Value
+	}
+
+	return reply, nil
+}
+
+// Stream добавляет сообщение в тред и стримит ответ ассистента
+func (c *Client) Stream(ctx context.Context, threadID, message string, opts llm.Options, onDelta func(text string) error) (*llm.Reply, error) {
+	if err := c.AddMessage(ctx, threadID, message, opts.Images...); err != nil {
+		return nil, err
+	}
+
+	var text []byte
+	run, err := c.StreamRun(ctx, threadID, opts, func(delta string) error {
+		text = append(text, delta...)
+		return onDelta(delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &llm.Reply{Text: string(text), RunID: run.ID, Model: run.Model, Usage: run.Usage.toLLM()}, nil
+}
+
+func (u *Usage) toLLM() *llm.Usage {
+	if u == nil {
+		return nil
+	}
+	return &llm.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
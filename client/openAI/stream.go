@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// События стриминга Assistants API, которые мы обрабатываем
+const (
+	EventMessageDelta      = "thread.message.delta"
+	EventRunCompleted      = "thread.run.completed"
+	EventRunRequiresAction = "thread.run.requires_action"
+	EventRunFailed         = "thread.run.failed"
+	EventRunCancelled      = "thread.run.cancelled"
+	EventRunExpired        = "thread.run.expired"
+	EventError             = "error"
+	EventDone              = "done"
+)
+
+// maxStreamLineSize - максимальный размер одной строки SSE от OpenAI
+const maxStreamLineSize = 1 << 20
+
+// messageDelta - фрагмент сообщения ассистента из события thread.message.delta
+type messageDelta struct {
+	Delta struct {
+		Content []Content `json:"content"`
+	} `json:"delta"`
+}
+
+// StreamRun запускает ассистента в режиме стриминга и передает каждый фрагмент текста в onDelta.
+// Запрошенные ассистентом функции выполняются, а их результаты отправляются в тот же стрим.
+// Возвращает завершенный run; ошибка onDelta прерывает стрим
+func (c *Client) StreamRun(ctx context.Context, threadID string, opts llm.Options, onDelta func(text string) error) (*Run, error) {
+	payload := c.runPayload(opts)
+	payload["stream"] = true
+
+	url := fmt.Sprintf("%s/threads/%s/runs", c.BaseURL, threadID)
+	run, err := c.streamRequest(ctx, url, payload, onDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	for round := 0; run.Status == RunStatusRequiresAction; round++ {
+		if round == maxToolRounds {
+			return nil, fmt.Errorf("run %s exceeded %d tool call rounds", run.ID, maxToolRounds)
+		}
+
+		outputs, err := callTools(ctx, opts.Tools, run)
+		if err != nil {
+			return nil, err
+		}
+
+		url := fmt.Sprintf("%s/threads/%s/runs/%s/submit_tool_outputs", c.BaseURL, threadID, run.ID)
+		run, err = c.streamRequest(ctx, url, map[string]interface{}{
+			"tool_outputs": outputs,
+			"stream":       true,
+		}, onDelta)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return run, nil
+}
+
+// streamRequest отправляет запрос со стримингом и читает события до завершения run
+// или до запроса на вызов функций
+func (c *Client) streamRequest(ctx context.Context, url string, payload map[string]interface{}, onDelta func(text string) error) (*Run, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
+
+	// Общий таймаут c.HTTP оборвал бы длинный стрим, время жизни ограничивает ctx
+	resp, err := c.Retry.Do(c.StreamHTTP, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			continue
+		case !strings.HasPrefix(line, "data:"):
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		switch event {
+		case EventMessageDelta:
+			var delta messageDelta
+			if err := json.Unmarshal([]byte(data), &delta); err != nil {
+				return nil, err
+			}
+			for _, content := range delta.Delta.Content {
+				if content.Text == nil || content.Text.Value == "" {
+					continue
+				}
+				if err := onDelta(content.Text.Value); err != nil {
+					return nil, err
+				}
+			}
+		case EventRunCompleted, EventRunRequiresAction:
+			var run Run
+			if err := json.Unmarshal([]byte(data), &run); err != nil {
+				return nil, err
+			}
+			return &run, nil
+		case EventRunFailed, EventRunCancelled, EventRunExpired:
+			return nil, fmt.Errorf("run failed with event: %s", event)
+		case EventError:
+			return nil, fmt.Errorf("openai stream error: %s", data)
+		case EventDone:
+			return nil, fmt.Errorf("stream ended before run completed")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("stream ended before run completed")
+}
@@ -0,0 +1,118 @@
+package openai
+
+import (
+	"GEEK_back/client/llm"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RunStatusRequiresAction - run ждет результатов вызова функций
+const RunStatusRequiresAction = "requires_action"
+
+// maxToolRounds - сколько раз за один ответ ассистент может запросить вызов функций
+const maxToolRounds = 5
+
+// RequiredAction - действие, которого ждет run в статусе requires_action
+type RequiredAction struct {
+	Type              string `json:"type"`
+	SubmitToolOutputs struct {
+		ToolCalls []ToolCall `json:"tool_calls"`
+	} `json:"submit_tool_outputs"`
+}
+
+// ToolCall - вызов функции, запрошенный ассистентом
+type ToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// ToolOutput - результат вызова функции для submit_tool_outputs
+type ToolOutput struct {
+	ToolCallID string `json:"tool_call_id"`
+	Output     string `json:"output"`
+}
+
+// toolDefinitions описывает серверные функции в формате Assistants API
+func toolDefinitions(tools []llm.Tool) []map[string]interface{} {
+	definitions := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		function := map[string]interface{}{
+			"name":        tool.Name,
+			"description": tool.Description,
+		}
+		if len(tool.Parameters) > 0 {
+			function["parameters"] = tool.Parameters
+		}
+		definitions = append(definitions, map[string]interface{}{
+			"type":     "function",
+			"function": function,
+		})
+	}
+	return definitions
+}
+
+// callTools выполняет функции, которые запросил run, и возвращает их результаты
+func callTools(ctx context.Context, tools []llm.Tool, run *Run) ([]ToolOutput, error) {
+	if run.RequiredAction == nil || run.RequiredAction.Type != "submit_tool_outputs" {
+		return nil, fmt.Errorf("unsupported required action for run %s", run.ID)
+	}
+
+	calls := run.RequiredAction.SubmitToolOutputs.ToolCalls
+	outputs := make([]ToolOutput, 0, len(calls))
+	for _, call := range calls {
+		output := llm.CallTool(ctx, tools, llm.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: json.RawMessage(call.Function.Arguments),
+		})
+		outputs = append(outputs, ToolOutput{ToolCallID: call.ID, Output: output})
+	}
+
+	return outputs, nil
+}
+
+// SubmitToolOutputs передает результаты функций в run, ожидающий их
+func (c *Client) SubmitToolOutputs(ctx context.Context, threadID, runID string, outputs []ToolOutput) (*Run, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"tool_outputs": outputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/threads/%s/runs/%s/submit_tool_outputs", c.BaseURL, threadID, runID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OpenAI-Beta", OpenAIBetaVersion)
+
+	resp, err := c.Retry.Do(c.HTTP, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai http error: %d %s", resp.StatusCode, string(b))
+	}
+
+	var run Run
+	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
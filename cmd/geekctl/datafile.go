@@ -0,0 +1,162 @@
+package main
+
+import (
+	"GEEK_back/store"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// target - где выполняются команды: запущенный сервер или файл данных
+type target interface {
+	CreateUser(email, password, role string) (*store.User, error)
+	CreateAccessCode(testID uint64, opts store.AccessCodeOptions) (*store.AccessCode, error)
+	ImportSnapshot(data []byte, name string) (*store.ImportResult, error)
+	Snapshot() ([]byte, error)
+	// Close сохраняет изменения файла данных или завершает сессию на сервере
+	Close() error
+}
+
+// dataFile - файл данных: фикстура загружается в хранилище в памяти, команды меняют хранилище,
+// а при закрытии файл перезаписывается снимком в том же формате
+type dataFile struct {
+	path    string
+	store   *store.Store
+	changed bool
+}
+
+// openDataFile загружает файл данных. Несуществующий файл - пустое хранилище, он будет создан при сохранении
+func openDataFile(path string) (*dataFile, error) {
+	s := store.NewStore()
+	if _, err := os.Stat(path); err == nil {
+		if err := s.InitFillStore(path); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return &dataFile{path: path, store: s}, nil
+}
+
+func (f *dataFile) CreateUser(email, password, role string) (*store.User, error) {
+	user, err := f.store.CreateUser(email, password)
+	if err != nil {
+		return nil, err
+	}
+	f.changed = true
+	if err := f.store.SetUserRole(user.ID, role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (f *dataFile) CreateAccessCode(testID uint64, opts store.AccessCodeOptions) (*store.AccessCode, error) {
+	accessCode, err := f.store.CreateAccessCode("", testID, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.changed = true
+	return accessCode, nil
+}
+
+func (f *dataFile) ImportSnapshot(data []byte, name string) (*store.ImportResult, error) {
+	result, err := f.store.ImportSnapshot(data, name)
+	if err != nil {
+		return nil, err
+	}
+	f.changed = true
+	return result, nil
+}
+
+func (f *dataFile) Snapshot() ([]byte, error) {
+	return f.store.Snapshot()
+}
+
+func (f *dataFile) Close() error {
+	if !f.changed {
+		return nil
+	}
+	data, err := f.store.Snapshot()
+	if err != nil {
+		return err
+	}
+	return writeSnapshotFile(f.path, data)
+}
+
+// writeSnapshotFile записывает снимок в JSON или, для .yaml/.yml, в YAML. Файл заменяется целиком
+// через временный, чтобы прерванная запись не испортила данные. В снимке хеши паролей, поэтому доступ 0600
+func writeSnapshotFile(path string, data []byte) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var err error
+		if data, err = jsonToYAML(data); err != nil {
+			return err
+		}
+	default:
+		data = append(data, '\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// jsonToYAML переводит снимок в YAML с сохранением порядка полей
+func jsonToYAML(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("convert snapshot to yaml: %w", err)
+	}
+	resetYAMLStyle(&doc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("convert snapshot to yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("convert snapshot to yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resetYAMLStyle убирает унаследованный от JSON стиль ({}, [] и кавычки), чтобы вывести блочный YAML
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// fixtureHasUsers проверяет, есть ли в фикстуре пользователи. JSON - подмножество YAML, поэтому разбор общий
+func fixtureHasUsers(data []byte) (bool, error) {
+	var doc struct {
+		Users []any `yaml:"users"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	return len(doc.Users) > 0, nil
+}
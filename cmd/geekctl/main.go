@@ -0,0 +1,248 @@
+// geekctl - административная утилита GEEK: создает пользователей, импортирует тесты, выпускает коды доступа,
+// выгружает и восстанавливает снимки. Работает с запущенным сервером через API администратора (-url)
+// или напрямую с файлом данных - фикстурой, которую сервер загружает из SEED_FIXTURE (-data)
+package main
+
+import (
+	"GEEK_back/store"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const usage = `Usage: geekctl [-url URL -email EMAIL -password PASSWORD | -data FILE] COMMAND [flags]
+
+Target:
+  -url       address of a running server, e.g. http://localhost:8080 (GEEKCTL_URL)
+  -email     admin email for -url (GEEKCTL_EMAIL)
+  -password  admin password for -url (GEEKCTL_PASSWORD)
+  -data      data file (seed fixture or snapshot, JSON or YAML), changed in place
+
+Commands:
+  create-user   -email EMAIL -password PASSWORD [-role admin|teacher|student]
+  import-tests  FILE            add tests (and their access codes) from a fixture file
+  create-codes  -test ID [-count N] [-max-uses N] [-expires DURATION|RFC3339] [-bound-email EMAIL] [-checksum]
+  dump          [-o FILE]       write a snapshot (JSON, or YAML for .yaml/.yml)
+  restore       FILE            add users, tests and codes from a snapshot; existing ones are skipped
+`
+
+func main() {
+	// Хранилище пишет служебные сообщения в журнал, в выводе утилиты они не нужны
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "geekctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	global := flag.NewFlagSet("geekctl", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(global.Output(), usage) }
+	url := global.String("url", os.Getenv("GEEKCTL_URL"), "")
+	email := global.String("email", os.Getenv("GEEKCTL_EMAIL"), "")
+	password := global.String("password", os.Getenv("GEEKCTL_PASSWORD"), "")
+	data := global.String("data", "", "")
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return errors.New("command is required")
+	}
+
+	var t target
+	switch {
+	case *url != "" && *data != "":
+		return errors.New("-url and -data cannot be used together")
+	case *url != "":
+		remote, err := newRemote(*url, *email, *password)
+		if err != nil {
+			return err
+		}
+		t = remote
+	case *data != "":
+		file, err := openDataFile(*data)
+		if err != nil {
+			return err
+		}
+		t = file
+	default:
+		return errors.New("-url or -data is required")
+	}
+
+	// Изменения сохраняются и при ошибке посередине: например, уже выведенные коды должны остаться в файле
+	err := runCommand(t, global.Arg(0), global.Args()[1:], out)
+	return errors.Join(err, t.Close())
+}
+
+func runCommand(t target, command string, args []string, out io.Writer) error {
+	switch command {
+	case "create-user":
+		return createUser(t, args, out)
+	case "import-tests":
+		return importTests(t, args, out)
+	case "create-codes":
+		return createCodes(t, args, out)
+	case "dump":
+		return dump(t, args, out)
+	case "restore":
+		return restore(t, args, out)
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func createUser(t target, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	email := flags.String("email", "", "user email")
+	password := flags.String("password", "", "user password")
+	role := flags.String("role", store.RoleAdmin, "admin, teacher or student")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" || *password == "" {
+		return errors.New("create-user: -email and -password are required")
+	}
+	switch *role {
+	case store.RoleAdmin, store.RoleTeacher, store.RoleStudent:
+	default:
+		return errors.New("create-user: -role must be one of: admin, teacher, student")
+	}
+
+	user, err := t.CreateUser(*email, *password, *role)
+	if err != nil {
+		return fmt.Errorf("create-user: %w", err)
+	}
+	fmt.Fprintf(out, "created %s %s (id %d)\n", user.Role, user.Email, user.ID)
+	return nil
+}
+
+func importTests(t target, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("import-tests: FILE is required")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("import-tests: %w", err)
+	}
+	// Пользователи переносятся только снимком целиком, чтобы импорт тестов не создавал учетных записей
+	if hasUsers, err := fixtureHasUsers(data); err != nil {
+		return fmt.Errorf("import-tests: %w", err)
+	} else if hasUsers {
+		return errors.New("import-tests: file contains users, use restore")
+	}
+
+	result, err := t.ImportSnapshot(data, args[0])
+	if err != nil {
+		return fmt.Errorf("import-tests: %w", err)
+	}
+	printImportResult(out, result)
+	return nil
+}
+
+func createCodes(t target, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("create-codes", flag.ContinueOnError)
+	testID := flags.Uint64("test", 0, "test ID")
+	count := flags.Int("count", 1, "number of codes")
+	maxUses := flags.Uint64("max-uses", 0, "uses per code, 0 = unlimited")
+	expires := flags.String("expires", "", "expiry as a duration from now (72h) or RFC3339 time")
+	boundEmail := flags.String("bound-email", "", "single-use code for this user")
+	checksum := flags.Bool("checksum", false, "append a check character")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *testID == 0 {
+		return errors.New("create-codes: -test is required")
+	}
+	if *count < 1 || *count > 1000 {
+		return errors.New("create-codes: -count must be between 1 and 1000")
+	}
+	if *boundEmail != "" && *count != 1 {
+		return errors.New("create-codes: -bound-email creates a single code")
+	}
+
+	opts := store.AccessCodeOptions{BoundEmail: *boundEmail, Checksum: *checksum}
+	if *maxUses > 0 {
+		opts.MaxUses = maxUses
+	}
+	if *expires != "" {
+		expiresAt, err := parseExpires(*expires)
+		if err != nil {
+			return fmt.Errorf("create-codes: -expires: %w", err)
+		}
+		opts.ExpiresAt = &expiresAt
+	}
+
+	for range *count {
+		accessCode, err := t.CreateAccessCode(*testID, opts)
+		if err != nil {
+			return fmt.Errorf("create-codes: %w", err)
+		}
+		fmt.Fprintln(out, accessCode.Code)
+	}
+	return nil
+}
+
+// parseExpires читает срок действия кода: длительность от текущего момента или время в RFC3339
+func parseExpires(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.New("duration must be positive")
+		}
+		return time.Now().Add(d).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func dump(t target, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, stdout by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := t.Snapshot()
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	if *output == "" {
+		_, err = out.Write(append(data, '\n'))
+		return err
+	}
+	if err := writeSnapshotFile(*output, data); err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	fmt.Fprintf(out, "snapshot written to %s\n", *output)
+	return nil
+}
+
+func restore(t target, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("restore: FILE is required")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	result, err := t.ImportSnapshot(data, args[0])
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	printImportResult(out, result)
+	return nil
+}
+
+func printImportResult(out io.Writer, result *store.ImportResult) {
+	fmt.Fprintf(out, "added %d users, %d tests, %d access codes\n", result.Users, result.Tests, result.AccessCodes)
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(out, "skipped existing: %s\n", strings.Join(result.Skipped, ", "))
+	}
+}
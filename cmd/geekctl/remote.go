@@ -0,0 +1,167 @@
+package main
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteTimeout - сколько ждать ответа сервера на одну команду
+const remoteTimeout = 30 * time.Second
+
+// remote - запущенный сервер: команды выполняются через API администратора от имени сессии,
+// открытой при подключении
+type remote struct {
+	baseURL string
+	http    *http.Client
+	csrf    string
+}
+
+// newRemote входит на сервер под учетной записью администратора
+func newRemote(baseURL, email, password string) (*remote, error) {
+	if email == "" || password == "" {
+		return nil, errors.New("-email and -password (or GEEKCTL_EMAIL and GEEKCTL_PASSWORD) are required with -url")
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &remote{
+		baseURL: strings.TrimRight(baseURL, "/") + "/api",
+		http:    &http.Client{Jar: jar, Timeout: remoteTimeout},
+	}
+
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	resp, err := r.do(http.MethodPost, "/login", "application/json", body)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	resp.Body.Close()
+	// Изменяющие запросы сессии подписываются CSRF-токеном из ответа на вход
+	r.csrf = resp.Header.Get(mw.CSRFHeader)
+
+	return r, nil
+}
+
+func (r *remote) CreateUser(email, password, role string) (*store.User, error) {
+	var user store.User
+	err := r.postJSON("/admin/users", map[string]string{"email": email, "password": password, "role": role}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *remote) CreateAccessCode(testID uint64, opts store.AccessCodeOptions) (*store.AccessCode, error) {
+	request := map[string]any{"checksum": opts.Checksum}
+	if opts.MaxUses != nil {
+		request["max_uses"] = *opts.MaxUses
+	}
+	if opts.ExpiresAt != nil {
+		request["expires_at"] = opts.ExpiresAt
+	}
+	if opts.BoundEmail != "" {
+		request["bound_email"] = opts.BoundEmail
+	}
+
+	var accessCode store.AccessCode
+	if err := r.postJSON(fmt.Sprintf("/tests/%d/codes", testID), request, &accessCode); err != nil {
+		return nil, err
+	}
+	return &accessCode, nil
+}
+
+func (r *remote) ImportSnapshot(data []byte, name string) (*store.ImportResult, error) {
+	contentType := "application/json"
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		contentType = "application/yaml"
+	}
+
+	resp, err := r.do(http.MethodPost, "/admin/snapshot", contentType, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result store.ImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &result, nil
+}
+
+func (r *remote) Snapshot() ([]byte, error) {
+	resp, err := r.do(http.MethodGet, "/admin/snapshot", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Close завершает сессию, чтобы она не висела на сервере до истечения срока
+func (r *remote) Close() error {
+	resp, err := r.do(http.MethodPost, "/logout", "", nil)
+	if err != nil {
+		return fmt.Errorf("logout: %w", err)
+	}
+	return resp.Body.Close()
+}
+
+func (r *remote) postJSON(path string, request, dst any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	resp, err := r.do(http.MethodPost, path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// do отправляет запрос к API. Ответ не 2xx возвращается ошибкой с сообщением и кодом сервера
+func (r *remote) do(method, path, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, r.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.csrf != "" {
+		req.Header.Set(mw.CSRFHeader, r.csrf)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr apiutils.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Message == "" {
+		return nil, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return nil, fmt.Errorf("%s (%s)", apiErr.Message, apiErr.Code)
+}
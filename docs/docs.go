@@ -4,264 +4,324 @@ package docs
 import "github.com/swaggo/swag"
 
 const docTemplate = `{
-    "schemes": {{ marshal .Schemes }},
+    "schemes": [[ marshal .Schemes ]],
     "swagger": "2.0",
     "info": {
-        "description": "{{escape .Description}}",
-        "title": "{{.Title}}",
+        "description": "[[escape .Description]]",
+        "title": "[[.Title]]",
         "contact": {},
-        "version": "{{.Version}}"
+        "version": "[[.Version]]"
     },
-    "host": "{{.Host}}",
-    "basePath": "{{.BasePath}}",
+    "host": "[[.Host]]",
+    "basePath": "[[.BasePath]]",
     "paths": {
-        "/attempt/{attempt_id}/question": {
+        "/admin/activity": {
             "get": {
-                "description": "Retrieves all questions for the specified attempt",
-                "summary": "Get questions for test attempt",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Counts started and submitted attempts per day or hour over [from, to), for all attempts or split by test or organization.\nEvery day or hour of the range is present, empty ones with zero counts; from is rounded down to the start of its day or hour.\nDays start at midnight in tz. An attempt is submitted when it finished in the range, including attempts awaiting review,\nand belongs to the organization of its test. Series with the most activity come first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Attempt activity time series",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "day or hour (default day)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (RFC3339), default 30 days or 48 hours before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end, exclusive (RFC3339), default now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries, e.g. Europe/Moscow (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Attempt ID",
-                        "name": "attempt_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only attempts of this test",
+                        "name": "test_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only attempts of tests of this organization",
+                        "name": "org_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "test or organization, a series per test or organization; empty for a single series",
+                        "name": "group_by",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/store.Question"
-                            }
+                            "$ref": "#/definitions/store.ActivityReport"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/attempt/{attempt_id}/question/{question_position}/submit": {
-            "post": {
-                "description": "Submits the answer for a given question in the attempt",
-                "summary": "Submit an answer for a question",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Attempt ID",
-                        "name": "attempt_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Question Position",
-                        "name": "question_position",
-                        "in": "path",
-                        "required": true
-                    },
+        "/admin/ai/budget": {
+            "get": {
+                "security": [
                     {
-                        "description": "Answer text",
-                        "name": "text",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.PostAnswerRequest"
-                        }
+                        "CookieAuth": []
                     }
                 ],
+                "description": "Returns monthly AI budget caps and the estimated spend of the current month (UTC). org_id 0 is the global cap",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "AI budget",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Answer"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/store.AIBudgetReport"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/attempt/{attempt_id}/submit": {
-            "post": {
-                "description": "Submits the entire attempt and evaluates the score",
-                "summary": "Submit the attempt and evaluate the result",
+            },
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sets a hard monthly AI budget in USD globally (org_id 0) or for an organization. When the estimated spend reaches the cap, AI endpoints return 503 until the next month. monthly_usd 0 removes the cap",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set AI budget",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Attempt ID",
-                        "name": "attempt_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Budget",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.setAIBudgetRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Attempt"
+                            "$ref": "#/definitions/store.AIBudget"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Authenticate user and set a session cookie",
-                "consumes": [
-                    "application/json"
+        "/admin/ai/usage": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
                 ],
+                "description": "Returns accumulated token usage and estimated cost in USD grouped by user, attempt, test or organization, most expensive first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login user",
+                "summary": "AI token usage report",
                 "parameters": [
                     {
-                        "description": "Login request",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.loginRequest"
-                        }
+                        "type": "string",
+                        "description": "user, attempt, test or organization (default user)",
+                        "name": "group_by",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.User"
+                            "$ref": "#/definitions/store.AIUsageReport"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/logout": {
-            "post": {
-                "description": "Delete user's session and invalidate cookie",
+        "/admin/attempts": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Lists attempts filtered by user, test, status and start date range, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Browse attempts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attempt status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Started at or after (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Started at or before (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset, ignored with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, result, started_at or finished_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Logout user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.Page-store_Attempt"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/register": {
+        "/admin/dev/synthetic": {
             "post": {
-                "description": "Create a new user (email, username, password). Returns created user on success.",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Dev mode only (DEV_MODE=true). Creates students with attempts and answers for the existing tests,\nwith realistic distributions of ability, attempt count, test popularity, time of day and duration.\nStudents get emails studentN@email_domain and the given password. The same seed on the same data reproduces the result",
                 "consumes": [
                     "application/json"
                 ],
@@ -269,17 +329,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register new user",
+                "summary": "Generate synthetic data",
                 "parameters": [
                     {
-                        "description": "Register request",
-                        "name": "register",
+                        "description": "What to generate",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.registerRequest"
+                            "$ref": "#/definitions/store.SyntheticOptions"
                         }
                     }
                 ],
@@ -287,100 +347,390 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/store.User"
+                            "$ref": "#/definitions/store.SyntheticResult"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/session": {
+        "/admin/jobs": {
             "get": {
-                "description": "Return user for current session cookie or null if not authenticated",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Shows registered background jobs with their interval, run counts and the result of the last run",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "List background jobs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name, runs or failures, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Check current session",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.User"
+                            "$ref": "#/definitions/apiutils.Page-scheduler_JobStatus"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/test/{test_id}": {
+        "/admin/log-level": {
             "get": {
-                "description": "Retrieves a test by its ID",
-                "summary": "Get test by ID",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the current log level and, if it was changed temporarily, when and to which level it reverts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Log level",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.logLevelResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Changes the log level of the running server. With revert_after_seconds the previous level is restored after that time,\nso debug logging switched on for an investigation does not stay on. The level is not persisted across restarts, see LOG_LEVEL",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set log level",
+                "parameters": [
+                    {
+                        "description": "Level",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.setLogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.logLevelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/organizations": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List organizations",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name contains (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Test ID",
-                        "name": "test_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Test"
+                            "$ref": "#/definitions/apiutils.Page-store_Organization"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates an organization. All access codes of its tests and classes are prefixed with code_prefix",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create organization",
+                "parameters": [
+                    {
+                        "description": "Organization",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Organization"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/tests/{test_id}/attempt": {
+        "/admin/prompts": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List prompt templates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name contains (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_PromptTemplate"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Starts a new attempt for the given test",
-                "summary": "Start test attempt",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates a reusable system prompt template. Available variables: {{test}}, {{question}}, {{position}}, {{subject}}, {{language}}, {{help_policy}}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create prompt template",
+                "parameters": [
+                    {
+                        "description": "Prompt template",
+                        "name": "prompt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createPromptTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.PromptTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/prompts/{prompt_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get prompt template",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Test ID",
-                        "name": "test_id",
+                        "description": "Prompt template ID",
+                        "name": "prompt_id",
                         "in": "path",
                         "required": true
                     }
@@ -389,172 +739,9209 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Attempt"
+                            "$ref": "#/definitions/store.PromptTemplate"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Adds a new version of the template text. Previous versions are kept; threads record the version they were started with",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update prompt template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Prompt template ID",
+                        "name": "prompt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Prompt text",
+                        "name": "prompt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.updatePromptTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.PromptTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.PostAnswerRequest": {
+        },
+        "/admin/snapshot": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns users (with bcrypt password hashes), tests with questions and reference answers and test access codes with their usage state.\nThe snapshot has the seed fixture format and can be passed to SEED_FIXTURE or imported back.\nAttempts, sessions, classes, organizations and assistant history are not included",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dump snapshot",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Adds users, tests and access codes from a snapshot or a seed fixture (JSON, or YAML with Content-Type application/yaml).\nExisting users (by email), tests (by id) and codes are skipped, so the import can be repeated; use it to import new tests.\nThe whole document is validated before anything is added. The body is limited by MAX_BODY_BYTES",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Import snapshot",
+                "parameters": [
+                    {
+                        "description": "Snapshot or fixture",
+                        "name": "snapshot",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tests/{test_id}/organization": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Access codes created for the test afterwards are prefixed with the organization code prefix",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set test organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Test version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Organization",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.setOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tests/{test_id}/prompt": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Assigns a prompt template to the test. version 0 follows the latest template version. null or templateId 0 restores the default context",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set test prompt template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Test version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Prompt assignment",
+                        "name": "prompt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.TestPrompt"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates a user with the given role without the registration form, e.g. another admin or a teacher",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Marks the user as deleted and ends all their sessions: the user can no longer sign in and disappears from lookups.\nAttempts and history are kept, the email stays taken until the user is restored. An admin cannot delete themselves",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user_id}/organization": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Classes created by the user belong to this organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set user organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Organization",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.setOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user_id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Clears the deletion mark; the user can sign in again with the same password",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Restore user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscribed to the event",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Webhook"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Registers a URL that receives signed POST requests on events: user.registered, attempt.submitted.\nThe body is {\"id\",\"event\",\"created_at\",\"data\"}; the id is the same for every delivery of an event and can be used to drop duplicates.\nX-Webhook-Signature is \"sha256=\" + hex HMAC-SHA256 of the raw body with the secret; X-Webhook-Event and X-Webhook-Delivery are also set.\nNetwork errors, 5xx, 408 and 429 are retried up to 5 times with growing delays; other responses are final.\nIf no secret is given, one is generated. The secret is returned only in this response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.createWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{webhook_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Deletes the webhook and its delivery log. Deliveries already in progress are completed",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Recent deliveries of the webhook (up to 200) with the payload, number of attempts, last response status and error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, delivered or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ai/status": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Reports whether the assistant is available. After repeated provider failures it is temporarily unavailable and AI endpoints return 503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "AI assistant status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiStatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/ai-transcript": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the AI conversation for each question of the attempt, including messages blocked by moderation or refused by the guard, so graders can judge how much help the student received.\nUse question_position to get the transcript of a single question, e.g. for a review queue item",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Attempt AI transcript",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only this question",
+                        "name": "question_position",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AttemptTranscript"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/answers:batch": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Accepts answers buffered on the client while offline. Each answer is validated by its client timestamp against the attempt deadline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attempts"
+                ],
+                "summary": "Sync buffered answers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Buffered answers",
+                        "name": "answers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.batchAnswersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.BatchAnswerResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Streams events of an open attempt as Server-Sent Events for clients that can't use the WebSocket.\nEvents: \"answer_accepted\" {\"attempt_id\",\"question_position\",\"question_id\",\"user_id\",\"status\"},\n\"time_warning\" {\"attempt_id\",\"test_id\",\"deadline\",\"remaining_seconds\"},\n\"attempt_submitted\" {\"attempt_id\",\"auto\"} - the last event, the stream is closed after it.\nThe stream is also closed on logout or when the session expires",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "attempts"
+                ],
+                "summary": "Attempt events stream",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/feedback": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Attaches a comment to the whole attempt or, when question_position is set, to a single answer. The student is notified",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Add teacher feedback",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Feedback",
+                        "name": "feedback",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.feedbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Feedback"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/heartbeat": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Frontend pings this every 30 seconds while the student works on the attempt. Resumes an auto-paused attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attempts"
+                ],
+                "summary": "Attempt heartbeat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AttemptLiveness"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/messages": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sends a teacher message to the student (or the whole team) of an attempt. It is stored as a notification and pushed over the WebSocket",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "realtime"
+                ],
+                "summary": "Message attempt participants",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.teacherMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.teacherMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/paraphrases": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Lists question texts paraphrased by the assistant for this attempt together with the original texts.\nFailed paraphrases have an error and the student saw the original text",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Attempt question paraphrases",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "question_position, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Paraphrase"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/proctoring": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Lists violations recorded during the attempt, e.g. AI messages blocked by moderation or refused requests to solve a question",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Attempt proctoring log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_ProctoringEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question": {
+            "get": {
+                "description": "Retrieves all questions for the specified attempt. Requesting a question_position records that the participant\nopened the question, see /attempt/{attempt_id}/timeline",
+                "summary": "Get questions for test attempt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Question"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}": {
+            "get": {
+                "description": "Retrieves all questions for the specified attempt. Requesting a question_position records that the participant\nopened the question, see /attempt/{attempt_id}/timeline",
+                "summary": "Get questions for test attempt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Question"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Finds the latest thread of the question, so the frontend does not have to remember thread IDs. 404 means no dialog was started yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Get AI thread of a question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiThreadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/runs/{run_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Polls a message queued by the send endpoint. Status is queued, running, completed (with response) or failed (with error)\nInstead of polling, set AI_RUN_WEBHOOK_URL to receive a POST with run_id, thread_id and message_id when the run finishes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Get AI run status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Run ID",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/start": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates an assistant thread for the question of the attempt. Messages are sent to .../ai/{thread_id}/send or .../stream",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Start AI assistant thread",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiThreadStarted"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns thread status, message count, the run still in progress and whether the provider still knows the thread, so the frontend can re-attach to a dialog after reload",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Get AI thread",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiThreadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/attachments": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Uploads a whitelisted test file to the AI provider (once) and attaches it to the thread. Only providers with file storage (OpenAI Assistants) support attachments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Attach resource to AI thread",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resource",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.attachResourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.attachResourceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/images": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Uploads an image (png, jpeg, webp or gif, up to 5 MB) to the thread, e.g. a photo of handwritten work. Pass the returned id in image_ids of the send or stream request",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Upload image for AI message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.AIImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/images/{image_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Redirects to a signed link to an image attached to the thread, the link is valid for 15 minutes. Available to attempt participants and teachers",
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Get AI message image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Image ID",
+                        "name": "image_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Location: signed image URL"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns all user messages and assistant replies of the thread. Available to attempt participants and teachers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Get AI conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AIThread"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/send": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Queues a message (text, images uploaded via .../images or both) for the assistant and returns the run to poll via GET .../ai/runs/{run_id}.\nA repeated question may be answered from cache, then the run is already completed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Send message to AI assistant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.aiRunAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/stream": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sends a message to the assistant thread and relays the answer token by token as Server-Sent Events.\nEvents: \"delta\" {\"text\"}, \"done\" {\"response\",\"run_id\",\"quota\",\"turns\"}, \"error\" {\"error\"}.\nGET takes the message from the \"message\" query parameter (for EventSource), POST from the JSON body.\nImages uploaded via .../images are attached with image_ids (POST) or repeated image_id (GET).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Stream AI response",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message (GET)",
+                        "name": "message",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Uploaded image IDs (GET)",
+                        "name": "image_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sends a message to the assistant thread and relays the answer token by token as Server-Sent Events.\nEvents: \"delta\" {\"text\"}, \"done\" {\"response\",\"run_id\",\"quota\",\"turns\"}, \"error\" {\"error\"}.\nGET takes the message from the \"message\" query parameter (for EventSource), POST from the JSON body.\nImages uploaded via .../images are attached with image_ids (POST) or repeated image_id (GET).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "Stream AI response",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message (GET)",
+                        "name": "message",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Uploaded image IDs (GET)",
+                        "name": "image_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/question/{question_position}/submit": {
+            "post": {
+                "description": "Submits the answer for a given question in the attempt",
+                "summary": "Submit an answer for a question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question Position",
+                        "name": "question_position",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Answer text",
+                        "name": "text",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PostAnswerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Answer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/resources": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the test whitelist of files that can be attached to AI threads of the attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai"
+                ],
+                "summary": "List attachable resources",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name, size or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_TestResource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/result": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the score of the attempt with late and AI assistance penalties, the answers and teacher feedback",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attempts"
+                ],
+                "summary": "Get attempt results",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Results"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/submit": {
+            "post": {
+                "description": "Submits the entire attempt and evaluates the score",
+                "summary": "Submit the attempt and evaluate the result",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Attempt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/suspicion": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Combines signals into a risk score from 0 to 100 with an explanation per factor, instead of raw event logs:\nblocked_ai_messages (messages blocked by moderation or refused by the guard), answer_from_assistant (free-text answers\nmostly repeating the assistant's replies), heavy_ai_use (at least 10 messages and 3 times the test median),\nfast_answers (answers appearing after opening the question faster than 8 characters per second),\nshort_duration (4 times faster than the test median with a result not below the median) and similar_answers\n(from the last similarity check, see /tests/{test_id}/similarity). Level is medium from 30 and high from 60.\nTest medians need at least 5 submitted attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Attempt suspicion score",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AttemptSuspicion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/attempt/{attempt_id}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Reconstructs the attempt in chronological order: start, questions opened, drafts saved and the final answer per question,\nmessages to and from the assistant including ones blocked by moderation or refused by the guard, other proctoring events,\nsubmission, manual grading, feedback and regrades. Answers saved offline are placed at the client time,\nreceived_at shows when the server got them. Opening the same question again in a row and saving the same text again are not recorded",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Attempt timeline",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AttemptTimeline"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/csrf": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the CSRF token of the current session. Every POST, PUT, PATCH and DELETE request authenticated by the session cookie must send it in the X-CSRF-Token header, otherwise it is rejected with 403 csrf_invalid.\nThe token stays valid for the whole session; login and /session return it as well",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get CSRF token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.csrfResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/me": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns everything the student home screen needs: tests the user can start now without an access code\n(open enrollment or assigned to one of the user's classes), tests that open again after the retake cooldown,\nattempts in progress including team attempts with the deadline and remaining time, and the 10 latest submitted attempts.\nTests with an attempt in progress are listed only under in_progress",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Student dashboard",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.StudentDashboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/teacher": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the teacher's tests with headline metrics: open attempts, attempts awaiting manual review, submissions\nduring the last days days, average result overall and for recent submissions, time of the last submission,\npoints deducted for assistant hints and assistant token usage, plus totals over all tests.\nA teacher in an organization sees the organization's tests; a teacher without one and admins see all tests.\nTests with the most recent submission come first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Teacher dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Window for recent submissions in days, 1-365 (default 7)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.TeacherDashboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Redeems a class code: adds the current user to the group, granting access to its tests",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Enroll into class",
+                "parameters": [
+                    {
+                        "description": "Class code",
+                        "name": "enroll",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.enrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates a group of users that can take team tests together",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create group",
+                "parameters": [
+                    {
+                        "description": "Group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/codes": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates a code that enrolls the user into the group and grants access to all tests assigned to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create class enrollment code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Class code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createClassCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/codes/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the class enrollment code with its limits, usage count and suspension state",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get class code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/codes/{code}/reactivate": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Reactivate class code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/codes/{code}/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Deactivates the class enrollment code immediately, e.g. when it leaked. Students already enrolled stay in the group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Suspend class code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/codes/{code}/usages": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns which users redeemed the class code and when",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Class code usages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "redeemed_at or email, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_CodeUsage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/members": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Add group member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.addGroupMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}/tests": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Members of the group can start the test without an access code. Only the group creator or an admin can assign, and only tests of the teacher's organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Assign test to group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Test",
+                        "name": "test",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.assignGroupTestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate user and set a session cookie. The X-CSRF-Token response header carries the CSRF token of the new session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login request",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Delete user's session and invalidate cookie",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns notifications of the current user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "List notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Notification type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at or id, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{notification_id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark notification as read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "notification_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Create a new user (email, username, password). Returns created user on success.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register new user",
+                "parameters": [
+                    {
+                        "description": "Register request",
+                        "name": "register",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns the current teacher's subscriptions with the time of the next email and the result of the last one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "List weekly report subscriptions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, next_send_at or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_ReportSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Subscribes the current teacher to a weekly email with activity and results of their tests over the last 7 days:\nattempts started and submitted, attempts in progress and awaiting review, average score and pass rate per test.\nThe email is sent on weekday (0 = Sunday) at hour in timezone, in lang (default: the request language).\nWithout test_ids the report covers all the teacher's tests, see /dashboard/teacher",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Subscribe to the weekly report",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.reportSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReportSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/subscriptions/{subscription_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get weekly report subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReportSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Replaces all settings of the subscription and reschedules the next email. Set paused to stop emails without losing the settings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Update weekly report subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.reportSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReportSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Unsubscribe from the weekly report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/subscriptions/{subscription_id}/send": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Queues the report for the last 7 days immediately, for example to preview the email. The schedule does not change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Send the weekly report now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReportSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns answers awaiting teacher grading in tests of the teacher's organization (all tests for admins), oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "List manual grading queue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending or claimed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "manual or low_confidence",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or test_id, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_ReviewItem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{item_id}/claim": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Assigns the review item to the current teacher so others don't grade it concurrently",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Claim review item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review item ID",
+                        "name": "item_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReviewItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{item_id}/grade": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sets the score for the answer; the attempt is finalized once all its answers are graded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Grade review item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review item ID",
+                        "name": "item_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Score",
+                        "name": "grade",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.gradeReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReviewItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{item_id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns a claimed review item back to the queue",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Release review item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review item ID",
+                        "name": "item_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ReviewItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session": {
+            "get": {
+                "description": "Return user for current session cookie or null if not authenticated. csrf_token is the CSRF token of the session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Check current session",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/test/{test_id}": {
+            "get": {
+                "description": "Retrieves a test by its ID",
+                "summary": "Get test by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns tests without questions. Filters combine with AND",
+                "summary": "List tests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name contains (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Owning organization ID",
+                        "name": "org_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only team (true) or individual (false) tests",
+                        "name": "team_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only tests that do (true) or do not (false) need an access code",
+                        "name": "open_enrollment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id or name, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/deleted": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns deleted tests of the teacher's organization (all tests for admins) without questions, with deletedAt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "List deleted tests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name contains (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id or name, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Marks the test as deleted: it disappears from lists and lookups, new attempts and access codes cannot be created.\nAttempts already started can be finished, results and history are kept",
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Delete test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/ai-config": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sets the assistant ID, model, temperature and help policy used by the AI dialog of this test. Empty object or null resets to server defaults.\nThe help policy is added to the context of new AI threads, strictness (off, low, medium, high) controls refusing requests to solve the question\ntools lists server-side functions the assistant may call (calculator, materials), off disables tools inherited from the test\nhintCost is the number of points deducted from the final result for each assistant reply\ncacheTtl (nanoseconds) enables caching of identical assistant responses per question, 0 disables caching\nmaxTurns limits student messages per AI thread, the thread is closed after the reply to the last one\nlanguage (ISO 639-1 code, e.g. ru, en, kk) forces the assistant to answer only in that language\nparaphrase makes the assistant reword question texts for every new attempt, originals are kept for audit in /attempt/{attempt_id}/paraphrases",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Set test AI configuration",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Test version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "AI configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.AIConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/analytics/ai": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Aggregates submitted attempts: how often each question triggers AI dialogs, average student messages per dialog,\naverage scores with and without the assistant and Pearson correlation between AI usage and scores.\nQuestions that need help most often come first. Correlations are omitted when undefined (fewer than two samples or no variance)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "AI usage analytics of a test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AITestAnalytics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/attempt": {
+            "post": {
+                "description": "Starts a new attempt for the given test with access code validation. Open-enrollment tests don't require a code",
+                "summary": "Start test attempt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Access code for the test",
+                        "name": "access_code",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.startAttemptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Attempt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Retake cooldown is active or too many invalid access codes",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/attempts/history": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Retrieves all completed attempts for the current user and specified test",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attempts"
+                ],
+                "summary": "Get user's attempt history for a test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "finished_at, started_at or result, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Attempt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/attempts/live": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Lists in-progress attempts with last heartbeat time, activity and auto-pause state",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Live attempts of a test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only attempts with a recent heartbeat (true) or without (false)",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only paused (true) or running (false) attempts",
+                        "name": "paused",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "attempt_id, last_seen_at or deadline, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_AttemptLiveness"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/codes": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Creates an access code for the test. Empty code is generated. Codes of tests owned by an organization are prefixed with its code prefix. With bound_email the code is single-use and only valid for that user. With checksum a check character is appended so guessed codes are rejected early. A custom code shaped like XXXX-XXXX-C must carry a valid check character. With activation_window_seconds the code stays valid only for that long after its first use",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "codes"
+                ],
+                "summary": "Create access code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Access code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.createAccessCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/codes/{code}/qr": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns a QR code (PNG or SVG) encoding a deep link to the test with the access code prefilled",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "codes"
+                ],
+                "summary": "Access code QR",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "png (default) or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "PNG size in pixels (default 256, max 1024)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/codes/{code}/reactivate": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "codes"
+                ],
+                "summary": "Reactivate access code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/codes/{code}/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Deactivates the access code immediately, e.g. when it leaked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "codes"
+                ],
+                "summary": "Suspend access code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.AccessCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/codes/{code}/usages": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns which users redeemed the access code and when",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "codes"
+                ],
+                "summary": "Access code usages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "redeemed_at or email, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_CodeUsage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/messages": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sends a teacher message to participants of all started attempts of the test, e.g. a correction to a question. It is stored as a notification and pushed over the WebSocket",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "realtime"
+                ],
+                "summary": "Message everyone taking a test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.teacherMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.teacherMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns active questions of the test with accepted answers and versions. Pass the version in If-Match when updating a question",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "List test questions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name or version, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions/{question_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Marks the question as deleted: new attempts no longer include it, started and finished attempts keep it",
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Delete question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions/{question_id}/ai-config": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Overrides the test AI configuration for one question. Empty object or null removes the override",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Set question AI configuration",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "AI configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.AIConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions/{question_id}/answer": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Replaces the accepted answer of the question. Use the regrade endpoint afterwards to re-evaluate submitted attempts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Fix accepted answer of a question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New accepted answer",
+                        "name": "answer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.updateQuestionAnswerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions/{question_id}/grading": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Sets how answers are graded: auto (exact match), manual (teacher review) or semantic (cosine similarity of embeddings to the reference answer).\nFor semantic grading answers with similarity \u003e= accept get the full score, between review and accept go to the review queue (reason low_confidence), below review get zero.\nDefault thresholds are accept 0.9 and review 0.75. Already graded answers are not changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Set question grading mode",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Grading mode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.questionGradingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions/{question_id}/materials": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Replaces the reference materials of a question. The assistant searches only these materials when the materials tool is enabled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Set question materials",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Question version from the version field or ETag, * skips the check",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Materials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.questionMaterialsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Modified by someone else, details contain current_version",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/questions/{question_id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Clears the deletion mark; new attempts can include the question again. A question of a deleted test is restored after the test",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Restore question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/regrade": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Re-evaluates automatically graded answers of all submitted attempts against current accepted answers and records a regrade event on each affected attempt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Regrade submitted attempts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limit regrade to these questions",
+                        "name": "regrade",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.regradeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.RegradeSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/resources": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "List test resources",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name, size or created_at, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.Page-store_TestResource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Adds a file (up to 10 MB) to the test whitelist, e.g. a dataset a question refers to. Students can attach whitelisted files to their AI threads.\nHTML, SVG, XML and JavaScript files are rejected",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Upload test resource",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Display name (default file name)",
+                        "name": "name",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.TestResource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/resources/{resource_id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Redirects to a signed link to the file, the link is valid for 15 minutes",
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Download test resource",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Location: signed file URL"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Removes the file from the whitelist and deletes it from the file storage and the AI provider storage",
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Delete test resource",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Clears the deletion mark; the test returns to lists with its questions, codes and settings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Restore test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Test"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/results.csv": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Streams graded attempts of the test, oldest first, as CSV (RFC 4180, UTF-8, comma separated): attempt and user,\ntimestamps in RFC 3339, working time without auto-pause, late penalty, assistant cost and result, then a column per question\nwith 1 for a correct answer, 0 for a wrong or missing one and an empty cell if the question was not in the attempt.\nCells starting with = + - @ are prefixed with ' so spreadsheets do not run them as formulas",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Export results as CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/results.xlsx": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Returns an .xlsx workbook with two sheets. \"Summary\" starts with the test statistics (see /tests/{test_id}/stats)\nfollowed by a table of graded attempts, oldest first: attempt and user, timestamps in UTC, working time in minutes\nwithout auto-pause, late penalty, assistant cost, result and percent of the max score.\n\"Questions\" has a row per question that was in at least one graded attempt: how many attempts had it,\nhow many answered it correctly and the share of correct answers",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Export results as an Excel workbook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 60,
+                        "description": "Pass score as percent of the max score, 0-100",
+                        "name": "pass_percent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/similarity": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Lists pairs of attempts by different students whose free-text answers (manual and semantic questions) are suspiciously similar.\nAnswers are compared by shared 3-word sequences, ignoring wording from the question and the reference answer and phrases\ncommon to many answers; answers shorter than 5 such sequences are not compared. Pairs with more similar answers come first.\nThe report is refreshed by a background job after new submissions; without a previous check it is computed on request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Similar answers report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only answers at least this similar, 0.5-1 (default 0.5)",
+                        "name": "min_similarity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.SimilarityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/similarity/check": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Compares the free-text answers of all submitted attempts of the test right away instead of waiting for the background job\nand returns the new report, see GET /tests/{test_id}/similarity",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Check answers for similarity now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.SimilarityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Aggregates attempts of the test: attempts started, in progress, awaiting review and graded, distinct students,\naverage and median result, average working time without auto-pause and pass rate. Scores, durations and pass rate\nare computed over graded attempts and omitted when there are none. The pass score is pass_percent of the test max score, rounded up",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Test statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pass score in percent of the max score (default 60)",
+                        "name": "pass_percent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.TestStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/stats/distribution": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Splits the range from 0 to the test max score into equal buckets and counts graded attempts in each.\nBucket bounds are [from, to), the last bucket includes the max score and results above it.\nThere are never more buckets than score points",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tests"
+                ],
+                "summary": "Score distribution of a test",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of buckets (default 10, max 100)",
+                        "name": "buckets",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ScoreDistribution"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tests/{test_id}/suspicion": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Scores every attempt of the test as in /attempt/{attempt_id}/suspicion and returns attempts with a score\nof at least min_score, the most suspicious first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Test suspicion scores",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Test ID",
+                        "name": "test_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum score, 0-100 (default 1: attempts with at least one factor)",
+                        "name": "min_score",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.TestSuspicion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Upgrades to a WebSocket that pushes JSON events {type, data, at}: time_warning, teacher_message and attempt_graded. The connection is bound to the session cookie and is closed on logout or when the session expires. Browsers may connect only from allowed origins",
+                "tags": [
+                    "realtime"
+                ],
+                "summary": "Real-time events",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiutils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apiutils.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "attempt not found"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "apiutils.Page-scheduler_JobStatus": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.JobStatus"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Attempt": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Attempt"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_AttemptLiveness": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AttemptLiveness"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_CodeUsage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.CodeUsage"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Notification": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Notification"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Organization": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Organization"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Paraphrase": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Paraphrase"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_ProctoringEvent": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ProctoringEvent"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_PromptTemplate": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PromptTemplate"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Question": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Question"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_ReportSubscription": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ReportSubscription"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_ReviewItem": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ReviewItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Test": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Test"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_TestResource": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TestResource"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_Webhook": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Webhook"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apiutils.Page-store_WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.WebhookDelivery"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.PostAnswerRequest": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.Results": {
+            "type": "object",
+            "properties": {
+                "ai_cost": {
+                    "description": "баллы, снятые за подсказки ассистента",
+                    "type": "integer"
+                },
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Answer"
+                    }
+                },
+                "feedback": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Feedback"
+                    }
+                },
+                "late": {
+                    "type": "boolean"
+                },
+                "penalty": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.addGroupMemberRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.aiMessageRequest": {
+            "type": "object",
+            "properties": {
+                "image_ids": {
+                    "description": "изображения, загруженные через .../images",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.aiRunAccepted": {
+            "type": "object",
+            "properties": {
+                "run_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.aiRunResponse": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "cached": {
+                    "description": "ответ взят из кеша без запроса к провайдеру",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "description": "номер ответа ассистента в истории диалога, начиная с 1",
+                    "type": "integer"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "quota": {
+                    "$ref": "#/definitions/store.AIQuota"
+                },
+                "response": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "turns": {
+                    "$ref": "#/definitions/store.AITurns"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.aiStatusResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "retry_after_seconds": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.aiThreadResponse": {
+            "type": "object",
+            "properties": {
+                "active_run": {
+                    "description": "запрос, ответ на который еще не готов",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AIRun"
+                        }
+                    ]
+                },
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "close_reason": {
+                    "description": "attempt_finished или turn_limit",
+                    "type": "string"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "description": "сообщения студента и ответы ассистента",
+                    "type": "integer"
+                },
+                "prompt": {
+                    "description": "версия шаблона промпта, с которой начат диалог",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.PromptVersionRef"
+                        }
+                    ]
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "quota": {
+                    "$ref": "#/definitions/store.AIQuota"
+                },
+                "remote": {
+                    "description": "ok, missing или unknown; для закрытых диалогов не проверяется",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "turns": {
+                    "$ref": "#/definitions/store.AITurns"
+                }
+            }
+        },
+        "handler.aiThreadStarted": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.assignGroupTestRequest": {
+            "type": "object",
+            "required": [
+                "test_id"
+            ],
+            "properties": {
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.attachResourceRequest": {
+            "type": "object",
+            "required": [
+                "resource_id"
+            ],
+            "properties": {
+                "resource_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.attachResourceResponse": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.batchAnswersRequest": {
+            "type": "object",
+            "required": [
+                "answers"
+            ],
+            "properties": {
+                "answers": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/store.BatchAnswer"
+                    }
+                }
+            }
+        },
+        "handler.createAccessCodeRequest": {
+            "type": "object",
+            "properties": {
+                "activation_window_seconds": {
+                    "description": "Срок действия в секундах с момента первого использования, 0 = без ограничения",
+                    "type": "integer"
+                },
+                "bound_email": {
+                    "description": "персональный одноразовый код",
+                    "type": "string"
+                },
+                "checksum": {
+                    "description": "добавить к коду контрольный символ",
+                    "type": "boolean"
+                },
+                "code": {
+                    "description": "пустой = сгенерировать",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "nil = не истекает",
+                    "type": "string"
+                },
+                "max_uses": {
+                    "description": "nil = без ограничений",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "handler.createClassCodeRequest": {
+            "type": "object",
+            "properties": {
+                "activation_window_seconds": {
+                    "description": "Срок действия в секундах с момента первого использования, 0 = без ограничения",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "пустой = сгенерировать",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "nil = не истекает",
+                    "type": "string"
+                },
+                "max_uses": {
+                    "description": "nil = без ограничений",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "handler.createGroupRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "member_ids": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.createOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "code_prefix",
+                "name"
+            ],
+            "properties": {
+                "code_prefix": {
+                    "type": "string",
+                    "maxLength": 8,
+                    "minLength": 2
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.createPromptTemplateRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "text"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.createUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "student",
+                        "teacher",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "handler.createWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "пустой = сгенерировать",
+                    "type": "string",
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.createWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.csrfResponse": {
+            "type": "object",
+            "properties": {
+                "csrf_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.enrollRequest": {
+            "type": "object",
+            "required": [
+                "access_code"
+            ],
+            "properties": {
+                "access_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.feedbackRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "question_position": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.gradeReviewRequest": {
+            "type": "object",
+            "properties": {
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.logLevelResponse": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string"
+                },
+                "revert_at": {
+                    "type": "string"
+                },
+                "revert_to": {
+                    "description": "уровень, который вернется в revert_at",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.loginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.questionGradingRequest": {
+            "type": "object",
+            "required": [
+                "gradingMode"
+            ],
+            "properties": {
+                "gradingMode": {
+                    "type": "string",
+                    "enum": [
+                        "auto",
+                        "manual",
+                        "semantic"
+                    ]
+                },
+                "semantic": {
+                    "$ref": "#/definitions/store.SemanticThresholds"
+                }
+            }
+        },
+        "handler.questionMaterialsRequest": {
+            "type": "object",
+            "required": [
+                "materials"
+            ],
+            "properties": {
+                "materials": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.registerRequest": {
+            "type": "object",
+            "required": [
+                "confirm_password",
+                "email",
+                "password"
+            ],
+            "properties": {
+                "confirm_password": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.regradeRequest": {
+            "type": "object",
+            "properties": {
+                "question_ids": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handler.reportSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "hour": {
+                    "description": "час отправки в timezone",
+                    "type": "integer",
+                    "maximum": 23,
+                    "minimum": 0
+                },
+                "lang": {
+                    "description": "пусто = язык запроса",
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "ru"
+                    ]
+                },
+                "pass_percent": {
+                    "description": "по умолчанию 60",
+                    "type": "integer",
+                    "maximum": 100
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "test_ids": {
+                    "description": "пусто = все тесты преподавателя",
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "timezone": {
+                    "description": "IANA, пусто = UTC",
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "0 = воскресенье",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "handler.setAIBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "monthly_usd": {
+                    "description": "0 = без лимита",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "org_id": {
+                    "description": "0 = общий лимит",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.setLogLevelRequest": {
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "trace",
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ]
+                },
+                "revert_after_seconds": {
+                    "description": "Через сколько секунд вернуть прежний уровень, 0 = оставить навсегда",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.setOrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "org_id": {
+                    "description": "0 = отвязать от организации",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.startAttemptRequest": {
+            "type": "object",
+            "properties": {
+                "access_code": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "description": "обязательно для командных тестов",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.teacherMessageRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "handler.teacherMessageResponse": {
+            "type": "object",
+            "properties": {
+                "recipients": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.updatePromptTemplateRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.updateQuestionAnswerRequest": {
+            "type": "object",
+            "required": [
+                "answer"
+            ],
+            "properties": {
+                "answer": {
+                    "type": "string"
+                }
+            }
+        },
+        "scheduler.JobStatus": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "interval_seconds": {
+                    "type": "number"
+                },
+                "last_duration_seconds": {
+                    "type": "number"
+                },
+                "last_error": {
+                    "description": "ошибка последнего запуска, пусто = успешно",
+                    "type": "string"
+                },
+                "last_finished_at": {
+                    "type": "string"
+                },
+                "last_started_at": {
+                    "type": "string"
+                },
+                "last_success_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "running": {
+                    "type": "boolean"
+                },
+                "runs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIBudget": {
+            "type": "object",
+            "properties": {
+                "exceeded": {
+                    "description": "ассистент отключен до конца месяца или повышения лимита",
+                    "type": "boolean"
+                },
+                "monthly_usd": {
+                    "description": "0 = без лимита",
+                    "type": "number"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "spent_usd": {
+                    "description": "оценка расхода за текущий месяц",
+                    "type": "number"
+                }
+            }
+        },
+        "store.AIBudgetReport": {
+            "type": "object",
+            "properties": {
+                "budgets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AIBudget"
+                    }
+                },
+                "month": {
+                    "description": "UTC, в формате 2006-01",
+                    "type": "string"
+                }
+            }
+        },
+        "store.AIConfig": {
+            "type": "object",
+            "properties": {
+                "assistantId": {
+                    "type": "string"
+                },
+                "cacheTtl": {
+                    "description": "сколько переиспользовать одинаковые ответы на одинаковые запросы, 0 = не кешировать",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "helpPolicy": {
+                    "description": "какую помощь ассистент может оказывать, пусто = DefaultAIHelpPolicy",
+                    "type": "string"
+                },
+                "hintCost": {
+                    "description": "сколько баллов снимается за каждый ответ ассистента, 0 = бесплатно",
+                    "type": "integer"
+                },
+                "language": {
+                    "description": "код языка ISO 639-1, на котором ассистент обязан отвечать, пусто = язык студента",
+                    "type": "string"
+                },
+                "maxTurns": {
+                    "description": "сколько сообщений студент может отправить в один диалог, 0 = без ограничения",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "model": {
+                    "type": "string"
+                },
+                "paraphrase": {
+                    "description": "перефразировать текст вопроса для каждой попытки, чтобы затруднить обмен ответами",
+                    "type": "boolean"
+                },
+                "strictness": {
+                    "description": "строгость отказа на просьбы решить вопрос: off, low, medium, high",
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "low",
+                        "medium",
+                        "high"
+                    ]
+                },
+                "temperature": {
+                    "type": "number",
+                    "maximum": 2,
+                    "minimum": 0
+                },
+                "tools": {
+                    "description": "серверные функции ассистента через запятую (calculator, materials), off = без функций",
+                    "type": "string"
+                }
+            }
+        },
+        "store.AIImage": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIMessage": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "description": "ответ взят из кеша",
+                    "type": "boolean"
+                },
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "cost_usd": {
+                    "description": "оценка стоимости ответа по тарифам модели",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hint_cost": {
+                    "description": "сколько баллов стоил ответ ассистента",
+                    "type": "integer"
+                },
+                "images": {
+                    "description": "изображения, приложенные к сообщению студента",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "model": {
+                    "description": "модель, ответившая на сообщение",
+                    "type": "string"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "автор сообщения или пользователь, запросивший ответ",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIQuestionAnalytics": {
+            "type": "object",
+            "properties": {
+                "ai_rate": {
+                    "description": "доля попыток с диалогом",
+                    "type": "number"
+                },
+                "attempts": {
+                    "description": "сданные попытки, в которые попал вопрос",
+                    "type": "integer"
+                },
+                "attempts_with_ai": {
+                    "description": "из них с диалогом по вопросу",
+                    "type": "integer"
+                },
+                "avg_score_with_ai": {
+                    "description": "средний балл за вопрос с ассистентом",
+                    "type": "number"
+                },
+                "avg_score_without_ai": {
+                    "description": "средний балл за вопрос без ассистента",
+                    "type": "number"
+                },
+                "avg_turns": {
+                    "description": "среднее число сообщений студента в диалоге",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "threads": {
+                    "description": "диалоги, в которых студент написал хотя бы одно сообщение",
+                    "type": "integer"
+                },
+                "turns_score_correlation": {
+                    "description": "корреляция Пирсона числа сообщений и балла",
+                    "type": "number"
+                }
+            }
+        },
+        "store.AIQuota": {
+            "type": "object",
+            "properties": {
+                "ai_cost": {
+                    "description": "сколько баллов уже снято за подсказки",
+                    "type": "integer"
+                },
+                "message_limit": {
+                    "type": "integer"
+                },
+                "messages_remaining": {
+                    "type": "integer"
+                },
+                "messages_used": {
+                    "type": "integer"
+                },
+                "token_limit": {
+                    "type": "integer"
+                },
+                "tokens_remaining": {
+                    "type": "integer"
+                },
+                "tokens_used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIRun": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "cached": {
+                    "description": "ответ взят из кеша без запроса к провайдеру",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "description": "номер ответа ассистента в истории диалога, начиная с 1",
+                    "type": "integer"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "response": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AITestAnalytics": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "attempts_with_ai": {
+                    "type": "integer"
+                },
+                "avg_result_with_ai": {
+                    "type": "number"
+                },
+                "avg_result_without_ai": {
+                    "type": "number"
+                },
+                "avg_turns_per_attempt": {
+                    "type": "number"
+                },
+                "questions": {
+                    "description": "чаще всего требующие помощи первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AIQuestionAnalytics"
+                    }
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "turns_result_correlation": {
+                    "description": "корреляция Пирсона числа сообщений и результата",
+                    "type": "number"
+                }
+            }
+        },
+        "store.AIThread": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "description": "файлы из белого списка теста, прикрепленные к диалогу",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "close_reason": {
+                    "description": "attempt_finished или turn_limit",
+                    "type": "string"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AIMessage"
+                    }
+                },
+                "prompt": {
+                    "description": "версия шаблона промпта, по которой собран контекст",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.PromptVersionRef"
+                        }
+                    ]
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.AITurns": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIUsage": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "cost_usd": {
+                    "description": "оценка расхода по тарифам моделей",
+                    "type": "number"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIUsageEntry": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "cost_usd": {
+                    "description": "оценка расхода по тарифам моделей",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AIUsageReport": {
+            "type": "object",
+            "properties": {
+                "group_by": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AIUsageEntry"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/store.AIUsage"
+                }
+            }
+        },
+        "store.AccessCode": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "description": "когда код был использован впервые",
+                    "type": "string"
+                },
+                "activation_window": {
+                    "description": "сколько код действует после первого использования, 0 = без ограничения",
+                    "type": "integer"
+                },
+                "bound_email": {
+                    "description": "персональный одноразовый код для пользователя с этим email",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "сам код доступа",
+                    "type": "string"
+                },
+                "consumed_at": {
+                    "description": "когда персональный код был использован",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "nil = не истекает",
+                    "type": "string"
+                },
+                "group_id": {
+                    "description": "код класса: зачисляет в группу и дает доступ ко всем ее тестам",
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "description": "nil = бесконечный, число = ограничение",
+                    "type": "integer"
+                },
+                "org_id": {
+                    "description": "организация, в пространстве имен которой создан код",
+                    "type": "integer"
+                },
+                "suspended": {
+                    "description": "код отозван и не принимается",
+                    "type": "boolean"
+                },
+                "suspended_at": {
+                    "description": "когда код был отозван",
+                    "type": "string"
+                },
+                "test_id": {
+                    "description": "к какому тесту относится",
+                    "type": "integer"
+                },
+                "used_count": {
+                    "description": "сколько раз использован",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ActivityPoint": {
+            "type": "object",
+            "properties": {
+                "start": {
+                    "type": "string"
+                },
+                "started": {
+                    "type": "integer"
+                },
+                "submitted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ActivityReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "series": {
+                    "description": "самые активные первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ActivitySeries"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.ActivitySeries": {
+            "type": "object",
+            "properties": {
+                "org_id": {
+                    "type": "integer"
+                },
+                "points": {
+                    "description": "все дни или часы периода, включая пустые",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ActivityPoint"
+                    }
+                },
+                "started": {
+                    "description": "всего за период",
+                    "type": "integer"
+                },
+                "submitted": {
+                    "description": "всего за период",
+                    "type": "integer"
+                },
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Answer": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "right_or_no": {
+                    "type": "boolean"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "близость к эталонному ответу в режиме semantic",
+                    "type": "number"
+                },
+                "status": {
+                    "description": "graded или pending_review",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Attempt": {
+            "type": "object",
+            "properties": {
+                "ai_cost": {
+                    "description": "сколько баллов снято за подсказки ассистента",
+                    "type": "integer"
+                },
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Answer"
+                    }
+                },
+                "feedback": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Feedback"
+                    }
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "description": "команда для командной попытки",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_seen_at": {
+                    "description": "время последнего heartbeat",
+                    "type": "string"
+                },
+                "late": {
+                    "description": "сдана в льготный период после дедлайна",
+                    "type": "boolean"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ParticipantEntry"
+                    }
+                },
+                "paused_for": {
+                    "description": "суммарное время автопаузы, сдвигает дедлайн",
+                    "type": "integer"
+                },
+                "penalty": {
+                    "description": "сколько баллов снято за опоздание",
+                    "type": "integer"
+                },
+                "question_ids": {
+                    "description": "ID выбранных вопросов в порядке показа студенту",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "regrades": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.RegradeEvent"
+                    }
+                },
+                "result": {
+                    "type": "integer"
+                },
+                "seed": {
+                    "description": "сид случайного выбора вопросов",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AttemptLiveness": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AttemptSuspicion": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "factors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.SuspicionFactor"
+                    }
+                },
+                "level": {
+                    "description": "low, medium или high",
+                    "type": "string"
+                },
+                "result": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "от 0 до 100, сумма баллов признаков",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AttemptTimeline": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "events": {
+                    "description": "по времени",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TimelineEvent"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "description": "журнал переполнен: поздние открытия вопросов и черновики не записаны",
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.AttemptTranscript": {
+            "type": "object",
+            "properties": {
+                "ai_cost": {
+                    "type": "integer"
+                },
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.QuestionTranscript"
+                    }
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.BatchAnswer": {
+            "type": "object",
+            "required": [
+                "client_timestamp",
+                "question_position"
+            ],
+            "properties": {
+                "client_timestamp": {
+                    "type": "string"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.BatchAnswerResult": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "boolean"
+                },
+                "answer": {
+                    "$ref": "#/definitions/store.Answer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "question_position": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.CodeUsage": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "redeemed_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Feedback": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "author_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "question_position": {
+                    "description": "nil = комментарий ко всей попытке",
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Group": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "test_ids": {
+                    "description": "тесты, назначенные классу",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "store.ImportResult": {
+            "type": "object",
+            "properties": {
+                "access_codes": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tests": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Notification": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Organization": {
+            "type": "object",
+            "properties": {
+                "code_prefix": {
+                    "description": "обязательный префикс всех кодов организации",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Paraphrase": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "original": {
+                    "type": "string"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.ParticipantEntry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ProctoringEvent": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "description": "сработавшие категории или правила",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "description": "текст, вызвавший событие",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "кто зафиксировал событие",
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.PromptTemplate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PromptVersion"
+                    }
+                }
+            }
+        },
+        "store.PromptVersion": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.PromptVersionRef": {
+            "type": "object",
+            "properties": {
+                "template_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Question": {
+            "type": "object",
+            "properties": {
+                "aiConfig": {
+                    "description": "переопределяет настройки ассистента теста",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AIConfig"
+                        }
+                    ]
+                },
+                "answer": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "мягкое удаление, см. DeleteQuestion",
+                    "type": "string"
+                },
+                "gradingMode": {
+                    "description": "auto (по умолчанию), manual или semantic",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "materials": {
+                    "description": "справочные материалы, доступные ассистенту через функцию materials",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "maxScore": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "semantic": {
+                    "description": "пороги близости для режима semantic, nil = по умолчанию",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.SemanticThresholds"
+                        }
+                    ]
+                },
+                "text": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "растет при каждом изменении, см. version.go",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.QuestionTranscript": {
+            "type": "object",
+            "properties": {
+                "answer_text": {
+                    "type": "string"
+                },
+                "hint_cost": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TranscriptMessage"
+                    }
+                },
+                "messages_blocked": {
+                    "description": "сообщения, заблокированные модерацией или отказом",
+                    "type": "integer"
+                },
+                "messages_sent": {
+                    "description": "сообщения, на которые ассистент ответил",
+                    "type": "integer"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "question_text": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.RegradeEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "new_result": {
+                    "type": "integer"
+                },
+                "old_result": {
+                    "type": "integer"
+                },
+                "regraded_by": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.RegradeSummary": {
+            "type": "object",
+            "properties": {
+                "answers_regraded": {
+                    "type": "integer"
+                },
+                "attempts_changed": {
+                    "type": "integer"
+                },
+                "attempts_checked": {
+                    "type": "integer"
+                },
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ReportSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "hour": {
+                    "description": "час отправки в часовом поясе Timezone",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lang": {
+                    "description": "язык письма",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "ошибка последней отправки, пусто = успешно",
+                    "type": "string"
+                },
+                "last_sent_at": {
+                    "description": "когда письмо последний раз ушло в очередь",
+                    "type": "string"
+                },
+                "next_send_at": {
+                    "description": "когда будет отправлено следующее письмо",
+                    "type": "string"
+                },
+                "pass_percent": {
+                    "description": "проходной балл в процентах от максимального",
+                    "type": "integer"
+                },
+                "paused": {
+                    "description": "письма не отправляются, настройки сохраняются",
+                    "type": "boolean"
+                },
+                "test_ids": {
+                    "description": "пусто = все тесты преподавателя",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "timezone": {
+                    "description": "IANA, например Europe/Moscow",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "weekday": {
+                    "description": "день отправки, 0 = воскресенье",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ReviewItem": {
+            "type": "object",
+            "properties": {
+                "answer_text": {
+                    "type": "string"
+                },
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "claimed_at": {
+                    "type": "string"
+                },
+                "claimed_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "graded_at": {
+                    "type": "string"
+                },
+                "graded_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_score": {
+                    "type": "integer"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "question_position": {
+                    "type": "integer"
+                },
+                "question_text": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reference_answer": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ScoreBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ScoreDistribution": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ScoreBucket"
+                    }
+                },
+                "max_score": {
+                    "type": "integer"
+                },
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.SemanticThresholds": {
+            "type": "object",
+            "properties": {
+                "accept": {
+                    "type": "number",
+                    "maximum": 1
+                },
+                "review": {
+                    "type": "number"
+                }
+            }
+        },
+        "store.SimilarAnswers": {
+            "type": "object",
+            "properties": {
+                "position_a": {
+                    "description": "позиция вопроса в попытке A",
+                    "type": "integer"
+                },
+                "position_b": {
+                    "type": "integer"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "question_text": {
+                    "type": "string"
+                },
+                "similarity": {
+                    "description": "доля общих шинглов без слов вопроса и эталона",
+                    "type": "number"
+                },
+                "text_a": {
+                    "type": "string"
+                },
+                "text_b": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.SimilarPair": {
+            "type": "object",
+            "properties": {
+                "answers": {
+                    "description": "самые похожие первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.SimilarAnswers"
+                    }
+                },
+                "attempt_a": {
+                    "type": "integer"
+                },
+                "attempt_b": {
+                    "type": "integer"
+                },
+                "max_similarity": {
+                    "type": "number"
+                },
+                "user_a": {
+                    "type": "integer"
+                },
+                "user_b": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.SimilarityReport": {
+            "type": "object",
+            "properties": {
+                "answers_compared": {
+                    "description": "развернутые ответы достаточной длины",
+                    "type": "integer"
+                },
+                "attempts_checked": {
+                    "description": "сданные попытки",
+                    "type": "integer"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "pairs": {
+                    "description": "больше похожих ответов и выше близость - раньше",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.SimilarPair"
+                    }
+                },
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.StudentAttempt": {
+            "type": "object",
+            "properties": {
+                "answered": {
+                    "type": "integer"
+                },
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "deadline": {
+                    "description": "без лимита времени дедлайна нет",
+                    "type": "string"
+                },
+                "group_id": {
+                    "description": "командная попытка",
+                    "type": "integer"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "questions": {
+                    "type": "integer"
+                },
+                "remaining_seconds": {
+                    "description": "Оставшееся время с учетом автопаузы, не меньше 0; только при лимите времени",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "test_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.StudentDashboard": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "можно начать сейчас",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.StudentTest"
+                    }
+                },
+                "in_progress": {
+                    "description": "меньше всего времени осталось первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.StudentAttempt"
+                    }
+                },
+                "recent_results": {
+                    "description": "последние сданные первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.StudentResult"
+                    }
+                },
+                "upcoming": {
+                    "description": "можно будет начать после паузы между попытками, ближайшие первыми",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.StudentTest"
+                    }
+                }
+            }
+        },
+        "store.StudentResult": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "late": {
+                    "type": "boolean"
+                },
+                "max_score": {
+                    "type": "integer"
+                },
+                "penalty": {
+                    "type": "integer"
+                },
+                "result": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "grading - результат еще не окончательный",
+                    "type": "string"
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "test_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.StudentTest": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "description": "назначен классу студента, иначе тест со свободным доступом",
+                    "type": "boolean"
+                },
+                "attempts": {
+                    "description": "попытки студента по тесту",
+                    "type": "integer"
+                },
+                "available_at": {
+                    "description": "Когда закончится пауза между попытками, только у тестов из upcoming",
+                    "type": "string"
+                },
+                "best_result": {
+                    "description": "лучший результат оцененных попыток",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "max_score": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "team_mode": {
+                    "type": "boolean"
+                },
+                "test_id": {
+                    "type": "integer"
+                },
+                "time_limit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.SuspicionFactor": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "explanation": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "question_positions": {
+                    "description": "вопросы, к которым относится признак",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "related_attempts": {
+                    "description": "попытки с похожими ответами",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "store.SyntheticOptions": {
+            "type": "object",
+            "properties": {
+                "attempts_per_user": {
+                    "description": "в среднем, у каждого свое число попыток",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "days": {
+                    "description": "за сколько последних дней распределить попытки, по умолчанию 30",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "email_domain": {
+                    "description": "по умолчанию load.test",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "пароль всех созданных пользователей, по умолчанию test",
+                    "type": "string"
+                },
+                "seed": {
+                    "description": "0 = случайный",
+                    "type": "integer"
+                },
+                "users": {
+                    "description": "студенты",
+                    "type": "integer",
+                    "maximum": 100000,
+                    "minimum": 1
+                }
+            }
+        },
+        "store.SyntheticResult": {
+            "type": "object",
+            "properties": {
+                "answers": {
+                    "type": "integer"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "first_user_id": {
+                    "type": "integer"
+                },
+                "last_user_id": {
+                    "type": "integer"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.TeacherDashboard": {
             "type": "object",
             "properties": {
-                "text": {
+                "ai_usage": {
+                    "$ref": "#/definitions/store.AIUsage"
+                },
+                "awaiting_review": {
+                    "type": "integer"
+                },
+                "open_attempts": {
+                    "type": "integer"
+                },
+                "recent_submissions": {
+                    "type": "integer"
+                },
+                "since": {
                     "type": "string"
+                },
+                "tests": {
+                    "description": "сначала тесты с недавней сдачей",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TeacherTestSummary"
+                    }
                 }
             }
         },
-        "handler.loginRequest": {
+        "store.TeacherTestSummary": {
             "type": "object",
             "properties": {
-                "email": {
+                "ai_hint_cost": {
+                    "description": "баллы, снятые за подсказки ассистента во всех попытках",
+                    "type": "integer"
+                },
+                "ai_usage": {
+                    "description": "расход токенов ассистента по тесту",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AIUsage"
+                        }
+                    ]
+                },
+                "attempts_graded": {
+                    "description": "оценены за все время, по ним считается средний результат",
+                    "type": "integer"
+                },
+                "avg_score": {
+                    "description": "средний результат оцененных попыток",
+                    "type": "number"
+                },
+                "awaiting_review": {
+                    "description": "сданы и ждут ручной проверки",
+                    "type": "integer"
+                },
+                "last_submission_at": {
                     "type": "string"
                 },
-                "password": {
+                "max_score": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
+                },
+                "open_attempts": {
+                    "description": "идут сейчас",
+                    "type": "integer"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "recent_avg_score": {
+                    "description": "средний результат оцененных после since",
+                    "type": "number"
+                },
+                "recent_submissions": {
+                    "description": "сданы после since, включая ждущие проверки",
+                    "type": "integer"
+                },
+                "test_id": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.registerRequest": {
+        "store.Test": {
             "type": "object",
             "properties": {
-                "confirm_password": {
+                "aiConfig": {
+                    "description": "Ассистент, модель и температура теста, nil = настройки сервера",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AIConfig"
+                        }
+                    ]
+                },
+                "aiMessageLimit": {
+                    "description": "Сколько сообщений ассистенту можно отправить за попытку, 0 = без ограничений",
+                    "type": "integer"
+                },
+                "aiTokenLimit": {
+                    "description": "Сколько токенов ассистента можно израсходовать за попытку, 0 = без ограничений",
+                    "type": "integer"
+                },
+                "autoPauseAfter": {
+                    "description": "Через сколько без heartbeat попытка ставится на паузу, 0 = не ставится. Паузы вместе не длиннее TimeLimit",
+                    "type": "integer"
+                },
+                "deletedAt": {
+                    "description": "Мягкое удаление, см. DeleteTest",
                     "type": "string"
                 },
-                "email": {
+                "description": {
                     "type": "string"
                 },
-                "password": {
+                "gracePeriod": {
+                    "description": "Льготный период после дедлайна, 0 = без льготного периода",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latePenalty": {
+                    "description": "Штраф в процентах от результата за сдачу в льготный период",
+                    "type": "integer"
+                },
+                "maxScore": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "numOfQuestions": {
+                    "description": "Количество вопросов, которые нужно выбрать для попытки",
+                    "type": "integer"
+                },
+                "openEnrollment": {
+                    "description": "Попытку можно начать без кода доступа",
+                    "type": "boolean"
+                },
+                "orgId": {
+                    "description": "Организация-владелец теста",
+                    "type": "integer"
+                },
+                "prompt": {
+                    "description": "Шаблон системного промпта, nil = контекст по умолчанию",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.TestPrompt"
+                        }
+                    ]
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Question"
+                    }
+                },
+                "retakeCooldown": {
+                    "description": "Минимальная пауза между попытками одного пользователя, 0 = без ограничений",
+                    "type": "integer"
+                },
+                "teamMode": {
+                    "description": "Тест проходится командой (группой) в одной общей попытке",
+                    "type": "boolean"
+                },
+                "timeLimit": {
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Растет при каждом изменении теста, см. version.go",
+                    "type": "integer"
+                }
+            }
+        },
+        "store.TestPrompt": {
+            "type": "object",
+            "properties": {
+                "language": {
                     "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "templateId": {
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "0 = всегда последняя версия",
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "store.Answer": {
+        "store.TestResource": {
             "type": "object",
             "properties": {
+                "content_type": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "created_by": {
                     "type": "integer"
                 },
-                "question_id": {
+                "id": {
                     "type": "integer"
                 },
-                "text": {
+                "name": {
+                    "type": "string"
+                },
+                "provider_file_id": {
+                    "description": "файл у провайдера, загружается при первом прикреплении",
                     "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "test_id": {
+                    "type": "integer"
                 }
             }
         },
-        "store.Attempt": {
+        "store.TestStats": {
             "type": "object",
             "properties": {
-                "answers": {
+                "attempts_grading": {
+                    "description": "сданы и ждут ручной проверки",
+                    "type": "integer"
+                },
+                "attempts_in_progress": {
+                    "description": "еще идут",
+                    "type": "integer"
+                },
+                "attempts_started": {
+                    "description": "все попытки, включая незаконченные",
+                    "type": "integer"
+                },
+                "attempts_submitted": {
+                    "description": "сданы и оценены, по ним считаются баллы",
+                    "type": "integer"
+                },
+                "avg_duration_seconds": {
+                    "description": "Среднее время работы над попыткой в секундах, без автопаузы",
+                    "type": "number"
+                },
+                "avg_score": {
+                    "description": "средний результат",
+                    "type": "number"
+                },
+                "max_score": {
+                    "type": "integer"
+                },
+                "median_score": {
+                    "description": "медиана результата",
+                    "type": "number"
+                },
+                "pass_rate": {
+                    "description": "доля оцененных попыток с результатом не ниже проходного",
+                    "type": "number"
+                },
+                "pass_score": {
+                    "description": "проходной балл",
+                    "type": "integer"
+                },
+                "students": {
+                    "description": "разные студенты, начавшие тест",
+                    "type": "integer"
+                },
+                "test_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.TestSuspicion": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "самые подозрительные первыми",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/store.Answer"
+                        "$ref": "#/definitions/store.AttemptSuspicion"
                     }
                 },
-                "finished_at": {
+                "attempts_checked": {
+                    "type": "integer"
+                },
+                "similarity_checked_at": {
+                    "description": "когда искали похожие ответы, пусто = еще не искали",
                     "type": "string"
                 },
-                "id": {
+                "test_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "store.TimelineEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
                 },
-                "result": {
+                "categories": {
+                    "description": "сработавшие категории модерации или правило отказа",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "detail": {
+                    "description": "тип события прокторинга; ai_moderation или ai_refusal - сообщение не дошло до ассистента",
+                    "type": "string"
+                },
+                "question_position": {
                     "type": "integer"
                 },
-                "started_at": {
+                "received_at": {
+                    "description": "когда сервер получил ответ, сохраненный офлайн",
                     "type": "string"
                 },
-                "status": {
+                "role": {
+                    "description": "роль в диалоге с ассистентом",
                     "type": "string"
                 },
-                "test_id": {
+                "score": {
+                    "description": "баллы за ответ или результат попытки",
                     "type": "integer"
                 },
+                "text": {
+                    "description": "ответ, сообщение диалога с ассистентом или комментарий",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
                 "user_id": {
+                    "description": "кто действовал: студент, участник команды или преподаватель",
                     "type": "integer"
                 }
             }
         },
-        "store.Question": {
+        "store.TranscriptMessage": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
+                "blocked": {
+                    "description": "ai_moderation или ai_refusal: сообщение не дошло до ассистента",
+                    "type": "string"
                 },
-                "maxScore": {
+                "cached": {
+                    "type": "boolean"
+                },
+                "categories": {
+                    "description": "сработавшие категории модерации или правило отказа",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hint_cost": {
                     "type": "integer"
                 },
-                "name": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "role": {
                     "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "store.Test": {
+        "store.User": {
             "type": "object",
             "properties": {
-                "description": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "мягкое удаление, см. DeleteUser",
+                    "type": "string"
+                },
+                "email": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "maxScore": {
+                "org_id": {
                     "type": "integer"
                 },
-                "name": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "numOfQuestions": {
-                    "description": "Количество вопросов, которые нужно выбрать для попытки",
+                "created_by": {
                     "type": "integer"
                 },
-                "questions": {
+                "events": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/store.Question"
+                        "type": "string"
                     }
                 },
-                "timeLimit": {
+                "id": {
                     "type": "integer"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "store.User": {
+        "store.WebhookDelivery": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "email": {
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "response_status": {
+                    "description": "код последнего ответа получателя",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
                 }
             }
         }
@@ -578,8 +9965,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "API for web-site GEEK",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
-	LeftDelim:        "{{",
-	RightDelim:       "}}",
+	LeftDelim:        "[[",
+	RightDelim:       "]]",
 }
 
 func init() {
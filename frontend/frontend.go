@@ -0,0 +1,86 @@
+// Package frontend отдает собранный фронтенд (SPA) из каталога, чтобы небольшим установкам
+// не нужен был отдельный веб-сервер. Пути клиентской маршрутизации (/tests/3, /admin/users),
+// которым не соответствует файл, получают index.html, дальше маршрут разбирает приложение
+package frontend
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// assetsPath - каталог сборки с хешами в именах файлов (так собирают Vite и create-react-app):
+// содержимое по имени не меняется, поэтому кешируется навсегда
+const assetsPath = "/assets/"
+
+// Frontend отдает файлы из Dir. index.html и остальные файлы без хеша в имени браузер
+// перепроверяет при каждом запросе, так что новая сборка видна без перезапуска сервера
+type Frontend struct {
+	Dir string
+}
+
+// New проверяет, что в каталоге есть index.html, и создает обработчик
+func New(dir string) (*Frontend, error) {
+	info, err := os.Stat(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, errors.New("index.html is a directory")
+	}
+	return &Frontend{Dir: dir}, nil
+}
+
+// ServeHTTP отдает файл по пути запроса, а если его нет - index.html. Отсутствующий файл
+// с расширением (/assets/app.js, /favicon.ico) - 404: вместо него HTML сломал бы страницу
+// непонятной ошибкой разбора
+func (f *Frontend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + r.URL.Path)
+
+	file, info, err := f.open(name)
+	if err != nil {
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = "/index.html"
+		if file, info, err = f.open(name); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	defer file.Close()
+
+	if strings.HasPrefix(name, assetsPath) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
+
+// open открывает обычный файл сборки. Каталоги и скрытые файлы (.env, .git) считаются отсутствующими
+func (f *Frontend) open(name string) (http.File, fs.FileInfo, error) {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return nil, nil, fs.ErrNotExist
+		}
+	}
+
+	file, err := http.Dir(f.Dir).Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, nil, fs.ErrNotExist
+	}
+	return file, info, nil
+}
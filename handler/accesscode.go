@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type createAccessCodeRequest struct {
+	Code       string     `json:"code"`                                   // пустой = сгенерировать
+	MaxUses    *uint64    `json:"max_uses" validate:"omitempty,gte=1"`    // nil = без ограничений
+	ExpiresAt  *time.Time `json:"expires_at"`                             // nil = не истекает
+	BoundEmail string     `json:"bound_email" validate:"omitempty,email"` // персональный одноразовый код
+	Checksum   bool       `json:"checksum"`                               // добавить к коду контрольный символ
+	// Срок действия в секундах с момента первого использования, 0 = без ограничения
+	ActivationWindowSeconds uint64 `json:"activation_window_seconds"`
+}
+
+// CreateAccessCode создает код доступа к тесту
+// @Summary Create access code
+// @Description Creates an access code for the test. Empty code is generated. Codes of tests owned by an organization are prefixed with its code prefix. With bound_email the code is single-use and only valid for that user. With checksum a check character is appended so guessed codes are rejected early. A custom code shaped like XXXX-XXXX-C must carry a valid check character. With activation_window_seconds the code stays valid only for that long after its first use
+// @Tags codes
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param code body createAccessCodeRequest true "Access code"
+// @Success 201 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/codes [post]
+// @Security CookieAuth
+func (h *Handler) CreateAccessCode(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	var request createAccessCodeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	accessCode, err := h.Store.CreateAccessCode(request.Code, testID, store.AccessCodeOptions{
+		MaxUses:          request.MaxUses,
+		ExpiresAt:        request.ExpiresAt,
+		BoundEmail:       request.BoundEmail,
+		ActivationWindow: time.Duration(request.ActivationWindowSeconds) * time.Second,
+		Checksum:         request.Checksum,
+	})
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, accessCode)
+}
+
+// SuspendAccessCode немедленно отзывает код доступа
+// @Summary Suspend access code
+// @Description Deactivates the access code immediately, e.g. when it leaked
+// @Tags codes
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param code path string true "Access code"
+// @Success 200 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/codes/{code}/suspend [post]
+// @Security CookieAuth
+func (h *Handler) SuspendAccessCode(w http.ResponseWriter, r *http.Request) {
+	h.setAccessCodeSuspended(w, r, true)
+}
+
+// ReactivateAccessCode возвращает отозванный код в работу
+// @Summary Reactivate access code
+// @Tags codes
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param code path string true "Access code"
+// @Success 200 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/codes/{code}/reactivate [post]
+// @Security CookieAuth
+func (h *Handler) ReactivateAccessCode(w http.ResponseWriter, r *http.Request) {
+	h.setAccessCodeSuspended(w, r, false)
+}
+
+func (h *Handler) setAccessCodeSuspended(w http.ResponseWriter, r *http.Request, suspended bool) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	accessCode, err := h.Store.SetAccessCodeSuspended(testID, vars["code"], suspended)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, accessCode)
+}
+
+// ListCodeUsages возвращает список использований кода доступа
+// @Summary Access code usages
+// @Description Returns which users redeemed the access code and when
+// @Tags codes
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param code path string true "Access code"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "redeemed_at or email, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.CodeUsage]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/codes/{code}/usages [get]
+// @Security CookieAuth
+func (h *Handler) ListCodeUsages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	usages, err := h.Store.ListCodeUsages(testID, vars["code"])
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeList(w, r, usages, codeUsageSorts)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+	"time"
+)
+
+// Период временного ряда активности по умолчанию, отсчитывается назад от to
+const (
+	defaultActivityDays  = 30
+	defaultActivityHours = 48
+)
+
+// ActivityReport возвращает временные ряды начатых и сданных попыток для графиков активности
+// @Summary Attempt activity time series
+// @Description Counts started and submitted attempts per day or hour over [from, to), for all attempts or split by test or organization.
+// @Description Every day or hour of the range is present, empty ones with zero counts; from is rounded down to the start of its day or hour.
+// @Description Days start at midnight in tz. An attempt is submitted when it finished in the range, including attempts awaiting review,
+// @Description and belongs to the organization of its test. Series with the most activity come first
+// @Tags admin
+// @Produce json
+// @Param interval query string false "day or hour (default day)"
+// @Param from query string false "Range start (RFC3339), default 30 days or 48 hours before to"
+// @Param to query string false "Range end, exclusive (RFC3339), default now"
+// @Param tz query string false "IANA time zone for day boundaries, e.g. Europe/Moscow (default UTC)"
+// @Param test_id query int false "Only attempts of this test"
+// @Param org_id query int false "Only attempts of tests of this organization"
+// @Param group_by query string false "test or organization, a series per test or organization; empty for a single series"
+// @Success 200 {object} store.ActivityReport
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/activity [get]
+// @Security CookieAuth
+func (h *Handler) ActivityReport(w http.ResponseWriter, r *http.Request) {
+	filters := apiutils.NewFilters(r)
+	query := store.ActivityQuery{
+		Interval: filters.OneOf("interval", store.ActivityByDay, store.ActivityByHour),
+		From:     filters.Time("from"),
+		To:       filters.Time("to"),
+		TestID:   filters.Uint("test_id"),
+		OrgID:    filters.Uint("org_id"),
+		GroupBy:  filters.OneOf("group_by", store.ActivityByTest, store.ActivityByOrg),
+	}
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	location, err := time.LoadLocation(filters.String("tz"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "unknown tz")
+		return
+	}
+	query.Location = location
+
+	if query.Interval == "" {
+		query.Interval = store.ActivityByDay
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		if query.Interval == store.ActivityByHour {
+			query.From = query.To.Add(-defaultActivityHours * time.Hour)
+		} else {
+			query.From = query.To.AddDate(0, 0, -defaultActivityDays)
+		}
+	}
+
+	report, err := h.Store.ActivityReport(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, report)
+}
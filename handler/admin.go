@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+)
+
+// ListAttempts возвращает попытки с фильтрами и пагинацией для администратора
+// @Summary Browse attempts
+// @Description Lists attempts filtered by user, test, status and start date range, newest first
+// @Tags admin
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param test_id query int false "Test ID"
+// @Param status query string false "Attempt status"
+// @Param from query string false "Started at or after (RFC3339)"
+// @Param to query string false "Started at or before (RFC3339)"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Page offset, ignored with cursor"
+// @Param sort query string false "id, result, started_at or finished_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Attempt]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/attempts [get]
+// @Security CookieAuth
+func (h *Handler) ListAttempts(w http.ResponseWriter, r *http.Request) {
+	filters := apiutils.NewFilters(r)
+	filter := store.AttemptFilter{
+		UserID: filters.Uint("user_id"),
+		TestID: filters.Uint("test_id"),
+		Status: filters.String("status"),
+		From:   filters.Time("from"),
+		To:     filters.Time("to"),
+	}
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	writeList(w, r, h.Store.ListAttempts(filter), attemptSorts)
+}
+
+// ListJobs возвращает состояние фоновых задач
+// @Summary List background jobs
+// @Description Shows registered background jobs with their interval, run counts and the result of the last run
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "name, runs or failures, prefix - for descending"
+// @Success 200 {object} apiutils.Page[scheduler.JobStatus]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/jobs [get]
+// @Security CookieAuth
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	writeList(w, r, h.Jobs.Status(), jobStatusSorts)
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"GEEK_back/aicache"
+	"GEEK_back/aitools"
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	"GEEK_back/limiter"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"GEEK_back/tracing"
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// saveAIMessage сохраняет сообщение в истории диалога; ошибка не прерывает ответ пользователю
+func (h *Handler) saveAIMessage(threadID string, message store.AIMessage) {
+	if err := h.Store.AppendAIMessage(threadID, message); err != nil {
+		log.Warn().Err(err).Str("thread_id", threadID).Msg("failed to save ai message")
+	}
+}
+
+// assistantAIMessage собирает ответ ассистента с расходом токенов и оценкой его стоимости.
+// Если провайдер не сообщил модель, берется модель из настроек теста
+func (h *Handler) assistantAIMessage(userID uint64, reply *llm.Reply, opts llm.Options) store.AIMessage {
+	message := store.AIMessage{Role: store.AIRoleAssistant, UserID: userID, Text: reply.Text, Model: reply.Model}
+	if message.Model == "" {
+		message.Model = opts.Model
+	}
+	if reply.Usage != nil {
+		message.PromptTokens = reply.Usage.PromptTokens
+		message.CompletionTokens = reply.Usage.CompletionTokens
+		message.CostUSD = h.Pricing.Cost(message.Model, message.PromptTokens, message.CompletionTokens)
+	}
+	return message
+}
+
+// GetAIMessages возвращает историю диалога с ассистентом
+// @Summary Get AI conversation
+// @Description Returns all user messages and assistant replies of the thread. Available to attempt participants and teachers
+// @Tags ai
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Success 200 {object} store.AIThread
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/messages [get]
+// @Security CookieAuth
+func (h *Handler) GetAIMessages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	thread, err := h.Store.GetAIThread(attemptID, vars["thread_id"], userID)
+	switch {
+	case errors.Is(err, store.ErrAIThreadNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, thread)
+}
+
+// aiQuotaDetails - подробности ошибки ai_quota_exhausted
+type aiQuotaDetails struct {
+	Quota *store.AIQuota `json:"quota"`
+}
+
+// checkAIQuota проверяет лимит ассистента попытки и пишет ошибку в ответ, если он исчерпан
+func (h *Handler) checkAIQuota(w http.ResponseWriter, r *http.Request, attemptID uint64) bool {
+	span := tracing.StoreOp(r.Context(), "CheckAIQuota")
+	quota, err := h.Store.CheckAIQuota(attemptID)
+	span.End()
+	switch {
+	case errors.Is(err, store.ErrAIQuotaExhausted):
+		writeErrorDetails(w, http.StatusTooManyRequests, codeAIQuotaExhausted, "ai quota exhausted for this attempt", aiQuotaDetails{Quota: quota})
+		return false
+	case err != nil:
+		writeErr(w, http.StatusBadRequest, err)
+		return false
+	}
+
+	return true
+}
+
+// defaultAIRateLimit - сколько сообщений ассистенту пользователь может отправить за минуту
+const defaultAIRateLimit = 10
+
+// newAILimiter создает лимит сообщений ассистенту в минуту из AI_RATE_LIMIT, 0 отключает лимит
+func newAILimiter() *limiter.RateLimiter {
+	limit := defaultAIRateLimit
+	if n, err := strconv.Atoi(os.Getenv("AI_RATE_LIMIT")); err == nil && n >= 0 {
+		limit = n
+	}
+	if limit == 0 {
+		return nil
+	}
+	return limiter.NewRateLimiter(limit, time.Minute)
+}
+
+// checkAIRateLimit проверяет частоту сообщений пользователя ассистенту. При превышении пишет 429 и возвращает false
+func (h *Handler) checkAIRateLimit(w http.ResponseWriter, userID uint64) bool {
+	if h.AILimiter == nil {
+		return true
+	}
+
+	allowed, retryAfter := h.AILimiter.Allow(strconv.FormatUint(userID, 10))
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		writeError(w, http.StatusTooManyRequests, apiutils.CodeRateLimited, "too many messages to the assistant, try again later")
+		return false
+	}
+	return true
+}
+
+// remainingAIQuota возвращает остаток лимита после ответа ассистента (исчерпанный лимит - не ошибка)
+func (h *Handler) remainingAIQuota(attemptID uint64) *store.AIQuota {
+	quota, _ := h.Store.CheckAIQuota(attemptID)
+	return quota
+}
+
+// AIUsageReport возвращает расход токенов ассистента для администратора
+// @Summary AI token usage report
+// @Description Returns accumulated token usage and estimated cost in USD grouped by user, attempt, test or organization, most expensive first
+// @Tags admin
+// @Produce json
+// @Param group_by query string false "user, attempt, test or organization (default user)"
+// @Success 200 {object} store.AIUsageReport
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/ai/usage [get]
+// @Security CookieAuth
+func (h *Handler) AIUsageReport(w http.ResponseWriter, r *http.Request) {
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = store.UsageByUser
+	}
+
+	report, err := h.Store.AIUsageReport(groupBy)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, report)
+}
+
+// aiOptions возвращает настройки ассистента и контекст диалога для вопроса попытки из пути запроса
+func (h *Handler) aiOptions(w http.ResponseWriter, r *http.Request, attemptID uint64) (llm.Options, bool) {
+	questionPos, err := strconv.ParseUint(mux.Vars(r)["question_position"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_position")
+		return llm.Options{}, false
+	}
+
+	span := tracing.StoreOp(r.Context(), "ResolveAIConfig")
+	config, err := h.Store.ResolveAIConfig(attemptID, questionPos)
+	span.End()
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return llm.Options{}, false
+	}
+
+	question, err := h.Store.AttemptQuestion(attemptID, questionPos)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return llm.Options{}, false
+	}
+
+	// Требование языка добавляется к контексту при каждом сообщении, чтобы смена настройки
+	// действовала и на уже начатые диалоги
+	threadContext := h.Store.AIThreadContext(mux.Vars(r)["thread_id"])
+	if instruction := store.AILanguageInstruction(config.Language); instruction != "" {
+		threadContext = strings.TrimSpace(threadContext + "\n" + instruction)
+	}
+
+	return llm.Options{
+		AssistantID: config.AssistantID,
+		Model:       config.Model,
+		Temperature: config.Temperature,
+		Context:     threadContext,
+		Tools:       aitools.Build(config.Tools, question.Materials),
+	}, true
+}
+
+type aiStatusResponse struct {
+	Available         bool   `json:"available"`
+	Provider          string `json:"provider"`
+	State             string `json:"state"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+// breakerStatus возвращает состояние предохранителя провайдера, если он есть
+func (h *Handler) breakerStatus() (llm.BreakerStatus, bool) {
+	breaker, ok := h.AI.(interface{ Status() llm.BreakerStatus })
+	if !ok {
+		return llm.BreakerStatus{}, false
+	}
+	return breaker.Status(), true
+}
+
+// writeAIError пишет ошибку провайдера: недоступность - 503 с Retry-After, остальное - 500
+func (h *Handler) writeAIError(w http.ResponseWriter, err error) {
+	if !errors.Is(err, llm.ErrUnavailable) {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := aiStatusResponse{Provider: h.AI.Name(), State: llm.BreakerOpen}
+	if status, ok := h.breakerStatus(); ok {
+		response.State = status.State
+		response.RetryAfterSeconds = int(math.Ceil(status.RetryAfter.Seconds()))
+	}
+	if response.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(response.RetryAfterSeconds))
+	}
+
+	writeErrorDetails(w, http.StatusServiceUnavailable, codeAIUnavailable, err.Error(), response)
+}
+
+// AIStatus сообщает, доступен ли ассистент
+// @Summary AI assistant status
+// @Description Reports whether the assistant is available. After repeated provider failures it is temporarily unavailable and AI endpoints return 503
+// @Tags ai
+// @Produce json
+// @Success 200 {object} aiStatusResponse
+// @Router /ai/status [get]
+// @Security CookieAuth
+func (h *Handler) AIStatus(w http.ResponseWriter, r *http.Request) {
+	response := aiStatusResponse{Available: true, Provider: h.AI.Name(), State: llm.BreakerClosed}
+	if status, ok := h.breakerStatus(); ok {
+		response.State = status.State
+		response.Available = status.State != llm.BreakerOpen
+		response.RetryAfterSeconds = int(math.Ceil(status.RetryAfter.Seconds()))
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, response)
+}
+
+// aiCacheKey возвращает ключ кеша ответов и TTL; пустой ключ - кеширование для вопроса выключено.
+// Сообщения с изображениями не кешируются
+func (h *Handler) aiCacheKey(attemptID, questionPos uint64, opts llm.Options, message string) (string, time.Duration) {
+	if len(opts.Images) > 0 {
+		return "", 0
+	}
+
+	config, err := h.Store.ResolveAIConfig(attemptID, questionPos)
+	if err != nil || config.CacheTTL <= 0 {
+		return "", 0
+	}
+
+	attempt, ok := h.Store.GetAttemptByID(attemptID)
+	if !ok {
+		return "", 0
+	}
+	question, err := h.Store.AttemptQuestion(attemptID, questionPos)
+	if err != nil {
+		return "", 0
+	}
+
+	return aicache.Key(attempt.TestID, question.ID, opts.AssistantID+"/"+opts.Model+"/"+config.Language, message), config.CacheTTL
+}
+
+// cachedAIReply возвращает ответ из кеша
+func (h *Handler) cachedAIReply(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	return h.AICache.Get(key)
+}
+
+// cacheAIReply сохраняет ответ в кеш, если кеширование включено
+func (h *Handler) cacheAIReply(key, reply string, ttl time.Duration) {
+	if key == "" || reply == "" {
+		return
+	}
+	h.AICache.Set(key, reply, ttl)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAIAnalytics показывает автору, по каким вопросам студенты чаще всего обращаются к ассистенту
+// @Summary AI usage analytics of a test
+// @Description Aggregates submitted attempts: how often each question triggers AI dialogs, average student messages per dialog,
+// @Description average scores with and without the assistant and Pearson correlation between AI usage and scores.
+// @Description Questions that need help most often come first. Correlations are omitted when undefined (fewer than two samples or no variance)
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Success 200 {object} store.AITestAnalytics
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/analytics/ai [get]
+// @Security CookieAuth
+func (h *Handler) GetAIAnalytics(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	analytics, err := h.Store.AIAnalytics(testID)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, analytics)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"GEEK_back/aipricing"
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"GEEK_back/tracing"
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newAIPricing загружает тарифы моделей из AI_PRICING_FILE, без файла или при ошибке - тарифы по умолчанию
+func newAIPricing() *aipricing.Table {
+	path := os.Getenv("AI_PRICING_FILE")
+	if path == "" {
+		return aipricing.Default()
+	}
+
+	table, err := aipricing.Load(path)
+	if err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("failed to load AI_PRICING_FILE, using default prices")
+		return aipricing.Default()
+	}
+	return table
+}
+
+// aiBudgetDetails - подробности ошибки ai_budget_exceeded
+type aiBudgetDetails struct {
+	Budget *store.AIBudget `json:"budget"`
+}
+
+// checkAIBudget проверяет месячный лимит расходов на ассистента. При превышении пишет 503
+// с Retry-After до начала следующего месяца и возвращает false
+func (h *Handler) checkAIBudget(w http.ResponseWriter, r *http.Request, attemptID uint64) bool {
+	span := tracing.StoreOp(r.Context(), "CheckAIBudget")
+	budget, err := h.Store.CheckAIBudget(attemptID)
+	span.End()
+	switch {
+	case errors.Is(err, store.ErrAIBudgetExceeded):
+		retryAfter := time.Until(store.NextAIBudgetReset())
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		writeErrorDetails(w, http.StatusServiceUnavailable, codeAIBudgetExceeded, "assistant is disabled: "+err.Error(), aiBudgetDetails{Budget: budget})
+		return false
+	case err != nil:
+		writeErr(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+type setAIBudgetRequest struct {
+	OrgID      uint64  `json:"org_id"`                       // 0 = общий лимит
+	MonthlyUSD float64 `json:"monthly_usd" validate:"gte=0"` // 0 = без лимита
+}
+
+// GetAIBudget возвращает месячные лимиты и расход на ассистента
+// @Summary AI budget
+// @Description Returns monthly AI budget caps and the estimated spend of the current month (UTC). org_id 0 is the global cap
+// @Tags admin
+// @Produce json
+// @Success 200 {object} store.AIBudgetReport
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/ai/budget [get]
+// @Security CookieAuth
+func (h *Handler) GetAIBudget(w http.ResponseWriter, r *http.Request) {
+	apiutils.WriteJSON(w, http.StatusOK, h.Store.AIBudgetReport())
+}
+
+// SetAIBudget задает месячный лимит расходов на ассистента
+// @Summary Set AI budget
+// @Description Sets a hard monthly AI budget in USD globally (org_id 0) or for an organization. When the estimated spend reaches the cap, AI endpoints return 503 until the next month. monthly_usd 0 removes the cap
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param budget body setAIBudgetRequest true "Budget"
+// @Success 200 {object} store.AIBudget
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/ai/budget [put]
+// @Security CookieAuth
+func (h *Handler) SetAIBudget(w http.ResponseWriter, r *http.Request) {
+	var request setAIBudgetRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	budget, err := h.Store.SetAIBudget(request.OrgID, request.MonthlyUSD)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, budget)
+}
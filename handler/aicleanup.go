@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"GEEK_back/client/llm"
+	"GEEK_back/store"
+	"GEEK_back/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Настройки очистки диалогов с ассистентом
+const (
+	defaultAIThreadCleanupInterval = time.Minute
+	aiThreadDeleteTimeout          = 10 * time.Second
+)
+
+// aiThreadCleanupInterval возвращает период очистки из AI_THREAD_CLEANUP_INTERVAL
+func aiThreadCleanupInterval() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("AI_THREAD_CLEANUP_INTERVAL")); err == nil && d > 0 {
+		return d
+	}
+	return defaultAIThreadCleanupInterval
+}
+
+// cleanupAIThreads закрывает диалоги сданных и истекших попыток и удаляет их треды у провайдера.
+// При остановке планировщика очистка прерывается, оставшиеся треды удалит следующий запуск
+func (h *Handler) cleanupAIThreads(ctx context.Context) error {
+	threadIDs := h.Store.CloseExpiredAIThreads()
+	failed := h.deleteAIThreads(ctx, threadIDs)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d ai threads not deleted", failed, len(threadIDs))
+	}
+	return nil
+}
+
+// deleteAIThreads удаляет треды у провайдера и возвращает число неудачных удалений.
+// Их повторит следующая очистка. Когда ctx завершен, оставшиеся треды не удаляются
+func (h *Handler) deleteAIThreads(ctx context.Context, threadIDs []string) int {
+	failed := 0
+	for _, threadID := range threadIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		deleteCtx, cancel := context.WithTimeout(ctx, aiThreadDeleteTimeout)
+		err := h.AI.DeleteThread(deleteCtx, threadID)
+		cancel()
+
+		if err != nil && !errors.Is(err, llm.ErrThreadNotFound) {
+			log.Warn().Err(err).Str("provider", h.AI.Name()).Str("thread_id", threadID).Msg("failed to delete ai thread")
+			failed++
+			continue
+		}
+		h.Store.MarkAIThreadDeleted(threadID)
+	}
+	return failed
+}
+
+// checkAIThreadOpen проверяет, что диалог принадлежит попытке, не закрыт после ее сдачи и не исчерпал лимит ходов
+func (h *Handler) checkAIThreadOpen(w http.ResponseWriter, r *http.Request, attemptID uint64, threadID string) bool {
+	span := tracing.StoreOp(r.Context(), "CheckAIThreadOpen")
+	err := h.Store.CheckAIThreadOpen(attemptID, threadID)
+	span.End()
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, store.ErrAIDialogLimit):
+		writeErrorDetails(w, http.StatusConflict, codeAIDialogLimit, "dialog limit reached", aiDialogLimitDetails{
+			ThreadID: threadID,
+			Status:   store.AIThreadClosed,
+			Turns:    h.aiThreadTurns(threadID),
+		})
+	case errors.Is(err, store.ErrAIThreadClosed):
+		writeErr(w, http.StatusConflict, err)
+	default:
+		writeErr(w, http.StatusNotFound, err)
+	}
+	return false
+}
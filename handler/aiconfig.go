@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// decodeAIConfig читает и проверяет настройки ассистента; пустой объект или null сбрасывает их.
+// При ошибке отвечает 400 и возвращает false
+func decodeAIConfig(w http.ResponseWriter, r *http.Request) (*store.AIConfig, bool) {
+	var config *store.AIConfig
+	if err := apiutils.DecodeJSON(r, &config); err != nil {
+		apiutils.WriteDecodeError(w, err)
+		return nil, false
+	}
+	if config == nil || *config == (store.AIConfig{}) {
+		return nil, true
+	}
+	return config, validateRequest(w, config)
+}
+
+// SetTestAIConfig задает ассистента, модель и температуру для теста
+// @Summary Set test AI configuration
+// @Description Sets the assistant ID, model, temperature and help policy used by the AI dialog of this test. Empty object or null resets to server defaults.
+// @Description The help policy is added to the context of new AI threads, strictness (off, low, medium, high) controls refusing requests to solve the question
+// @Description tools lists server-side functions the assistant may call (calculator, materials), off disables tools inherited from the test
+// @Description hintCost is the number of points deducted from the final result for each assistant reply
+// @Description cacheTtl (nanoseconds) enables caching of identical assistant responses per question, 0 disables caching
+// @Description maxTurns limits student messages per AI thread, the thread is closed after the reply to the last one
+// @Description language (ISO 639-1 code, e.g. ru, en, kk) forces the assistant to answer only in that language
+// @Description paraphrase makes the assistant reword question texts for every new attempt, originals are kept for audit in /attempt/{attempt_id}/paraphrases
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param If-Match header string true "Test version from the version field or ETag, * skips the check"
+// @Param config body store.AIConfig true "AI configuration"
+// @Success 200 {object} store.Test
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /tests/{test_id}/ai-config [put]
+// @Security CookieAuth
+func (h *Handler) SetTestAIConfig(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	config, ok := decodeAIConfig(w, r)
+	if !ok {
+		return
+	}
+
+	test, err := h.Store.SetTestAIConfig(testID, config, version)
+	if err != nil {
+		writeUpdateErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setVersionETag(w, test.Version)
+	apiutils.WriteJSON(w, http.StatusOK, test)
+}
+
+// SetQuestionAIConfig задает ассистента, модель и температуру для отдельного вопроса
+// @Summary Set question AI configuration
+// @Description Overrides the test AI configuration for one question. Empty object or null removes the override
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param question_id path int true "Question ID"
+// @Param If-Match header string true "Question version from the version field or ETag, * skips the check"
+// @Param config body store.AIConfig true "AI configuration"
+// @Success 200 {object} store.Question
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /tests/{test_id}/questions/{question_id}/ai-config [put]
+// @Security CookieAuth
+func (h *Handler) SetQuestionAIConfig(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questionID, err := strconv.ParseUint(vars["question_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	config, ok := decodeAIConfig(w, r)
+	if !ok {
+		return
+	}
+
+	question, err := h.Store.SetQuestionAIConfig(testID, questionID, config, version)
+	if err != nil {
+		writeUpdateErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setVersionETag(w, question.Version)
+	apiutils.WriteJSON(w, http.StatusOK, question)
+}
+
+type questionMaterialsRequest struct {
+	Materials []string `json:"materials" validate:"dive,required"`
+}
+
+// SetQuestionMaterials задает справочные материалы вопроса для функции materials ассистента
+// @Summary Set question materials
+// @Description Replaces the reference materials of a question. The assistant searches only these materials when the materials tool is enabled
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param question_id path int true "Question ID"
+// @Param If-Match header string true "Question version from the version field or ETag, * skips the check"
+// @Param request body questionMaterialsRequest true "Materials"
+// @Success 200 {object} store.Question
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /tests/{test_id}/questions/{question_id}/materials [put]
+// @Security CookieAuth
+func (h *Handler) SetQuestionMaterials(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questionID, err := strconv.ParseUint(vars["question_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req questionMaterialsRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	question, err := h.Store.SetQuestionMaterials(testID, questionID, req.Materials, version)
+	if err != nil {
+		writeUpdateErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setVersionETag(w, question.Version)
+	apiutils.WriteJSON(w, http.StatusOK, question)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	mw "GEEK_back/middleware"
+	"GEEK_back/storage"
+	"GEEK_back/store"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Ограничения изображений для ассистента
+const (
+	maxAIImageSize        = 5 << 20
+	maxAIImagesPerMessage = 4
+)
+
+// aiImagePolicy - форматы изображений, которые принимают провайдеры. Тип определяем по содержимому,
+// а не по заголовку клиента
+var aiImagePolicy = storage.Policy{
+	MaxSize: maxAIImageSize,
+	Types:   []string{"image/png", "image/jpeg", "image/webp", "image/gif"},
+	Sniff:   true,
+}
+
+// UploadAIImage загружает изображение для сообщения ассистенту
+// @Summary Upload image for AI message
+// @Description Uploads an image (png, jpeg, webp or gif, up to 5 MB) to the thread, e.g. a photo of handwritten work. Pass the returned id in image_ids of the send or stream request
+// @Tags ai
+// @Accept multipart/form-data
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Param image formData file true "Image"
+// @Success 201 {object} store.AIImage
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Failure 413 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/images [post]
+// @Security CookieAuth
+func (h *Handler) UploadAIImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	if err := h.Store.CheckDeadline(attemptID); err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Запас на заголовки multipart сверх размера самого файла
+	r.Body = http.MaxBytesReader(w, r.Body, maxAIImageSize+64<<10)
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, apiutils.CodePayloadTooLarge, "image is too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, "image file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAIImageSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeBadRequest, "failed to read image")
+		return
+	}
+
+	contentType, err := aiImagePolicy.Check("", data)
+	switch {
+	case errors.Is(err, storage.ErrTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, apiutils.CodePayloadTooLarge, "image is too large")
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnsupportedMediaType, "unsupported image type: "+contentType)
+		return
+	}
+
+	key := storage.NewKey("images", strconv.FormatUint(attemptID, 10))
+	if !h.saveFile(w, r, key, contentType, data) {
+		return
+	}
+
+	image, err := h.Store.AddAIImage(attemptID, vars["thread_id"], userID, contentType, len(data), key)
+	if err != nil {
+		go h.deleteFile(key)
+	}
+	switch {
+	case errors.Is(err, store.ErrAIThreadNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrAIThreadClosed):
+		writeErr(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, image)
+}
+
+// GetAIImage возвращает изображение из диалога с ассистентом
+// @Summary Get AI message image
+// @Description Redirects to a signed link to an image attached to the thread, the link is valid for 15 minutes. Available to attempt participants and teachers
+// @Tags ai
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Param image_id path int true "Image ID"
+// @Success 302 "Location: signed image URL"
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/images/{image_id} [get]
+// @Security CookieAuth
+func (h *Handler) GetAIImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	imageID, err := strconv.ParseUint(vars["image_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid image_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	image, err := h.Store.GetAIImage(attemptID, vars["thread_id"], imageID, userID)
+	switch {
+	case errors.Is(err, store.ErrAIImageNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.redirectToFile(w, r, image.Key, storage.URLOptions{ContentType: image.ContentType})
+}
+
+// aiMessageImages находит загруженные пользователем изображения для сообщения ассистенту
+func (h *Handler) aiMessageImages(w http.ResponseWriter, r *http.Request, threadID string, userID uint64, imageIDs []uint64) ([]llm.Image, bool) {
+	if len(imageIDs) == 0 {
+		return nil, true
+	}
+	if len(imageIDs) > maxAIImagesPerMessage {
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, "too many images, max "+strconv.Itoa(maxAIImagesPerMessage))
+		return nil, false
+	}
+
+	stored, err := h.Store.AIImagesForMessage(threadID, userID, imageIDs)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	images := make([]llm.Image, len(stored))
+	for i, image := range stored {
+		data, ok := h.readFile(w, r, image.Key)
+		if !ok {
+			return nil, false
+		}
+		images[i] = llm.Image{MIMEType: image.ContentType, Data: data}
+	}
+	return images, true
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	mw "GEEK_back/middleware"
+	"GEEK_back/reporting"
+	"GEEK_back/store"
+	"GEEK_back/tracing"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Настройки очереди запросов к ассистенту
+const (
+	defaultAIWorkers = 4
+	aiQueueSize      = 256
+	aiRunTimeout     = 2 * time.Minute
+)
+
+var errAIQueueFull = errors.New("ai queue is full")
+
+// aiJob - задание воркеру: отправить сообщение в тред и сохранить ответ
+type aiJob struct {
+	runID     uint64
+	userID    uint64
+	threadID  string
+	message   string
+	imageIDs  []uint64
+	sentAt    time.Time
+	opts      llm.Options
+	cacheKey  string // пусто, если кеширование для вопроса выключено
+	cacheTTL  time.Duration
+	trace     trace.SpanContext // span запроса, поставившего задание; воркер продолжает его трейс
+	requestID string            // ID запроса, поставившего задание, для логов воркера
+}
+
+type aiRunAccepted struct {
+	RunID  uint64 `json:"run_id"`
+	Status string `json:"status"`
+}
+
+type aiRunResponse struct {
+	*store.AIRun
+	Quota *store.AIQuota `json:"quota,omitempty"`
+	Turns *store.AITurns `json:"turns,omitempty"`
+}
+
+// aiWorkers возвращает размер пула воркеров из AI_WORKERS
+func aiWorkers() int {
+	if n, err := strconv.Atoi(os.Getenv("AI_WORKERS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultAIWorkers
+}
+
+// startAIWorkers запускает пул воркеров, обрабатывающих очередь запросов к ассистенту.
+// Воркеры работают, пока Shutdown не закроет очередь и они не выполнят оставшиеся задания
+func (h *Handler) startAIWorkers(n int) {
+	h.aiQueue.ctx, h.aiQueue.cancel = context.WithCancel(context.Background())
+	for i := 0; i < n; i++ {
+		h.aiQueue.workers.Add(1)
+		go func() {
+			defer h.aiQueue.workers.Done()
+			for job := range h.aiJobs {
+				h.processAIJob(job)
+			}
+		}()
+	}
+}
+
+// processAIJob отправляет сообщение ассистенту и сохраняет результат запроса
+func (h *Handler) processAIJob(job aiJob) {
+	ctx, span := tracing.Start(trace.ContextWithRemoteSpanContext(h.aiQueue.ctx, job.trace), "ai.run",
+		attribute.Int64("ai.run_id", int64(job.runID)),
+		attribute.String("ai.thread_id", job.threadID),
+		attribute.Int64("ai.queue_wait_ms", time.Since(job.sentAt).Milliseconds()),
+	)
+	defer span.End()
+
+	logger := log.With().Str("request_id", job.requestID).Logger()
+
+	// Сбои провайдера в Sentry привязываются к пользователю и запросу, поставившему сообщение в очередь
+	ctx = reporting.NewContext(ctx, nil)
+	reporting.SetUser(ctx, job.userID)
+	reporting.SetTag(ctx, "request_id", job.requestID)
+
+	// Остановка сервера не дождалась задания: к провайдеру не обращаемся
+	if ctx.Err() != nil {
+		logger.Warn().Uint64("run_id", job.runID).Msg("ai run dropped on shutdown")
+		h.finishAIRun(job.runID, "", false, errShuttingDown)
+		return
+	}
+
+	if err := h.Store.StartAIRun(job.runID); err != nil {
+		logger.Error().Err(err).Uint64("run_id", job.runID).Msg("failed to start ai run")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, aiRunTimeout)
+	defer cancel()
+
+	reply, err := h.AI.Send(ctx, job.threadID, job.message, job.opts)
+	if err != nil && h.aiQueue.ctx.Err() != nil {
+		err = errShuttingDown
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("provider", h.AI.Name()).Uint64("run_id", job.runID).Msg("ai run failed")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.finishAIRun(job.runID, "", false, err)
+		return
+	}
+
+	saveSpan := tracing.StoreOp(ctx, "SaveAIReply")
+	defer saveSpan.End()
+	h.saveAIMessage(job.threadID, store.AIMessage{
+		Role:      store.AIRoleUser,
+		UserID:    job.userID,
+		Text:      job.message,
+		Images:    job.imageIDs,
+		CreatedAt: job.sentAt,
+	})
+	h.saveAIMessage(job.threadID, h.assistantAIMessage(job.userID, reply, job.opts))
+	h.cacheAIReply(job.cacheKey, reply.Text, job.cacheTTL)
+	h.finishAIRun(job.runID, reply.Text, false, nil)
+}
+
+// enqueueAIJob ставит задание в очередь; при переполненной очереди или остановке сервера
+// запрос сразу помечается неудачным с errAIQueueFull или errShuttingDown
+func (h *Handler) enqueueAIJob(job aiJob) error {
+	h.aiQueue.mu.Lock()
+	defer h.aiQueue.mu.Unlock()
+
+	err := errShuttingDown
+	if !h.aiQueue.closed {
+		select {
+		case h.aiJobs <- job:
+			return nil
+		default:
+			err = errAIQueueFull
+		}
+	}
+	h.finishAIRun(job.runID, "", false, err)
+	return err
+}
+
+// aiRunConflictDetails - подробности ошибки ai_run_in_progress
+type aiRunConflictDetails struct {
+	RunID uint64 `json:"run_id,omitempty"` // запрос, который нужно дождаться
+}
+
+// createAIRun регистрирует запрос к ассистенту. Если в треде уже идет запрос, отвечает 409 с его ID
+func (h *Handler) createAIRun(w http.ResponseWriter, r *http.Request, attemptID, questionPos, userID uint64, threadID string) (*store.AIRun, bool) {
+	span := tracing.StoreOp(r.Context(), "CreateAIRun")
+	run, err := h.Store.CreateAIRun(attemptID, questionPos, userID, threadID)
+	span.End()
+	switch {
+	case err == nil:
+		return run, true
+	case errors.Is(err, store.ErrAIRunInProgress):
+		var details aiRunConflictDetails
+		if active, ok := h.Store.ActiveAIRun(threadID); ok {
+			details.RunID = active.ID
+		}
+		writeErrorDetails(w, http.StatusConflict, codeAIRunInProgress, err.Error(), details)
+	default:
+		writeErr(w, http.StatusBadRequest, err)
+	}
+	return nil, false
+}
+
+// GetAIRun возвращает статус и результат запроса к ассистенту
+// @Summary Get AI run status
+// @Description Polls a message queued by the send endpoint. Status is queued, running, completed (with response) or failed (with error)
+// @Description Instead of polling, set AI_RUN_WEBHOOK_URL to receive a POST with run_id, thread_id and message_id when the run finishes
+// @Tags ai
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param run_id path int true "Run ID"
+// @Success 200 {object} aiRunResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/runs/{run_id} [get]
+// @Security CookieAuth
+func (h *Handler) GetAIRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	runID, err := strconv.ParseUint(vars["run_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid run_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	run, err := h.Store.GetAIRun(attemptID, runID, userID)
+	switch {
+	case errors.Is(err, store.ErrAIRunNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := aiRunResponse{AIRun: run}
+	if run.Status == store.AIRunCompleted {
+		response.Quota = h.remainingAIQuota(attemptID)
+		response.Turns = h.aiThreadTurns(run.ThreadID)
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, response)
+}
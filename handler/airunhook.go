@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"GEEK_back/webhook"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Настройки доставки вебхука о завершении запроса к ассистенту
+const (
+	aiRunWebhookTimeout  = 10 * time.Second
+	aiRunWebhookAttempts = 3
+	aiRunWebhookBackoff  = time.Second
+)
+
+// aiRunWebhookEvent - тип события в теле вебхука
+const aiRunWebhookEvent = "ai_run.finished"
+
+// aiRunWebhook отправляет POST на AI_RUN_WEBHOOK_URL, когда запрос к ассистенту завершился,
+// чтобы фронтенд и внешние системы не опрашивали GET .../ai/runs/{run_id}.
+// Если задан AI_RUN_WEBHOOK_SECRET, тело подписывается HMAC-SHA256 в заголовке X-Webhook-Signature
+type aiRunWebhook struct {
+	URL    string
+	Secret string
+	HTTP   *http.Client
+}
+
+// aiRunWebhookPayload - тело вебхука
+type aiRunWebhookPayload struct {
+	Event            string     `json:"event"`
+	RunID            uint64     `json:"run_id"`
+	AttemptID        uint64     `json:"attempt_id"`
+	QuestionPosition uint64     `json:"question_position"`
+	ThreadID         string     `json:"thread_id"`
+	MessageID        int        `json:"message_id,omitempty"`
+	Status           string     `json:"status"`
+	Error            string     `json:"error,omitempty"`
+	Cached           bool       `json:"cached,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// newAIRunWebhook читает адрес вебхука из AI_RUN_WEBHOOK_URL, без адреса вебхук выключен
+func newAIRunWebhook() *aiRunWebhook {
+	url := os.Getenv("AI_RUN_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	return &aiRunWebhook{
+		URL:    url,
+		Secret: os.Getenv("AI_RUN_WEBHOOK_SECRET"),
+		HTTP:   &http.Client{Timeout: aiRunWebhookTimeout},
+	}
+}
+
+// finishAIRun сохраняет результат запроса к ассистенту и отправляет вебхук о его завершении
+func (h *Handler) finishAIRun(runID uint64, response string, cached bool, runErr error) {
+	if err := h.Store.FinishAIRun(runID, response, cached, runErr); err != nil {
+		return
+	}
+	if h.runHook == nil {
+		return
+	}
+
+	run, ok := h.Store.AIRunByID(runID)
+	if !ok {
+		return
+	}
+	go h.runHook.deliver(aiRunWebhookPayload{
+		Event:            aiRunWebhookEvent,
+		RunID:            run.ID,
+		AttemptID:        run.AttemptID,
+		QuestionPosition: run.QuestionPosition,
+		ThreadID:         run.ThreadID,
+		MessageID:        run.MessageID,
+		Status:           run.Status,
+		Error:            run.Error,
+		Cached:           run.Cached,
+		FinishedAt:       run.FinishedAt,
+	})
+}
+
+// deliver отправляет вебхук, повторяя попытку при сетевой ошибке или ответе 5xx
+func (wh *aiRunWebhook) deliver(payload aiRunWebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	backoff := aiRunWebhookBackoff
+	for attempt := 1; ; attempt++ {
+		err = wh.post(body)
+		if err == nil {
+			return
+		}
+		if attempt == aiRunWebhookAttempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	log.Warn().Err(err).Uint64("run_id", payload.RunID).Str("url", wh.URL).Msg("ai run webhook delivery failed")
+}
+
+// post делает одну попытку доставки вебхука
+func (wh *aiRunWebhook) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), aiRunWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", aiRunWebhookEvent)
+	if wh.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", webhook.Sign(wh.Secret, body))
+	}
+
+	resp, err := wh.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	// 4xx означает, что получатель отклонил событие, повтор не поможет
+	if resp.StatusCode >= http.StatusBadRequest {
+		log.Warn().Int("status", resp.StatusCode).Str("url", wh.URL).Msg("ai run webhook rejected")
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// aiThreadCheckTimeout - сколько ждем провайдера при проверке треда
+const aiThreadCheckTimeout = 5 * time.Second
+
+// Состояние треда у провайдера
+const (
+	remoteThreadOK      = "ok"
+	remoteThreadMissing = "missing" // провайдер тред не знает, нужно начать новый диалог
+	remoteThreadUnknown = "unknown" // провайдер недоступен
+)
+
+type aiThreadResponse struct {
+	AttemptID        uint64                  `json:"attempt_id"`
+	QuestionPosition uint64                  `json:"question_position"`
+	ThreadID         string                  `json:"thread_id"`
+	Status           string                  `json:"status"`
+	CloseReason      string                  `json:"close_reason,omitempty"` // attempt_finished или turn_limit
+	CreatedAt        time.Time               `json:"created_at"`
+	ClosedAt         *time.Time              `json:"closed_at,omitempty"`
+	MessageCount     int                     `json:"message_count"`        // сообщения студента и ответы ассистента
+	Prompt           *store.PromptVersionRef `json:"prompt,omitempty"`     // версия шаблона промпта, с которой начат диалог
+	ActiveRun        *store.AIRun            `json:"active_run,omitempty"` // запрос, ответ на который еще не готов
+	Remote           string                  `json:"remote,omitempty"`     // ok, missing или unknown; для закрытых диалогов не проверяется
+	Quota            *store.AIQuota          `json:"quota,omitempty"`
+	Turns            *store.AITurns          `json:"turns,omitempty"`
+}
+
+// GetAIThread возвращает метаданные диалога с ассистентом
+// @Summary Get AI thread
+// @Description Returns thread status, message count, the run still in progress and whether the provider still knows the thread, so the frontend can re-attach to a dialog after reload
+// @Tags ai
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Success 200 {object} aiThreadResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id} [get]
+// @Security CookieAuth
+func (h *Handler) GetAIThread(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	h.writeAIThread(w, r, attemptID, mux.Vars(r)["thread_id"])
+}
+
+// GetQuestionAIThread возвращает метаданные последнего диалога по вопросу
+// @Summary Get AI thread of a question
+// @Description Finds the latest thread of the question, so the frontend does not have to remember thread IDs. 404 means no dialog was started yet
+// @Tags ai
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Success 200 {object} aiThreadResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai [get]
+// @Security CookieAuth
+func (h *Handler) GetQuestionAIThread(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	questionPos, err := strconv.ParseUint(vars["question_position"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_position")
+		return
+	}
+
+	threadID, ok := h.Store.QuestionAIThreadID(attemptID, questionPos)
+	if !ok {
+		writeErr(w, http.StatusNotFound, store.ErrAIThreadNotFound)
+		return
+	}
+
+	h.writeAIThread(w, r, attemptID, threadID)
+}
+
+// writeAIThread собирает метаданные диалога с проверкой доступа и состояния треда у провайдера
+func (h *Handler) writeAIThread(w http.ResponseWriter, r *http.Request, attemptID uint64, threadID string) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	thread, err := h.Store.GetAIThread(attemptID, threadID, userID)
+	switch {
+	case errors.Is(err, store.ErrAIThreadNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := aiThreadResponse{
+		AttemptID:        thread.AttemptID,
+		QuestionPosition: thread.QuestionPosition,
+		ThreadID:         thread.ThreadID,
+		Status:           thread.Status,
+		CloseReason:      thread.CloseReason,
+		CreatedAt:        thread.CreatedAt,
+		ClosedAt:         thread.ClosedAt,
+		Prompt:           thread.Prompt,
+		Quota:            h.remainingAIQuota(attemptID),
+		Turns:            h.aiThreadTurns(threadID),
+	}
+	for _, message := range thread.Messages {
+		if message.Role != store.AIRoleSystem {
+			response.MessageCount++
+		}
+	}
+	if run, ok := h.Store.ActiveAIRun(threadID); ok {
+		response.ActiveRun = run
+	}
+
+	if thread.Status != store.AIThreadClosed {
+		ctx, cancel := context.WithTimeout(r.Context(), aiThreadCheckTimeout)
+		defer cancel()
+
+		_, err := h.AI.GetThread(ctx, threadID)
+		switch {
+		case err == nil:
+			response.Remote = remoteThreadOK
+		case errors.Is(err, llm.ErrThreadNotFound):
+			response.Remote = remoteThreadMissing
+		default:
+			response.Remote = remoteThreadUnknown
+		}
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, response)
+}
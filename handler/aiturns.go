@@ -0,0 +1,16 @@
+package handler
+
+import "GEEK_back/store"
+
+// aiDialogLimitDetails - подробности ошибки ai_dialog_limit_reached на сообщение в диалог, исчерпавший лимит ходов
+type aiDialogLimitDetails struct {
+	ThreadID string         `json:"thread_id"`
+	Status   string         `json:"status"` // диалог закрыт, продолжить можно только в новом
+	Turns    *store.AITurns `json:"turns"`
+}
+
+// aiThreadTurns возвращает расход ходов диалога или nil, если диалог неизвестен
+func (h *Handler) aiThreadTurns(threadID string) *store.AITurns {
+	turns, _ := h.Store.AIThreadTurns(threadID)
+	return turns
+}
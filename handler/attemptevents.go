@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/realtime"
+	"GEEK_back/store"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// attemptEventsPing - как часто поток событий попытки шлет комментарий и проверяет сессию
+const attemptEventsPing = 25 * time.Second
+
+// answerAcceptedEvent - ответ на вопрос попытки сохранен
+type answerAcceptedEvent struct {
+	AttemptID        uint64 `json:"attempt_id"`
+	QuestionPosition uint64 `json:"question_position"`
+	QuestionID       uint64 `json:"question_id"`
+	UserID           uint64 `json:"user_id"` // кто ответил: в командной попытке это может быть другой участник
+	Status           string `json:"status"`
+}
+
+// attemptSubmittedEvent - попытка сдана, после этого события поток закрывается
+type attemptSubmittedEvent struct {
+	AttemptID uint64 `json:"attempt_id"`
+	Auto      bool   `json:"auto"` // сдана автоматически по истечении времени
+}
+
+// StreamAttemptEvents транслирует события попытки через SSE
+// @Summary Attempt events stream
+// @Description Streams events of an open attempt as Server-Sent Events for clients that can't use the WebSocket.
+// @Description Events: "answer_accepted" {"attempt_id","question_position","question_id","user_id","status"},
+// @Description "time_warning" {"attempt_id","test_id","deadline","remaining_seconds"},
+// @Description "attempt_submitted" {"attempt_id","auto"} - the last event, the stream is closed after it.
+// @Description The stream is also closed on logout or when the session expires
+// @Tags attempts
+// @Produce text/event-stream
+// @Param attempt_id path int true "Attempt ID"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/events [get]
+// @Security CookieAuth
+func (h *Handler) StreamAttemptEvents(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+	session, err := r.Cookie("session_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "no session cookie")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "streaming is not supported")
+		return
+	}
+
+	// Подписываемся до проверки, чтобы не пропустить сдачу попытки между проверкой и подпиской
+	sub := h.Realtime.SubscribeAttempt(attemptID, session.Value)
+	defer h.Realtime.Unsubscribe(sub)
+
+	if err := h.Store.CheckAttemptOpen(attemptID, userID); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, store.ErrAttemptClosed) {
+			status = http.StatusConflict
+		}
+		writeErr(w, status, err)
+		return
+	}
+
+	sse := &sseWriter{w: w, flusher: flusher}
+	sse.start()
+	flusher.Flush()
+
+	ticker := time.NewTicker(attemptEventsPing)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case <-ticker.C:
+			if _, ok := h.Store.GetUserBySession(session.Value); !ok {
+				return
+			}
+			if err := sse.comment("ping"); err != nil {
+				return
+			}
+		case event := <-sub.Events():
+			if err := sse.send(event.Type, event.Data); err != nil {
+				return
+			}
+			if event.Type == realtime.EventAttemptSubmitted {
+				return
+			}
+		}
+	}
+}
+
+// publishAnswerAccepted сообщает подписчикам попытки о сохраненном ответе
+func (h *Handler) publishAnswerAccepted(attemptID, userID, questionPos uint64, answer *store.Answer) {
+	h.Realtime.PublishAttempt(attemptID, realtime.EventAnswerAccepted, answerAcceptedEvent{
+		AttemptID:        attemptID,
+		QuestionPosition: questionPos,
+		QuestionID:       answer.QuestionID,
+		UserID:           userID,
+		Status:           answer.Status,
+	})
+}
+
+// publishAttemptSubmitted сообщает подписчикам попытки, что она сдана
+func (h *Handler) publishAttemptSubmitted(attemptID uint64, auto bool) {
+	h.Realtime.PublishAttempt(attemptID, realtime.EventAttemptSubmitted, attemptSubmittedEvent{
+		AttemptID: attemptID,
+		Auto:      auto,
+	})
+}
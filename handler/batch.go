@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type batchAnswersRequest struct {
+	Answers []store.BatchAnswer `json:"answers" validate:"required,min=1,dive"`
+}
+
+// SyncAnswers принимает ответы, накопленные клиентом без сети
+// @Summary Sync buffered answers
+// @Description Accepts answers buffered on the client while offline. Each answer is validated by its client timestamp against the attempt deadline
+// @Tags attempts
+// @Accept json
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param answers body batchAnswersRequest true "Buffered answers"
+// @Success 200 {array} store.BatchAnswerResult
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/answers:batch [post]
+// @Security CookieAuth
+func (h *Handler) SyncAnswers(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	var request batchAnswersRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	results, err := h.Store.SyncAnswers(attemptID, userID, request.Answers)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	for _, result := range results {
+		if result.Accepted {
+			result.Answer = h.scoreSemanticAnswer(r.Context(), attemptID, result.QuestionPosition, result.Answer)
+			h.publishAnswerAccepted(attemptID, userID, result.QuestionPosition, result.Answer)
+		}
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// GetTeacherDashboard возвращает тесты преподавателя с основными показателями одним запросом
+// @Summary Teacher dashboard
+// @Description Returns the teacher's tests with headline metrics: open attempts, attempts awaiting manual review, submissions
+// @Description during the last days days, average result overall and for recent submissions, time of the last submission,
+// @Description points deducted for assistant hints and assistant token usage, plus totals over all tests.
+// @Description A teacher in an organization sees the organization's tests; a teacher without one and admins see all tests.
+// @Description Tests with the most recent submission come first
+// @Tags dashboard
+// @Produce json
+// @Param days query int false "Window for recent submissions in days, 1-365 (default 7)"
+// @Success 200 {object} store.TeacherDashboard
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /dashboard/teacher [get]
+// @Security CookieAuth
+func (h *Handler) GetTeacherDashboard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	since, ok := readDashboardSince(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.Store.TeacherDashboard(userID, since)
+	switch {
+	case errors.Is(err, store.ErrUserNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, dashboard)
+}
+
+// readDashboardSince читает days и возвращает начало окна недавней активности,
+// при ошибке пишет ответ и возвращает false
+func readDashboardSince(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	days := uint64(store.DefaultDashboardDays)
+	if r.URL.Query().Has("days") {
+		filters := apiutils.NewFilters(r)
+		days = filters.Uint("days")
+		if err := filters.Err(); err != nil || days < 1 || days > store.MaxDashboardDays {
+			writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter,
+				fmt.Sprintf("days must be from 1 to %d", store.MaxDashboardDays))
+			return time.Time{}, false
+		}
+	}
+	return time.Now().UTC().AddDate(0, 0, -int(days)), true
+}
+
+// GetStudentDashboard возвращает главную страницу студента одним запросом
+// @Summary Student dashboard
+// @Description Returns everything the student home screen needs: tests the user can start now without an access code
+// @Description (open enrollment or assigned to one of the user's classes), tests that open again after the retake cooldown,
+// @Description attempts in progress including team attempts with the deadline and remaining time, and the 10 latest submitted attempts.
+// @Description Tests with an attempt in progress are listed only under in_progress
+// @Tags dashboard
+// @Produce json
+// @Success 200 {object} store.StudentDashboard
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /dashboard/me [get]
+// @Security CookieAuth
+func (h *Handler) GetStudentDashboard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	dashboard, err := h.Store.StudentDashboard(userID)
+	switch {
+	case errors.Is(err, store.ErrUserNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, dashboard)
+}
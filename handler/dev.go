@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GenerateSyntheticData наполняет хранилище синтетическими студентами и попытками, доступен только с DEV_MODE=true
+// @Summary Generate synthetic data
+// @Description Dev mode only (DEV_MODE=true). Creates students with attempts and answers for the existing tests,
+// @Description with realistic distributions of ability, attempt count, test popularity, time of day and duration.
+// @Description Students get emails studentN@email_domain and the given password. The same seed on the same data reproduces the result
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body store.SyntheticOptions true "What to generate"
+// @Success 201 {object} store.SyntheticResult
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/dev/synthetic [post]
+// @Security CookieAuth
+func (h *Handler) GenerateSyntheticData(w http.ResponseWriter, r *http.Request) {
+	var req store.SyntheticOptions
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	started := time.Now()
+	result, err := h.Store.GenerateSynthetic(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, err.Error())
+		return
+	}
+	log.Ctx(r.Context()).Info().Int("users", result.Users).Int("attempts", result.Attempts).
+		Int64("seed", result.Seed).Dur("took", time.Since(started)).Msg("synthetic data generated")
+
+	apiutils.WriteJSON(w, http.StatusCreated, result)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	"GEEK_back/store"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Коды ошибок предметной области. Значения стабильны: фронтенд ветвится по ним, а не по тексту
+const (
+	codeUserExists             = "user_exists"
+	codeInvalidCredentials     = "invalid_credentials"
+	codeAccessCodeInvalid      = "access_code_invalid"
+	codeRetakeCooldown         = "retake_cooldown"
+	codeAIThreadNotFound       = "ai_thread_not_found"
+	codeAIThreadAccessDenied   = "ai_thread_access_denied"
+	codeAIThreadClosed         = "ai_thread_closed"
+	codeAIDialogLimit          = "ai_dialog_limit_reached"
+	codeAIRunNotFound          = "ai_run_not_found"
+	codeAIRunInProgress        = "ai_run_in_progress"
+	codeAIQuotaExhausted       = "ai_quota_exhausted"
+	codeAIBudgetExceeded       = "ai_budget_exceeded"
+	codeAIUnavailable          = "ai_unavailable"
+	codeAIQueueFull            = "ai_queue_full"
+	codeAIStreamFailed         = "ai_stream_failed"
+	codeAIModerationBlocked    = "ai_moderation_blocked"
+	codeAIRefused              = "ai_refused"
+	codeAIImageNotFound        = "ai_image_not_found"
+	codeResourceNotFound       = "resource_not_found"
+	codeResourceAttached       = "resource_already_attached"
+	codeFilesNotSupported      = "files_not_supported"
+	codeEmbeddingsNotSupported = "embeddings_not_supported"
+	codeReviewItemNotFound     = "review_item_not_found"
+	codeReviewItemClaimed      = "review_item_claimed"
+	codeReviewItemGraded       = "review_item_graded"
+	codePromptNotFound         = "prompt_not_found"
+	codeAttemptClosed          = "attempt_closed"
+	codeVersionConflict        = "version_conflict"
+	codeVersionRequired        = "version_required"
+)
+
+// errorCodes сопоставляет ошибки хранилища и провайдера с кодами. Порядок важен:
+// ErrAIDialogLimit оборачивает ErrAIThreadClosed и должен проверяться раньше
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{store.ErrUserExists, codeUserExists},
+	{store.ErrInvalidEmailOrPassword, codeInvalidCredentials},
+	{store.ErrAIDialogLimit, codeAIDialogLimit},
+	{store.ErrAIThreadClosed, codeAIThreadClosed},
+	{store.ErrAIThreadNotFound, codeAIThreadNotFound},
+	{store.ErrAIThreadAccess, codeAIThreadAccessDenied},
+	{store.ErrAIRunNotFound, codeAIRunNotFound},
+	{store.ErrAIRunInProgress, codeAIRunInProgress},
+	{store.ErrAIQuotaExhausted, codeAIQuotaExhausted},
+	{store.ErrAIBudgetExceeded, codeAIBudgetExceeded},
+	{store.ErrAIImageNotFound, codeAIImageNotFound},
+	{store.ErrResourceNotFound, codeResourceNotFound},
+	{store.ErrResourceAlreadyAttached, codeResourceAttached},
+	{store.ErrReviewItemNotFound, codeReviewItemNotFound},
+	{store.ErrReviewItemClaimed, codeReviewItemClaimed},
+	{store.ErrReviewItemGraded, codeReviewItemGraded},
+	{store.ErrPromptNotFound, codePromptNotFound},
+	{store.ErrAttemptClosed, codeAttemptClosed},
+	{llm.ErrUnavailable, codeAIUnavailable},
+	{errShuttingDown, codeAIUnavailable},
+	{llm.ErrFilesNotSupported, codeFilesNotSupported},
+	{llm.ErrEmbeddingsNotSupported, codeEmbeddingsNotSupported},
+}
+
+// errorCode возвращает код ошибки или общий код статуса, если ошибка не из таблицы
+func errorCode(err error, status int) string {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+	return apiutils.StatusCode(status)
+}
+
+// writeError пишет ошибку с указанным кодом
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	apiutils.WriteError(w, status, code, message, nil)
+}
+
+// writeErr пишет ошибку хранилища или провайдера, код выбирается по errorCodes
+func writeErr(w http.ResponseWriter, status int, err error) {
+	apiutils.WriteError(w, status, errorCode(err, status), err.Error(), nil)
+}
+
+// writeErrorDetails пишет ошибку с дополнительными данными (квота, ID активного запроса и т.п.)
+func writeErrorDetails(w http.ResponseWriter, status int, code, message string, details any) {
+	apiutils.WriteError(w, status, code, message, details)
+}
+
+// opError - ошибка операции, общей для HTTP и gRPC: статус и код ответа выбираются там, где ошибка возникла
+type opError struct {
+	status     int
+	code       string
+	message    string
+	details    any
+	retryAfter time.Duration // > 0 - для заголовка Retry-After
+}
+
+func (e *opError) Error() string {
+	return e.message
+}
+
+// newOpError создает ошибку операции с указанным кодом
+func newOpError(status int, code, message string) *opError {
+	return &opError{status: status, code: code, message: message}
+}
+
+// wrapOpError оборачивает ошибку хранилища или провайдера, код выбирается по errorCodes
+func wrapOpError(status int, err error) *opError {
+	return &opError{status: status, code: errorCode(err, status), message: err.Error()}
+}
+
+// writeOpError пишет ошибку операции; прочие ошибки отдаются как внутренние
+func writeOpError(w http.ResponseWriter, err error) {
+	var opErr *opError
+	if !errors.As(err, &opErr) {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	if opErr.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(opErr.retryAfter.Seconds()))))
+	}
+	apiutils.WriteError(w, opErr.status, opErr.code, opErr.message, opErr.details)
+}
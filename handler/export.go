@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+// csvFlushRows - через сколько строк выгрузка отправляется клиенту, не дожидаясь конца
+const csvFlushRows = 500
+
+// ExportResultsCSV выгружает результаты теста в CSV для импорта в журнал оценок
+// @Summary Export results as CSV
+// @Description Streams graded attempts of the test, oldest first, as CSV (RFC 4180, UTF-8, comma separated): attempt and user,
+// @Description timestamps in RFC 3339, working time without auto-pause, late penalty, assistant cost and result, then a column per question
+// @Description with 1 for a correct answer, 0 for a wrong or missing one and an empty cell if the question was not in the attempt.
+// @Description Cells starting with = + - @ are prefixed with ' so spreadsheets do not run them as formulas
+// @Tags tests
+// @Produce text/csv
+// @Param test_id path int true "Test ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/results.csv [get]
+// @Security CookieAuth
+func (h *Handler) ExportResultsCSV(w http.ResponseWriter, r *http.Request) {
+	export, ok := h.exportResults(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exportFilename(export, "csv")}))
+	w.WriteHeader(http.StatusOK)
+
+	out := csv.NewWriter(w)
+	_ = out.Write(resultsHeader(export))
+	for i, row := range export.Rows {
+		_ = out.Write(resultsRecord(export, row))
+		if (i+1)%csvFlushRows == 0 {
+			out.Flush()
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		// Заголовки уже отправлены, остается только записать в журнал
+		zerolog.Ctx(r.Context()).Warn().Err(err).Uint64("test_id", export.TestID).Msg("results export interrupted")
+	}
+}
+
+// exportResults читает test_id и собирает результаты теста, при ошибке пишет ответ и возвращает false
+func (h *Handler) exportResults(w http.ResponseWriter, r *http.Request) (*store.ResultsExport, bool) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return nil, false
+	}
+
+	export, err := h.Store.ExportResults(testID)
+	switch {
+	case errors.Is(err, store.ErrTestNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return nil, false
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return export, true
+}
+
+// exportFilename - имя файла выгрузки: test-3-results-20250101-120000.csv
+func exportFilename(export *store.ResultsExport, ext string) string {
+	return fmt.Sprintf("test-%d-results-%s.%s", export.TestID, time.Now().UTC().Format("20060102-150405"), ext)
+}
+
+// resultsHeader возвращает заголовки столбцов выгрузки; столбец вопроса называется по вопросу и его ID
+func resultsHeader(export *store.ResultsExport) []string {
+	header := []string{"attempt_id", "user_id", "email", "started_at", "finished_at", "duration_seconds",
+		"late", "penalty", "ai_cost", "result", "max_score"}
+	for _, question := range export.Questions {
+		name := question.Name
+		if name == "" {
+			name = "question"
+		}
+		header = append(header, spreadsheetSafe(fmt.Sprintf("%s (#%d)", name, question.ID)))
+	}
+	return header
+}
+
+// resultsRecord возвращает строку выгрузки по попытке
+func resultsRecord(export *store.ResultsExport, row *store.ResultRow) []string {
+	record := []string{
+		strconv.FormatUint(row.AttemptID, 10),
+		strconv.FormatUint(row.UserID, 10),
+		spreadsheetSafe(row.Email),
+		row.StartedAt.Format(time.RFC3339),
+		row.FinishedAt.Format(time.RFC3339),
+		strconv.FormatInt(int64(row.Duration.Seconds()), 10),
+		strconv.FormatBool(row.Late),
+		strconv.FormatUint(row.Penalty, 10),
+		strconv.FormatUint(row.AICost, 10),
+		strconv.FormatUint(row.Result, 10),
+		strconv.FormatUint(export.MaxScore, 10),
+	}
+	for _, question := range export.Questions {
+		correct, asked := row.Correct[question.ID]
+		switch {
+		case !asked:
+			record = append(record, "")
+		case correct:
+			record = append(record, "1")
+		default:
+			record = append(record, "0")
+		}
+	}
+	return record
+}
+
+// spreadsheetSafe экранирует текст, который табличный редактор принял бы за формулу
+func spreadsheetSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
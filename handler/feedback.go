@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type feedbackRequest struct {
+	Text             string  `json:"text" validate:"required"`
+	QuestionPosition *uint64 `json:"question_position"`
+}
+
+// AddFeedback добавляет комментарий преподавателя к попытке или ответу
+// @Summary Add teacher feedback
+// @Description Attaches a comment to the whole attempt or, when question_position is set, to a single answer. The student is notified
+// @Tags review
+// @Accept json
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param feedback body feedbackRequest true "Feedback"
+// @Success 201 {object} store.Feedback
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/feedback [post]
+// @Security CookieAuth
+func (h *Handler) AddFeedback(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	var request feedbackRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	authorID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	feedback, err := h.Store.AddFeedback(attemptID, authorID, request.QuestionPosition, request.Text)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, feedback)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/storage"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Файлы пользователей (материалы тестов, изображения для ассистента) лежат в файловом хранилище,
+// store хранит только их метаданные и ключи. Скачивание идет по подписанным ссылкам: обработчик
+// проверяет доступ и перенаправляет на ссылку, а отдает файл уже хранилище
+const (
+	fileURLTTL        = 15 * time.Minute // срок действия подписанных ссылок
+	fileDeleteTimeout = 30 * time.Second
+)
+
+// saveFile сохраняет файл в хранилище. При ошибке отвечает 500 и возвращает false
+func (h *Handler) saveFile(w http.ResponseWriter, r *http.Request, key, contentType string, data []byte) bool {
+	if err := h.Files.Put(r.Context(), key, contentType, data); err != nil {
+		log.Error().Err(err).Str("storage", h.Files.Name()).Str("key", key).Msg("failed to save file")
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "failed to save file")
+		return false
+	}
+	return true
+}
+
+// readFile читает файл из хранилища. При ошибке отвечает 500 и возвращает false
+func (h *Handler) readFile(w http.ResponseWriter, r *http.Request, key string) ([]byte, bool) {
+	data, err := h.Files.Get(r.Context(), key)
+	if err != nil {
+		log.Error().Err(err).Str("storage", h.Files.Name()).Str("key", key).Msg("failed to read file")
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "failed to read file")
+		return nil, false
+	}
+	return data, true
+}
+
+// deleteFile удаляет файл из хранилища; ошибка только логируется, вызывается в фоне
+func (h *Handler) deleteFile(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), fileDeleteTimeout)
+	defer cancel()
+
+	if err := h.Files.Delete(ctx, key); err != nil {
+		log.Warn().Err(err).Str("storage", h.Files.Name()).Str("key", key).Msg("failed to delete file")
+	}
+}
+
+// redirectToFile перенаправляет на подписанную ссылку файла. Перенаправление кешируется
+// вдвое меньше срока ссылки, чтобы браузер не пошел по уже истекшей
+func (h *Handler) redirectToFile(w http.ResponseWriter, r *http.Request, key string, opts storage.URLOptions) {
+	url, err := h.Files.SignedURL(r.Context(), key, fileURLTTL, opts)
+	if err != nil {
+		log.Error().Err(err).Str("storage", h.Files.Name()).Str("key", key).Msg("failed to sign file url")
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(fileURLTTL.Seconds()/2)))
+	http.Redirect(w, r, url, http.StatusFound)
+}
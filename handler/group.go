@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type createGroupRequest struct {
+	Name      string   `json:"name" validate:"required"`
+	MemberIDs []uint64 `json:"member_ids" validate:"unique"`
+}
+
+type addGroupMemberRequest struct {
+	UserID uint64 `json:"user_id" validate:"required"`
+}
+
+// CreateGroup создает группу (команду) пользователей
+// @Summary Create group
+// @Description Creates a group of users that can take team tests together
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group body createGroupRequest true "Group"
+// @Success 201 {object} store.Group
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /groups [post]
+// @Security CookieAuth
+func (h *Handler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var request createGroupRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	group, err := h.Store.CreateGroup(request.Name, userID, request.MemberIDs)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, group)
+}
+
+// GetGroup возвращает группу по ID
+// @Summary Get group
+// @Tags groups
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Success 200 {object} store.Group
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id} [get]
+// @Security CookieAuth
+func (h *Handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	group, ok := h.Store.GetGroup(groupID)
+	if !ok {
+		writeError(w, http.StatusNotFound, apiutils.CodeNotFound, "group not found")
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, group)
+}
+
+// AddGroupMember добавляет пользователя в группу
+// @Summary Add group member
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param member body addGroupMemberRequest true "Member"
+// @Success 200 {object} store.Group
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/members [post]
+// @Security CookieAuth
+func (h *Handler) AddGroupMember(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	var request addGroupMemberRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	group, err := h.Store.AddGroupMember(groupID, teacherID, request.UserID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, group)
+}
+
+type assignGroupTestRequest struct {
+	TestID uint64 `json:"test_id" validate:"required"`
+}
+
+type createClassCodeRequest struct {
+	Code      string     `json:"code"`                                // пустой = сгенерировать
+	MaxUses   *uint64    `json:"max_uses" validate:"omitempty,gte=1"` // nil = без ограничений
+	ExpiresAt *time.Time `json:"expires_at"`                          // nil = не истекает
+	// Срок действия в секундах с момента первого использования, 0 = без ограничения
+	ActivationWindowSeconds uint64 `json:"activation_window_seconds"`
+}
+
+type enrollRequest struct {
+	AccessCode string `json:"access_code" validate:"required"`
+}
+
+// AssignGroupTest назначает тест классу
+// @Summary Assign test to group
+// @Description Members of the group can start the test without an access code. Only the group creator or an admin can assign, and only tests of the teacher's organization
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param test body assignGroupTestRequest true "Test"
+// @Success 200 {object} store.Group
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/tests [post]
+// @Security CookieAuth
+func (h *Handler) AssignGroupTest(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	var request assignGroupTestRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	group, err := h.Store.AssignGroupTest(groupID, teacherID, request.TestID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, group)
+}
+
+// CreateClassCode создает код зачисления в класс
+// @Summary Create class enrollment code
+// @Description Creates a code that enrolls the user into the group and grants access to all tests assigned to it
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param code body createClassCodeRequest true "Class code"
+// @Success 201 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/codes [post]
+// @Security CookieAuth
+func (h *Handler) CreateClassCode(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	var request createClassCodeRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	accessCode, err := h.Store.CreateClassCode(request.Code, groupID, teacherID, store.AccessCodeOptions{
+		MaxUses:          request.MaxUses,
+		ExpiresAt:        request.ExpiresAt,
+		ActivationWindow: time.Duration(request.ActivationWindowSeconds) * time.Second,
+	})
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, accessCode)
+}
+
+// GetClassCode возвращает код класса
+// @Summary Get class code
+// @Description Returns the class enrollment code with its limits, usage count and suspension state
+// @Tags groups
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param code path string true "Class code"
+// @Success 200 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/codes/{code} [get]
+// @Security CookieAuth
+func (h *Handler) GetClassCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groupID, err := strconv.ParseUint(vars["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	accessCode, err := h.Store.GetClassCode(groupID, teacherID, vars["code"])
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, accessCode)
+}
+
+// SuspendClassCode немедленно отзывает код класса
+// @Summary Suspend class code
+// @Description Deactivates the class enrollment code immediately, e.g. when it leaked. Students already enrolled stay in the group
+// @Tags groups
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param code path string true "Class code"
+// @Success 200 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/codes/{code}/suspend [post]
+// @Security CookieAuth
+func (h *Handler) SuspendClassCode(w http.ResponseWriter, r *http.Request) {
+	h.setClassCodeSuspended(w, r, true)
+}
+
+// ReactivateClassCode возвращает отозванный код класса в работу
+// @Summary Reactivate class code
+// @Tags groups
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param code path string true "Class code"
+// @Success 200 {object} store.AccessCode
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/codes/{code}/reactivate [post]
+// @Security CookieAuth
+func (h *Handler) ReactivateClassCode(w http.ResponseWriter, r *http.Request) {
+	h.setClassCodeSuspended(w, r, false)
+}
+
+func (h *Handler) setClassCodeSuspended(w http.ResponseWriter, r *http.Request, suspended bool) {
+	vars := mux.Vars(r)
+	groupID, err := strconv.ParseUint(vars["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	accessCode, err := h.Store.SetClassCodeSuspended(groupID, teacherID, vars["code"], suspended)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, accessCode)
+}
+
+// ListClassCodeUsages возвращает список использований кода класса
+// @Summary Class code usages
+// @Description Returns which users redeemed the class code and when
+// @Tags groups
+// @Produce json
+// @Param group_id path int true "Group ID"
+// @Param code path string true "Class code"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "redeemed_at or email, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.CodeUsage]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /groups/{group_id}/codes/{code}/usages [get]
+// @Security CookieAuth
+func (h *Handler) ListClassCodeUsages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groupID, err := strconv.ParseUint(vars["group_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid group_id")
+		return
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	usages, err := h.Store.ListClassCodeUsages(groupID, teacherID, vars["code"])
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeList(w, r, usages, codeUsageSorts)
+}
+
+// Enroll зачисляет текущего пользователя в класс по коду
+// @Summary Enroll into class
+// @Description Redeems a class code: adds the current user to the group, granting access to its tests
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param enroll body enrollRequest true "Class code"
+// @Success 200 {object} store.Group
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /enroll [post]
+// @Security CookieAuth
+func (h *Handler) Enroll(w http.ResponseWriter, r *http.Request) {
+	var request enrollRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	group, err := h.Store.EnrollWithCode(request.AccessCode, userID)
+	if err != nil {
+		writeErr(w, http.StatusForbidden, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, group)
+}
+
+// writeGroupError отвечает 404 на чужую или несуществующую группу и недоступный тест, остальное - 400
+func writeGroupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrGroupNotFound) || errors.Is(err, store.ErrTestNotFound) {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	writeErr(w, http.StatusBadRequest, err)
+}
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/i18n"
+	"GEEK_back/limiter"
+	mw "GEEK_back/middleware"
+	geekv1 "GEEK_back/proto/geek/v1"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcPublicMethods - методы, доступные без сессии
+var grpcPublicMethods = []string{
+	geekv1.AuthService_Login_FullMethodName,
+}
+
+// grpcServiceRoles - роли, которым доступен сервис; сервисов без записи достаточно сессии
+var grpcServiceRoles = map[string][]string{
+	geekv1.GradingService_ServiceDesc.ServiceName: {store.RoleTeacher, store.RoleAdmin},
+}
+
+type grpcSessionKey struct{}
+
+type grpcCallKey struct{}
+
+// grpcCall - данные вызова для строки лога, пользователя заполняет grpcAuth
+type grpcCall struct {
+	userID uint64
+}
+
+// NewGRPCServer создает gRPC-сервер с сервисами авторизации, попыток и ручной проверки.
+// Сессия передается в метаданных authorization: Bearer <session_token>
+func (h *Handler) NewGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcLanguage,
+		grpcLogging, // снаружи grpcRecovery, чтобы вызов с паникой тоже попал в лог
+		grpcRecovery,
+		grpcLoginLimit(),
+		h.grpcAuth,
+	))
+	geekv1.RegisterAuthServiceServer(server, &grpcAuthService{h: h})
+	geekv1.RegisterAttemptServiceServer(server, &grpcAttemptService{h: h})
+	geekv1.RegisterGradingServiceServer(server, &grpcGradingService{h: h})
+	return server
+}
+
+// grpcLanguage переводит сообщения ошибок на язык из метаданных accept-language, как middleware.Language
+// для HTTP. Для сообщений без перевода используется код из ErrorInfo.Reason. Журнал видит исходный текст
+func grpcLanguage(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	lang := i18n.Default()
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		lang = i18n.Match(strings.Join(md.Get("accept-language"), ","))
+	}
+
+	resp, err := handler(mw.WithLanguage(ctx, lang), req)
+	st, ok := status.FromError(err)
+	if err == nil || !ok {
+		return resp, err
+	}
+
+	code := ""
+	for _, detail := range st.Details() {
+		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+			code = errorInfo.Reason
+		}
+	}
+	translated := st.Proto()
+	translated.Message = i18n.Error(lang, code, translated.Message)
+	return resp, status.FromProto(translated).Err()
+}
+
+// grpcRecovery превращает панику обработчика в ошибку Internal, чтобы не ронять сервер
+func grpcRecovery(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error().Interface("panic", p).Str("method", info.FullMethod).Msg("grpc handler panicked")
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// grpcLogging пишет строку лога на каждый вызов, как AccessLog для HTTP
+func grpcLogging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	call := &grpcCall{}
+	start := time.Now()
+	resp, err := handler(context.WithValue(ctx, grpcCallKey{}, call), req)
+
+	code := status.Code(err)
+	event := log.Info()
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		event = log.Error().Err(err)
+	default:
+		event = log.Warn().Err(err)
+	}
+	event = event.Str("method", info.FullMethod).
+		Str("code", code.String()).
+		Dur("latency_ms", time.Since(start)).
+		Str("remote_ip", grpcClientIP(ctx))
+	if call.userID != 0 {
+		event = event.Uint64("user_id", call.userID)
+	}
+	event.Msg("grpc request")
+
+	return resp, err
+}
+
+// grpcLoginLimit ограничивает частоту входа тем же лимитом, что и POST /api/login в HTTP API:
+// отдельно по адресу клиента и по email, чтобы перебор пароля одного пользователя с разных адресов
+// тоже упирался в лимит
+func grpcLoginLimit() grpc.UnaryServerInterceptor {
+	policy, ok := mw.RouteRateLimit("POST /api/login")
+	if !ok {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+	byIP := limiter.NewBucketLimiter(policy.Rate, policy.Burst)
+	byEmail := limiter.NewBucketLimiter(policy.Rate, policy.Burst)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != geekv1.AuthService_Login_FullMethodName {
+			return handler(ctx, req)
+		}
+
+		allowed, retryAfter := byIP.Allow(grpcClientIP(ctx))
+		if login, ok := req.(*geekv1.LoginRequest); ok && allowed && login.GetEmail() != "" {
+			allowed, retryAfter = byEmail.Allow(strings.ToLower(strings.TrimSpace(login.GetEmail())))
+		}
+		if !allowed {
+			opErr := newOpError(http.StatusTooManyRequests, apiutils.CodeRateLimited, "too many requests")
+			opErr.retryAfter = retryAfter
+			return nil, grpcError(opErr)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcAuth проверяет сессию из метаданных и роль пользователя для сервиса
+func (h *Handler) grpcAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if slices.Contains(grpcPublicMethods, info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	token := grpcSessionToken(ctx)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "no session token")
+	}
+	user, ok := h.Store.GetUserBySession(token)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+
+	service, _, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+	if roles, ok := grpcServiceRoles[service]; ok && !slices.Contains(roles, user.Role) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if call, ok := ctx.Value(grpcCallKey{}).(*grpcCall); ok {
+		call.userID = user.ID
+	}
+	ctx = mw.WithUserID(ctx, user.ID)
+	ctx = context.WithValue(ctx, grpcSessionKey{}, token)
+	return handler(ctx, req)
+}
+
+// grpcSessionToken читает сессию из метаданных authorization: Bearer <session_token>
+func grpcSessionToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// grpcClientIP возвращает адрес клиента, аналог apiutils.ClientIP для gRPC
+func grpcClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// grpcCodes - коды gRPC для статусов HTTP, с которыми возвращаются ошибки операций
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.FailedPrecondition,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// grpcError переводит ошибку операции в статус gRPC. Код ошибки из HTTP API передается в ErrorInfo.Reason,
+// пауза перед повтором - в RetryInfo
+func grpcError(err error) error {
+	var opErr *opError
+	if !errors.As(err, &opErr) {
+		log.Error().Err(err).Msg("unexpected grpc error")
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	code, ok := grpcCodes[opErr.status]
+	if !ok {
+		code = codes.Internal
+	}
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: opErr.code, Domain: "geek"}}
+	if opErr.retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(opErr.retryAfter)})
+	}
+
+	st := status.New(code, opErr.message)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// grpcUserID возвращает пользователя вызова, его проставляет grpcAuth
+func grpcUserID(ctx context.Context) (uint64, error) {
+	userID, ok := mw.GetUserID(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return userID, nil
+}
+
+// grpcInvalid - ошибка некорректного аргумента запроса
+func grpcInvalid(message string) error {
+	return grpcError(newOpError(http.StatusBadRequest, apiutils.CodeInvalidParameter, message))
+}
+
+// timestampProto возвращает nil для нулевого времени, чтобы поле в ответе осталось незаданным
+func timestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timestampPtrProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestampProto(*t)
+}
+
+func userProto(user *store.User) *geekv1.User {
+	return &geekv1.User{
+		Id:        user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		OrgId:     user.OrgID,
+		CreatedAt: timestampProto(user.CreatedAt),
+	}
+}
+
+func answerProto(answer *store.Answer) *geekv1.Answer {
+	if answer == nil {
+		return &geekv1.Answer{}
+	}
+	return &geekv1.Answer{
+		Id:         answer.ID,
+		QuestionId: answer.QuestionID,
+		Text:       answer.Text,
+		Right:      answer.RightOrNot,
+		Score:      answer.Score,
+		Status:     answer.Status,
+		Similarity: answer.Similarity,
+		CreatedAt:  timestampProto(answer.CreatedAt),
+	}
+}
+
+func attemptProto(attempt *store.Attempt) *geekv1.Attempt {
+	answers := make([]*geekv1.Answer, 0, len(attempt.Answers))
+	for _, answer := range attempt.Answers {
+		answers = append(answers, answerProto(answer))
+	}
+	return &geekv1.Attempt{
+		Id:          attempt.ID,
+		UserId:      attempt.UserID,
+		TestId:      attempt.TestID,
+		GroupId:     attempt.GroupID,
+		Status:      attempt.Status,
+		QuestionIds: slices.Clone(attempt.Questions),
+		Answers:     answers,
+		Result:      attempt.Result,
+		Late:        attempt.Late,
+		Penalty:     attempt.Penalty,
+		AiCost:      attempt.AICost,
+		StartedAt:   timestampProto(attempt.StartedAt),
+		FinishedAt:  timestampProto(attempt.FinishedAt),
+	}
+}
+
+func reviewItemProto(item *store.ReviewItem) *geekv1.ReviewItem {
+	result := &geekv1.ReviewItem{
+		Id:               item.ID,
+		AttemptId:        item.AttemptID,
+		TestId:           item.TestID,
+		QuestionId:       item.QuestionID,
+		QuestionPosition: item.QuestionPosition,
+		QuestionText:     item.QuestionText,
+		ReferenceAnswer:  item.ReferenceAnswer,
+		AnswerText:       item.AnswerText,
+		MaxScore:         item.MaxScore,
+		Reason:           item.Reason,
+		Status:           item.Status,
+		ClaimedAt:        timestampPtrProto(item.ClaimedAt),
+		GradedAt:         timestampPtrProto(item.GradedAt),
+		Score:            item.Score,
+	}
+	if item.ClaimedBy != nil {
+		result.ClaimedBy = *item.ClaimedBy
+	}
+	if item.GradedBy != nil {
+		result.GradedBy = *item.GradedBy
+	}
+	return result
+}
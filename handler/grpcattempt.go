@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	geekv1 "GEEK_back/proto/geek/v1"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// grpcAttemptService - AttemptService: прохождение теста с теми же правилами, что и в HTTP API
+type grpcAttemptService struct {
+	geekv1.UnimplementedAttemptServiceServer
+	h *Handler
+}
+
+// StartAttempt начинает попытку теста
+func (s *grpcAttemptService) StartAttempt(ctx context.Context, req *geekv1.StartAttemptRequest) (*geekv1.Attempt, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetTestId() == 0 {
+		return nil, grpcInvalid("invalid test_id")
+	}
+
+	request := startAttemptRequest{AccessCode: req.GetAccessCode(), GroupID: req.GetGroupId()}
+	attempt, err := s.h.startAttempt(ctx, userID, req.GetTestId(), request, grpcClientIP(ctx))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return attemptProto(attempt), nil
+}
+
+// GetAttempt возвращает попытку участнику
+func (s *grpcAttemptService) GetAttempt(ctx context.Context, req *geekv1.GetAttemptRequest) (*geekv1.Attempt, error) {
+	attempt, err := s.userAttempt(ctx, req.GetAttemptId())
+	if err != nil {
+		return nil, err
+	}
+	return attemptProto(attempt), nil
+}
+
+// ListQuestions возвращает вопросы попытки в порядке показа студенту, без эталонных ответов
+func (s *grpcAttemptService) ListQuestions(ctx context.Context, req *geekv1.ListQuestionsRequest) (*geekv1.ListQuestionsResponse, error) {
+	attempt, err := s.userAttempt(ctx, req.GetAttemptId())
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.h.Store.GetAttemptQuestions(attempt.ID)
+	if err != nil {
+		return nil, grpcError(wrapOpError(http.StatusInternalServerError, err))
+	}
+
+	response := &geekv1.ListQuestionsResponse{Questions: make([]*geekv1.Question, 0, len(questions))}
+	for i, question := range questions {
+		gradingMode := question.GradingMode
+		if gradingMode == "" {
+			gradingMode = store.GradingAuto
+		}
+		response.Questions = append(response.Questions, &geekv1.Question{
+			Id:          question.ID,
+			Position:    uint64(i + 1),
+			Name:        question.Name,
+			Text:        question.Text,
+			MaxScore:    question.MaxScore,
+			GradingMode: gradingMode,
+		})
+	}
+	return response, nil
+}
+
+// SubmitAnswer сохраняет и оценивает ответ на вопрос
+func (s *grpcAttemptService) SubmitAnswer(ctx context.Context, req *geekv1.SubmitAnswerRequest) (*geekv1.Answer, error) {
+	attempt, err := s.userAttempt(ctx, req.GetAttemptId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetQuestionPosition() == 0 || req.GetQuestionPosition() > uint64(len(attempt.Questions)) {
+		return nil, grpcInvalid("question position out of range")
+	}
+
+	userID, _ := grpcUserID(ctx)
+	answer, err := s.h.answerQuestion(ctx, attempt.ID, userID, req.GetQuestionPosition(), req.GetText())
+	if err != nil {
+		return nil, s.attemptError(attempt.ID, userID, err)
+	}
+	return answerProto(answer), nil
+}
+
+// SubmitAttempt сдает попытку
+func (s *grpcAttemptService) SubmitAttempt(ctx context.Context, req *geekv1.SubmitAttemptRequest) (*geekv1.Attempt, error) {
+	attempt, err := s.userAttempt(ctx, req.GetAttemptId())
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := grpcUserID(ctx)
+	attempt, err = s.h.submitAttempt(attempt.ID)
+	if err != nil {
+		return nil, s.attemptError(req.GetAttemptId(), userID, err)
+	}
+	return attemptProto(attempt), nil
+}
+
+// userAttempt возвращает попытку, если пользователь вызова - ее владелец или участник команды
+func (s *grpcAttemptService) userAttempt(ctx context.Context, attemptID uint64) (*store.Attempt, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if attemptID == 0 {
+		return nil, grpcInvalid("invalid attempt_id")
+	}
+
+	attempt, ok := s.h.Store.GetUserAttempt(attemptID, userID)
+	if !ok {
+		return nil, grpcError(newOpError(http.StatusNotFound, apiutils.CodeNotFound, "attempt not found"))
+	}
+	return attempt, nil
+}
+
+// attemptError уточняет ошибку ответа или сдачи: хранилище не различает сданную попытку
+// и истекшее время, а клиенту gRPC нужен FailedPrecondition вместо Internal
+func (s *grpcAttemptService) attemptError(attemptID, userID uint64, err error) error {
+	if openErr := s.h.Store.CheckAttemptOpen(attemptID, userID); errors.Is(openErr, store.ErrAttemptClosed) {
+		return grpcError(wrapOpError(http.StatusConflict, openErr))
+	}
+	if deadlineErr := s.h.Store.CheckDeadline(attemptID); deadlineErr != nil {
+		// Время попытки истекло, ее сдаст планировщик
+		return grpcError(newOpError(http.StatusConflict, codeAttemptClosed, deadlineErr.Error()))
+	}
+	return grpcError(err)
+}
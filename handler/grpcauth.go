@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	geekv1 "GEEK_back/proto/geek/v1"
+	"context"
+	"net/http"
+	"time"
+)
+
+// grpcAuthService - AuthService: те же сессии, что и cookie session_id в HTTP API
+type grpcAuthService struct {
+	geekv1.UnimplementedAuthServiceServer
+	h *Handler
+}
+
+// Login проверяет email и пароль и создает сессию
+func (s *grpcAuthService) Login(ctx context.Context, req *geekv1.LoginRequest) (*geekv1.LoginResponse, error) {
+	if req.GetEmail() == "" || req.GetPassword() == "" {
+		return nil, grpcInvalid("email and password are required")
+	}
+
+	user, err := s.h.Store.AuthenticateUser(req.GetEmail(), req.GetPassword())
+	if err != nil {
+		return nil, grpcError(wrapOpError(http.StatusUnauthorized, err))
+	}
+
+	sessionID := s.h.Store.CreateSession(user.ID)
+	return &geekv1.LoginResponse{
+		SessionToken: sessionID,
+		ExpiresAt:    timestampProto(time.Now().Add(sessionDuration)),
+		User:         userProto(user),
+	}, nil
+}
+
+// Logout завершает сессию вызова и закрывает ее соединения WebSocket и потоки SSE
+func (s *grpcAuthService) Logout(ctx context.Context, _ *geekv1.LogoutRequest) (*geekv1.LogoutResponse, error) {
+	sessionID, _ := ctx.Value(grpcSessionKey{}).(string)
+	s.h.Store.DeleteSession(sessionID)
+	s.h.Realtime.CloseSession(sessionID)
+	return &geekv1.LogoutResponse{}, nil
+}
+
+// GetCurrentUser возвращает пользователя сессии
+func (s *grpcAuthService) GetCurrentUser(ctx context.Context, _ *geekv1.GetCurrentUserRequest) (*geekv1.User, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := s.h.Store.GetUserByID(userID)
+	if !ok {
+		return nil, grpcError(newOpError(http.StatusNotFound, apiutils.CodeNotFound, "user not found"))
+	}
+	return userProto(user), nil
+}
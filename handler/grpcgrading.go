@@ -0,0 +1,81 @@
+package handler
+
+import (
+	geekv1 "GEEK_back/proto/geek/v1"
+	"GEEK_back/store"
+	"context"
+	"net/http"
+	"slices"
+)
+
+// grpcGradingService - GradingService: очередь ручной проверки, роль проверяет grpcAuth
+type grpcGradingService struct {
+	geekv1.UnimplementedGradingServiceServer
+	h *Handler
+}
+
+// ListReviewItems возвращает ответы, ожидающие проверки, от старых к новым
+func (s *grpcGradingService) ListReviewItems(ctx context.Context, req *geekv1.ListReviewItemsRequest) (*geekv1.ListReviewItemsResponse, error) {
+	if status := req.GetStatus(); status != "" && !slices.Contains([]string{store.ReviewPending, store.ReviewClaimed}, status) {
+		return nil, grpcInvalid("status must be one of: pending, claimed")
+	}
+	if reason := req.GetReason(); reason != "" && !slices.Contains([]string{store.ReviewReasonManual, store.ReviewReasonLowConfidence}, reason) {
+		return nil, grpcInvalid("reason must be one of: manual, low_confidence")
+	}
+
+	teacherID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.h.reviewQueue(teacherID, req.GetTestId(), req.GetStatus(), req.GetReason())
+	if err != nil {
+		return nil, grpcError(wrapOpError(http.StatusNotFound, err))
+	}
+	response := &geekv1.ListReviewItemsResponse{Items: make([]*geekv1.ReviewItem, 0, len(items))}
+	for _, item := range items {
+		response.Items = append(response.Items, reviewItemProto(item))
+	}
+	return response, nil
+}
+
+// ClaimReviewItem закрепляет ответ за преподавателем вызова
+func (s *grpcGradingService) ClaimReviewItem(ctx context.Context, req *geekv1.ClaimReviewItemRequest) (*geekv1.ReviewItem, error) {
+	teacherID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.h.Store.ClaimReviewItem(req.GetItemId(), teacherID)
+	if err != nil {
+		return nil, grpcError(wrapOpError(reviewErrorStatus(err), err))
+	}
+	return reviewItemProto(item), nil
+}
+
+// ReleaseReviewItem возвращает закрепленный ответ в очередь
+func (s *grpcGradingService) ReleaseReviewItem(ctx context.Context, req *geekv1.ReleaseReviewItemRequest) (*geekv1.ReviewItem, error) {
+	teacherID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.h.Store.ReleaseReviewItem(req.GetItemId(), teacherID)
+	if err != nil {
+		return nil, grpcError(wrapOpError(reviewErrorStatus(err), err))
+	}
+	return reviewItemProto(item), nil
+}
+
+// GradeReviewItem выставляет оценку за ответ
+func (s *grpcGradingService) GradeReviewItem(ctx context.Context, req *geekv1.GradeReviewItemRequest) (*geekv1.ReviewItem, error) {
+	teacherID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.h.gradeReviewItem(req.GetItemId(), teacherID, req.GetScore())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return reviewItemProto(item), nil
+}
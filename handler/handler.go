@@ -1,38 +1,104 @@
 package handler
 
 import (
+	"GEEK_back/aicache"
+	"GEEK_back/aipricing"
 	"GEEK_back/apiutils"
-	openai "GEEK_back/client/openAI"
+	"GEEK_back/client/llm"
+	"GEEK_back/limiter"
+	"GEEK_back/mailer"
 	mw "GEEK_back/middleware"
+	"GEEK_back/realtime"
+	"GEEK_back/scheduler"
+	"GEEK_back/storage"
 	"GEEK_back/store"
-	"encoding/json"
+	"GEEK_back/tracing"
+	"GEEK_back/webhook"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 const sessionDuration = 24 * time.Hour
 
+// Лимит неудачных вводов кода доступа с одного IP или одного пользователя
+const (
+	codeFailureLimit  = 10
+	codeFailureWindow = 15 * time.Minute
+)
+
 type Handler struct {
-	Store  *store.Store
-	Openai *openai.Client
+	Store       *store.Store
+	AI          llm.Provider
+	Moderator   llm.Moderator   // проверяет сообщения студентов ассистенту, nil = без модерации
+	Mailer      *mailer.Mailer  // очередь писем пользователям
+	Files       storage.Storage // содержимое загруженных файлов, см. files.go
+	AICache     *aicache.Cache
+	CodeLimiter *limiter.FailureLimiter
+	AILimiter   *limiter.RateLimiter // лимит сообщений ассистенту от одного пользователя, nil = без лимита
+	Pricing     *aipricing.Table     // тарифы моделей для оценки расхода на ассистента
+	Jobs        *scheduler.Scheduler // фоновые задачи: очистка сессий и диалогов, автосдача попыток
+	Realtime    *realtime.Hub        // WebSocket-соединения пользователей для событий в реальном времени
+	Webhooks    *webhook.Dispatcher  // доставка событий во внешние системы
+	DevMode     bool                 // служебные эндпоинты для разработки, например генератор данных, см. dev.go
+
+	aiJobs   chan aiJob    // очередь запросов к ассистенту, обрабатывается пулом воркеров
+	aiQueue  aiQueueState  // остановка очереди, см. Shutdown
+	runHook  *aiRunWebhook // уведомляет внешние системы о завершении запросов, nil = выключено
+	logLevel logLevelState // временное изменение уровня логов через /admin/log-level
 }
 
-type errorResponse struct {
-	Error string `json:"error"`
+func NewHandler(s *store.Store, p llm.Provider, m llm.Moderator, mail *mailer.Mailer, files storage.Storage) *Handler {
+	h := &Handler{
+		Store:       s,
+		AI:          p,
+		Moderator:   m,
+		Mailer:      mail,
+		Files:       files,
+		AICache:     aicache.New(aicache.DefaultMaxEntries),
+		CodeLimiter: limiter.NewFailureLimiter(codeFailureLimit, codeFailureWindow),
+		AILimiter:   newAILimiter(),
+		Pricing:     newAIPricing(),
+		aiJobs:      make(chan aiJob, aiQueueSize),
+		runHook:     newAIRunWebhook(),
+		Jobs:        scheduler.New(),
+		Realtime:    realtime.NewHub(),
+		Webhooks:    newWebhookDispatcher(s),
+	}
+	h.startAIWorkers(aiWorkers())
+	h.startJobs()
+
+	return h
 }
 
-func NewHandler(s *store.Store, o *openai.Client) *Handler {
-	return &Handler{
-		Store:  s,
-		Openai: o,
+// sessionCookie собирает cookie сессии. По HTTPS (TLS на нашем сервере или, при TRUST_PROXY_HEADERS=true,
+// на прокси с X-Forwarded-Proto: https) cookie помечается Secure и SameSite=None, чтобы фронтенд
+// на другом домене получал ее в cross-origin запросах. По HTTP браузер не примет такую cookie,
+// поэтому остается Lax без Secure
+func sessionCookie(r *http.Request, value string, expires time.Time) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     "session_id",
+		Value:    value,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
 	}
+	if apiutils.IsHTTPS(r) {
+		cookie.Secure = true
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
 }
 
 // registerRequest - тело запроса регистрации пользователя
@@ -44,9 +110,9 @@ func NewHandler(s *store.Store, o *openai.Client) *Handler {
 // "confirm_password": "secret"
 // }
 type registerRequest struct {
-	Email           string `json:"email"`
-	Password        string `json:"password"`
-	ConfirmPassword string `json:"confirm_password"`
+	Email           string `json:"email" validate:"required,email"`
+	Password        string `json:"password" validate:"required"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
 }
 
 // Register создает нового пользователя
@@ -57,43 +123,26 @@ type registerRequest struct {
 // @Produce json
 // @Param register body registerRequest true "Register request"
 // @Success 201 {object} store.User
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /register [post]
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	var request registerRequest
-	err := json.NewDecoder(r.Body).Decode(&request)
-	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
-		return
-	}
-	if request.Email == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no email provided"})
-		return
-	}
-	if request.Password == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no password provided"})
-		return
-	}
-	if request.ConfirmPassword == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no confirm password provided"})
-		return
-	}
-	if request.Password != request.ConfirmPassword {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "passwords do not match"})
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
 	user, err := h.Store.CreateUser(request.Email, request.Password)
 	if errors.Is(err, store.ErrUserExists) {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "user already exists"})
+		writeError(w, http.StatusBadRequest, codeUserExists, "user already exists")
 		return
 	}
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("error creating user: %s", err)})
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, fmt.Sprintf("error creating user: %s", err))
 		return
 	}
 
+	h.emitUserRegistered(user)
 	apiutils.WriteJSON(w, http.StatusCreated, user)
 }
 
@@ -104,56 +153,38 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 // "password": "secret"
 // }
 type loginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 // Login аутентифицирует пользователя и устанавливает cookie-сессию
 // @Summary Login user
-// @Description Authenticate user and set a session cookie
+// @Description Authenticate user and set a session cookie. The X-CSRF-Token response header carries the CSRF token of the new session
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param login body loginRequest true "Login request"
 // @Success 200 {object} store.User
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 401 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /login [post]
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var request loginRequest
-	err := json.NewDecoder(r.Body).Decode(&request)
-	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
-		return
-	}
-	if request.Email == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no email provided"})
-		return
-	}
-	if request.Password == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no password provided"})
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
 	user, err := h.Store.AuthenticateUser(request.Email, request.Password)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": fmt.Sprintf("error authenticating user: %s", err)})
+		writeError(w, http.StatusUnauthorized, errorCode(err, http.StatusUnauthorized), fmt.Sprintf("error authenticating user: %s", err))
 		return
 	}
 
 	sessionID := h.Store.CreateSession(user.ID)
-	expiration := time.Now().Add(sessionDuration)
-	session := &http.Cookie{
-		Name:     "session_id",
-		Value:    sessionID,
-		Expires:  expiration,
-		HttpOnly: true,
-		Secure:   false,                // false для работы по HTTP
-		SameSite: http.SameSiteLaxMode, // Lax для cross-origin по HTTP
-		Path:     "/",
-	}
-	http.SetCookie(w, session)
+	http.SetCookie(w, sessionCookie(r, sessionID, time.Now().Add(sessionDuration)))
+	// Токен новой сессии сразу, чтобы клиенту не нужен был отдельный запрос к /csrf
+	w.Header().Set(mw.CSRFHeader, mw.CSRFToken(sessionID))
 
 	apiutils.WriteJSON(w, http.StatusOK, user)
 }
@@ -164,41 +195,42 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 // @Tags auth
 // @Produce json
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /logout [post]
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	session, err := r.Cookie("session_id")
 	if errors.Is(err, http.ErrNoCookie) {
-		log.Info().Msg("no session cookie found")
-		apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no session cookie"})
+		zerolog.Ctx(r.Context()).Info().Msg("no session cookie found")
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "no session cookie")
 		return
 	}
 	if err != nil {
-		log.Error().Err(err).Msg("error getting session cookie")
-		apiutils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("error getting session cookie")
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error")
 		return
 	}
 
 	h.Store.DeleteSession(session.Value)
-	session.Expires = time.Now().Add(-1 * time.Hour)
-	http.SetCookie(w, session)
+	h.Realtime.CloseSession(session.Value)
+	http.SetCookie(w, sessionCookie(r, "", time.Now().Add(-1*time.Hour)))
 
-	apiutils.WriteJSON(w, http.StatusOK, errorResponse{"logged out"})
+	apiutils.WriteJSON(w, http.StatusOK, map[string]string{"message": "logged out"})
 }
 
 type sessionResponse struct {
 	Authenticated bool        `json:"authenticated"`
 	User          *store.User `json:"user,omitempty"`
+	CSRFToken     string      `json:"csrf_token,omitempty"` // передается в X-CSRF-Token изменяющих запросов
 }
 
 // CheckSession проверяет валидность сессии и возвращает пользователя
 // @Summary Check current session
-// @Description Return user for current session cookie or null if not authenticated
+// @Description Return user for current session cookie or null if not authenticated. csrf_token is the CSRF token of the session
 // @Tags auth
 // @Produce json
 // @Success 200 {object} store.User
-// @Failure 500 {object} map[string]string
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /session [get]
 func (h *Handler) CheckSession(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("session_id")
@@ -207,8 +239,8 @@ func (h *Handler) CheckSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err != nil {
-		log.Error().Err(err).Msg("error reading session cookie")
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{"internal server error"})
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("error reading session cookie")
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error")
 		return
 	}
 
@@ -216,7 +248,7 @@ func (h *Handler) CheckSession(w http.ResponseWriter, r *http.Request) {
 
 	user, ok := h.Store.GetUserBySession(sessionID)
 	if !ok {
-		log.Error().Err(err).Msg("error loading user for session")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("error loading user for session")
 		apiutils.WriteJSON(w, http.StatusOK, sessionResponse{Authenticated: false})
 		return
 	}
@@ -224,111 +256,283 @@ func (h *Handler) CheckSession(w http.ResponseWriter, r *http.Request) {
 	apiutils.WriteJSON(w, http.StatusOK, sessionResponse{
 		Authenticated: true,
 		User:          user,
+		CSRFToken:     mw.CSRFToken(sessionID),
 	})
 }
 
+type csrfResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// GetCSRFToken выдает CSRF-токен текущей сессии
+// @Summary Get CSRF token
+// @Description Returns the CSRF token of the current session. Every POST, PUT, PATCH and DELETE request authenticated by the session cookie must send it in the X-CSRF-Token header, otherwise it is rejected with 403 csrf_invalid.
+// @Description The token stays valid for the whole session; login and /session return it as well
+// @Tags auth
+// @Produce json
+// @Success 200 {object} csrfResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /csrf [get]
+// @Security CookieAuth
+func (h *Handler) GetCSRFToken(w http.ResponseWriter, r *http.Request) {
+	session, err := r.Cookie("session_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "no session cookie")
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, csrfResponse{CSRFToken: mw.CSRFToken(session.Value)})
+}
+
+// ListTests возвращает список тестов без вопросов
+// @Summary List tests
+// @Description Returns tests without questions. Filters combine with AND
+// @Param q query string false "Name contains (case-insensitive)"
+// @Param org_id query int false "Owning organization ID"
+// @Param team_mode query bool false "Only team (true) or individual (false) tests"
+// @Param open_enrollment query bool false "Only tests that do (true) or do not (false) need an access code"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id or name, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Test]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /tests [get]
+// @Security CookieAuth
+func (h *Handler) ListTests(w http.ResponseWriter, r *http.Request) {
+	filters := apiutils.NewFilters(r)
+	q := filters.String("q")
+	orgID := filters.Uint("org_id")
+	teamMode := filters.Bool("team_mode")
+	openEnrollment := filters.Bool("open_enrollment")
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	tests := filterList(h.Store.ListTests(), func(t *store.Test) bool {
+		return containsFold(t.Name, q) &&
+			(orgID == 0 || t.OrgID == orgID) &&
+			(teamMode == nil || t.TeamMode == *teamMode) &&
+			(openEnrollment == nil || t.OpenEnrollment == *openEnrollment)
+	})
+	writeList(w, r, tests, testSorts)
+}
+
 // TestById возвращает тест по ID
 // @Summary Get test by ID
 // @Description Retrieves a test by its ID
 // @Param test_id path int true "Test ID"
 // @Success 200 {object} store.Test
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
 // @Router /test/{test_id} [get]
 func (h *Handler) TestById(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid test_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
 		return
 	}
 
 	test, ok := h.Store.TestById(testID)
 	if !ok {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"test does not exist"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeNotFound, "test does not exist")
+		return
 	}
 
 	testWithoutQuestions := *test
 	testWithoutQuestions.Questions = nil
 
+	setVersionETag(w, testWithoutQuestions.Version)
 	apiutils.WriteJSON(w, http.StatusOK, testWithoutQuestions)
 }
 
 type startAttemptRequest struct {
 	AccessCode string `json:"access_code"`
+	GroupID    uint64 `json:"group_id"` // обязательно для командных тестов
+}
+
+// retakeCooldownDetails - подробности ошибки retake_cooldown
+type retakeCooldownDetails struct {
+	NextAttemptAt time.Time `json:"next_attempt_at"`
 }
 
 // StartAttempt начинает попытку теста
 // @Summary Start test attempt
-// @Description Starts a new attempt for the given test with access code validation
+// @Description Starts a new attempt for the given test with access code validation. Open-enrollment tests don't require a code
 // @Param test_id path int true "Test ID"
-// @Param access_code body startAttemptRequest true "Access code for the test"
+// @Param access_code body startAttemptRequest false "Access code for the test"
 // @Success 200 {object} store.Attempt
-// @Failure 400 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 429 {object} apiutils.ErrorResponse "Retake cooldown is active or too many invalid access codes"
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /tests/{test_id}/attempt [post]
 func (h *Handler) StartAttempt(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid test_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
 		return
 	}
 
-	// Читаем access code из body
+	// Читаем access code из body, для тестов со свободным доступом тело может быть пустым
 	var request startAttemptRequest
-	err = json.NewDecoder(r.Body).Decode(&request)
-	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid json"})
+	err = apiutils.DecodeJSON(r, &request)
+	if err != nil && !errors.Is(err, io.EOF) {
+		apiutils.WriteDecodeError(w, err)
 		return
 	}
 
-	if request.AccessCode == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"access code is required"})
+	userId, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
 		return
 	}
 
-	// Валидируем код доступа
-	err = h.Store.ValidateAccessCode(request.AccessCode, testID)
+	userAttempt, err := h.startAttempt(r.Context(), userId, testID, request, apiutils.ClientIP(r))
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusForbidden, errorResponse{err.Error()})
+		writeOpError(w, err)
 		return
 	}
 
-	userId, ok := mw.GetUserID(r.Context())
+	apiutils.WriteJSON(w, http.StatusOK, userAttempt)
+}
+
+// startAttempt проверяет доступ к тесту и начинает попытку: личную или общую попытку команды
+func (h *Handler) startAttempt(ctx context.Context, userId, testID uint64, request startAttemptRequest, clientIP string) (*store.Attempt, error) {
+	test, ok := h.Store.TestById(testID)
 	if !ok {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid user_id"})
-		return
+		return nil, newOpError(http.StatusBadRequest, apiutils.CodeNotFound, "test does not exist")
 	}
 
-	userAttempt, err := h.Store.CreateAttempt(testID, userId)
+	// Код не нужен для тестов со свободным доступом и для тестов, назначенных классу пользователя
+	needsCode := !test.OpenEnrollment && !h.Store.HasTestAccess(userId, testID)
+
+	if request.AccessCode == "" && needsCode {
+		return nil, newOpError(http.StatusBadRequest, apiutils.CodeValidationFailed, "access code is required")
+	}
+	if test.TeamMode && request.GroupID == 0 {
+		return nil, newOpError(http.StatusBadRequest, apiutils.CodeValidationFailed, "group_id is required for team tests")
+	}
+
+	// Проверяем паузу между попытками до списания использования кода. Окончательно ее проверяет
+	// создание попытки под своей блокировкой
+	err := h.Store.CheckRetakeCooldown(userId, testID)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{"internal server error"})
-		return
+		return nil, startAttemptError(err, http.StatusBadRequest)
 	}
-	apiutils.WriteJSON(w, http.StatusOK, userAttempt)
+
+	if needsCode {
+		if err := h.redeemAccessCode(testID, userId, request.AccessCode, clientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	// Попытка не создана - возвращаем использование кода
+	rollback := func() {
+		if needsCode {
+			h.Store.RollbackAccessCode(request.AccessCode, userId)
+		}
+	}
+
+	// Командный тест проходится одной общей попыткой группы
+	var userAttempt *store.Attempt
+	if test.TeamMode {
+		userAttempt, err = h.Store.StartTeamAttempt(testID, request.GroupID, userId)
+		if err != nil {
+			rollback()
+			return nil, startAttemptError(err, http.StatusBadRequest)
+		}
+		h.paraphraseAttempt(ctx, userAttempt.ID)
+		return userAttempt, nil
+	}
+
+	userAttempt, err = h.Store.CreateAttempt(testID, userId)
+	if err != nil {
+		rollback()
+		return nil, startAttemptError(err, http.StatusInternalServerError)
+	}
+	// Вопросы перефразируются до того, как студент их увидит
+	h.paraphraseAttempt(ctx, userAttempt.ID)
+	return userAttempt, nil
+}
+
+// startAttemptError переводит ошибку начала попытки в ответ: активная пауза между попытками -
+// 429 со временем следующей попытки, остальные ошибки - status
+func startAttemptError(err error, status int) error {
+	var cooldownErr *store.RetakeCooldownError
+	if errors.As(err, &cooldownErr) {
+		opErr := newOpError(http.StatusTooManyRequests, codeRetakeCooldown, "retake cooldown is active")
+		opErr.details = retakeCooldownDetails{NextAttemptAt: cooldownErr.NextAttemptAt}
+		return opErr
+	}
+	if status == http.StatusInternalServerError {
+		return newOpError(status, apiutils.CodeInternal, "internal server error")
+	}
+	return wrapOpError(status, err)
+}
+
+// redeemAccessCode проверяет и списывает код доступа
+func (h *Handler) redeemAccessCode(testID, userID uint64, code, clientIP string) error {
+	// Защита от перебора: ограничиваем число неверных кодов с одного IP и от одного пользователя
+	limiterKeys := []string{"ip:" + clientIP, fmt.Sprintf("user:%d", userID)}
+	for _, key := range limiterKeys {
+		if blocked, retryAfter := h.CodeLimiter.Blocked(key); blocked {
+			opErr := newOpError(http.StatusTooManyRequests, apiutils.CodeRateLimited, "too many invalid access codes, try again later")
+			opErr.retryAfter = retryAfter
+			return opErr
+		}
+	}
+
+	// Код с неверным контрольным символом отклоняем без обращения к хранилищу: хранилище не создает
+	// коды такого вида без верного контрольного символа, поэтому среди них его точно нет
+	var err error
+	if store.HasChecksumFormat(code) && !store.ValidChecksum(code) {
+		err = errors.New("invalid access code")
+	} else {
+		err = h.Store.ValidateAccessCode(code, testID, userID)
+	}
+	if err != nil {
+		for _, key := range limiterKeys {
+			h.CodeLimiter.Fail(key)
+		}
+		return newOpError(http.StatusForbidden, codeAccessCodeInvalid, err.Error())
+	}
+	h.CodeLimiter.Reset(limiterKeys[1])
+
+	return nil
 }
 
 // GetAttemptQuestions получает вопросы для попытки
 // @Summary Get questions for test attempt
-// @Description Retrieves all questions for the specified attempt
+// @Description Retrieves all questions for the specified attempt. Requesting a question_position records that the participant
+// @Description opened the question, see /attempt/{attempt_id}/timeline
 // @Param attempt_id path int true "Attempt ID"
 // @Success 200 {array} store.Question
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /attempt/{attempt_id}/question [get]
+// @Router /attempt/{attempt_id}/question/{question_position} [get]
 func (h *Handler) GetAttemptQuestions(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
 		return
 	}
 
 	questions, err := h.Store.GetAttemptQuestions(attemptID)
 
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Открытие конкретного вопроса попадает в хронологию попытки
+	if questionPos, err := strconv.ParseUint(vars["question_position"], 10, 64); err == nil {
+		if userID, ok := mw.GetUserID(r.Context()); ok {
+			h.Store.RecordQuestionView(attemptID, userID, questionPos)
+		}
 	}
 
 	apiutils.WriteJSON(w, http.StatusOK, questions)
@@ -345,177 +549,314 @@ type PostAnswerRequest struct {
 // @Param question_position path int true "Question Position"
 // @Param text body PostAnswerRequest true "Answer text"
 // @Success 200 {object} store.Answer
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /attempt/{attempt_id}/question/{question_position}/submit [post]
 func (h *Handler) PostQuestionAnswer(w http.ResponseWriter, r *http.Request) {
 	var request PostAnswerRequest
-	err := json.NewDecoder(r.Body).Decode(&request)
-
-	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid request"})
+	if !decodeRequest(w, r, &request) {
+		return
 	}
 
 	vars := mux.Vars(r)
 	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
 
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
 	}
 
 	questionPos, err := strconv.ParseUint(vars["question_position"], 10, 64)
 
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid question_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
 	}
 
-	answer, err := h.Store.CreateAnswer(attemptID, questionPos, request.Text)
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
 
+	answer, err := h.answerQuestion(r.Context(), attemptID, userID, questionPos, request.Text)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		writeOpError(w, err)
+		return
 	}
 
 	apiutils.WriteJSON(w, http.StatusOK, answer)
 }
 
+// answerQuestion сохраняет и оценивает ответ на вопрос попытки
+func (h *Handler) answerQuestion(ctx context.Context, attemptID, userID, questionPos uint64, text string) (*store.Answer, error) {
+	answer, err := h.Store.CreateAnswer(attemptID, userID, questionPos, text)
+	if err != nil {
+		return nil, wrapOpError(http.StatusInternalServerError, err)
+	}
+
+	answer = h.scoreSemanticAnswer(ctx, attemptID, questionPos, answer)
+	h.publishAnswerAccepted(attemptID, userID, questionPos, answer)
+	return answer, nil
+}
+
 // SubmitAttempt завершает попытку
 // @Summary Submit the attempt and evaluate the result
 // @Description Submits the entire attempt and evaluates the score
 // @Param attempt_id path int true "Attempt ID"
 // @Success 200 {object} store.Attempt
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /attempt/{attempt_id}/submit [post]
 func (h *Handler) SubmitAttempt(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
 	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	attempt, err := h.submitAttempt(attemptID)
+	if err != nil {
+		writeOpError(w, err)
+		return
 	}
 
-	attempt, err := h.Store.SubmitAttempt(attemptID)
+	apiutils.WriteJSON(w, http.StatusOK, attempt)
+}
 
+// submitAttempt сдает попытку и сообщает об этом подписчикам и внешним системам
+func (h *Handler) submitAttempt(attemptID uint64) (*store.Attempt, error) {
+	attempt, err := h.Store.SubmitAttempt(attemptID)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		return nil, wrapOpError(http.StatusInternalServerError, err)
 	}
 
-	apiutils.WriteJSON(w, http.StatusOK, attempt)
+	// Диалоги с ассистентом больше не нужны, удаляем их треды у провайдера в фоне
+	go h.deleteAIThreads(context.Background(), h.Store.CloseAttemptAIThreads(attemptID))
+
+	h.publishAttemptSubmitted(attemptID, false)
+	h.emitAttemptSubmitted(attemptID, false)
+	return attempt, nil
 }
 
+// aiMessageRequest - сообщение студента ассистенту: текст, изображения или и то и другое
+type aiMessageRequest struct {
+	Message  string   `json:"message" validate:"required_without=ImageIDs"`
+	ImageIDs []uint64 `json:"image_ids"` // изображения, загруженные через .../images
+}
+
+// SentMassage ставит сообщение студента ассистенту в очередь
+// @Summary Send message to AI assistant
+// @Description Queues a message (text, images uploaded via .../images or both) for the assistant and returns the run to poll via GET .../ai/runs/{run_id}.
+// @Description A repeated question may be answered from cache, then the run is already completed
+// @Tags ai
+// @Accept json
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Param request body aiMessageRequest true "Message"
+// @Success 202 {object} aiRunAccepted
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Failure 422 {object} apiutils.ErrorResponse
+// @Failure 429 {object} apiutils.ErrorResponse
+// @Failure 503 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/send [post]
+// @Security CookieAuth
 func (h *Handler) SentMassage(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
 	threadID := vars["thread_id"]
 	if threadID == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"thread_id is required"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, "thread_id is required")
 		return
 	}
 
 	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
 		return
 	}
 
 	// Читаем тело запроса
-	var req struct {
-		Message string `json:"message"`
+	var req aiMessageRequest
+	if !decodeRequest(w, r, &req) {
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid request body"})
+
+	// Проверяем дедлайн попытки
+	span := tracing.StoreOp(r.Context(), "CheckDeadline")
+	err = h.Store.CheckDeadline(attemptID)
+	span.End()
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if req.Message == "" {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"message cannot be empty"})
+	// Диалоги сданной попытки закрыты
+	if !h.checkAIThreadOpen(w, r, attemptID, threadID) {
 		return
 	}
 
-	// Проверяем дедлайн попытки
-	if err := h.Store.CheckDeadline(attemptID); err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+	// Проверяем лимит сообщений и токенов ассистента
+	if !h.checkAIQuota(w, r, attemptID) {
 		return
 	}
 
-	// Добавляем сообщение в тред
-	if err := h.Openai.AddMessage(r.Context(), threadID, req.Message); err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+	// Месячный лимит расходов на ассистента
+	if !h.checkAIBudget(w, r, attemptID) {
 		return
 	}
 
-	// Запускаем ассистента
-	run, err := h.Openai.RunAssistant(r.Context(), threadID)
-	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+	// Ограничиваем частоту сообщений от одного пользователя
+	if !h.checkAIRateLimit(w, userID) {
 		return
 	}
 
-	// Ждем завершения (максимум 30 секунд)
-	if err := h.Openai.WaitForCompletion(r.Context(), threadID, run.ID, 30*time.Second); err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+	// Блокируем попытки вытащить ответы и оскорбления
+	if !h.moderateAIMessage(w, r, attemptID, userID, threadID, req.Message) {
 		return
 	}
 
-	// Получаем последние сообщения
-	messages, err := h.Openai.GetMessages(r.Context(), threadID, 1)
-	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{"failed to get response"})
+	// Отказываем на просьбы решить вопрос за студента
+	if !h.guardAIMessage(w, r, attemptID, userID, threadID, req.Message) {
 		return
 	}
 
-	if len(messages) == 0 {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{"no response from assistant"})
+	// Ассистент, модель и температура теста или вопроса
+	opts, ok := h.aiOptions(w, r, attemptID)
+	if !ok {
 		return
 	}
+	if opts.Images, ok = h.aiMessageImages(w, r, threadID, userID, req.ImageIDs); !ok {
+		return
+	}
+
+	questionPos, _ := strconv.ParseUint(vars["question_position"], 10, 64)
+	cacheKey, cacheTTL := h.aiCacheKey(attemptID, questionPos, opts, req.Message)
 
-	// Извлекаем текст ответа
-	assistantMessage := messages[0]
-	var responseText string
-	if len(assistantMessage.Content) > 0 && assistantMessage.Content[0].Text != nil {
-		responseText = assistantMessage.Content[0].Text.Value
+	// Одинаковый запрос к тому же вопросу отдаем из кеша без запроса к провайдеру
+	if cached, ok := h.cachedAIReply(cacheKey); ok {
+		run, ok := h.createAIRun(w, r, attemptID, questionPos, userID, threadID)
+		if !ok {
+			return
+		}
+		h.saveAIMessage(threadID, store.AIMessage{Role: store.AIRoleUser, UserID: userID, Text: req.Message})
+		h.saveAIMessage(threadID, store.AIMessage{Role: store.AIRoleAssistant, UserID: userID, Text: cached, Cached: true})
+		h.finishAIRun(run.ID, cached, true, nil)
+
+		apiutils.WriteJSON(w, http.StatusAccepted, aiRunAccepted{RunID: run.ID, Status: store.AIRunCompleted})
+		return
 	}
 
-	// Возвращаем ответ
-	apiutils.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"response": responseText,
-	})
+	// Недоступный ассистент отклоняем сразу, а не через очередь
+	if status, ok := h.breakerStatus(); ok && status.State == llm.BreakerOpen {
+		h.writeAIError(w, llm.ErrUnavailable)
+		return
+	}
+
+	// Ставим сообщение в очередь, ответ забирается через GET .../ai/runs/{run_id}
+	// Второе сообщение, пока ассистент отвечает на первое, получает 409
+	run, ok := h.createAIRun(w, r, attemptID, questionPos, userID, threadID)
+	if !ok {
+		return
+	}
+
+	if err := h.enqueueAIJob(aiJob{
+		runID:     run.ID,
+		userID:    userID,
+		threadID:  threadID,
+		message:   req.Message,
+		imageIDs:  req.ImageIDs,
+		sentAt:    run.CreatedAt,
+		opts:      opts,
+		cacheKey:  cacheKey,
+		cacheTTL:  cacheTTL,
+		trace:     trace.SpanContextFromContext(r.Context()),
+		requestID: mw.GetRequestID(r.Context()),
+	}); err != nil {
+		if errors.Is(err, errShuttingDown) {
+			writeErr(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		writeError(w, http.StatusServiceUnavailable, codeAIQueueFull, "ai queue is full, try again later")
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusAccepted, aiRunAccepted{RunID: run.ID, Status: run.Status})
 }
 
+// aiThreadStarted - новый диалог с ассистентом
+type aiThreadStarted struct {
+	ThreadID  string `json:"thread_id"`
+	AttemptID uint64 `json:"attempt_id"`
+	Status    string `json:"status"`
+}
+
+// NewDialoge начинает диалог с ассистентом по вопросу попытки
+// @Summary Start AI assistant thread
+// @Description Creates an assistant thread for the question of the attempt. Messages are sent to .../ai/{thread_id}/send or .../stream
+// @Tags ai
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Success 201 {object} aiThreadStarted
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 429 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
+// @Failure 503 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/start [post]
+// @Security CookieAuth
 func (h *Handler) NewDialoge(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
 	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
 		return
 	}
 
 	questionPos, err := strconv.ParseUint(vars["question_position"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid question_position"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_position")
+		return
+	}
+
+	// При исчерпанном месячном лимите ассистент отключен
+	if !h.checkAIBudget(w, r, attemptID) {
 		return
 	}
 
-	// Создаем thread в OpenAI
-	threadID, err := h.Openai.CreateThread(r.Context())
+	// Создаем thread у провайдера ассистента
+	threadID, err := h.AI.CreateThread(r.Context())
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		h.writeAIError(w, err)
 		return
 	}
 
 	// Сохраняем в Store
 	thread, err := h.Store.CreateAIThread(attemptID, questionPos, threadID)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		// Тред у провайдера без записи в Store никто не удалит
+		go h.deleteAIThreads(context.Background(), []string{threadID})
+		writeErr(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	// Возвращаем успешный ответ
-	apiutils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
-		"thread_id":  thread.ThreadID,
-		"attempt_id": thread.AttemptID,
-		"status":     thread.Status,
+	apiutils.WriteJSON(w, http.StatusCreated, aiThreadStarted{
+		ThreadID:  thread.ThreadID,
+		AttemptID: thread.AttemptID,
+		Status:    thread.Status,
 	})
 }
 
@@ -525,54 +866,75 @@ func (h *Handler) NewDialoge(w http.ResponseWriter, r *http.Request) {
 // @Tags attempts
 // @Produce json
 // @Param test_id path int true "Test ID"
-// @Success 200 {array} store.Attempt
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "finished_at, started_at or result, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Attempt]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
 // @Router /tests/{test_id}/attempts/history [get]
 // @Security CookieAuth
 func (h *Handler) GetAttemptHistory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid test_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
 		return
 	}
 
 	userID, ok := mw.GetUserID(r.Context())
 	if !ok {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid user_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
 		return
 	}
 
 	history, err := h.Store.GetUserAttemptHistory(userID, testID)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		writeErr(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	apiutils.WriteJSON(w, http.StatusOK, history)
+	writeList(w, r, history, attemptSorts)
 }
 
 type Results struct {
-	Score   uint64          `json:"score"`
-	Answers []*store.Answer `json:"answers"`
+	Score    uint64            `json:"score"`
+	Late     bool              `json:"late"`
+	Penalty  uint64            `json:"penalty"`
+	AICost   uint64            `json:"ai_cost"` // баллы, снятые за подсказки ассистента
+	Answers  []*store.Answer   `json:"answers"`
+	Feedback []*store.Feedback `json:"feedback"`
 }
 
+// GetAttemptResults возвращает результат попытки: баллы, штрафы, ответы и отзывы преподавателя
+// @Summary Get attempt results
+// @Description Returns the score of the attempt with late and AI assistance penalties, the answers and teacher feedback
+// @Tags attempts
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Success 200 {object} Results
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/result [get]
+// @Security CookieAuth
 func (h *Handler) GetAttemptResults(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
 	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
 	if err != nil {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
 	}
 
 	attempt, ok := h.Store.GetAttemptByID(attemptID)
 	if !ok {
-		apiutils.WriteJSON(w, http.StatusBadRequest, errorResponse{"invalid attempt_id"})
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
 	}
 
 	apiutils.WriteJSON(w, http.StatusOK, Results{
-		Score:   attempt.Result,
-		Answers: attempt.Answers,
+		Score:    attempt.Result,
+		Late:     attempt.Late,
+		Penalty:  attempt.Penalty,
+		AICost:   attempt.AICost,
+		Answers:  attempt.Answers,
+		Feedback: attempt.Feedback,
 	})
 }
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Heartbeat отмечает активность студента в попытке
+// @Summary Attempt heartbeat
+// @Description Frontend pings this every 30 seconds while the student works on the attempt. Resumes an auto-paused attempt
+// @Tags attempts
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Success 200 {object} store.AttemptLiveness
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/heartbeat [post]
+// @Security CookieAuth
+func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	liveness, err := h.Store.Heartbeat(attemptID, userID)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, liveness)
+}
+
+// ListLiveAttempts показывает проктору, кто сейчас работает над тестом
+// @Summary Live attempts of a test
+// @Description Lists in-progress attempts with last heartbeat time, activity and auto-pause state
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param active query bool false "Only attempts with a recent heartbeat (true) or without (false)"
+// @Param paused query bool false "Only paused (true) or running (false) attempts"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "attempt_id, last_seen_at or deadline, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.AttemptLiveness]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/attempts/live [get]
+// @Security CookieAuth
+func (h *Handler) ListLiveAttempts(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	filters := apiutils.NewFilters(r)
+	active, paused := filters.Bool("active"), filters.Bool("paused")
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	live, err := h.Store.ListLiveAttempts(testID)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	live = filterList(live, func(l *store.AttemptLiveness) bool {
+		return (active == nil || l.Active == *active) && (paused == nil || l.Paused == *paused)
+	})
+	writeList(w, r, live, livenessSorts)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Интервалы фоновых задач по умолчанию, переопределяются через SCHEDULER_JOBS
+const (
+	sessionCleanupInterval = 10 * time.Minute
+	autoSubmitInterval     = 30 * time.Second
+	timeWarningInterval    = 15 * time.Second
+	aiThreadCleanupTimeout = 5 * time.Minute
+)
+
+// startJobs регистрирует фоновые задачи сервера и запускает планировщик
+func (h *Handler) startJobs() {
+	h.Jobs.Register("session_cleanup", sessionCleanupInterval, 0, h.cleanupSessions)
+	h.Jobs.Register("auto_submit", autoSubmitInterval, 0, h.submitExpiredAttempts)
+	h.Jobs.Register("ai_thread_gc", aiThreadCleanupInterval(), aiThreadCleanupTimeout, h.cleanupAIThreads)
+	h.Jobs.Register("time_warnings", timeWarningInterval, 0, h.sendTimeWarnings)
+	h.Jobs.Register("weekly_reports", reportCheckInterval, 0, h.sendWeeklyReports)
+	h.Jobs.Register("answer_similarity", similarityCheckInterval, 0, h.checkAnswerSimilarity)
+	h.Jobs.Start()
+}
+
+// cleanupSessions удаляет сессии, у которых истек срок cookie
+func (h *Handler) cleanupSessions(ctx context.Context) error {
+	if deleted := h.Store.DeleteExpiredSessions(sessionDuration); deleted > 0 {
+		log.Info().Int("sessions", deleted).Msg("expired sessions deleted")
+	}
+	return nil
+}
+
+// submitExpiredAttempts сдает попытки, которые студенты не сдали до конца льготного периода
+func (h *Handler) submitExpiredAttempts(ctx context.Context) error {
+	submitted := h.Store.SubmitExpiredAttempts()
+	if len(submitted) > 0 {
+		log.Info().Interface("attempt_ids", submitted).Msg("expired attempts submitted")
+	}
+	for _, attemptID := range submitted {
+		h.publishAttemptSubmitted(attemptID, true)
+		h.emitAttemptSubmitted(attemptID, true)
+		h.publishAttemptGraded(attemptID)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/scheduler"
+	"GEEK_back/store"
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// writeList отвечает страницей списка по параметрам limit, cursor (или offset) и sort запроса
+func writeList[T any](w http.ResponseWriter, r *http.Request, items []T, sorts apiutils.Sorts[T]) {
+	params, err := apiutils.ParseListParams(r, sorts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+	apiutils.WriteJSON(w, http.StatusOK, apiutils.Paginate(items, params, sorts))
+}
+
+// filterList оставляет элементы, подходящие под keep
+func filterList[T any](items []T, keep func(T) bool) []T {
+	return slices.DeleteFunc(items, func(item T) bool { return !keep(item) })
+}
+
+// containsFold сообщает, содержит ли s подстроку substr без учета регистра; пустая подстрока подходит всегда
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// Поля сортировки списков. Имена совпадают с именами полей в JSON
+var (
+	testSorts = apiutils.Sorts[*store.Test]{
+		"id":   func(a, b *store.Test) int { return cmp.Compare(a.ID, b.ID) },
+		"name": func(a, b *store.Test) int { return strings.Compare(a.Name, b.Name) },
+	}
+	attemptSorts = apiutils.Sorts[*store.Attempt]{
+		"id":          func(a, b *store.Attempt) int { return cmp.Compare(a.ID, b.ID) },
+		"result":      func(a, b *store.Attempt) int { return cmp.Compare(a.Result, b.Result) },
+		"started_at":  func(a, b *store.Attempt) int { return a.StartedAt.Compare(b.StartedAt) },
+		"finished_at": func(a, b *store.Attempt) int { return a.FinishedAt.Compare(b.FinishedAt) },
+	}
+	organizationSorts = apiutils.Sorts[*store.Organization]{
+		"id":         func(a, b *store.Organization) int { return cmp.Compare(a.ID, b.ID) },
+		"name":       func(a, b *store.Organization) int { return strings.Compare(a.Name, b.Name) },
+		"created_at": func(a, b *store.Organization) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	webhookSorts = apiutils.Sorts[*store.Webhook]{
+		"id":         func(a, b *store.Webhook) int { return cmp.Compare(a.ID, b.ID) },
+		"created_at": func(a, b *store.Webhook) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	webhookDeliverySorts = apiutils.Sorts[*store.WebhookDelivery]{
+		"id":         func(a, b *store.WebhookDelivery) int { return cmp.Compare(a.ID, b.ID) },
+		"created_at": func(a, b *store.WebhookDelivery) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	promptTemplateSorts = apiutils.Sorts[*store.PromptTemplate]{
+		"id":         func(a, b *store.PromptTemplate) int { return cmp.Compare(a.ID, b.ID) },
+		"name":       func(a, b *store.PromptTemplate) int { return strings.Compare(a.Name, b.Name) },
+		"created_at": func(a, b *store.PromptTemplate) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	notificationSorts = apiutils.Sorts[*store.Notification]{
+		"id":         func(a, b *store.Notification) int { return cmp.Compare(a.ID, b.ID) },
+		"created_at": func(a, b *store.Notification) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	reviewItemSorts = apiutils.Sorts[*store.ReviewItem]{
+		"id":         func(a, b *store.ReviewItem) int { return cmp.Compare(a.ID, b.ID) },
+		"created_at": func(a, b *store.ReviewItem) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		"test_id":    func(a, b *store.ReviewItem) int { return cmp.Compare(a.TestID, b.TestID) },
+	}
+	questionSorts = apiutils.Sorts[*store.Question]{
+		"id":      func(a, b *store.Question) int { return cmp.Compare(a.ID, b.ID) },
+		"name":    func(a, b *store.Question) int { return strings.Compare(a.Name, b.Name) },
+		"version": func(a, b *store.Question) int { return cmp.Compare(a.Version, b.Version) },
+	}
+	reportSubscriptionSorts = apiutils.Sorts[*store.ReportSubscription]{
+		"id":           func(a, b *store.ReportSubscription) int { return cmp.Compare(a.ID, b.ID) },
+		"next_send_at": func(a, b *store.ReportSubscription) int { return a.NextSendAt.Compare(b.NextSendAt) },
+		"created_at":   func(a, b *store.ReportSubscription) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	codeUsageSorts = apiutils.Sorts[*store.CodeUsage]{
+		"email":       func(a, b *store.CodeUsage) int { return strings.Compare(a.Email, b.Email) },
+		"redeemed_at": func(a, b *store.CodeUsage) int { return a.RedeemedAt.Compare(b.RedeemedAt) },
+	}
+	livenessSorts = apiutils.Sorts[*store.AttemptLiveness]{
+		"attempt_id":   func(a, b *store.AttemptLiveness) int { return cmp.Compare(a.AttemptID, b.AttemptID) },
+		"last_seen_at": func(a, b *store.AttemptLiveness) int { return a.LastSeenAt.Compare(b.LastSeenAt) },
+		"deadline":     func(a, b *store.AttemptLiveness) int { return a.Deadline.Compare(b.Deadline) },
+	}
+	proctoringEventSorts = apiutils.Sorts[*store.ProctoringEvent]{
+		"created_at": func(a, b *store.ProctoringEvent) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	paraphraseSorts = apiutils.Sorts[*store.Paraphrase]{
+		"question_position": func(a, b *store.Paraphrase) int { return cmp.Compare(a.QuestionPosition, b.QuestionPosition) },
+	}
+	resourceSorts = apiutils.Sorts[*store.TestResource]{
+		"id":         func(a, b *store.TestResource) int { return cmp.Compare(a.ID, b.ID) },
+		"name":       func(a, b *store.TestResource) int { return strings.Compare(a.Name, b.Name) },
+		"size":       func(a, b *store.TestResource) int { return cmp.Compare(a.Size, b.Size) },
+		"created_at": func(a, b *store.TestResource) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	}
+	jobStatusSorts = apiutils.Sorts[scheduler.JobStatus]{
+		"name":     func(a, b scheduler.JobStatus) int { return strings.Compare(a.Name, b.Name) },
+		"runs":     func(a, b scheduler.JobStatus) int { return cmp.Compare(a.Runs, b.Runs) },
+		"failures": func(a, b scheduler.JobStatus) int { return cmp.Compare(a.Failures, b.Failures) },
+	}
+)
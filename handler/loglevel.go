@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// logLevelState - временное изменение уровня логов: через revertAt уровень возвращается к revertTo
+type logLevelState struct {
+	mu       sync.Mutex
+	timer    *time.Timer
+	revertTo zerolog.Level
+	revertAt time.Time
+}
+
+type setLogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=trace debug info warn error"`
+	// Через сколько секунд вернуть прежний уровень, 0 = оставить навсегда
+	RevertAfterSeconds uint64 `json:"revert_after_seconds"`
+}
+
+type logLevelResponse struct {
+	Level    string     `json:"level"`
+	RevertTo string     `json:"revert_to,omitempty"` // уровень, который вернется в revert_at
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// GetLogLevel возвращает текущий уровень логов
+// @Summary Log level
+// @Description Returns the current log level and, if it was changed temporarily, when and to which level it reverts
+// @Tags admin
+// @Produce json
+// @Success 200 {object} logLevelResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/log-level [get]
+// @Security CookieAuth
+func (h *Handler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	apiutils.WriteJSON(w, http.StatusOK, h.logLevelResponse())
+}
+
+// SetLogLevel меняет уровень логов без перезапуска сервера
+// @Summary Set log level
+// @Description Changes the log level of the running server. With revert_after_seconds the previous level is restored after that time,
+// @Description so debug logging switched on for an investigation does not stay on. The level is not persisted across restarts, see LOG_LEVEL
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body setLogLevelRequest true "Level"
+// @Success 200 {object} logLevelResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/log-level [put]
+// @Security CookieAuth
+func (h *Handler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req setLogLevelRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	level, err := zerolog.ParseLevel(req.Level)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, err.Error())
+		return
+	}
+
+	state := &h.logLevel
+	state.mu.Lock()
+	previous := zerolog.GlobalLevel()
+	// Повторное временное изменение продлевает его, но возвращает уровень, который был до первого
+	if state.timer != nil {
+		state.timer.Stop()
+		state.timer = nil
+		previous = state.revertTo
+	}
+	if req.RevertAfterSeconds > 0 {
+		after := time.Duration(req.RevertAfterSeconds) * time.Second
+		state.revertTo = previous
+		state.revertAt = time.Now().Add(after)
+		state.timer = time.AfterFunc(after, h.revertLogLevel)
+	}
+	zerolog.SetGlobalLevel(level)
+	state.mu.Unlock()
+
+	// Без уровня: запись об изменении попадает в лог при любом уровне
+	log.Ctx(r.Context()).Log().Str("level", level.String()).
+		Uint64("revert_after_seconds", req.RevertAfterSeconds).Msg("log level changed")
+
+	apiutils.WriteJSON(w, http.StatusOK, h.logLevelResponse())
+}
+
+// revertLogLevel возвращает уровень логов после временного изменения
+func (h *Handler) revertLogLevel() {
+	state := &h.logLevel
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	// Таймер успел сработать, пока уровень меняли заново: возвращать уже нечего или еще рано
+	if state.timer == nil || time.Now().Before(state.revertAt) {
+		return
+	}
+	zerolog.SetGlobalLevel(state.revertTo)
+	state.timer = nil
+	log.Log().Str("level", state.revertTo.String()).Msg("log level reverted")
+}
+
+func (h *Handler) logLevelResponse() logLevelResponse {
+	state := &h.logLevel
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	response := logLevelResponse{Level: zerolog.GlobalLevel().String()}
+	if state.timer != nil {
+		revertAt := state.revertAt
+		response.RevertTo, response.RevertAt = state.revertTo.String(), &revertAt
+	}
+	return response
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"GEEK_back/aiguard"
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+// moderationTimeout - сколько ждем модерацию, прежде чем пропустить сообщение
+const moderationTimeout = 5 * time.Second
+
+// moderationBlockedDetails - подробности ошибки ai_moderation_blocked
+type moderationBlockedDetails struct {
+	Categories []string `json:"categories,omitempty"`
+}
+
+// moderateAIMessage проверяет сообщение студента и записывает нарушение в журнал попытки.
+// Сбой модерации не блокирует студента на экзамене - сообщение пропускается с предупреждением в логе
+func (h *Handler) moderateAIMessage(w http.ResponseWriter, r *http.Request, attemptID, userID uint64, threadID, message string) bool {
+	if h.Moderator == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), moderationTimeout)
+	defer cancel()
+
+	result, err := h.Moderator.Moderate(ctx, message)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Warn().Err(err).Uint64("attempt_id", attemptID).Msg("ai message moderation failed")
+		return true
+	}
+	if !result.Flagged {
+		return true
+	}
+
+	if err := h.Store.RecordProctoringEvent(attemptID, store.ProctoringEvent{
+		Type:       store.ProctoringAIModeration,
+		UserID:     userID,
+		ThreadID:   threadID,
+		Message:    message,
+		Source:     result.Source,
+		Categories: result.Categories,
+	}); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Uint64("attempt_id", attemptID).Msg("failed to record moderation violation")
+	}
+
+	writeErrorDetails(w, http.StatusUnprocessableEntity, codeAIModerationBlocked, "message blocked by moderation", moderationBlockedDetails{
+		Categories: result.Categories,
+	})
+	return false
+}
+
+// aiRefusalDetails - подробности ошибки ai_refused
+type aiRefusalDetails struct {
+	Refused bool   `json:"refused"`
+	Rule    string `json:"rule"`
+}
+
+// guardAIMessage отказывает на просьбы решить вопрос за студента с учетом строгости теста
+// и записывает отказ в журнал прокторинга
+func (h *Handler) guardAIMessage(w http.ResponseWriter, r *http.Request, attemptID, userID uint64, threadID, message string) bool {
+	questionPos, err := strconv.ParseUint(mux.Vars(r)["question_position"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_position")
+		return false
+	}
+
+	config, err := h.Store.ResolveAIConfig(attemptID, questionPos)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return false
+	}
+
+	var questionText string
+	if question, err := h.Store.AttemptQuestion(attemptID, questionPos); err == nil {
+		questionText = question.Text
+	}
+
+	verdict := aiguard.Check(message, questionText, config.Strictness)
+	if !verdict.Refused {
+		return true
+	}
+
+	if err := h.Store.RecordProctoringEvent(attemptID, store.ProctoringEvent{
+		Type:       store.ProctoringAIRefusal,
+		UserID:     userID,
+		ThreadID:   threadID,
+		Message:    message,
+		Source:     "aiguard",
+		Categories: []string{verdict.Rule},
+	}); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Uint64("attempt_id", attemptID).Msg("failed to record ai refusal")
+	}
+
+	writeErrorDetails(w, http.StatusUnprocessableEntity, codeAIRefused, "the assistant can help you understand the question but will not solve it for you", aiRefusalDetails{
+		Refused: true,
+		Rule:    verdict.Rule,
+	})
+	return false
+}
+
+// ListProctoringEvents возвращает журнал прокторинга попытки
+// @Summary Attempt proctoring log
+// @Description Lists violations recorded during the attempt, e.g. AI messages blocked by moderation or refused requests to solve a question
+// @Tags review
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param type query string false "Event type"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.ProctoringEvent]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/proctoring [get]
+// @Security CookieAuth
+func (h *Handler) ListProctoringEvents(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	eventType := apiutils.NewFilters(r).String("type")
+
+	events, err := h.Store.ListProctoringEvents(attemptID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	if eventType != "" {
+		events = filterList(events, func(e *store.ProctoringEvent) bool { return e.Type == eventType })
+	}
+	writeList(w, r, events, proctoringEventSorts)
+}
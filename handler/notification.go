@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ListNotifications возвращает уведомления текущего пользователя
+// @Summary List notifications
+// @Description Returns notifications of the current user, newest first
+// @Tags notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param type query string false "Notification type"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "created_at or id, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Notification]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /notifications [get]
+// @Security CookieAuth
+func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	filters := apiutils.NewFilters(r)
+	unread := filters.Bool("unread")
+	notificationType := filters.String("type")
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	notifications := h.Store.ListNotifications(userID, unread != nil && *unread)
+	if notificationType != "" {
+		notifications = filterList(notifications, func(n *store.Notification) bool { return n.Type == notificationType })
+	}
+	writeList(w, r, notifications, notificationSorts)
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным
+// @Summary Mark notification as read
+// @Tags notifications
+// @Produce json
+// @Param notification_id path int true "Notification ID"
+// @Success 200 {object} store.Notification
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /notifications/{notification_id}/read [post]
+// @Security CookieAuth
+func (h *Handler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseUint(mux.Vars(r)["notification_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid notification_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	notification, err := h.Store.MarkNotificationRead(userID, notificationID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, notification)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type createOrganizationRequest struct {
+	Name       string `json:"name" validate:"required"`
+	CodePrefix string `json:"code_prefix" validate:"required,alphanum,min=2,max=8"`
+}
+
+type setOrganizationRequest struct {
+	OrgID uint64 `json:"org_id"` // 0 = отвязать от организации
+}
+
+// CreateOrganization создает организацию с собственным префиксом кодов доступа
+// @Summary Create organization
+// @Description Creates an organization. All access codes of its tests and classes are prefixed with code_prefix
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param organization body createOrganizationRequest true "Organization"
+// @Success 201 {object} store.Organization
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/organizations [post]
+// @Security CookieAuth
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var request createOrganizationRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	org, err := h.Store.CreateOrganization(request.Name, request.CodePrefix)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, org)
+}
+
+// ListOrganizations возвращает все организации
+// @Summary List organizations
+// @Tags admin
+// @Produce json
+// @Param q query string false "Name contains (case-insensitive)"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, name or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Organization]
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/organizations [get]
+// @Security CookieAuth
+func (h *Handler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	q := apiutils.NewFilters(r).String("q")
+	orgs := filterList(h.Store.ListOrganizations(), func(org *store.Organization) bool { return containsFold(org.Name, q) })
+	writeList(w, r, orgs, organizationSorts)
+}
+
+// SetUserOrganization привязывает пользователя к организации
+// @Summary Set user organization
+// @Description Classes created by the user belong to this organization
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param organization body setOrganizationRequest true "Organization"
+// @Success 200 {object} store.User
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/users/{user_id}/organization [put]
+// @Security CookieAuth
+func (h *Handler) SetUserOrganization(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(mux.Vars(r)["user_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	var request setOrganizationRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	user, err := h.Store.SetUserOrganization(userID, request.OrgID)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, user)
+}
+
+// SetTestOrganization привязывает тест к организации
+// @Summary Set test organization
+// @Description Access codes created for the test afterwards are prefixed with the organization code prefix
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param If-Match header string true "Test version from the version field or ETag, * skips the check"
+// @Param organization body setOrganizationRequest true "Organization"
+// @Success 200 {object} store.Test
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /admin/tests/{test_id}/organization [put]
+// @Security CookieAuth
+func (h *Handler) SetTestOrganization(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var request setOrganizationRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	test, err := h.Store.SetTestOrganization(testID, request.OrgID, version)
+	if err != nil {
+		writeUpdateErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setVersionETag(w, test.Version)
+	apiutils.WriteJSON(w, http.StatusOK, test)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// paraphraseTimeout - сколько начало попытки ждет перефразирования всех вопросов
+const paraphraseTimeout = 20 * time.Second
+
+// paraphraseInstruction - инструкция ассистенту для перефразирования условия вопроса
+const paraphraseInstruction = "Перефразируй условие задания из сообщения пользователя другими словами. " +
+	"Сохрани смысл, все числа, даты, имена, единицы измерения и термины так, чтобы правильный ответ не изменился. " +
+	"Не добавляй подсказок, пояснений и не раскрывай ответ. Ответь только новым текстом задания."
+
+// errParaphraseRevealsAnswer - новый текст содержит эталонный ответ, которого не было в исходном
+var errParaphraseRevealsAnswer = errors.New("paraphrase reveals the reference answer")
+
+// paraphraseAttempt перефразирует вопросы попытки, для которых это включено в настройках ассистента.
+// Вопросы обрабатываются параллельно; при ошибке студент видит исходный текст
+func (h *Handler) paraphraseAttempt(ctx context.Context, attemptID uint64) {
+	targets, err := h.Store.ParaphraseTargets(attemptID)
+	if err != nil || len(targets) == 0 {
+		return
+	}
+
+	// Попытка уже создана, отключение клиента не должно оставить ее наполовину перефразированной
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), paraphraseTimeout)
+	defer cancel()
+
+	var budgetErr error
+	if _, err := h.Store.CheckAIBudget(attemptID); err != nil {
+		budgetErr = err
+	}
+
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			paraphrase := store.Paraphrase{
+				QuestionPosition: target.QuestionPosition,
+				QuestionID:       target.QuestionID,
+				Original:         target.Text,
+			}
+
+			err := budgetErr
+			if err == nil {
+				paraphrase.Text, paraphrase.Model, err = h.paraphraseQuestion(ctx, target)
+			}
+			if err != nil {
+				paraphrase.Text = ""
+				paraphrase.Error = err.Error()
+				log.Warn().Err(err).Uint64("attempt_id", attemptID).Uint64("question_id", target.QuestionID).Msg("question paraphrase failed")
+			}
+
+			_ = h.Store.SaveParaphrase(attemptID, paraphrase)
+		}()
+	}
+	wg.Wait()
+}
+
+// paraphraseQuestion просит ассистента перефразировать вопрос в отдельном временном диалоге
+func (h *Handler) paraphraseQuestion(ctx context.Context, target store.ParaphraseTarget) (string, string, error) {
+	threadID, err := h.AI.CreateThread(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), aiThreadDeleteTimeout)
+		defer cancel()
+		if err := h.AI.DeleteThread(ctx, threadID); err != nil && !errors.Is(err, llm.ErrThreadNotFound) {
+			log.Warn().Err(err).Str("provider", h.AI.Name()).Str("thread_id", threadID).Msg("failed to delete ai thread")
+		}
+	}()
+
+	reply, err := h.AI.Send(ctx, threadID, target.Text, llm.Options{Context: paraphraseInstruction})
+	if err != nil {
+		return "", "", err
+	}
+
+	text := strings.TrimSpace(reply.Text)
+	if text == "" {
+		return "", "", errors.New("empty paraphrase")
+	}
+
+	// Ответ, которого не было в условии, выдал бы его всем студентам
+	answer := strings.ToLower(strings.TrimSpace(target.Answer))
+	if answer != "" && strings.Contains(strings.ToLower(text), answer) && !strings.Contains(strings.ToLower(target.Text), answer) {
+		return "", "", errParaphraseRevealsAnswer
+	}
+
+	return text, reply.Model, nil
+}
+
+// ListParaphrases возвращает перефразированные вопросы попытки для аудита
+// @Summary Attempt question paraphrases
+// @Description Lists question texts paraphrased by the assistant for this attempt together with the original texts.
+// @Description Failed paraphrases have an error and the student saw the original text
+// @Tags review
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "question_position, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Paraphrase]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/paraphrases [get]
+// @Security CookieAuth
+func (h *Handler) ListParaphrases(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	paraphrases, err := h.Store.ListParaphrases(attemptID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeList(w, r, paraphrases, paraphraseSorts)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type createPromptTemplateRequest struct {
+	Name string `json:"name" validate:"required"`
+	Text string `json:"text" validate:"required"`
+}
+
+type updatePromptTemplateRequest struct {
+	Text string `json:"text" validate:"required"`
+}
+
+// writePromptError отвечает 404 на отсутствующий шаблон и 400 на остальные ошибки
+func writePromptError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrPromptNotFound) {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	writeUpdateErr(w, http.StatusBadRequest, err)
+}
+
+// CreatePromptTemplate создает шаблон системного промпта ассистента
+// @Summary Create prompt template
+// @Description Creates a reusable system prompt template. Available variables: {{test}}, {{question}}, {{position}}, {{subject}}, {{language}}, {{help_policy}}
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param prompt body createPromptTemplateRequest true "Prompt template"
+// @Success 201 {object} store.PromptTemplate
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/prompts [post]
+// @Security CookieAuth
+func (h *Handler) CreatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	var request createPromptTemplateRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	template, err := h.Store.CreatePromptTemplate(request.Name, request.Text, userID)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, template)
+}
+
+// ListPromptTemplates возвращает все шаблоны промптов
+// @Summary List prompt templates
+// @Tags admin
+// @Produce json
+// @Param q query string false "Name contains (case-insensitive)"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, name or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.PromptTemplate]
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/prompts [get]
+// @Security CookieAuth
+func (h *Handler) ListPromptTemplates(w http.ResponseWriter, r *http.Request) {
+	q := apiutils.NewFilters(r).String("q")
+	templates := filterList(h.Store.ListPromptTemplates(), func(t *store.PromptTemplate) bool { return containsFold(t.Name, q) })
+	writeList(w, r, templates, promptTemplateSorts)
+}
+
+// GetPromptTemplate возвращает шаблон со всеми версиями
+// @Summary Get prompt template
+// @Tags admin
+// @Produce json
+// @Param prompt_id path int true "Prompt template ID"
+// @Success 200 {object} store.PromptTemplate
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /admin/prompts/{prompt_id} [get]
+// @Security CookieAuth
+func (h *Handler) GetPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	promptID, err := strconv.ParseUint(mux.Vars(r)["prompt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid prompt_id")
+		return
+	}
+
+	template, err := h.Store.GetPromptTemplate(promptID)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, template)
+}
+
+// UpdatePromptTemplate сохраняет новую версию текста шаблона
+// @Summary Update prompt template
+// @Description Adds a new version of the template text. Previous versions are kept; threads record the version they were started with
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param prompt_id path int true "Prompt template ID"
+// @Param prompt body updatePromptTemplateRequest true "Prompt text"
+// @Success 200 {object} store.PromptTemplate
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /admin/prompts/{prompt_id} [put]
+// @Security CookieAuth
+func (h *Handler) UpdatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	promptID, err := strconv.ParseUint(mux.Vars(r)["prompt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid prompt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	var request updatePromptTemplateRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	template, err := h.Store.UpdatePromptTemplate(promptID, request.Text, userID)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, template)
+}
+
+// SetTestPrompt назначает тесту шаблон промпта
+// @Summary Set test prompt template
+// @Description Assigns a prompt template to the test. version 0 follows the latest template version. null or templateId 0 restores the default context
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param If-Match header string true "Test version from the version field or ETag, * skips the check"
+// @Param prompt body store.TestPrompt true "Prompt assignment"
+// @Success 200 {object} store.Test
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /admin/tests/{test_id}/prompt [put]
+// @Security CookieAuth
+func (h *Handler) SetTestPrompt(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var request *store.TestPrompt
+	if err := apiutils.DecodeJSON(r, &request); err != nil {
+		apiutils.WriteDecodeError(w, err)
+		return
+	}
+	if request != nil && request.TemplateID == 0 {
+		request = nil
+	}
+	if request != nil && !validateRequest(w, request) {
+		return
+	}
+
+	test, err := h.Store.SetTestPrompt(testID, request, version)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+
+	setVersionETag(w, test.Version)
+	apiutils.WriteJSON(w, http.StatusOK, test)
+}
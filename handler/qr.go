@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	defaultFrontendURL = "http://localhost:3000"
+	defaultQRSize      = 256
+	maxQRSize          = 1024
+)
+
+// frontendURL возвращает адрес фронтенда для ссылок, задается переменной окружения FRONTEND_URL
+func frontendURL() string {
+	if v := os.Getenv("FRONTEND_URL"); v != "" {
+		return strings.TrimRight(v, "/")
+	}
+	return defaultFrontendURL
+}
+
+// accessCodeDeepLink возвращает ссылку на страницу теста с подставленным кодом доступа
+func accessCodeDeepLink(testID uint64, code string) string {
+	return fmt.Sprintf("%s/tests/%d?code=%s", frontendURL(), testID, url.QueryEscape(code))
+}
+
+// AccessCodeQR возвращает QR-код со ссылкой на тест и кодом доступа
+// @Summary Access code QR
+// @Description Returns a QR code (PNG or SVG) encoding a deep link to the test with the access code prefilled
+// @Tags codes
+// @Produce png
+// @Produce image/svg+xml
+// @Param test_id path int true "Test ID"
+// @Param code path string true "Access code"
+// @Param format query string false "png (default) or svg"
+// @Param size query int false "PNG size in pixels (default 256, max 1024)"
+// @Success 200 {file} binary
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/codes/{code}/qr [get]
+// @Security CookieAuth
+func (h *Handler) AccessCodeQR(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	accessCode, ok := h.Store.GetAccessCode(testID, vars["code"])
+	if !ok {
+		writeError(w, http.StatusNotFound, codeAccessCodeInvalid, "access code not found")
+		return
+	}
+
+	size := defaultQRSize
+	if v := r.URL.Query().Get("size"); v != "" {
+		size, err = strconv.Atoi(v)
+		if err != nil || size <= 0 || size > maxQRSize {
+			writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid size")
+			return
+		}
+	}
+
+	qr, err := qrcode.New(accessCodeDeepLink(testID, accessCode.Code), qrcode.Medium)
+	if err != nil {
+		log.Error().Err(err).Msg("qr encode error")
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error")
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "png":
+		png, err := qr.PNG(size)
+		if err != nil {
+			log.Error().Err(err).Msg("qr png error")
+			writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error")
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+		w.Write(png)
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(qrSVG(qr.Bitmap())))
+	default:
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid format")
+	}
+}
+
+// qrSVG рисует битовую карту QR-кода как SVG, один модуль = один квадрат
+func qrSVG(bitmap [][]bool) string {
+	n := len(bitmap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+
+	return b.String()
+}
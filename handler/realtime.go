@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/realtime"
+	"GEEK_back/store"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// timeWarningThresholds - за сколько до конца попытки участники получают предупреждение
+var timeWarningThresholds = []time.Duration{5 * time.Minute, time.Minute}
+
+// wsUpgrader принимает WebSocket только с разрешенных origins: браузер не применяет к нему CORS,
+// а cookie сессии отправляет с любого сайта. Запросы без Origin приходят не из браузера
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || mw.OriginAllowed(origin)
+	},
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		code := apiutils.CodeBadRequest
+		if status == http.StatusForbidden {
+			code = apiutils.CodeForbidden
+		}
+		writeError(w, status, code, reason.Error())
+	},
+}
+
+// teacherMessageRequest - сообщение преподавателя студентам
+type teacherMessageRequest struct {
+	Message string `json:"message" validate:"required,max=2000"`
+}
+
+// teacherMessageResponse - сколько пользователей получили сообщение
+type teacherMessageResponse struct {
+	Recipients int `json:"recipients"`
+}
+
+// ServeWS открывает WebSocket с событиями для пользователя
+// @Summary Real-time events
+// @Description Upgrades to a WebSocket that pushes JSON events {type, data, at}: time_warning, teacher_message and attempt_graded. The connection is bound to the session cookie and is closed on logout or when the session expires. Browsers may connect only from allowed origins
+// @Tags realtime
+// @Success 101
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 401 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /ws [get]
+// @Security CookieAuth
+func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+	session, err := r.Cookie("session_id")
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "no session cookie")
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader уже ответил ошибкой
+		return
+	}
+
+	sessionID := session.Value
+	h.Realtime.Serve(conn, userID, sessionID, func() bool {
+		_, ok := h.Store.GetUserBySession(sessionID)
+		return ok
+	})
+}
+
+// SendAttemptMessage отправляет сообщение преподавателя участникам попытки
+// @Summary Message attempt participants
+// @Description Sends a teacher message to the student (or the whole team) of an attempt. It is stored as a notification and pushed over the WebSocket
+// @Tags realtime
+// @Accept json
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param message body teacherMessageRequest true "Message"
+// @Success 201 {object} teacherMessageResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/messages [post]
+// @Security CookieAuth
+func (h *Handler) SendAttemptMessage(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	var request teacherMessageRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	notifications, err := h.Store.NotifyAttempt(attemptID, store.NotificationTeacherMessage, request.Message)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	h.publishTeacherMessages(notifications)
+	apiutils.WriteJSON(w, http.StatusCreated, teacherMessageResponse{Recipients: len(notifications)})
+}
+
+// SendTestMessage отправляет сообщение преподавателя всем, кто сейчас проходит тест
+// @Summary Message everyone taking a test
+// @Description Sends a teacher message to participants of all started attempts of the test, e.g. a correction to a question. It is stored as a notification and pushed over the WebSocket
+// @Tags realtime
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param message body teacherMessageRequest true "Message"
+// @Success 201 {object} teacherMessageResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/messages [post]
+// @Security CookieAuth
+func (h *Handler) SendTestMessage(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	var request teacherMessageRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	notifications, err := h.Store.NotifyActiveAttempts(testID, store.NotificationTeacherMessage, request.Message)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	h.publishTeacherMessages(notifications)
+	apiutils.WriteJSON(w, http.StatusCreated, teacherMessageResponse{Recipients: len(notifications)})
+}
+
+// publishTeacherMessages отправляет сообщения преподавателя подключенным получателям
+func (h *Handler) publishTeacherMessages(notifications []*store.Notification) {
+	for _, notification := range notifications {
+		h.Realtime.Publish([]uint64{notification.UserID}, realtime.EventTeacherMessage, notification)
+	}
+}
+
+// publishAttemptGraded сообщает участникам итог попытки, если она уже полностью оценена
+func (h *Handler) publishAttemptGraded(attemptID uint64) {
+	if grade, ok := h.Store.GradedAttempt(attemptID); ok {
+		h.Realtime.Publish(grade.UserIDs, realtime.EventAttemptGraded, grade)
+	}
+}
+
+// sendTimeWarnings предупреждает участников попыток, у которых заканчивается время
+func (h *Handler) sendTimeWarnings(ctx context.Context) error {
+	for _, warning := range h.Store.DueTimeWarnings(timeWarningThresholds) {
+		h.Realtime.Publish(warning.UserIDs, realtime.EventTimeWarning, warning)
+		h.Realtime.PublishAttempt(warning.AttemptID, realtime.EventTimeWarning, warning)
+	}
+	return nil
+}
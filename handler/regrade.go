@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type updateQuestionAnswerRequest struct {
+	Answer string `json:"answer" validate:"required"`
+}
+
+type regradeRequest struct {
+	QuestionIDs []uint64 `json:"question_ids" validate:"unique"`
+}
+
+// UpdateQuestionAnswer исправляет эталонный ответ на вопрос
+// @Summary Fix accepted answer of a question
+// @Description Replaces the accepted answer of the question. Use the regrade endpoint afterwards to re-evaluate submitted attempts
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param question_id path int true "Question ID"
+// @Param If-Match header string true "Question version from the version field or ETag, * skips the check"
+// @Param answer body updateQuestionAnswerRequest true "New accepted answer"
+// @Success 200 {object} store.Question
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /tests/{test_id}/questions/{question_id}/answer [put]
+// @Security CookieAuth
+func (h *Handler) UpdateQuestionAnswer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questionID, err := strconv.ParseUint(vars["question_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var request updateQuestionAnswerRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	question, err := h.Store.UpdateQuestionAnswer(testID, questionID, request.Answer, version)
+	if err != nil {
+		writeUpdateErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	setVersionETag(w, question.Version)
+	apiutils.WriteJSON(w, http.StatusOK, question)
+}
+
+// RegradeTest перепроверяет сданные попытки теста
+// @Summary Regrade submitted attempts
+// @Description Re-evaluates automatically graded answers of all submitted attempts against current accepted answers and records a regrade event on each affected attempt
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param regrade body regradeRequest false "Limit regrade to these questions"
+// @Success 200 {object} store.RegradeSummary
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/regrade [post]
+// @Security CookieAuth
+func (h *Handler) RegradeTest(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	// Тело необязательное: без него перепроверяются все вопросы
+	var request regradeRequest
+	if r.ContentLength != 0 {
+		if !decodeRequest(w, r, &request) {
+			return
+		}
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	summary, err := h.Store.RegradeTest(testID, userID, request.QuestionIDs)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// reportCheckInterval - как часто задача weekly_reports ищет подписки, по которым пора отправить письмо
+const reportCheckInterval = 5 * time.Minute
+
+// reportTemplate - шаблон письма с еженедельным отчетом
+const reportTemplate = "weekly_report"
+
+// reportSubscriptionRequest - настройки подписки на еженедельный отчет
+type reportSubscriptionRequest struct {
+	TestIDs     []uint64 `json:"test_ids" validate:"unique"`                // пусто = все тесты преподавателя
+	Weekday     int      `json:"weekday" validate:"min=0,max=6"`            // 0 = воскресенье
+	Hour        int      `json:"hour" validate:"min=0,max=23"`              // час отправки в timezone
+	Timezone    string   `json:"timezone"`                                  // IANA, пусто = UTC
+	Lang        string   `json:"lang" validate:"omitempty,oneof=en ru"`     // пусто = язык запроса
+	PassPercent *uint64  `json:"pass_percent" validate:"omitempty,max=100"` // по умолчанию 60
+	Paused      bool     `json:"paused"`
+}
+
+// options переводит запрос в настройки хранилища, lang - язык запроса
+func (request reportSubscriptionRequest) options(lang string) store.ReportSubscriptionOptions {
+	opts := store.ReportSubscriptionOptions{
+		TestIDs:     request.TestIDs,
+		Weekday:     time.Weekday(request.Weekday),
+		Hour:        request.Hour,
+		Timezone:    request.Timezone,
+		Lang:        request.Lang,
+		PassPercent: store.DefaultPassPercent,
+		Paused:      request.Paused,
+	}
+	if opts.Lang == "" {
+		opts.Lang = lang
+	}
+	if request.PassPercent != nil {
+		opts.PassPercent = *request.PassPercent
+	}
+	return opts
+}
+
+// CreateReportSubscription подписывает преподавателя на еженедельный отчет
+// @Summary Subscribe to the weekly report
+// @Description Subscribes the current teacher to a weekly email with activity and results of their tests over the last 7 days:
+// @Description attempts started and submitted, attempts in progress and awaiting review, average score and pass rate per test.
+// @Description The email is sent on weekday (0 = Sunday) at hour in timezone, in lang (default: the request language).
+// @Description Without test_ids the report covers all the teacher's tests, see /dashboard/teacher
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param subscription body reportSubscriptionRequest true "Subscription"
+// @Success 201 {object} store.ReportSubscription
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /reports/subscriptions [post]
+// @Security CookieAuth
+func (h *Handler) CreateReportSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	var request reportSubscriptionRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	subscription, err := h.Store.CreateReportSubscription(userID, request.options(mw.GetLanguage(r.Context())))
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, subscription)
+}
+
+// ListReportSubscriptions возвращает подписки текущего преподавателя
+// @Summary List weekly report subscriptions
+// @Description Returns the current teacher's subscriptions with the time of the next email and the result of the last one
+// @Tags reports
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, next_send_at or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.ReportSubscription]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /reports/subscriptions [get]
+// @Security CookieAuth
+func (h *Handler) ListReportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	writeList(w, r, h.Store.ListReportSubscriptions(userID), reportSubscriptionSorts)
+}
+
+// GetReportSubscription возвращает подписку текущего преподавателя
+// @Summary Get weekly report subscription
+// @Tags reports
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {object} store.ReportSubscription
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /reports/subscriptions/{subscription_id} [get]
+// @Security CookieAuth
+func (h *Handler) GetReportSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, subscriptionID, ok := reportSubscriptionVars(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.Store.GetReportSubscription(userID, subscriptionID)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, subscription)
+}
+
+// UpdateReportSubscription меняет настройки подписки
+// @Summary Update weekly report subscription
+// @Description Replaces all settings of the subscription and reschedules the next email. Set paused to stop emails without losing the settings
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Param subscription body reportSubscriptionRequest true "Subscription"
+// @Success 200 {object} store.ReportSubscription
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /reports/subscriptions/{subscription_id} [put]
+// @Security CookieAuth
+func (h *Handler) UpdateReportSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, subscriptionID, ok := reportSubscriptionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var request reportSubscriptionRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	subscription, err := h.Store.UpdateReportSubscription(userID, subscriptionID, request.options(mw.GetLanguage(r.Context())))
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, subscription)
+}
+
+// DeleteReportSubscription отписывает преподавателя от отчета
+// @Summary Unsubscribe from the weekly report
+// @Tags reports
+// @Param subscription_id path int true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /reports/subscriptions/{subscription_id} [delete]
+// @Security CookieAuth
+func (h *Handler) DeleteReportSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, subscriptionID, ok := reportSubscriptionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Store.DeleteReportSubscription(userID, subscriptionID); err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendReportNow отправляет отчет по подписке сейчас, не дожидаясь расписания
+// @Summary Send the weekly report now
+// @Description Queues the report for the last 7 days immediately, for example to preview the email. The schedule does not change
+// @Tags reports
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Success 202 {object} store.ReportSubscription
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 503 {object} apiutils.ErrorResponse
+// @Router /reports/subscriptions/{subscription_id}/send [post]
+// @Security CookieAuth
+func (h *Handler) SendReportNow(w http.ResponseWriter, r *http.Request) {
+	userID, subscriptionID, ok := reportSubscriptionVars(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.Store.GetReportSubscription(userID, subscriptionID)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	err = h.sendReport(subscription, now)
+	h.Store.MarkReportSent(subscription.ID, now, err)
+	if err != nil {
+		writeErr(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	subscription, err = h.Store.GetReportSubscription(userID, subscriptionID)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+	apiutils.WriteJSON(w, http.StatusAccepted, subscription)
+}
+
+// reportSubscriptionVars читает пользователя и subscription_id, при ошибке пишет ответ и возвращает false
+func reportSubscriptionVars(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return 0, 0, false
+	}
+	subscriptionID, err := strconv.ParseUint(mux.Vars(r)["subscription_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid subscription_id")
+		return 0, 0, false
+	}
+	return userID, subscriptionID, true
+}
+
+func writeReportError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrReportSubscriptionNotFound) {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	writeErr(w, http.StatusBadRequest, err)
+}
+
+// sendWeeklyReports отправляет письма по подпискам, для которых наступило время по расписанию
+func (h *Handler) sendWeeklyReports(ctx context.Context) error {
+	now := time.Now().UTC()
+	due := h.Store.DueReportSubscriptions(now)
+
+	failed := 0
+	for _, subscription := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := h.sendReport(subscription, now)
+		if err != nil {
+			failed++
+			log.Warn().Err(err).Uint64("subscription_id", subscription.ID).Uint64("user_id", subscription.UserID).Msg("weekly report not sent")
+		}
+		h.Store.MarkReportSent(subscription.ID, now, err)
+	}
+
+	if len(due) > 0 {
+		log.Info().Int("reports", len(due)-failed).Int("failed", failed).Msg("weekly reports queued")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d weekly reports not sent", failed, len(due))
+	}
+	return nil
+}
+
+// sendReport собирает отчет за последние store.ReportPeriod до now и ставит письмо в очередь
+func (h *Handler) sendReport(subscription *store.ReportSubscription, now time.Time) error {
+	report, err := h.Store.BuildReport(subscription, now.Add(-store.ReportPeriod), now)
+	if err != nil {
+		return err
+	}
+	return h.Mailer.EnqueueTemplate([]string{report.Email}, reportTemplate, subscription.Lang, newReportEmail(report, subscription))
+}
+
+// reportEmail - данные шаблона weekly_report: числа уже отформатированы, пустые значения заменены прочерком
+type reportEmail struct {
+	Period string
+	Tests  []reportEmailTest
+	URL    string
+}
+
+type reportEmailTest struct {
+	Name           string
+	MaxScore       uint64
+	Started        int
+	Submitted      int
+	OpenAttempts   int
+	AwaitingReview int
+	AvgScore       string
+	PassRate       string
+}
+
+// newReportEmail готовит отчет для шаблона. Даты периода - в часовом поясе подписки
+func newReportEmail(report *store.Report, subscription *store.ReportSubscription) reportEmail {
+	location, err := time.LoadLocation(subscription.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	email := reportEmail{
+		Period: report.From.In(location).Format("02.01.2006") + " - " + report.To.In(location).Format("02.01.2006"),
+		Tests:  make([]reportEmailTest, len(report.Tests)),
+		URL:    frontendURL() + "/dashboard",
+	}
+	for i, test := range report.Tests {
+		entry := reportEmailTest{
+			Name:           test.Name,
+			MaxScore:       test.MaxScore,
+			Started:        test.Started,
+			Submitted:      test.Submitted,
+			OpenAttempts:   test.OpenAttempts,
+			AwaitingReview: test.AwaitingReview,
+			AvgScore:       "-",
+			PassRate:       "-",
+		}
+		if test.AvgScore != nil {
+			entry.AvgScore = strconv.FormatFloat(*test.AvgScore, 'f', 1, 64)
+		}
+		if test.PassRate != nil {
+			entry.PassRate = strconv.FormatFloat(*test.PassRate*100, 'f', 0, 64) + "%"
+		}
+		email.Tests[i] = entry
+	}
+	return email
+}
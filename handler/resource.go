@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	mw "GEEK_back/middleware"
+	"GEEK_back/storage"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// maxTestResourceSize - максимальный размер файла из белого списка теста
+const maxTestResourceSize = 10 << 20
+
+// testResourcePolicy - файлы белого списка: любые типы, кроме исполняемых браузером
+var testResourcePolicy = storage.Policy{MaxSize: maxTestResourceSize}
+
+// UploadTestResource добавляет файл в белый список теста
+// @Summary Upload test resource
+// @Description Adds a file (up to 10 MB) to the test whitelist, e.g. a dataset a question refers to. Students can attach whitelisted files to their AI threads.
+// @Description HTML, SVG, XML and JavaScript files are rejected
+// @Tags tests
+// @Accept multipart/form-data
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param file formData file true "File"
+// @Param name formData string false "Display name (default file name)"
+// @Success 201 {object} store.TestResource
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 413 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/resources [post]
+// @Security CookieAuth
+func (h *Handler) UploadTestResource(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTestResourceSize+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, apiutils.CodePayloadTooLarge, "file is too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, "file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxTestResourceSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeBadRequest, "failed to read file")
+		return
+	}
+
+	contentType, err := testResourcePolicy.Check(header.Header.Get("Content-Type"), data)
+	switch {
+	case errors.Is(err, storage.ErrTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, apiutils.CodePayloadTooLarge, "file is too large")
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnsupportedMediaType, "unsupported file type: "+contentType)
+		return
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		name = header.Filename
+	}
+
+	key := storage.NewKey("resources", strconv.FormatUint(testID, 10))
+	if !h.saveFile(w, r, key, contentType, data) {
+		return
+	}
+
+	resource, err := h.Store.AddTestResource(testID, name, contentType, len(data), key, userID)
+	if err != nil {
+		go h.deleteFile(key)
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusCreated, resource)
+}
+
+// ListTestResources возвращает белый список файлов теста
+// @Summary List test resources
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, name, size or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.TestResource]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/resources [get]
+// @Security CookieAuth
+func (h *Handler) ListTestResources(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	writeList(w, r, h.Store.ListTestResources(testID), resourceSorts)
+}
+
+// DownloadTestResource перенаправляет на подписанную ссылку файла из белого списка теста
+// @Summary Download test resource
+// @Description Redirects to a signed link to the file, the link is valid for 15 minutes
+// @Tags tests
+// @Param test_id path int true "Test ID"
+// @Param resource_id path int true "Resource ID"
+// @Success 302 "Location: signed file URL"
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/resources/{resource_id} [get]
+// @Security CookieAuth
+func (h *Handler) DownloadTestResource(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	resourceID, err := strconv.ParseUint(vars["resource_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid resource_id")
+		return
+	}
+
+	resource, err := h.Store.TestResource(testID, resourceID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	h.redirectToFile(w, r, resource.Key, storage.URLOptions{ContentType: resource.ContentType, Filename: resource.Name})
+}
+
+// DeleteTestResource убирает файл из белого списка теста
+// @Summary Delete test resource
+// @Description Removes the file from the whitelist and deletes it from the file storage and the AI provider storage
+// @Tags tests
+// @Param test_id path int true "Test ID"
+// @Param resource_id path int true "Resource ID"
+// @Success 204
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/resources/{resource_id} [delete]
+// @Security CookieAuth
+func (h *Handler) DeleteTestResource(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	resourceID, err := strconv.ParseUint(vars["resource_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid resource_id")
+		return
+	}
+
+	resource, err := h.Store.DeleteTestResource(testID, resourceID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	go h.deleteFile(resource.Key)
+	if resource.ProviderFileID != "" {
+		go h.deleteProviderFile(resource.ProviderFileID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteProviderFile удаляет файл у провайдера; ошибка только логируется
+func (h *Handler) deleteProviderFile(fileID string) {
+	files, ok := h.AI.(llm.FileStore)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiThreadDeleteTimeout)
+	defer cancel()
+
+	if err := files.DeleteFile(ctx, fileID); err != nil {
+		log.Warn().Err(err).Str("provider", h.AI.Name()).Str("file_id", fileID).Msg("failed to delete ai file")
+	}
+}
+
+// ListAttemptResources возвращает файлы, которые можно прикрепить к диалогам попытки
+// @Summary List attachable resources
+// @Description Returns the test whitelist of files that can be attached to AI threads of the attempt
+// @Tags ai
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, name, size or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.TestResource]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/resources [get]
+// @Security CookieAuth
+func (h *Handler) ListAttemptResources(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	resources, err := h.Store.AttemptResources(attemptID, userID)
+	switch {
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeList(w, r, resources, resourceSorts)
+}
+
+type attachResourceRequest struct {
+	ResourceID uint64 `json:"resource_id" validate:"required"`
+}
+
+type attachResourceResponse struct {
+	ThreadID    string   `json:"thread_id"`
+	Attachments []uint64 `json:"attachments"`
+}
+
+// AttachAIResource прикрепляет файл из белого списка теста к диалогу с ассистентом
+// @Summary Attach resource to AI thread
+// @Description Uploads a whitelisted test file to the AI provider (once) and attaches it to the thread. Only providers with file storage (OpenAI Assistants) support attachments
+// @Tags ai
+// @Accept json
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Param request body attachResourceRequest true "Resource"
+// @Success 200 {object} attachResourceResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Failure 501 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/attachments [post]
+// @Security CookieAuth
+func (h *Handler) AttachAIResource(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	threadID := vars["thread_id"]
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	var req attachResourceRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if err := h.Store.CheckDeadline(attemptID); err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	files, ok := h.AI.(llm.FileStore)
+	if !ok {
+		writeErr(w, http.StatusNotImplemented, llm.ErrFilesNotSupported)
+		return
+	}
+
+	resource, err := h.Store.AttachableResource(attemptID, threadID, userID, req.ResourceID)
+	switch {
+	case errors.Is(err, store.ErrAIThreadNotFound), errors.Is(err, store.ErrResourceNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrAIThreadClosed), errors.Is(err, store.ErrResourceAlreadyAttached):
+		writeErr(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, store.ErrAIThreadAccess):
+		writeErr(w, http.StatusForbidden, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Пока ассистент отвечает, провайдер не принимает новые сообщения в тред
+	if active, ok := h.Store.ActiveAIRun(threadID); ok {
+		writeErrorDetails(w, http.StatusConflict, codeAIRunInProgress, store.ErrAIRunInProgress.Error(), aiRunConflictDetails{RunID: active.ID})
+		return
+	}
+
+	// Файл загружается один раз и переиспользуется всеми диалогами теста
+	if resource.ProviderFileID == "" {
+		data, ok := h.readFile(w, r, resource.Key)
+		if !ok {
+			return
+		}
+		fileID, err := files.UploadAttachment(r.Context(), resource.Name, resource.ContentType, data)
+		if err != nil {
+			h.writeAttachmentError(w, err)
+			return
+		}
+		resource.ProviderFileID = fileID
+		h.Store.SetResourceProviderFile(resource.ID, fileID)
+	}
+
+	if err := files.AttachFile(r.Context(), threadID, resource.ProviderFileID, resource.Name); err != nil {
+		h.writeAttachmentError(w, err)
+		return
+	}
+
+	thread, err := h.Store.RecordAIThreadAttachment(threadID, resource.ID)
+	if err != nil {
+		writeErr(w, http.StatusConflict, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, attachResourceResponse{ThreadID: threadID, Attachments: thread.Attachments})
+}
+
+// writeAttachmentError пишет ошибку провайдера при работе с файлами
+func (h *Handler) writeAttachmentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, llm.ErrFilesNotSupported) {
+		writeErr(w, http.StatusNotImplemented, err)
+		return
+	}
+	log.Error().Err(err).Str("provider", h.AI.Name()).Msg("ai file attachment failed")
+	h.writeAIError(w, err)
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type gradeReviewRequest struct {
+	Score uint64 `json:"score"`
+}
+
+// ListReviewQueue возвращает ответы, ожидающие ручной проверки
+// @Summary List manual grading queue
+// @Description Returns answers awaiting teacher grading in tests of the teacher's organization (all tests for admins), oldest first
+// @Tags review
+// @Produce json
+// @Param test_id query int false "Test ID"
+// @Param status query string false "pending or claimed"
+// @Param reason query string false "manual or low_confidence"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or test_id, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.ReviewItem]
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /review [get]
+// @Security CookieAuth
+func (h *Handler) ListReviewQueue(w http.ResponseWriter, r *http.Request) {
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	filters := apiutils.NewFilters(r)
+	testID := filters.Uint("test_id")
+	status := filters.OneOf("status", store.ReviewPending, store.ReviewClaimed)
+	reason := filters.OneOf("reason", store.ReviewReasonManual, store.ReviewReasonLowConfidence)
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	items, err := h.reviewQueue(teacherID, testID, status, reason)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	writeList(w, r, items, reviewItemSorts)
+}
+
+// reviewQueue возвращает очередь проверки по тестам преподавателя с фильтрами, пустой фильтр не ограничивает
+func (h *Handler) reviewQueue(teacherID, testID uint64, status, reason string) ([]*store.ReviewItem, error) {
+	items, err := h.Store.ListReviewQueue(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	return filterList(items, func(item *store.ReviewItem) bool {
+		return (testID == 0 || item.TestID == testID) &&
+			(status == "" || item.Status == status) &&
+			(reason == "" || item.Reason == reason)
+	}), nil
+}
+
+// ClaimReviewItem закрепляет ответ за преподавателем
+// @Summary Claim review item
+// @Description Assigns the review item to the current teacher so others don't grade it concurrently
+// @Tags review
+// @Produce json
+// @Param item_id path int true "Review item ID"
+// @Success 200 {object} store.ReviewItem
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Router /review/{item_id}/claim [post]
+// @Security CookieAuth
+func (h *Handler) ClaimReviewItem(w http.ResponseWriter, r *http.Request) {
+	itemID, teacherID, ok := h.reviewParams(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.Store.ClaimReviewItem(itemID, teacherID)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, item)
+}
+
+// ReleaseReviewItem возвращает ответ обратно в очередь
+// @Summary Release review item
+// @Description Returns a claimed review item back to the queue
+// @Tags review
+// @Produce json
+// @Param item_id path int true "Review item ID"
+// @Success 200 {object} store.ReviewItem
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Router /review/{item_id}/release [post]
+// @Security CookieAuth
+func (h *Handler) ReleaseReviewItem(w http.ResponseWriter, r *http.Request) {
+	itemID, teacherID, ok := h.reviewParams(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.Store.ReleaseReviewItem(itemID, teacherID)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, item)
+}
+
+// GradeReviewItem выставляет оценку за ответ
+// @Summary Grade review item
+// @Description Sets the score for the answer; the attempt is finalized once all its answers are graded
+// @Tags review
+// @Accept json
+// @Produce json
+// @Param item_id path int true "Review item ID"
+// @Param grade body gradeReviewRequest true "Score"
+// @Success 200 {object} store.ReviewItem
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Router /review/{item_id}/grade [post]
+// @Security CookieAuth
+func (h *Handler) GradeReviewItem(w http.ResponseWriter, r *http.Request) {
+	itemID, teacherID, ok := h.reviewParams(w, r)
+	if !ok {
+		return
+	}
+
+	var request gradeReviewRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	item, err := h.gradeReviewItem(itemID, teacherID, request.Score)
+	if err != nil {
+		writeOpError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, item)
+}
+
+// gradeReviewItem выставляет оценку и, если попытка проверена целиком, сообщает участникам итог
+func (h *Handler) gradeReviewItem(itemID, teacherID, score uint64) (*store.ReviewItem, error) {
+	item, err := h.Store.GradeReviewItem(itemID, teacherID, score)
+	if err != nil {
+		return nil, wrapOpError(reviewErrorStatus(err), err)
+	}
+	h.publishAttemptGraded(item.AttemptID)
+	return item, nil
+}
+
+func (h *Handler) reviewParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	itemID, err := strconv.ParseUint(mux.Vars(r)["item_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid item_id")
+		return 0, 0, false
+	}
+
+	teacherID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return 0, 0, false
+	}
+
+	return itemID, teacherID, true
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	writeErr(w, reviewErrorStatus(err), err)
+}
+
+// reviewErrorStatus возвращает статус ответа на ошибку очереди проверки
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrReviewItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrReviewItemClaimed), errors.Is(err, store.ErrReviewItemGraded):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/client/llm"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// semanticScoreTimeout - сколько ждем эмбеддинги при сохранении ответа
+const semanticScoreTimeout = 10 * time.Second
+
+// scoreSemanticAnswer оценивает ответ на вопрос semantic по близости к эталону. Если провайдер
+// не строит эмбеддинги или недоступен, ответ остается pending_review и его проверит преподаватель
+func (h *Handler) scoreSemanticAnswer(ctx context.Context, attemptID, questionPos uint64, answer *store.Answer) *store.Answer {
+	if answer.Status != "pending_review" {
+		return answer
+	}
+	question, err := h.Store.AttemptQuestion(attemptID, questionPos)
+	if err != nil || question.GradingMode != store.GradingSemantic {
+		return answer
+	}
+
+	// Пустой ответ не с чем сравнивать
+	similarity := 0.0
+	if strings.TrimSpace(answer.Text) != "" {
+		embedder, ok := h.AI.(llm.Embedder)
+		if !ok {
+			return answer
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, semanticScoreTimeout)
+		defer cancel()
+
+		vectors, err := embedder.Embed(ctx, []string{question.TrueAnswer, answer.Text})
+		if err != nil {
+			if !errors.Is(err, llm.ErrEmbeddingsNotSupported) {
+				log.Warn().Err(err).Str("provider", h.AI.Name()).Uint64("attempt_id", attemptID).Msg("semantic scoring failed, answer left for review")
+			}
+			return answer
+		}
+		similarity = llm.CosineSimilarity(vectors[0], vectors[1])
+	}
+
+	scored, err := h.Store.ScoreSemanticAnswer(attemptID, questionPos, answer.Text, similarity)
+	if err != nil {
+		return answer
+	}
+	return scored
+}
+
+type questionGradingRequest struct {
+	GradingMode string                    `json:"gradingMode" validate:"required,oneof=auto manual semantic"`
+	Semantic    *store.SemanticThresholds `json:"semantic,omitempty"`
+}
+
+// SetQuestionGrading задает режим проверки вопроса
+// @Summary Set question grading mode
+// @Description Sets how answers are graded: auto (exact match), manual (teacher review) or semantic (cosine similarity of embeddings to the reference answer).
+// @Description For semantic grading answers with similarity >= accept get the full score, between review and accept go to the review queue (reason low_confidence), below review get zero.
+// @Description Default thresholds are accept 0.9 and review 0.75. Already graded answers are not changed
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param question_id path int true "Question ID"
+// @Param If-Match header string true "Question version from the version field or ETag, * skips the check"
+// @Param request body questionGradingRequest true "Grading mode"
+// @Success 200 {object} store.Question
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse "Modified by someone else, details contain current_version"
+// @Failure 428 {object} apiutils.ErrorResponse "If-Match is missing"
+// @Router /tests/{test_id}/questions/{question_id}/grading [put]
+// @Security CookieAuth
+func (h *Handler) SetQuestionGrading(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questionID, err := strconv.ParseUint(vars["question_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req questionGradingRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	question, err := h.Store.SetQuestionGrading(testID, questionID, req.GradingMode, req.Semantic, version)
+	if err != nil {
+		writeUpdateErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setVersionETag(w, question.Version)
+	apiutils.WriteJSON(w, http.StatusOK, question)
+}
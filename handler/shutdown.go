@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// aiCancelGrace - сколько ждать воркеров после отмены запросов к ассистенту: провайдеры
+// прерывают запрос по контексту, так что это время нужно только на запись результата
+const aiCancelGrace = 5 * time.Second
+
+// errShuttingDown - ошибка запросов к ассистенту, которые сервер не успел выполнить до остановки.
+// Хранилище в памяти, после перезапуска запрос не продолжится, поэтому студент отправляет сообщение заново
+var errShuttingDown = errors.New("server is restarting, send the message again")
+
+// aiQueueState - состояние очереди запросов к ассистенту для плавной остановки
+type aiQueueState struct {
+	mu      sync.Mutex
+	closed  bool // новые задания не принимаются, канал aiJobs закрыт
+	workers sync.WaitGroup
+	// ctx отменяется, если запросы не успели завершиться за время остановки
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Shutdown останавливает фоновую работу при плавной остановке сервера: новые запросы к ассистенту
+// больше не принимаются, а уже поставленные в очередь и идущие выполняются до конца, пока не истечет ctx.
+// Не успевшие запросы прерываются и завершаются с errShuttingDown, чтобы клиент, опрашивающий запрос,
+// и вебхук AI_RUN_WEBHOOK_URL узнали, что сообщение нужно отправить заново.
+// Стримы ассистента - обычные HTTP-запросы, их дожидается http.Server.Shutdown
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.Jobs.Stop()
+
+	queue := &h.aiQueue
+	queue.mu.Lock()
+	if !queue.closed {
+		queue.closed = true
+		close(h.aiJobs)
+	}
+	pending := len(h.aiJobs)
+	queue.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		queue.workers.Wait()
+		close(done)
+	}()
+
+	log.Info().Int("queued", pending).Msg("waiting for ai runs to finish")
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Warn().Int("queued", len(h.aiJobs)).Msg("ai runs did not finish in time, interrupting them")
+	queue.cancel()
+	select {
+	case <-done:
+	case <-time.After(aiCancelGrace):
+	}
+	return ctx.Err()
+}
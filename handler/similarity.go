@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// similarityCheckInterval - как часто задача answer_similarity проверяет тесты с новыми сданными попытками
+const similarityCheckInterval = time.Hour
+
+// GetAnswerSimilarity возвращает пары попыток с подозрительно похожими развернутыми ответами
+// @Summary Similar answers report
+// @Description Lists pairs of attempts by different students whose free-text answers (manual and semantic questions) are suspiciously similar.
+// @Description Answers are compared by shared 3-word sequences, ignoring wording from the question and the reference answer and phrases
+// @Description common to many answers; answers shorter than 5 such sequences are not compared. Pairs with more similar answers come first.
+// @Description The report is refreshed by a background job after new submissions; without a previous check it is computed on request
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param min_similarity query number false "Only answers at least this similar, 0.5-1 (default 0.5)"
+// @Success 200 {object} store.SimilarityReport
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/similarity [get]
+// @Security CookieAuth
+func (h *Handler) GetAnswerSimilarity(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	minSimilarity := store.SimilarityFlagThreshold
+	if v := r.URL.Query().Get("min_similarity"); v != "" {
+		minSimilarity, err = strconv.ParseFloat(v, 64)
+		if err != nil || minSimilarity < store.SimilarityFlagThreshold || minSimilarity > 1 {
+			writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter,
+				fmt.Sprintf("min_similarity must be from %g to 1", store.SimilarityFlagThreshold))
+			return
+		}
+	}
+
+	report, ok, err := h.Store.LastSimilarityReport(testID)
+	if err == nil && !ok {
+		report, err = h.Store.CheckAnswerSimilarity(testID)
+	}
+	if err != nil {
+		writeSimilarityError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, filterSimilarity(report, minSimilarity))
+}
+
+// CheckAnswerSimilarity проверяет ответы теста на списывание сейчас
+// @Summary Check answers for similarity now
+// @Description Compares the free-text answers of all submitted attempts of the test right away instead of waiting for the background job
+// @Description and returns the new report, see GET /tests/{test_id}/similarity
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Success 200 {object} store.SimilarityReport
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/similarity/check [post]
+// @Security CookieAuth
+func (h *Handler) CheckAnswerSimilarity(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	report, err := h.Store.CheckAnswerSimilarity(testID)
+	if err != nil {
+		writeSimilarityError(w, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, report)
+}
+
+func writeSimilarityError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrTestNotFound) {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	writeErr(w, http.StatusInternalServerError, err)
+}
+
+// filterSimilarity оставляет ответы не менее похожие, чем minSimilarity, и пары, в которых они остались.
+// Сохраненный отчет не меняется
+func filterSimilarity(report *store.SimilarityReport, minSimilarity float64) *store.SimilarityReport {
+	filtered := *report
+	filtered.Pairs = make([]*store.SimilarPair, 0, len(report.Pairs))
+	for _, pair := range report.Pairs {
+		answers := make([]*store.SimilarAnswers, 0, len(pair.Answers))
+		for _, answer := range pair.Answers {
+			if answer.Similarity >= minSimilarity {
+				answers = append(answers, answer)
+			}
+		}
+		if len(answers) == 0 {
+			continue
+		}
+		entry := *pair
+		entry.Answers = answers
+		filtered.Pairs = append(filtered.Pairs, &entry)
+	}
+	return &filtered
+}
+
+// checkAnswerSimilarity проверяет на списывание тесты, в которых после прошлой проверки сданы попытки
+func (h *Handler) checkAnswerSimilarity(ctx context.Context) error {
+	for _, testID := range h.Store.StaleSimilarityTests() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report, err := h.Store.CheckAnswerSimilarity(testID)
+		if err != nil {
+			continue
+		}
+		if len(report.Pairs) > 0 {
+			log.Info().Uint64("test_id", testID).Int("pairs", len(report.Pairs)).Msg("similar answers found")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+type createUserRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=student teacher admin"`
+}
+
+// CreateUser создает пользователя с ролью, например первого администратора
+// @Summary Create user
+// @Description Creates a user with the given role without the registration form, e.g. another admin or a teacher
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user body createUserRequest true "User"
+// @Success 201 {object} store.User
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/users [post]
+// @Security CookieAuth
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var request createUserRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	user, err := h.Store.CreateUser(request.Email, request.Password)
+	if errors.Is(err, store.ErrUserExists) {
+		writeError(w, http.StatusBadRequest, codeUserExists, "user already exists")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := h.Store.SetUserRole(user.ID, request.Role); err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.emitUserRegistered(user)
+	apiutils.WriteJSON(w, http.StatusCreated, user)
+}
+
+// GetSnapshot выгружает снимок пользователей, тестов и кодов доступа
+// @Summary Dump snapshot
+// @Description Returns users (with bcrypt password hashes), tests with questions and reference answers and test access codes with their usage state.
+// @Description The snapshot has the seed fixture format and can be passed to SEED_FIXTURE or imported back.
+// @Description Attempts, sessions, classes, organizations and assistant history are not included
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]any
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/snapshot [get]
+// @Security CookieAuth
+func (h *Handler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	data, err := h.Store.Snapshot()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := fmt.Sprintf("geek-snapshot-%s.json", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// ImportSnapshot загружает снимок или фикстуру в работающий сервер
+// @Summary Import snapshot
+// @Description Adds users, tests and access codes from a snapshot or a seed fixture (JSON, or YAML with Content-Type application/yaml).
+// @Description Existing users (by email), tests (by id) and codes are skipped, so the import can be repeated; use it to import new tests.
+// @Description The whole document is validated before anything is added. The body is limited by MAX_BODY_BYTES
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param snapshot body object true "Snapshot or fixture"
+// @Success 200 {object} store.ImportResult
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 413 {object} apiutils.ErrorResponse
+// @Router /admin/snapshot [post]
+// @Security CookieAuth
+func (h *Handler) ImportSnapshot(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiutils.WriteDecodeError(w, err)
+		return
+	}
+
+	// Формат фикстуры определяется по расширению имени
+	name := "snapshot.json"
+	switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		name = "snapshot.yaml"
+	}
+
+	result, err := h.Store.ImportSnapshot(data, name)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, err.Error())
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, result)
+}
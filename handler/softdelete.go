@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// DeleteUser мягко удаляет пользователя
+// @Summary Delete user
+// @Description Marks the user as deleted and ends all their sessions: the user can no longer sign in and disappears from lookups.
+// @Description Attempts and history are kept, the email stays taken until the user is restored. An admin cannot delete themselves
+// @Tags admin
+// @Param user_id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /admin/users/{user_id} [delete]
+// @Security CookieAuth
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(mux.Vars(r)["user_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid user_id")
+		return
+	}
+	if currentUserID, _ := mw.GetUserID(r.Context()); currentUserID == userID {
+		writeError(w, http.StatusBadRequest, apiutils.CodeBadRequest, "you cannot delete yourself")
+		return
+	}
+
+	sessions, err := h.Store.DeleteUser(userID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	for _, sessionID := range sessions {
+		h.Realtime.CloseSession(sessionID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreUser восстанавливает удаленного пользователя
+// @Summary Restore user
+// @Description Clears the deletion mark; the user can sign in again with the same password
+// @Tags admin
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} store.User
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /admin/users/{user_id}/restore [post]
+// @Security CookieAuth
+func (h *Handler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(mux.Vars(r)["user_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid user_id")
+		return
+	}
+
+	user, err := h.Store.RestoreUser(userID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, user)
+}
+
+// DeleteTest мягко удаляет тест
+// @Summary Delete test
+// @Description Marks the test as deleted: it disappears from lists and lookups, new attempts and access codes cannot be created.
+// @Description Attempts already started can be finished, results and history are kept
+// @Tags tests
+// @Param test_id path int true "Test ID"
+// @Success 204
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id} [delete]
+// @Security CookieAuth
+func (h *Handler) DeleteTest(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	if err := h.Store.DeleteTest(testID); err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreTest восстанавливает удаленный тест
+// @Summary Restore test
+// @Description Clears the deletion mark; the test returns to lists with its questions, codes and settings
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Success 200 {object} store.Test
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/restore [post]
+// @Security CookieAuth
+func (h *Handler) RestoreTest(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	test, err := h.Store.RestoreTest(testID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	setVersionETag(w, test.Version)
+	apiutils.WriteJSON(w, http.StatusOK, test)
+}
+
+// ListDeletedTests возвращает удаленные тесты, которые можно восстановить
+// @Summary List deleted tests
+// @Description Returns deleted tests of the teacher's organization (all tests for admins) without questions, with deletedAt
+// @Tags tests
+// @Produce json
+// @Param q query string false "Name contains (case-insensitive)"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id or name, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Test]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /tests/deleted [get]
+// @Security CookieAuth
+func (h *Handler) ListDeletedTests(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	deleted, err := h.Store.ListDeletedTests(userID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	q := apiutils.NewFilters(r).String("q")
+	tests := filterList(deleted, func(t *store.Test) bool { return containsFold(t.Name, q) })
+	writeList(w, r, tests, testSorts)
+}
+
+// DeleteQuestion мягко удаляет вопрос теста
+// @Summary Delete question
+// @Description Marks the question as deleted: new attempts no longer include it, started and finished attempts keep it
+// @Tags tests
+// @Param test_id path int true "Test ID"
+// @Param question_id path int true "Question ID"
+// @Success 204
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/questions/{question_id} [delete]
+// @Security CookieAuth
+func (h *Handler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questionID, err := strconv.ParseUint(vars["question_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
+	}
+
+	if err := h.Store.DeleteQuestion(testID, questionID); err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreQuestion восстанавливает удаленный вопрос
+// @Summary Restore question
+// @Description Clears the deletion mark; new attempts can include the question again. A question of a deleted test is restored after the test
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param question_id path int true "Question ID"
+// @Success 200 {object} store.Question
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/questions/{question_id}/restore [post]
+// @Security CookieAuth
+func (h *Handler) RestoreQuestion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	testID, err := strconv.ParseUint(vars["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questionID, err := strconv.ParseUint(vars["question_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_id")
+		return
+	}
+
+	question, err := h.Store.RestoreQuestion(testID, questionID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	setVersionETag(w, question.Version)
+	apiutils.WriteJSON(w, http.StatusOK, question)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTestStats возвращает сводку по попыткам теста для панели преподавателя
+// @Summary Test statistics
+// @Description Aggregates attempts of the test: attempts started, in progress, awaiting review and graded, distinct students,
+// @Description average and median result, average working time without auto-pause and pass rate. Scores, durations and pass rate
+// @Description are computed over graded attempts and omitted when there are none. The pass score is pass_percent of the test max score, rounded up
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param pass_percent query int false "Pass score in percent of the max score (default 60)"
+// @Success 200 {object} store.TestStats
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/stats [get]
+// @Security CookieAuth
+func (h *Handler) GetTestStats(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	passPercent, ok := readPassPercent(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.Store.TestStats(testID, passPercent)
+	switch {
+	case errors.Is(err, store.ErrTestNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, stats)
+}
+
+// readPassPercent читает pass_percent (по умолчанию store.DefaultPassPercent), при ошибке пишет ответ и возвращает false
+func readPassPercent(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	if !r.URL.Query().Has("pass_percent") {
+		return store.DefaultPassPercent, true
+	}
+	filters := apiutils.NewFilters(r)
+	passPercent := filters.Uint("pass_percent")
+	if err := filters.Err(); err != nil || passPercent > 100 {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "pass_percent must be from 0 to 100")
+		return 0, false
+	}
+	return passPercent, true
+}
+
+// GetScoreDistribution возвращает распределение результатов теста по интервалам
+// @Summary Score distribution of a test
+// @Description Splits the range from 0 to the test max score into equal buckets and counts graded attempts in each.
+// @Description Bucket bounds are [from, to), the last bucket includes the max score and results above it.
+// @Description There are never more buckets than score points
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param buckets query int false "Number of buckets (default 10, max 100)"
+// @Success 200 {object} store.ScoreDistribution
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/stats/distribution [get]
+// @Security CookieAuth
+func (h *Handler) GetScoreDistribution(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	filters := apiutils.NewFilters(r)
+	buckets := filters.Uint("buckets")
+	if err := filters.Err(); err != nil || buckets > store.MaxScoreBuckets {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "buckets must be from 1 to 100")
+		return
+	}
+	if buckets == 0 {
+		buckets = store.DefaultScoreBuckets
+	}
+
+	distribution, err := h.Store.ScoreDistribution(testID, int(buckets))
+	switch {
+	case errors.Is(err, store.ErrTestNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, distribution)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+// aiStreamTimeout - максимальная длительность стрима ответа ассистента
+const aiStreamTimeout = 2 * time.Minute
+
+type streamDelta struct {
+	Text string `json:"text"`
+}
+
+type streamDone struct {
+	Response string         `json:"response"`
+	RunID    string         `json:"run_id"`
+	Cached   bool           `json:"cached,omitempty"`
+	Quota    *store.AIQuota `json:"quota"`
+	Turns    *store.AITurns `json:"turns,omitempty"`
+}
+
+// sseWriter пишет события Server-Sent Events и сразу отправляет их клиенту.
+// Заголовки стрима отправляются с первым событием, до этого можно ответить обычным JSON
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+}
+
+func (s *sseWriter) start() {
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("Connection", "keep-alive")
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	s.started = true
+}
+
+func (s *sseWriter) send(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if !s.started {
+		s.start()
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// comment пишет комментарий SSE: клиент его игнорирует, а прокси не закрывают соединение по простою
+func (s *sseWriter) comment(text string) error {
+	if !s.started {
+		s.start()
+	}
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// StreamMessage отправляет сообщение ассистенту и транслирует ответ по мере генерации через SSE
+// @Summary Stream AI response
+// @Description Sends a message to the assistant thread and relays the answer token by token as Server-Sent Events.
+// @Description Events: "delta" {"text"}, "done" {"response","run_id","quota","turns"}, "error" {"error"}.
+// @Description GET takes the message from the "message" query parameter (for EventSource), POST from the JSON body.
+// @Description Images uploaded via .../images are attached with image_ids (POST) or repeated image_id (GET).
+// @Tags ai
+// @Accept json
+// @Produce text/event-stream
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position path int true "Question position"
+// @Param thread_id path string true "Thread ID"
+// @Param message query string false "Message (GET)"
+// @Param image_id query []int false "Uploaded image IDs (GET)" collectionFormat(multi)
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 409 {object} apiutils.ErrorResponse
+// @Failure 429 {object} apiutils.ErrorResponse
+// @Failure 500 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/stream [post]
+// @Router /attempt/{attempt_id}/question/{question_position}/ai/{thread_id}/stream [get]
+// @Security CookieAuth
+func (h *Handler) StreamMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	threadID := vars["thread_id"]
+	if threadID == "" {
+		writeError(w, http.StatusBadRequest, apiutils.CodeValidationFailed, "thread_id is required")
+		return
+	}
+
+	attemptID, err := strconv.ParseUint(vars["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	// EventSource умеет только GET, поэтому сообщение может прийти в query
+	message := r.URL.Query().Get("message")
+	var imageIDs []uint64
+	for _, v := range r.URL.Query()["image_id"] {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid image_id")
+			return
+		}
+		imageIDs = append(imageIDs, id)
+	}
+	req := aiMessageRequest{Message: message, ImageIDs: imageIDs}
+	if r.Method == http.MethodPost {
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+	} else if !validateRequest(w, &req) {
+		return
+	}
+	message, imageIDs = req.Message, req.ImageIDs
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, apiutils.CodeInternal, "streaming is not supported")
+		return
+	}
+
+	// Проверяем дедлайн попытки
+	if err := h.Store.CheckDeadline(attemptID); err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Диалоги сданной попытки закрыты
+	if !h.checkAIThreadOpen(w, r, attemptID, threadID) {
+		return
+	}
+
+	// Проверяем лимит сообщений и токенов ассистента
+	if !h.checkAIQuota(w, r, attemptID) {
+		return
+	}
+
+	// Месячный лимит расходов на ассистента
+	if !h.checkAIBudget(w, r, attemptID) {
+		return
+	}
+
+	// Ограничиваем частоту сообщений от одного пользователя
+	if !h.checkAIRateLimit(w, userID) {
+		return
+	}
+
+	// Блокируем попытки вытащить ответы и оскорбления
+	if !h.moderateAIMessage(w, r, attemptID, userID, threadID, message) {
+		return
+	}
+
+	// Отказываем на просьбы решить вопрос за студента
+	if !h.guardAIMessage(w, r, attemptID, userID, threadID, message) {
+		return
+	}
+
+	// Ассистент, модель и температура теста или вопроса
+	opts, ok := h.aiOptions(w, r, attemptID)
+	if !ok {
+		return
+	}
+	if opts.Images, ok = h.aiMessageImages(w, r, threadID, userID, imageIDs); !ok {
+		return
+	}
+
+	// Стрим тоже занимает тред, пока ассистент не ответит
+	questionPos, _ := strconv.ParseUint(vars["question_position"], 10, 64)
+	run, ok := h.createAIRun(w, r, attemptID, questionPos, userID, threadID)
+	if !ok {
+		return
+	}
+	_ = h.Store.StartAIRun(run.ID)
+
+	stream := &sseWriter{w: w, flusher: flusher}
+
+	// Одинаковый запрос к тому же вопросу отдаем из кеша одним фрагментом
+	cacheKey, cacheTTL := h.aiCacheKey(attemptID, questionPos, opts, message)
+	if cached, ok := h.cachedAIReply(cacheKey); ok {
+		h.saveAIMessage(threadID, store.AIMessage{Role: store.AIRoleUser, UserID: userID, Text: message})
+		h.saveAIMessage(threadID, store.AIMessage{Role: store.AIRoleAssistant, UserID: userID, Text: cached, Cached: true})
+		h.finishAIRun(run.ID, cached, true, nil)
+		_ = stream.send("delta", streamDelta{Text: cached})
+		_ = stream.send("done", streamDone{Response: cached, Cached: true, Quota: h.remainingAIQuota(attemptID), Turns: h.aiThreadTurns(threadID)})
+		return
+	}
+
+	// Отключение клиента отменяет контекст запроса и прерывает генерацию ответа
+	ctx, cancel := context.WithTimeout(r.Context(), aiStreamTimeout)
+	defer cancel()
+
+	reply, err := h.AI.Stream(ctx, threadID, message, opts, func(text string) error {
+		return stream.send("delta", streamDelta{Text: text})
+	})
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("provider", h.AI.Name()).Str("thread_id", threadID).Msg("ai stream failed")
+		h.finishAIRun(run.ID, "", false, err)
+		// Если ответ еще не начался, возвращаем обычную ошибку
+		if !stream.started {
+			h.writeAIError(w, err)
+			return
+		}
+		_ = stream.send("error", apiutils.NewError(w, codeAIStreamFailed, "assistant stream failed", nil))
+		return
+	}
+
+	h.saveAIMessage(threadID, store.AIMessage{Role: store.AIRoleUser, UserID: userID, Text: message, Images: imageIDs})
+	h.saveAIMessage(threadID, h.assistantAIMessage(userID, reply, opts))
+	h.cacheAIReply(cacheKey, reply.Text, cacheTTL)
+	h.finishAIRun(run.ID, reply.Text, false, nil)
+	_ = stream.send("done", streamDone{
+		Response: reply.Text,
+		RunID:    reply.RunID,
+		Quota:    h.remainingAIQuota(attemptID),
+		Turns:    h.aiThreadTurns(threadID),
+	})
+}
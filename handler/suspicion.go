@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAttemptSuspicion возвращает оценку риска списывания в попытке с объяснениями
+// @Summary Attempt suspicion score
+// @Description Combines signals into a risk score from 0 to 100 with an explanation per factor, instead of raw event logs:
+// @Description blocked_ai_messages (messages blocked by moderation or refused by the guard), answer_from_assistant (free-text answers
+// @Description mostly repeating the assistant's replies), heavy_ai_use (at least 10 messages and 3 times the test median),
+// @Description fast_answers (answers appearing after opening the question faster than 8 characters per second),
+// @Description short_duration (4 times faster than the test median with a result not below the median) and similar_answers
+// @Description (from the last similarity check, see /tests/{test_id}/similarity). Level is medium from 30 and high from 60.
+// @Description Test medians need at least 5 submitted attempts
+// @Tags review
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Success 200 {object} store.AttemptSuspicion
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/suspicion [get]
+// @Security CookieAuth
+func (h *Handler) GetAttemptSuspicion(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	suspicion, err := h.Store.AttemptSuspicion(attemptID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, suspicion)
+}
+
+// GetTestSuspicion возвращает попытки теста, отсортированные по риску списывания
+// @Summary Test suspicion scores
+// @Description Scores every attempt of the test as in /attempt/{attempt_id}/suspicion and returns attempts with a score
+// @Description of at least min_score, the most suspicious first
+// @Tags review
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param min_score query int false "Minimum score, 0-100 (default 1: attempts with at least one factor)"
+// @Success 200 {object} store.TestSuspicion
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/suspicion [get]
+// @Security CookieAuth
+func (h *Handler) GetTestSuspicion(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	minScore := uint64(1)
+	if r.URL.Query().Has("min_score") {
+		filters := apiutils.NewFilters(r)
+		minScore = filters.Uint("min_score")
+		if err := filters.Err(); err != nil || minScore > store.MaxSuspicionScore {
+			writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter,
+				fmt.Sprintf("min_score must be from 0 to %d", store.MaxSuspicionScore))
+			return
+		}
+	}
+
+	suspicion, err := h.Store.TestSuspicion(testID, int(minScore))
+	switch {
+	case errors.Is(err, store.ErrTestNotFound):
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, suspicion)
+}
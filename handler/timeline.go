@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAttemptTimeline возвращает хронологию попытки для разбора спорных случаев
+// @Summary Attempt timeline
+// @Description Reconstructs the attempt in chronological order: start, questions opened, drafts saved and the final answer per question,
+// @Description messages to and from the assistant including ones blocked by moderation or refused by the guard, other proctoring events,
+// @Description submission, manual grading, feedback and regrades. Answers saved offline are placed at the client time,
+// @Description received_at shows when the server got them. Opening the same question again in a row and saving the same text again are not recorded
+// @Tags review
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Success 200 {object} store.AttemptTimeline
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/timeline [get]
+// @Security CookieAuth
+func (h *Handler) GetAttemptTimeline(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	timeline, err := h.Store.AttemptTimeline(attemptID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, timeline)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAttemptAITranscript возвращает диалоги студента с ассистентом для проверки попытки
+// @Summary Attempt AI transcript
+// @Description Returns the AI conversation for each question of the attempt, including messages blocked by moderation or refused by the guard, so graders can judge how much help the student received.
+// @Description Use question_position to get the transcript of a single question, e.g. for a review queue item
+// @Tags review
+// @Produce json
+// @Param attempt_id path int true "Attempt ID"
+// @Param question_position query int false "Only this question"
+// @Success 200 {object} store.AttemptTranscript
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /attempt/{attempt_id}/ai-transcript [get]
+// @Security CookieAuth
+func (h *Handler) GetAttemptAITranscript(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid attempt_id")
+		return
+	}
+
+	transcript, err := h.Store.AttemptAITranscript(attemptID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	if v := r.URL.Query().Get("question_position"); v != "" {
+		pos, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || pos == 0 || pos > uint64(len(transcript.Questions)) {
+			writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid question_position")
+			return
+		}
+		transcript.Questions = []*store.QuestionTranscript{transcript.Questions[pos-1]}
+	}
+
+	apiutils.WriteJSON(w, http.StatusOK, transcript)
+}
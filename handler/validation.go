@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"net/http"
+)
+
+// decodeRequest строго читает JSON-тело в dst и проверяет его по тегам validate.
+// При ошибке отвечает 400 (invalid_json или validation_failed со всеми полями) или 413 и возвращает false
+func decodeRequest(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if err := apiutils.DecodeJSON(r, dst); err != nil {
+		apiutils.WriteDecodeError(w, err)
+		return false
+	}
+	return validateRequest(w, dst)
+}
+
+// validateRequest проверяет уже прочитанный запрос, например когда тело необязательно
+func validateRequest(w http.ResponseWriter, request any) bool {
+	fields := apiutils.Validate(request)
+	if len(fields) == 0 {
+		return true
+	}
+	writeErrorDetails(w, http.StatusBadRequest, apiutils.CodeValidationFailed, "validation failed", apiutils.ValidationDetails{
+		Fields: fields,
+	})
+	return false
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Изменения тестов и вопросов защищены оптимистичной блокировкой: клиент передает в If-Match
+// версию ресурса, которую видел (поле version, она же ETag ответа), и получает 409 version_conflict,
+// если ресурс с тех пор изменили. If-Match: * изменяет без проверки
+
+// versionConflictDetails - подробности ошибки version_conflict
+type versionConflictDetails struct {
+	CurrentVersion uint64 `json:"current_version"`
+}
+
+// ifMatchVersion читает ожидаемую версию ресурса из If-Match: "3" или 3, * - без проверки (0).
+// Без заголовка отвечает 428, с неразборчивым - 400 и возвращает false
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		writeError(w, http.StatusPreconditionRequired, codeVersionRequired, "If-Match header with the expected version is required")
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version == 0 {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "If-Match must contain the resource version")
+		return 0, false
+	}
+	return version, true
+}
+
+// setVersionETag выставляет версию ресурса в ETag ответа, чтобы ее можно было вернуть в If-Match
+func setVersionETag(w http.ResponseWriter, version uint64) {
+	w.Header().Set("ETag", `"`+strconv.FormatUint(version, 10)+`"`)
+}
+
+// writeUpdateErr пишет ошибку изменения ресурса: конфликт версий - 409 с текущей версией,
+// остальные ошибки - как writeErr
+func writeUpdateErr(w http.ResponseWriter, status int, err error) {
+	var conflict *store.VersionConflictError
+	if errors.As(err, &conflict) {
+		writeErrorDetails(w, http.StatusConflict, codeVersionConflict, err.Error(), versionConflictDetails{CurrentVersion: conflict.Current})
+		return
+	}
+	writeErr(w, status, err)
+}
+
+// ListTestQuestions возвращает вопросы теста с версиями
+// @Summary List test questions
+// @Description Returns active questions of the test with accepted answers and versions. Pass the version in If-Match when updating a question
+// @Tags tests
+// @Produce json
+// @Param test_id path int true "Test ID"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, name or version, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Question]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/questions [get]
+// @Security CookieAuth
+func (h *Handler) ListTestQuestions(w http.ResponseWriter, r *http.Request) {
+	testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid test_id")
+		return
+	}
+
+	questions, err := h.Store.ListQuestions(testID)
+	if err != nil {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeList(w, r, questions, questionSorts)
+}
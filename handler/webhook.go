@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"GEEK_back/apiutils"
+	mw "GEEK_back/middleware"
+	"GEEK_back/store"
+	"GEEK_back/webhook"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+type createWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Secret string   `json:"secret" validate:"omitempty,min=16"` // пустой = сгенерировать
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=user.registered attempt.submitted"`
+}
+
+// createWebhookResponse - созданный вебхук с секретом. Секрет возвращается только здесь
+type createWebhookResponse struct {
+	*store.Webhook
+	Secret string `json:"secret"`
+}
+
+// webhookEnvelope - тело вебхука. ID одинаков у всех доставок события и помогает получателю отбросить повтор
+type webhookEnvelope struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// userRegisteredWebhook - данные события user.registered
+type userRegisteredWebhook struct {
+	UserID    uint64    `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// attemptSubmittedWebhook - данные события attempt.submitted
+type attemptSubmittedWebhook struct {
+	*store.AttemptGrade
+	UserIDs []uint64 `json:"user_ids"` // студент или вся команда
+	Auto    bool     `json:"auto"`     // сдана автоматически по истечении времени
+}
+
+// writeWebhookError отвечает 404 на отсутствующий вебхук и 400 на остальные ошибки
+func writeWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrWebhookNotFound) {
+		writeErr(w, http.StatusNotFound, err)
+		return
+	}
+	writeErr(w, http.StatusBadRequest, err)
+}
+
+// newWebhookDispatcher создает диспетчер, который записывает каждую попытку доставки в журнал
+func newWebhookDispatcher(s *store.Store) *webhook.Dispatcher {
+	return webhook.New(webhook.DefaultWorkers, webhook.DefaultQueueSize, func(d *webhook.Delivery, r webhook.Result) {
+		var next *time.Time
+		if !r.Final {
+			next = &r.NextRetry
+		}
+		s.RecordWebhookAttempt(d.ID, r.Attempt, r.StatusCode, r.Err, next)
+
+		if r.Err != nil && r.Final {
+			log.Warn().Err(r.Err).Uint64("delivery_id", d.ID).Str("event", d.Event).Str("url", d.URL).Msg("webhook delivery failed")
+		}
+	})
+}
+
+// emitWebhook отправляет событие всем вебхукам, подписанным на него
+func (h *Handler) emitWebhook(event string, data any) {
+	envelope := webhookEnvelope{
+		ID:        uuid.NewString(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		log.Error().Err(err).Str("event", event).Msg("failed to encode webhook event")
+		return
+	}
+
+	for _, target := range h.Store.AddWebhookDeliveries(event, envelope.ID, body) {
+		delivery := &webhook.Delivery{
+			ID:     target.Delivery.ID,
+			URL:    target.URL,
+			Secret: target.Secret,
+			Event:  event,
+			Body:   body,
+		}
+		if err := h.Webhooks.Enqueue(delivery); err != nil {
+			h.Store.RecordWebhookAttempt(delivery.ID, 0, 0, err, nil)
+			log.Warn().Err(err).Uint64("delivery_id", delivery.ID).Str("event", event).Msg("webhook delivery dropped")
+		}
+	}
+}
+
+// emitUserRegistered сообщает внешним системам о новом пользователе
+func (h *Handler) emitUserRegistered(user *store.User) {
+	h.emitWebhook(webhook.EventUserRegistered, userRegisteredWebhook{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+	})
+}
+
+// emitAttemptSubmitted сообщает внешним системам о сданной попытке
+func (h *Handler) emitAttemptSubmitted(attemptID uint64, auto bool) {
+	grade, ok := h.Store.SubmittedAttempt(attemptID)
+	if !ok {
+		return
+	}
+	h.emitWebhook(webhook.EventAttemptSubmitted, attemptSubmittedWebhook{
+		AttemptGrade: grade,
+		UserIDs:      grade.UserIDs,
+		Auto:         auto,
+	})
+}
+
+// CreateWebhook регистрирует вебхук
+// @Summary Create webhook
+// @Description Registers a URL that receives signed POST requests on events: user.registered, attempt.submitted.
+// @Description The body is {"id","event","created_at","data"}; the id is the same for every delivery of an event and can be used to drop duplicates.
+// @Description X-Webhook-Signature is "sha256=" + hex HMAC-SHA256 of the raw body with the secret; X-Webhook-Event and X-Webhook-Delivery are also set.
+// @Description Network errors, 5xx, 408 and 429 are retried up to 5 times with growing delays; other responses are final.
+// @Description If no secret is given, one is generated. The secret is returned only in this response
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param webhook body createWebhookRequest true "Webhook"
+// @Success 201 {object} createWebhookResponse
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/webhooks [post]
+// @Security CookieAuth
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mw.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid user_id")
+		return
+	}
+
+	var request createWebhookRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		secret = rand.Text()
+	}
+
+	hook := h.Store.CreateWebhook(request.URL, secret, request.Events, userID)
+	apiutils.WriteJSON(w, http.StatusCreated, createWebhookResponse{Webhook: hook, Secret: secret})
+}
+
+// ListWebhooks возвращает зарегистрированные вебхуки
+// @Summary List webhooks
+// @Tags admin
+// @Produce json
+// @Param event query string false "Subscribed to the event"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.Webhook]
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Router /admin/webhooks [get]
+// @Security CookieAuth
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	event := apiutils.NewFilters(r).String("event")
+	webhooks := filterList(h.Store.ListWebhooks(), func(hook *store.Webhook) bool {
+		return event == "" || slices.Contains(hook.Events, event)
+	})
+	writeList(w, r, webhooks, webhookSorts)
+}
+
+// DeleteWebhook удаляет вебхук
+// @Summary Delete webhook
+// @Description Deletes the webhook and its delivery log. Deliveries already in progress are completed
+// @Tags admin
+// @Param webhook_id path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /admin/webhooks/{webhook_id} [delete]
+// @Security CookieAuth
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhook_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid webhook_id")
+		return
+	}
+
+	if err := h.Store.DeleteWebhook(webhookID); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries возвращает журнал доставок вебхука
+// @Summary Webhook delivery log
+// @Description Recent deliveries of the webhook (up to 200) with the payload, number of attempts, last response status and error
+// @Tags admin
+// @Produce json
+// @Param webhook_id path int true "Webhook ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param event query string false "Event"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id or created_at, prefix - for descending"
+// @Success 200 {object} apiutils.Page[store.WebhookDelivery]
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /admin/webhooks/{webhook_id}/deliveries [get]
+// @Security CookieAuth
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhook_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, "invalid webhook_id")
+		return
+	}
+
+	deliveries, err := h.Store.ListWebhookDeliveries(webhookID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	filters := apiutils.NewFilters(r)
+	status := filters.OneOf("status", store.WebhookDeliveryPending, store.WebhookDeliveryDelivered, store.WebhookDeliveryFailed)
+	event := filters.String("event")
+	if err := filters.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, apiutils.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	deliveries = filterList(deliveries, func(d *store.WebhookDelivery) bool {
+		return (status == "" || d.Status == status) && (event == "" || d.Event == event)
+	})
+	writeList(w, r, deliveries, webhookDeliverySorts)
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"GEEK_back/store"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxContentType - MIME-тип книги Excel
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Листы книги с результатами
+const (
+	xlsxSummarySheet   = "Summary"
+	xlsxQuestionsSheet = "Questions"
+)
+
+// ExportResultsXLSX выгружает результаты теста книгой Excel: многие учебные заведения принимают только .xlsx
+// @Summary Export results as an Excel workbook
+// @Description Returns an .xlsx workbook with two sheets. "Summary" starts with the test statistics (see /tests/{test_id}/stats)
+// @Description followed by a table of graded attempts, oldest first: attempt and user, timestamps in UTC, working time in minutes
+// @Description without auto-pause, late penalty, assistant cost, result and percent of the max score.
+// @Description "Questions" has a row per question that was in at least one graded attempt: how many attempts had it,
+// @Description how many answered it correctly and the share of correct answers
+// @Tags tests
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param test_id path int true "Test ID"
+// @Param pass_percent query int false "Pass score as percent of the max score, 0-100" default(60)
+// @Success 200 {file} binary
+// @Failure 400 {object} apiutils.ErrorResponse
+// @Failure 403 {object} apiutils.ErrorResponse
+// @Failure 404 {object} apiutils.ErrorResponse
+// @Router /tests/{test_id}/results.xlsx [get]
+// @Security CookieAuth
+func (h *Handler) ExportResultsXLSX(w http.ResponseWriter, r *http.Request) {
+	passPercent, ok := readPassPercent(w, r)
+	if !ok {
+		return
+	}
+	export, ok := h.exportResults(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.Store.TestStats(export.TestID, passPercent)
+	switch {
+	case errors.Is(err, store.ErrTestNotFound):
+		// Тест удалили между запросами к хранилищу
+		writeErr(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	book, err := resultsWorkbook(export, stats)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer book.Close()
+
+	w.Header().Set("Content-Type", xlsxContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exportFilename(export, "xlsx")}))
+	w.WriteHeader(http.StatusOK)
+	if err := book.Write(w); err != nil {
+		// Заголовки уже отправлены, остается только записать в журнал
+		zerolog.Ctx(r.Context()).Warn().Err(err).Uint64("test_id", export.TestID).Msg("results export interrupted")
+	}
+}
+
+// resultsWorkbook собирает книгу с листами Summary и Questions
+func resultsWorkbook(export *store.ResultsExport, stats *store.TestStats) (*excelize.File, error) {
+	book := excelize.NewFile()
+	if err := book.SetSheetName("Sheet1", xlsxSummarySheet); err != nil {
+		book.Close()
+		return nil, err
+	}
+	if _, err := book.NewSheet(xlsxQuestionsSheet); err != nil {
+		book.Close()
+		return nil, err
+	}
+
+	bold, err := book.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		book.Close()
+		return nil, err
+	}
+	styles := xlsxStyles{bold: bold}
+	for _, format := range []struct {
+		id  *int
+		fmt string
+	}{
+		{&styles.date, "yyyy-mm-dd hh:mm:ss"},
+		{&styles.decimal, "0.0"},
+		{&styles.percent, "0.0%"},
+	} {
+		if *format.id, err = book.NewStyle(&excelize.Style{CustomNumFmt: &format.fmt}); err != nil {
+			book.Close()
+			return nil, err
+		}
+	}
+
+	if err := writeSummarySheet(book, styles, export, stats); err != nil {
+		book.Close()
+		return nil, err
+	}
+	if err := writeQuestionsSheet(book, styles, export); err != nil {
+		book.Close()
+		return nil, err
+	}
+	return book, nil
+}
+
+// xlsxStyles - ID стилей ячеек книги
+type xlsxStyles struct {
+	bold, date, decimal, percent int
+}
+
+// writeSummarySheet пишет сводку по тесту и таблицу попыток под ней
+func writeSummarySheet(book *excelize.File, styles xlsxStyles, export *store.ResultsExport, stats *store.TestStats) error {
+	sheet := xlsxSummarySheet
+	// Пустая ячейка, если значения нет: например, средний балл без оцененных попыток
+	optional := func(v *float64) any {
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	var avgMinutes any
+	if stats.AvgDurationSeconds != nil {
+		avgMinutes = *stats.AvgDurationSeconds / 60
+	}
+	summary := []struct {
+		name  string
+		value any
+		style int
+	}{
+		{"Test", spreadsheetSafe(export.TestName), 0},
+		{"Test ID", export.TestID, 0},
+		{"Max score", stats.MaxScore, 0},
+		{"Students", stats.Students, 0},
+		{"Attempts started", stats.AttemptsStarted, 0},
+		{"Attempts in progress", stats.AttemptsInProgress, 0},
+		{"Attempts awaiting grading", stats.AttemptsGrading, 0},
+		{"Attempts graded", stats.AttemptsSubmitted, 0},
+		{"Average score", optional(stats.AvgScore), styles.decimal},
+		{"Median score", optional(stats.MedianScore), styles.decimal},
+		{"Pass score", stats.PassScore, 0},
+		{"Pass rate", optional(stats.PassRate), styles.percent},
+		{"Average time, minutes", avgMinutes, styles.decimal},
+	}
+	for i, item := range summary {
+		row := i + 1
+		if err := book.SetSheetRow(sheet, cell(1, row), &[]any{item.name, item.value}); err != nil {
+			return err
+		}
+		if item.style != 0 {
+			if err := book.SetCellStyle(sheet, cell(2, row), cell(2, row), item.style); err != nil {
+				return err
+			}
+		}
+	}
+	if err := book.SetCellStyle(sheet, "A1", cell(1, len(summary)), styles.bold); err != nil {
+		return err
+	}
+
+	// Таблица попыток отделена от сводки пустой строкой
+	top := len(summary) + 2
+	header := []any{"Attempt ID", "User ID", "Email", "Started (UTC)", "Finished (UTC)", "Minutes",
+		"Late", "Penalty", "AI cost", "Result", "Percent"}
+	if err := book.SetSheetRow(sheet, cell(1, top), &header); err != nil {
+		return err
+	}
+	if err := book.SetCellStyle(sheet, cell(1, top), cell(len(header), top), styles.bold); err != nil {
+		return err
+	}
+	for i, row := range export.Rows {
+		var percent any
+		if export.MaxScore > 0 {
+			percent = float64(row.Result) / float64(export.MaxScore)
+		}
+		record := []any{row.AttemptID, row.UserID, spreadsheetSafe(row.Email), row.StartedAt.UTC(), row.FinishedAt.UTC(),
+			row.Duration.Minutes(), row.Late, row.Penalty, row.AICost, row.Result, percent}
+		if err := book.SetSheetRow(sheet, cell(1, top+i+1), &record); err != nil {
+			return err
+		}
+	}
+
+	if last := top + len(export.Rows); len(export.Rows) > 0 {
+		for _, column := range []struct {
+			col   int
+			style int
+		}{{4, styles.date}, {5, styles.date}, {6, styles.decimal}, {11, styles.percent}} {
+			if err := book.SetCellStyle(sheet, cell(column.col, top+1), cell(column.col, last), column.style); err != nil {
+				return err
+			}
+		}
+		if err := book.AutoFilter(sheet, cell(1, top)+":"+cell(len(header), last), nil); err != nil {
+			return err
+		}
+	}
+
+	// Первый столбец шире: в нем и подписи сводки, и ID попыток
+	if err := book.SetColWidth(sheet, "A", "A", 26); err != nil {
+		return err
+	}
+	if err := book.SetColWidth(sheet, "B", "B", 14); err != nil {
+		return err
+	}
+	if err := book.SetColWidth(sheet, "C", "C", 30); err != nil {
+		return err
+	}
+	return book.SetColWidth(sheet, "D", "E", 20)
+}
+
+// writeQuestionsSheet пишет статистику ответов по вопросам
+func writeQuestionsSheet(book *excelize.File, styles xlsxStyles, export *store.ResultsExport) error {
+	sheet := xlsxQuestionsSheet
+	header := []any{"Question ID", "Question", "Max score", "Attempts", "Correct", "Correct rate"}
+	if err := book.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	if err := book.SetCellStyle(sheet, "A1", cell(len(header), 1), styles.bold); err != nil {
+		return err
+	}
+
+	for i, question := range export.Questions {
+		asked, correct := 0, 0
+		for _, row := range export.Rows {
+			if right, ok := row.Correct[question.ID]; ok {
+				asked++
+				if right {
+					correct++
+				}
+			}
+		}
+		var rate any
+		if asked > 0 {
+			rate = float64(correct) / float64(asked)
+		}
+		record := []any{question.ID, spreadsheetSafe(question.Name), question.MaxScore, asked, correct, rate}
+		if err := book.SetSheetRow(sheet, cell(1, i+2), &record); err != nil {
+			return err
+		}
+	}
+	if len(export.Questions) > 0 {
+		last := len(export.Questions) + 1
+		if err := book.SetCellStyle(sheet, cell(6, 2), cell(6, last), styles.percent); err != nil {
+			return err
+		}
+	}
+
+	if err := book.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	if err := book.SetColWidth(sheet, "A", "A", 12); err != nil {
+		return err
+	}
+	return book.SetColWidth(sheet, "B", "B", 40)
+}
+
+// cell возвращает адрес ячейки по номерам столбца и строки, начиная с 1
+func cell(col, row int) string {
+	name, _ := excelize.CoordinatesToCellName(col, row)
+	return name
+}
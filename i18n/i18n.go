@@ -0,0 +1,203 @@
+// Package i18n переводит тексты для пользователя: сообщения об ошибках API и письма.
+// Исходные тексты в коде пишутся по-английски и служат ключами каталогов locales/<язык>.json,
+// поэтому места, где ошибка возникает, ничего не знают о языке: перевод выбирается при ответе
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/text/language"
+)
+
+// Поддерживаемые языки
+const (
+	English = "en"
+	Russian = "ru"
+)
+
+// Languages - поддерживаемые языки, первый - язык исходных текстов
+var Languages = []string{English, Russian}
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// catalog - переводы одного языка. Messages переводит исходный текст целиком; ключ может содержать %s,
+// тогда подставленное значение переносится в перевод. Codes - общий текст для кода ошибки,
+// если у сообщения нет перевода (например, оно собрано из нескольких ошибок)
+type catalog struct {
+	Messages map[string]string `json:"messages"`
+	Codes    map[string]string `json:"codes"`
+
+	patterns []pattern
+}
+
+// pattern - ключ каталога с %s, разобранный в регулярное выражение
+type pattern struct {
+	re          *regexp.Regexp
+	translation string
+	literal     int // длина ключа без %s: более конкретные шаблоны проверяются первыми
+}
+
+// catalogs загружает встроенные каталоги при первом обращении
+var catalogs = sync.OnceValue(func() map[string]*catalog {
+	result := make(map[string]*catalog, len(Languages))
+	for _, lang := range Languages {
+		c, err := loadCatalog(lang)
+		if err != nil {
+			// Каталоги встроены в бинарник, ошибка в них - ошибка сборки
+			panic(err)
+		}
+		result[lang] = c
+	}
+	return result
+})
+
+func loadCatalog(lang string) (*catalog, error) {
+	data, err := localesFS.ReadFile(path.Join("locales", lang+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("i18n: %w", err)
+	}
+	c := &catalog{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("i18n: locales/%s.json: %w", lang, err)
+	}
+
+	for key, translation := range c.Messages {
+		if !strings.Contains(key, "%s") {
+			continue
+		}
+		if strings.Count(key, "%s") != strings.Count(translation, "%s") {
+			return nil, fmt.Errorf("i18n: locales/%s.json: %q: translation must keep every %%s", lang, key)
+		}
+		if strings.Trim(strings.ReplaceAll(key, "%s", ""), " ") == "" {
+			return nil, fmt.Errorf("i18n: locales/%s.json: %q: key must contain text besides %%s", lang, key)
+		}
+		parts := strings.Split(key, "%s")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		c.patterns = append(c.patterns, pattern{
+			re:          regexp.MustCompile("^" + strings.Join(parts, "(.+?)") + "$"),
+			translation: translation,
+			literal:     len(key) - 2*strings.Count(key, "%s"),
+		})
+	}
+	slices.SortFunc(c.patterns, func(a, b pattern) int {
+		if a.literal != b.literal {
+			return b.literal - a.literal
+		}
+		return strings.Compare(a.re.String(), b.re.String())
+	})
+
+	return c, nil
+}
+
+// Default возвращает язык для запросов без подходящего Accept-Language: DEFAULT_LANGUAGE или английский
+var Default = sync.OnceValue(func() string {
+	lang := os.Getenv("DEFAULT_LANGUAGE")
+	if lang == "" {
+		return English
+	}
+	if !slices.Contains(Languages, lang) {
+		log.Warn().Str("value", lang).Strs("supported", Languages).Msg("unsupported DEFAULT_LANGUAGE, using en")
+		return English
+	}
+	return lang
+})
+
+// languageMatcher сопоставляет языки клиента с поддерживаемыми. Язык по умолчанию идет первым:
+// его matcher выбирает, когда ничего не подошло
+type languageMatcher struct {
+	matcher   language.Matcher
+	languages []string
+}
+
+var matcher = sync.OnceValue(func() *languageMatcher {
+	languages := []string{Default()}
+	for _, lang := range Languages {
+		if lang != Default() {
+			languages = append(languages, lang)
+		}
+	}
+	tags := make([]language.Tag, len(languages))
+	for i, lang := range languages {
+		tags[i] = language.Make(lang)
+	}
+	return &languageMatcher{matcher: language.NewMatcher(tags), languages: languages}
+})
+
+// Match выбирает язык ответа по заголовку Accept-Language. Пустой или неразборчивый заголовок дает Default
+func Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default()
+	}
+	m := matcher()
+	_, index, confidence := m.matcher.Match(tags...)
+	if confidence == language.No {
+		return Default()
+	}
+	return m.languages[index]
+}
+
+// Supported нормализует язык: неподдерживаемый и пустой заменяются языком по умолчанию
+func Supported(lang string) string {
+	if slices.Contains(Languages, lang) {
+		return lang
+	}
+	return Default()
+}
+
+// Translate переводит исходный текст message на язык lang. Текст без перевода возвращается как есть
+func Translate(lang, message string) string {
+	translated, _ := lookup(lang, message)
+	return translated
+}
+
+// Error переводит сообщение об ошибке с кодом code. Если у самого текста перевода нет,
+// возвращается общий текст кода на языке lang, чтобы в ответе не смешивались языки
+func Error(lang, code, message string) string {
+	if translated, ok := lookup(lang, message); ok {
+		return translated
+	}
+	if text, ok := catalogs()[Supported(lang)].Codes[code]; ok {
+		return text
+	}
+	return message
+}
+
+// lookup ищет перевод message: сначала точное совпадение, затем шаблоны с %s. Для английского,
+// языка исходных текстов, перевод - сам текст, если каталог его не переопределяет
+func lookup(lang, message string) (string, bool) {
+	lang = Supported(lang)
+	c := catalogs()[lang]
+	if translated, ok := c.Messages[message]; ok {
+		return translated, true
+	}
+	for _, p := range c.patterns {
+		match := p.re.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		// Подставленные значения тоже переводятся, если могут: так обернутая ошибка
+		// вида "import snapshot: test not found" переводится целиком
+		translated := p.translation
+		for _, value := range match[1:] {
+			if inner, ok := lookup(lang, value); ok {
+				value = inner
+			}
+			translated = strings.Replace(translated, "%s", value, 1)
+		}
+		return translated, true
+	}
+	return message, lang == English && message != ""
+}
@@ -0,0 +1,75 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// bucketSweepInterval - как часто удаляются корзины давно не появлявшихся ключей
+const bucketSweepInterval = time.Minute
+
+// BucketLimiter - token bucket по ключу: корзина на burst токенов пополняется со скоростью
+// rate токенов в секунду, каждый запрос забирает один токен. Короткие всплески до burst
+// проходят, длительный поток ограничивается скоростью rate
+type BucketLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewBucketLimiter(rate float64, burst int) *BucketLimiter {
+	return &BucketLimiter{
+		rate:      rate,
+		burst:     float64(max(burst, 1)),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow забирает токен из корзины ключа. Если корзина пуста, возвращает false
+// и время, через которое появится следующий токен
+func (l *BucketLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep удаляет корзины, которые успели наполниться до краев: они ничем не отличаются от новых.
+// Вызывается под блокировкой
+func (l *BucketLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
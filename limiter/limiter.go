@@ -0,0 +1,98 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// FailureLimiter считает неудачные попытки по ключу (IP, пользователь) в скользящем окне
+// и блокирует ключ, когда их становится больше лимита
+type FailureLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	failures map[string][]time.Time
+}
+
+func NewFailureLimiter(limit int, window time.Duration) *FailureLimiter {
+	return &FailureLimiter{
+		limit:    limit,
+		window:   window,
+		failures: make(map[string][]time.Time),
+	}
+}
+
+// Blocked возвращает true и время до разблокировки, если для ключа превышен лимит неудач
+func (l *FailureLimiter) Blocked(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	recent := l.prune(key, now)
+	if len(recent) < l.limit {
+		return false, 0
+	}
+
+	// Ключ разблокируется, когда самая старая неудача в окне выйдет за его пределы
+	return true, recent[len(recent)-l.limit].Add(l.window).Sub(now)
+}
+
+// Fail регистрирует неудачную попытку для ключа
+func (l *FailureLimiter) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.failures[key] = append(l.prune(key, now), now)
+}
+
+// Reset сбрасывает счетчик неудач ключа
+func (l *FailureLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
+
+// prune удаляет неудачи вне окна, вызывается под блокировкой
+func (l *FailureLimiter) prune(key string, now time.Time) []time.Time {
+	recent := l.failures[key]
+	i := 0
+	for i < len(recent) && now.Sub(recent[i]) >= l.window {
+		i++
+	}
+	recent = recent[i:]
+
+	if len(recent) == 0 {
+		delete(l.failures, key)
+		return nil
+	}
+	l.failures[key] = recent
+
+	return recent
+}
+
+// RateLimiter пропускает не больше limit событий по ключу в скользящем окне
+type RateLimiter struct {
+	events *FailureLimiter
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{events: NewFailureLimiter(limit, window)}
+}
+
+// Allow регистрирует событие для ключа, если лимит не исчерпан. Иначе возвращает false
+// и время, через которое можно повторить
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.events.mu.Lock()
+	defer l.events.mu.Unlock()
+
+	now := time.Now()
+	recent := l.events.prune(key, now)
+	if len(recent) >= l.events.limit {
+		return false, recent[len(recent)-l.events.limit].Add(l.events.window).Sub(now)
+	}
+
+	l.events.failures[key] = append(recent, now)
+	return true, 0
+}
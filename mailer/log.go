@@ -0,0 +1,21 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LogSender не отправляет письма, а пишет их тему и текст в журнал. Для разработки без почтового сервера
+type LogSender struct{}
+
+// Name возвращает имя способа доставки
+func (LogSender) Name() string {
+	return "log"
+}
+
+// Send пишет письмо в журнал
+func (LogSender) Send(ctx context.Context, msg *Message) error {
+	log.Info().Int("recipients", len(msg.To)).Str("subject", msg.Subject).Str("text", msg.Text).Msg("email")
+	return nil
+}
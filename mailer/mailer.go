@@ -0,0 +1,156 @@
+package mailer
+
+import (
+	"GEEK_back/metrics"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Настройки очереди писем по умолчанию
+const (
+	DefaultWorkers   = 2
+	DefaultQueueSize = 256
+
+	sendTimeout  = 30 * time.Second
+	sendAttempts = 5
+	sendBackoff  = 5 * time.Second
+)
+
+var (
+	// ErrQueueFull возвращается, если очередь писем переполнена
+	ErrQueueFull = errors.New("mail queue is full")
+	// ErrClosed возвращается при постановке письма в очередь после Close
+	ErrClosed = errors.New("mailer is closed")
+	// ErrRejected оборачивает постоянные отказы провайдера (несуществующий адрес, запрет отправки):
+	// такие письма не отправляются повторно
+	ErrRejected = errors.New("message rejected")
+)
+
+// Message - письмо. Text - текстовая версия для почтовых клиентов без HTML
+type Message struct {
+	To      []string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender - способ доставки писем: SMTP, HTTP API почтового сервиса или журнал для разработки
+type Sender interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Mailer отправляет письма через Sender в фоне: Enqueue ставит письмо в очередь, пул воркеров
+// доставляет его, повторяя попытки при временных сбоях с растущей паузой
+type Mailer struct {
+	sender    Sender
+	templates *Templates
+
+	mu     sync.RWMutex
+	queue  chan *Message
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New создает почтовую службу с workers воркерами и очередью на queueSize писем
+func New(sender Sender, workers, queueSize int) (*Mailer, error) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mailer{
+		sender:    sender,
+		templates: templates,
+		queue:     make(chan *Message, max(queueSize, 1)),
+	}
+	for range max(workers, 1) {
+		m.wg.Add(1)
+		go m.worker()
+	}
+
+	return m, nil
+}
+
+// Name возвращает имя способа доставки
+func (m *Mailer) Name() string {
+	return m.sender.Name()
+}
+
+// Send отправляет письмо сразу, без очереди и повторов
+func (m *Mailer) Send(ctx context.Context, msg *Message) error {
+	err := m.sender.Send(ctx, msg)
+	metrics.ObserveEmail(m.sender.Name(), err)
+	return err
+}
+
+// Enqueue ставит письмо в очередь на отправку. Не блокируется: при переполненной очереди возвращает ErrQueueFull
+func (m *Mailer) Enqueue(msg *Message) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	select {
+	case m.queue <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// EnqueueTemplate собирает письмо по шаблону name на языке lang с данными data и ставит его в очередь
+func (m *Mailer) EnqueueTemplate(to []string, name, lang string, data any) error {
+	msg, err := m.templates.Render(name, lang, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	return m.Enqueue(msg)
+}
+
+// Close перестает принимать письма и ждет, пока воркеры отправят уже поставленные в очередь
+func (m *Mailer) Close() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+func (m *Mailer) worker() {
+	defer m.wg.Done()
+
+	for msg := range m.queue {
+		m.deliver(msg)
+	}
+}
+
+// deliver отправляет письмо, повторяя попытку при временной ошибке
+func (m *Mailer) deliver(msg *Message) {
+	backoff := sendBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err = m.Send(ctx, msg)
+		cancel()
+
+		if err == nil || errors.Is(err, ErrRejected) || attempt == sendAttempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	if err != nil {
+		log.Warn().Err(err).Str("provider", m.sender.Name()).Int("recipients", len(msg.To)).Str("subject", msg.Subject).Msg("email delivery failed")
+	}
+}
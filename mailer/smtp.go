@@ -0,0 +1,194 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Режимы шифрования соединения с SMTP-сервером
+const (
+	SMTPStartTLS = "starttls" // STARTTLS после подключения, обычно порт 587
+	SMTPImplicit = "implicit" // TLS сразу при подключении, обычно порт 465
+	SMTPNone     = "none"     // без шифрования, только для локального релея
+)
+
+// SMTPSender отправляет письма через SMTP-сервер
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string // пусто = без авторизации
+	Password string
+	From     string // адрес отправителя, можно с именем: "GEEK <noreply@example.com>"
+	TLS      string // SMTPStartTLS (по умолчанию), SMTPImplicit или SMTPNone
+}
+
+// Name возвращает имя способа доставки
+func (s *SMTPSender) Name() string {
+	return "smtp"
+}
+
+// Send отправляет письмо за одно SMTP-соединение. Отказы сервера с кодом 5xx
+// оборачиваются в ErrRejected: повтор их не исправит
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrRejected)
+	}
+	recipients := make([]string, len(msg.To))
+	for i, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("%w: invalid recipient %q", ErrRejected, to)
+		}
+		recipients[i] = addr.Address
+	}
+
+	body, err := s.build(from, msg)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.TLS == "" || s.TLS == SMTPStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return smtpError(err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return smtpError(err)
+	}
+	for _, to := range recipients {
+		if err := client.Rcpt(to); err != nil {
+			return smtpError(err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(err)
+	}
+
+	return client.Quit()
+}
+
+// dial подключается к серверу, при SMTPImplicit - сразу по TLS
+func (s *SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.Host, s.Port)
+	if s.TLS == SMTPImplicit {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: s.Host}}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// build собирает письмо в формате MIME: multipart/alternative с текстовой и HTML-версией
+func (s *SMTPSender) build(from *mail.Address, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	var out bytes.Buffer
+	for _, field := range [][2]string{
+		{"From", from.String()},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(msg.Subject), " "))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(from.Address)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + parts.Boundary()},
+	} {
+		out.WriteString(field[0] + ": " + field[1] + "\r\n")
+	}
+	out.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	out.Write(buf.Bytes())
+	return out.Bytes(), nil
+}
+
+// messageID генерирует Message-ID в домене отправителя
+func messageID(from string) string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok {
+		domain = d
+	}
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
+
+// smtpError помечает постоянные отказы сервера (коды 5xx)
+func smtpError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"GEEK_back/i18n"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Templates - шаблоны писем на поддерживаемых языках. Письмо name на языке lang состоит из
+// templates/lang/name.txt (тема в блоке subject и текстовая версия) и templates/lang/name.html
+// (блок body, который вставляется в templates/lang/layout.html)
+type Templates struct {
+	html map[string]*htmltemplate.Template // ключ - lang/name
+	text map[string]*texttemplate.Template
+}
+
+// LoadTemplates разбирает встроенные шаблоны писем. Каждое письмо должно быть переведено на все языки i18n
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+	names := make(map[string]bool)
+	for _, lang := range i18n.Languages {
+		files, err := fs.Glob(templateFS, "templates/"+lang+"/*.txt")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".txt")
+			names[name] = true
+
+			text, err := texttemplate.New(path.Base(file)).Option("missingkey=error").ParseFS(templateFS, file)
+			if err != nil {
+				return nil, fmt.Errorf("email template %s/%s: %w", lang, name, err)
+			}
+			html, err := htmltemplate.New("layout.html").Option("missingkey=error").
+				ParseFS(templateFS, "templates/"+lang+"/layout.html", "templates/"+lang+"/"+name+".html")
+			if err != nil {
+				return nil, fmt.Errorf("email template %s/%s: %w", lang, name, err)
+			}
+
+			t.text[lang+"/"+name] = text
+			t.html[lang+"/"+name] = html
+		}
+	}
+
+	for name := range names {
+		for _, lang := range i18n.Languages {
+			if _, ok := t.text[lang+"/"+name]; !ok {
+				return nil, fmt.Errorf("email template %s/%s not found", lang, name)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// Render собирает письмо по шаблону name на языке lang (неподдерживаемый заменяется языком по умолчанию)
+// с данными data. Получателей заполняет вызывающий
+func (t *Templates) Render(name, lang string, data any) (*Message, error) {
+	key := i18n.Supported(lang) + "/" + name
+	text, ok := t.text[key]
+	if !ok {
+		return nil, fmt.Errorf("email template %s not found", name)
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("email template %s: %w", name, err)
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("email template %s: %w", name, err)
+	}
+	if err := t.html[key].Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("email template %s: %w", name, err)
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
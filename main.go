@@ -1,22 +1,48 @@
 package main
 
 import (
+	"GEEK_back/client/anthropic"
+	"GEEK_back/client/fake"
+	"GEEK_back/client/llm"
 	"GEEK_back/client/openAI"
 	_ "GEEK_back/docs"
+	"GEEK_back/frontend"
+	"GEEK_back/handler"
+	"GEEK_back/mailer"
+	"GEEK_back/metrics"
+	"GEEK_back/reporting"
 	"GEEK_back/router"
+	"GEEK_back/storage"
 	"GEEK_back/store"
+	"GEEK_back/tracing"
+	"context"
+	"crypto/rand"
 	"errors"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/acme/autocert"
+	"google.golang.org/grpc"
 )
 
 const localhost = "localhost"
 const host = "0.0.0.0"
 const port = "8080"
 
+// Спецификация API в docs собирается из аннотаций обработчиков, после их изменения нужен go generate
+//
+//go:generate swag init --parseDependency --parseInternal --templateDelims "[[,]]" -g main.go -o docs
+
 // @title GEEK API
 // @version 1.0
 // @description API for web-site GEEK
@@ -30,35 +56,581 @@ func main() {
 		log.Fatal().Err(err).Msg("Error loading .env file")
 
 	}
+	configureLogging()
 
 	s := store.NewStore()
 
-	if err := s.InitFillStore(); err != nil {
-		log.Fatal().Err(err).Msg("failed to init store")
+	// SEED_FIXTURE - файл с начальными пользователями, тестами и кодами доступа (JSON или YAML),
+	// без него загружаются демонстрационные данные, off оставляет хранилище пустым
+	if fixture := os.Getenv("SEED_FIXTURE"); fixture != "off" {
+		if err := s.InitFillStore(fixture); err != nil {
+			log.Fatal().Err(err).Msg("failed to init store")
+		}
 	}
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		log.Fatal().Msg("OPENAI_API_KEY is not set")
+	// AI_MONTHLY_BUDGET - общий месячный лимит расходов на ассистента в долларах
+	if v := os.Getenv("AI_MONTHLY_BUDGET"); v != "" {
+		budget, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Fatal().Str("value", v).Msg("invalid AI_MONTHLY_BUDGET")
+		}
+		if _, err := s.SetAIBudget(0, budget); err != nil {
+			log.Fatal().Err(err).Msg("invalid AI_MONTHLY_BUDGET")
+		}
 	}
 
-	assistantID := os.Getenv("OPENAI_ASSISTANT_ID")
-	if assistantID == "" {
-		log.Fatal().Msg("OPENAI_ASSISTANT_ID is not set")
+	metrics.RegisterStore(s)
+
+	// Трейсы по OTLP, если задан OTEL_EXPORTER_OTLP_ENDPOINT
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to init tracing")
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = shutdownTracing(ctx)
+	}()
+
+	// Ошибки в Sentry, если задан SENTRY_DSN. Подключается до провайдеров, чтобы их клиенты сообщали о сбоях
+	flushReports, err := reporting.Init()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to init error reporting")
+	}
+	defer flushReports(5 * time.Second)
+
+	requestLogger := requestLoggerFromEnv()
 
-	o := openai.NewClient(apiKey, assistantID)
+	// Предохранитель: после серии сбоев провайдера AI-эндпоинты сразу отвечают 503
+	provider := llm.NewBreaker(newAIProvider(requestLogger), breakerThresholdFromEnv(), breakerCooldownFromEnv())
+	log.Info().Str("provider", provider.Name()).Msg("ai provider configured")
 
-	r := router.NewRouter(s, o)
+	mail := newMailer()
+	log.Info().Str("provider", mail.Name()).Msg("mail provider configured")
+
+	files := newStorage()
+	log.Info().Str("storage", files.Name()).Msg("file storage configured")
+
+	h := handler.NewHandler(s, provider, newModerator(requestLogger), mail, files)
+	// DEV_MODE=true включает служебные эндпоинты для разработки, в рабочей установке их быть не должно
+	if h.DevMode = os.Getenv("DEV_MODE") == "true"; h.DevMode {
+		log.Warn().Msg("dev mode is on")
+	}
+	r := router.NewRouter(s, h, newFrontend())
+
+	// GRPC_ADDR - адрес gRPC-сервера для внутренних сервисов (например, :9090), без него gRPC выключен
+	var grpcServer *grpc.Server
+	if addr := os.Getenv("GRPC_ADDR"); addr != "" {
+		grpcServer = h.NewGRPCServer()
+		go serveGRPC(addr, grpcServer)
+	}
 
 	server := &http.Server{
 		Addr:    host + ":" + port,
 		Handler: r,
 	}
 
-	log.Info().Str("addr", server.Addr).Msg("listening")
-	err = server.ListenAndServe()
+	// SIGINT и SIGTERM (так останавливают при выкладке) останавливают сервер плавно
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	timeout := shutdownTimeoutFromEnv()
+	stopped := make(chan struct{})
+	go func() {
+		<-signals.Done()
+		shutdown(server, grpcServer, h, timeout)
+		close(stopped)
+	}()
+
+	err = listenAndServe(server)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal().Err(err).Msg("server error")
 	}
+	<-stopped
+}
+
+// shutdown останавливает сервер, не обрывая начатого: новые соединения не принимаются, а идущие
+// запросы (в том числе стримы ассистента) и запросы к ассистенту из очереди выполняются до конца,
+// но не дольше timeout. Потоки событий попыток и WebSocket-соединения закрываются сразу.
+// Не успевшие запросы прерываются, запросы к ассистенту завершаются ошибкой,
+// по которой студент отправляет сообщение заново
+func shutdown(server *http.Server, grpcServer *grpc.Server, h *handler.Handler, timeout time.Duration) {
+	log.Info().Dur("timeout", timeout).Msg("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if grpcServer != nil {
+		go func() {
+			<-ctx.Done()
+			grpcServer.Stop()
+		}()
+		go grpcServer.GracefulStop()
+	}
+
+	// Потоки событий и WebSocket-соединения не заканчиваются сами, клиенты переподключатся к новому экземпляру
+	server.RegisterOnShutdown(h.Realtime.CloseAll)
+
+	// Запросы к ассистенту из очереди дожидаются параллельно с HTTP-запросами, каждый получает весь timeout
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := h.Shutdown(ctx); err != nil {
+			log.Warn().Err(err).Msg("ai runs interrupted on shutdown")
+		}
+	}()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Warn().Err(err).Msg("requests did not finish in time, closing connections")
+		_ = server.Close()
+	}
+	wg.Wait()
+	log.Info().Msg("server stopped")
+}
+
+// shutdownTimeoutFromEnv читает SHUTDOWN_TIMEOUT - сколько ждать начатые запросы при остановке
+// (в формате time.ParseDuration, по умолчанию 30s). Больше срока, который дает оркестратор
+// до SIGKILL (terminationGracePeriodSeconds в Kubernetes), ставить бессмысленно
+func shutdownTimeoutFromEnv() time.Duration {
+	v := os.Getenv("SHUTDOWN_TIMEOUT")
+	if v == "" {
+		return 30 * time.Second
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatal().Str("value", v).Msg("invalid SHUTDOWN_TIMEOUT")
+	}
+	return d
+}
+
+// listenAndServe запускает сервер по HTTP или, если настроен TLS, по HTTPS:
+//   - TLS_CERT_FILE и TLS_KEY_FILE - готовый сертификат, HTTPS на обычном адресе сервера;
+//   - TLS_AUTOCERT_DOMAINS (через запятую) - сертификаты Let's Encrypt, выпускаются и продлеваются сами.
+//     HTTPS слушает TLS_ADDR (:443 по умолчанию), на TLS_HTTP_ADDR (:80) отвечает на проверки ACME
+//     и перенаправляет на HTTPS. Сертификаты хранятся в TLS_AUTOCERT_CACHE (certs), TLS_AUTOCERT_EMAIL -
+//     адрес для уведомлений Let's Encrypt
+func listenAndServe(server *http.Server) error {
+	certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	domains := os.Getenv("TLS_AUTOCERT_DOMAINS")
+
+	switch {
+	case certFile != "" || keyFile != "":
+		if certFile == "" || keyFile == "" {
+			log.Fatal().Msg("both TLS_CERT_FILE and TLS_KEY_FILE must be set")
+		}
+		if domains != "" {
+			log.Fatal().Msg("TLS_AUTOCERT_DOMAINS cannot be used together with TLS_CERT_FILE")
+		}
+		if addr := os.Getenv("TLS_ADDR"); addr != "" {
+			server.Addr = addr
+		}
+		log.Info().Str("addr", server.Addr).Msg("listening with tls")
+		return server.ListenAndServeTLS(certFile, keyFile)
+	case domains != "":
+		hosts := make([]string, 0)
+		for _, domain := range strings.Split(domains, ",") {
+			if domain = strings.TrimSpace(domain); domain != "" {
+				hosts = append(hosts, domain)
+			}
+		}
+
+		cacheDir := os.Getenv("TLS_AUTOCERT_CACHE")
+		if cacheDir == "" {
+			cacheDir = "certs"
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(hosts...),
+			Cache:      autocert.DirCache(cacheDir),
+			Email:      os.Getenv("TLS_AUTOCERT_EMAIL"),
+		}
+
+		httpAddr := os.Getenv("TLS_HTTP_ADDR")
+		if httpAddr == "" {
+			httpAddr = ":80"
+		}
+		go func() {
+			// Проверки http-01 и перенаправление всех остальных запросов на HTTPS
+			if err := http.ListenAndServe(httpAddr, manager.HTTPHandler(nil)); err != nil {
+				log.Error().Err(err).Str("addr", httpAddr).Msg("acme http listener stopped")
+			}
+		}()
+
+		server.Addr = os.Getenv("TLS_ADDR")
+		if server.Addr == "" {
+			server.Addr = ":443"
+		}
+		server.TLSConfig = manager.TLSConfig()
+		log.Info().Str("addr", server.Addr).Strs("domains", hosts).Msg("listening with autocert")
+		return server.ListenAndServeTLS("", "")
+	default:
+		log.Info().Str("addr", server.Addr).Msg("listening")
+		return server.ListenAndServe()
+	}
+}
+
+// serveGRPC слушает addr и обслуживает gRPC-запросы, ошибка запуска останавливает приложение
+func serveGRPC(addr string, server *grpc.Server) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen grpc")
+	}
+	log.Info().Str("addr", addr).Msg("listening grpc")
+	if err := server.Serve(listener); err != nil {
+		log.Fatal().Err(err).Msg("grpc server error")
+	}
+}
+
+// newAIProvider выбирает бэкенд ассистента по переменной AI_PROVIDER (openai по умолчанию,
+// anthropic или fake - детерминированные ответы для разработки без ключа)
+func newAIProvider(logger *llm.RequestLogger) llm.Provider {
+	switch provider := os.Getenv("AI_PROVIDER"); provider {
+	case "", "openai":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			log.Fatal().Msg("OPENAI_API_KEY is not set")
+		}
+
+		// OpenAI-совместимый сервер (Azure, локальная модель) задается через OPENAI_BASE_URL
+		baseURL := os.Getenv("OPENAI_BASE_URL")
+
+		// OPENAI_API_MODE=chat - Chat Completions с историей на нашем сервере вместо беты Assistants
+		switch mode := os.Getenv("OPENAI_API_MODE"); mode {
+		case "", "assistants":
+			assistantID := os.Getenv("OPENAI_ASSISTANT_ID")
+			if assistantID == "" {
+				log.Fatal().Msg("OPENAI_ASSISTANT_ID is not set")
+			}
+
+			o := openai.NewClient(apiKey, assistantID)
+			o.Retry = retryPolicyFromEnv()
+			o.EmbeddingModel = os.Getenv("OPENAI_EMBEDDING_MODEL")
+			logger.Instrument(o.HTTP, "openai")
+			metrics.InstrumentHTTP(o.HTTP, "openai")
+			tracing.InstrumentHTTP(o.HTTP, "openai")
+			reporting.InstrumentHTTP(o.HTTP, "openai")
+			logger.Instrument(o.StreamHTTP, "openai")
+			metrics.InstrumentHTTP(o.StreamHTTP, "openai")
+			tracing.InstrumentHTTP(o.StreamHTTP, "openai")
+			reporting.InstrumentHTTP(o.StreamHTTP, "openai")
+			if baseURL != "" {
+				o.BaseURL = baseURL
+			}
+			return o
+		case "chat":
+			model := os.Getenv("OPENAI_MODEL")
+			if model == "" {
+				log.Fatal().Msg("OPENAI_MODEL is not set")
+			}
+
+			o := openai.NewChatClient(apiKey, model, os.Getenv("AI_SYSTEM_PROMPT"))
+			o.Retry = retryPolicyFromEnv()
+			o.EmbeddingModel = os.Getenv("OPENAI_EMBEDDING_MODEL")
+			logger.Instrument(o.HTTP, "openai-chat")
+			metrics.InstrumentHTTP(o.HTTP, "openai-chat")
+			tracing.InstrumentHTTP(o.HTTP, "openai-chat")
+			reporting.InstrumentHTTP(o.HTTP, "openai-chat")
+			logger.Instrument(o.StreamHTTP, "openai-chat")
+			metrics.InstrumentHTTP(o.StreamHTTP, "openai-chat")
+			tracing.InstrumentHTTP(o.StreamHTTP, "openai-chat")
+			reporting.InstrumentHTTP(o.StreamHTTP, "openai-chat")
+			if baseURL != "" {
+				o.BaseURL = baseURL
+			}
+			return o
+		default:
+			log.Fatal().Str("mode", mode).Msg("unknown OPENAI_API_MODE")
+			return nil
+		}
+	case "anthropic":
+		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		if apiKey == "" {
+			log.Fatal().Msg("ANTHROPIC_API_KEY is not set")
+		}
+
+		model := os.Getenv("ANTHROPIC_MODEL")
+		if model == "" {
+			log.Fatal().Msg("ANTHROPIC_MODEL is not set")
+		}
+
+		a := anthropic.NewClient(apiKey, model, os.Getenv("AI_SYSTEM_PROMPT"))
+		a.Retry = retryPolicyFromEnv()
+		logger.Instrument(a.HTTP, "anthropic")
+		metrics.InstrumentHTTP(a.HTTP, "anthropic")
+		tracing.InstrumentHTTP(a.HTTP, "anthropic")
+		reporting.InstrumentHTTP(a.HTTP, "anthropic")
+		logger.Instrument(a.StreamHTTP, "anthropic")
+		metrics.InstrumentHTTP(a.StreamHTTP, "anthropic")
+		tracing.InstrumentHTTP(a.StreamHTTP, "anthropic")
+		reporting.InstrumentHTTP(a.StreamHTTP, "anthropic")
+		if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" {
+			a.BaseURL = baseURL
+		}
+		return a
+	case "fake":
+		// Без ключа и сети: эхо или заготовленные ответы из AI_FAKE_RESPONSES
+		var responses []fake.Response
+		if path := os.Getenv("AI_FAKE_RESPONSES"); path != "" {
+			var err error
+			if responses, err = fake.LoadResponses(path); err != nil {
+				log.Fatal().Err(err).Str("path", path).Msg("invalid AI_FAKE_RESPONSES")
+			}
+		}
+		log.Warn().Msg("using fake AI provider")
+		return fake.NewClient(responses)
+	default:
+		log.Fatal().Str("provider", provider).Msg("unknown AI_PROVIDER")
+		return nil
+	}
+}
+
+// requestLoggerFromEnv настраивает журнал запросов к провайдерам: AI_REQUEST_LOG=off отключает его,
+// bodies добавляет тела запросов и ответов. AI_REQUEST_LOG_FILE сохраняет записи в файл построчно в JSON.
+// Ключи API, email и телефоны вырезаются
+func requestLoggerFromEnv() *llm.RequestLogger {
+	logger := &llm.RequestLogger{}
+	switch mode := os.Getenv("AI_REQUEST_LOG"); mode {
+	case "off":
+		return nil
+	case "", "on":
+	case "bodies":
+		logger.Bodies = true
+	default:
+		log.Fatal().Str("mode", mode).Msg("unknown AI_REQUEST_LOG")
+	}
+
+	if path := os.Getenv("AI_REQUEST_LOG_FILE"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", path).Msg("failed to open AI_REQUEST_LOG_FILE")
+		}
+		logger.Sink = f
+	}
+
+	return logger
+}
+
+// retryPolicyFromEnv читает настройки повторов запросов к провайдеру:
+// AI_MAX_RETRIES, AI_RETRY_BASE_DELAY и AI_RETRY_MAX_DELAY (в формате time.ParseDuration)
+func retryPolicyFromEnv() llm.RetryPolicy {
+	policy := llm.DefaultRetryPolicy()
+
+	if v := os.Getenv("AI_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatal().Str("value", v).Msg("invalid AI_MAX_RETRIES")
+		}
+		policy.MaxRetries = n
+	}
+	if v := os.Getenv("AI_RETRY_BASE_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatal().Str("value", v).Msg("invalid AI_RETRY_BASE_DELAY")
+		}
+		policy.BaseDelay = d
+	}
+	if v := os.Getenv("AI_RETRY_MAX_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatal().Str("value", v).Msg("invalid AI_RETRY_MAX_DELAY")
+		}
+		policy.MaxDelay = d
+	}
+
+	return policy
+}
+
+// breakerThresholdFromEnv читает AI_BREAKER_THRESHOLD - число сбоев подряд до размыкания предохранителя
+func breakerThresholdFromEnv() int {
+	v := os.Getenv("AI_BREAKER_THRESHOLD")
+	if v == "" {
+		return llm.DefaultBreakerThreshold
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Fatal().Str("value", v).Msg("invalid AI_BREAKER_THRESHOLD")
+	}
+	return n
+}
+
+// breakerCooldownFromEnv читает AI_BREAKER_COOLDOWN - сколько предохранитель остается разомкнутым
+func breakerCooldownFromEnv() time.Duration {
+	v := os.Getenv("AI_BREAKER_COOLDOWN")
+	if v == "" {
+		return llm.DefaultBreakerCooldown
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatal().Str("value", v).Msg("invalid AI_BREAKER_COOLDOWN")
+	}
+	return d
+}
+
+// newModerator собирает модерацию сообщений ассистенту: правила-регулярки (AI_BLOCKED_PATTERNS
+// через ";" или правила по умолчанию) и, если AI_MODERATION=openai, OpenAI Moderation API.
+// AI_MODERATION=off отключает модерацию
+func newModerator(logger *llm.RequestLogger) llm.Moderator {
+	mode := os.Getenv("AI_MODERATION")
+	if mode == "off" {
+		return nil
+	}
+
+	patterns := llm.DefaultBlockedPatterns
+	if v := os.Getenv("AI_BLOCKED_PATTERNS"); v != "" {
+		patterns = strings.Split(v, ";")
+	}
+	keywords, err := llm.NewKeywordModerator(patterns)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid AI_BLOCKED_PATTERNS")
+	}
+
+	switch mode {
+	case "", "keywords":
+		return keywords
+	case "openai":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			log.Fatal().Msg("OPENAI_API_KEY is not set")
+		}
+		m := openai.NewModerator(apiKey)
+		logger.Instrument(m.HTTP, "openai-moderation")
+		metrics.InstrumentHTTP(m.HTTP, "openai-moderation")
+		tracing.InstrumentHTTP(m.HTTP, "openai-moderation")
+		reporting.InstrumentHTTP(m.HTTP, "openai-moderation")
+		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+			m.BaseURL = baseURL
+		}
+		return llm.ModeratorChain{keywords, m}
+	default:
+		log.Fatal().Str("mode", mode).Msg("unknown AI_MODERATION")
+		return nil
+	}
+}
+
+// newMailer выбирает способ отправки писем по MAIL_PROVIDER: log (по умолчанию) пишет письма в журнал,
+// smtp отправляет через SMTP_HOST:SMTP_PORT (587 по умолчанию) от имени MAIL_FROM.
+// SMTP_USERNAME и SMTP_PASSWORD - авторизация, SMTP_TLS - starttls (по умолчанию), implicit или none
+func newMailer() *mailer.Mailer {
+	var sender mailer.Sender
+	switch provider := os.Getenv("MAIL_PROVIDER"); provider {
+	case "", "log":
+		sender = mailer.LogSender{}
+	case "smtp":
+		smtpSender := &mailer.SMTPSender{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+			TLS:      os.Getenv("SMTP_TLS"),
+		}
+		if smtpSender.Host == "" {
+			log.Fatal().Msg("SMTP_HOST is not set")
+		}
+		if smtpSender.From == "" {
+			log.Fatal().Msg("MAIL_FROM is not set")
+		}
+		if smtpSender.Port == "" {
+			smtpSender.Port = "587"
+		}
+		switch smtpSender.TLS {
+		case "", mailer.SMTPStartTLS, mailer.SMTPImplicit, mailer.SMTPNone:
+		default:
+			log.Fatal().Str("mode", smtpSender.TLS).Msg("unknown SMTP_TLS")
+		}
+		sender = smtpSender
+	default:
+		log.Fatal().Str("provider", provider).Msg("unknown MAIL_PROVIDER")
+	}
+
+	m, err := mailer.New(sender, mailer.DefaultWorkers, mailer.DefaultQueueSize)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to init mailer")
+	}
+	return m
+}
+
+// configureLogging настраивает логи: LOG_LEVEL - trace, debug, info, warn или error (по умолчанию пишется все),
+// LOG_FORMAT - json (по умолчанию) или console - читаемый цветной вывод для разработки.
+// Уровень меняется без перезапуска через PUT /api/admin/log-level
+func configureLogging() {
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		level, err := zerolog.ParseLevel(v)
+		if err != nil || level == zerolog.NoLevel {
+			log.Fatal().Str("value", v).Msg("invalid LOG_LEVEL")
+		}
+		zerolog.SetGlobalLevel(level)
+	}
+
+	switch format := os.Getenv("LOG_FORMAT"); format {
+	case "", "json":
+	case "console":
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+	default:
+		log.Fatal().Str("format", format).Msg("unknown LOG_FORMAT")
+	}
+}
+
+// newFrontend отдает собранный фронтенд из STATIC_DIR (например, web/dist), без переменной
+// фронтенд обслуживает отдельный веб-сервер
+func newFrontend() http.Handler {
+	dir := os.Getenv("STATIC_DIR")
+	if dir == "" {
+		return nil
+	}
+
+	f, err := frontend.New(dir)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", dir).Msg("invalid STATIC_DIR")
+	}
+	log.Info().Str("dir", dir).Msg("serving frontend")
+	return f
+}
+
+// newStorage выбирает хранилище загруженных файлов по переменной STORAGE:
+//   - local (по умолчанию) - каталог STORAGE_DIR (uploads), файлы отдает этот же сервер по подписанным ссылкам.
+//     STORAGE_PUBLIC_URL - внешний адрес сервера для ссылок (без него ссылки относительные),
+//     STORAGE_URL_SECRET - ключ подписи; без него ключ случайный и ссылки перестают работать после перезапуска;
+//   - s3 - бакет S3_BUCKET в S3 или совместимом хранилище: S3_ENDPOINT (пусто - AWS), S3_REGION,
+//     S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY, S3_PATH_STYLE=true для MinIO
+func newStorage() storage.Storage {
+	switch backend := os.Getenv("STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+
+		secret := []byte(os.Getenv("STORAGE_URL_SECRET"))
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				log.Fatal().Err(err).Msg("failed to generate storage url secret")
+			}
+			log.Warn().Msg("STORAGE_URL_SECRET is not set, file links stop working after restart")
+		}
+
+		local, err := storage.NewLocal(dir, os.Getenv("STORAGE_PUBLIC_URL"), secret)
+		if err != nil {
+			log.Fatal().Err(err).Str("dir", dir).Msg("failed to init file storage")
+		}
+		return local
+	case "s3":
+		bucket := os.Getenv("S3_BUCKET")
+		if bucket == "" {
+			log.Fatal().Msg("S3_BUCKET is not set")
+		}
+		accessKey, secretKey := os.Getenv("S3_ACCESS_KEY_ID"), os.Getenv("S3_SECRET_ACCESS_KEY")
+		if accessKey == "" || secretKey == "" {
+			log.Fatal().Msg("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set")
+		}
+
+		s3 := storage.NewS3(os.Getenv("S3_ENDPOINT"), os.Getenv("S3_REGION"), bucket, accessKey, secretKey, os.Getenv("S3_PATH_STYLE") == "true")
+		tracing.InstrumentHTTP(s3.HTTP, "s3")
+		return s3
+	default:
+		log.Fatal().Str("backend", backend).Msg("unknown STORAGE")
+		return nil
+	}
 }
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	jobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Background job runs by job and result (ok or error).",
+	}, []string{"job", "result"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Background job run duration.",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 15, 60, 300},
+	}, []string{"job"})
+
+	jobLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful run of a background job.",
+	}, []string{"job"})
+)
+
+// ObserveJob записывает запуск фоновой задачи
+func ObserveJob(job string, duration time.Duration, err error) {
+	jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+	if err != nil {
+		jobRuns.WithLabelValues(job, "error").Inc()
+		return
+	}
+	jobRuns.WithLabelValues(job, "ok").Inc()
+	jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+}
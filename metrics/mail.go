@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var emailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "emails_sent_total",
+	Help:      "Email send attempts by provider and result (ok or error).",
+}, []string{"provider", "result"})
+
+// ObserveEmail записывает попытку отправки письма
+func ObserveEmail(provider string, err error) {
+	if err != nil {
+		emailsSent.WithLabelValues(provider, "error").Inc()
+		return
+	}
+	emailsSent.WithLabelValues(provider, "ok").Inc()
+}
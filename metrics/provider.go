@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"GEEK_back/client/llm"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	providerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ai_provider_request_duration_seconds",
+		Help:      "Latency of HTTP calls to the AI provider by endpoint.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"provider", "endpoint"})
+
+	providerRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_provider_requests_total",
+		Help:      "HTTP calls to the AI provider by endpoint and status code (\"error\" for transport failures).",
+	}, []string{"provider", "endpoint", "code"})
+
+	providerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_provider_errors_total",
+		Help:      "Failed HTTP calls to the AI provider: transport errors and 4xx/5xx responses.",
+	}, []string{"provider", "endpoint"})
+)
+
+// InstrumentHTTP подключает метрики ко всем запросам HTTP-клиента провайдера
+func InstrumentHTTP(client *http.Client, provider string) {
+	if client == nil {
+		return
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &providerTransport{base: base, provider: provider}
+}
+
+type providerTransport struct {
+	base     http.RoundTripper
+	provider string
+}
+
+func (t *providerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := llm.EndpointPath(req.URL.Path)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	providerDuration.WithLabelValues(t.provider, endpoint).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	providerRequests.WithLabelValues(t.provider, endpoint, code).Inc()
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		providerErrors.WithLabelValues(t.provider, endpoint).Inc()
+	}
+
+	return resp, err
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"GEEK_back/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// storeCollector отдает размеры хранилища в момент сбора метрик
+type storeCollector struct {
+	store *store.Store
+
+	users     *prometheus.Desc
+	tests     *prometheus.Desc
+	attempts  *prometheus.Desc
+	sessions  *prometheus.Desc
+	aiThreads *prometheus.Desc
+}
+
+// RegisterStore регистрирует метрики размеров хранилища
+func RegisterStore(s *store.Store) {
+	prometheus.MustRegister(&storeCollector{
+		store:     s,
+		users:     prometheus.NewDesc(namespace+"_store_users", "Registered users.", nil, nil),
+		tests:     prometheus.NewDesc(namespace+"_store_tests", "Tests.", nil, nil),
+		attempts:  prometheus.NewDesc(namespace+"_store_attempts", "Attempts by status.", []string{"status"}, nil),
+		sessions:  prometheus.NewDesc(namespace+"_store_sessions", "Active sessions.", nil, nil),
+		aiThreads: prometheus.NewDesc(namespace+"_store_ai_threads", "AI threads.", nil, nil),
+	})
+}
+
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.users
+	ch <- c.tests
+	ch <- c.attempts
+	ch <- c.sessions
+	ch <- c.aiThreads
+}
+
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	sizes := c.store.Sizes()
+
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(sizes.Users))
+	ch <- prometheus.MustNewConstMetric(c.tests, prometheus.GaugeValue, float64(sizes.Tests))
+	ch <- prometheus.MustNewConstMetric(c.sessions, prometheus.GaugeValue, float64(sizes.Sessions))
+	ch <- prometheus.MustNewConstMetric(c.aiThreads, prometheus.GaugeValue, float64(sizes.AIThreads))
+	for status, count := range sizes.Attempts {
+		ch <- prometheus.MustNewConstMetric(c.attempts, prometheus.GaugeValue, float64(count), status)
+	}
+}
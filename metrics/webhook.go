@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var webhooksSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "webhook_deliveries_total",
+	Help:      "Webhook delivery attempts by event and result (ok or error).",
+}, []string{"event", "result"})
+
+// ObserveWebhook записывает попытку доставки вебхука
+func ObserveWebhook(event string, err error) {
+	if err != nil {
+		webhooksSent.WithLabelValues(event, "error").Inc()
+		return
+	}
+	webhooksSent.WithLabelValues(event, "ok").Inc()
+}
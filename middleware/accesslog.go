@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"GEEK_back/apiutils"
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const accessLogKey ctxKey = "accessLog"
+
+// accessEntry - данные запроса, которые становятся известны внутри цепочки обработчиков
+type accessEntry struct {
+	userID uint64
+}
+
+// AccessLog пишет строку лога на каждый запрос: метод, путь, код ответа, время обработки,
+// размер ответа и ID пользователя, если запрос авторизован. ACCESS_LOG=off отключает лог,
+// запросы Prometheus к /metrics не логируются. Должен стоять после RequestID, чтобы в записи был request_id
+func AccessLog(next http.Handler) http.Handler {
+	if os.Getenv("ACCESS_LOG") == "off" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := &accessEntry{}
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), accessLogKey, entry)))
+
+		logger := zerolog.Ctx(r.Context())
+		var event *zerolog.Event
+		switch {
+		case rec.status >= http.StatusInternalServerError:
+			event = logger.Error()
+		case rec.status >= http.StatusBadRequest:
+			event = logger.Warn()
+		default:
+			event = logger.Info()
+		}
+
+		event = event.Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Int("status", rec.status).
+			Dur("latency_ms", time.Since(start)).
+			Int64("size", rec.size).
+			Str("remote_ip", apiutils.ClientIP(r))
+		if entry.userID != 0 {
+			event = event.Uint64("user_id", entry.userID)
+		}
+		event.Msg("http request")
+	})
+}
+
+// setAccessUser запоминает пользователя запроса для строки access-лога
+func setAccessUser(ctx context.Context, userID uint64) {
+	if entry, ok := ctx.Value(accessLogKey).(*accessEntry); ok {
+		entry.userID = userID
+	}
+}
+
+// responseRecorder запоминает код и размер ответа. Flush пробрасывается, чтобы работали SSE-ответы
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack пробрасывается, чтобы работали WebSocket-соединения
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && !r.wroteHeader {
+		r.status = http.StatusSwitchingProtocols
+		r.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"GEEK_back/apiutils"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultMaxBodyBytes - максимальный размер JSON-тела запроса по умолчанию
+const defaultMaxBodyBytes = 1 << 20
+
+// BodyLimit ограничивает размер тела запроса MAX_BODY_BYTES байт (по умолчанию 1 МБ).
+// Запрос с заведомо большим Content-Length сразу получает 413, остальные обрываются
+// при чтении через http.MaxBytesReader. multipart/form-data не ограничивается здесь:
+// загрузки файлов задают собственные лимиты в обработчиках
+func BodyLimit() mux.MiddlewareFunc {
+	limit := int64(defaultMaxBodyBytes)
+	if v, err := strconv.ParseInt(os.Getenv("MAX_BODY_BYTES"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > limit {
+				apiutils.WriteError(w, http.StatusRequestEntityTooLarge, apiutils.CodePayloadTooLarge,
+					fmt.Sprintf("request body must not exceed %d bytes", limit), nil)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
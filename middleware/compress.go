@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// compressMinSize - ответы меньше этого размера отдаются без сжатия: выигрыш не окупает заголовки и CPU
+const compressMinSize = 1 << 10
+
+var (
+	gzipWriters = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}
+	flateWriters = sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return w
+	}}
+)
+
+// Compress сжимает JSON и текстовые ответы gzip или deflate в зависимости от Accept-Encoding.
+// Ответ буферизуется до compressMinSize: короткие ответы уходят как есть. Потоки SSE не сжимаются,
+// чтобы фрагменты ответа ассистента доходили до клиента сразу
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		// Переход на WebSocket забирает соединение целиком, сжимать нечего
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding выбирает gzip или deflate из Accept-Encoding, gzip предпочтительнее
+func acceptedEncoding(header string) string {
+	gzipOK, deflateOK := false, false
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip":
+			gzipOK = true
+		case "deflate":
+			deflateOK = true
+		}
+	}
+
+	switch {
+	case gzipOK:
+		return "gzip"
+	case deflateOK:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressible сообщает, стоит ли сжимать ответ с таким Content-Type
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		return false
+	case strings.HasPrefix(contentType, "application/json"), strings.HasPrefix(contentType, "text/"):
+		return true
+	default:
+		return false
+	}
+}
+
+// compressWriter копит начало ответа, пока не станет ясно, сжимать ли его
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+
+	buf     []byte
+	decided bool
+	writer  io.WriteCloser // nil, если ответ идет без сжатия
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.decided {
+		return
+	}
+	cw.status = code
+	// Ответы без тела и уже сжатые отправляем как есть
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified ||
+		cw.Header().Get("Content-Encoding") != "" {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		if !compressible(cw.Header().Get("Content-Type")) || cw.Header().Get("Content-Encoding") != "" {
+			cw.start(false)
+		} else {
+			cw.buf = append(cw.buf, b...)
+			if len(cw.buf) < compressMinSize {
+				return len(b), nil
+			}
+			cw.start(true)
+			return len(b), nil
+		}
+	}
+
+	if cw.writer != nil {
+		return cw.writer.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// start отправляет заголовки и накопленный буфер, со сжатием или без
+func (cw *compressWriter) start(compress bool) {
+	cw.decided = true
+
+	if compress {
+		header := cw.Header()
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+		switch cw.encoding {
+		case "gzip":
+			gz := gzipWriters.Get().(*gzip.Writer)
+			gz.Reset(cw.ResponseWriter)
+			cw.writer = gz
+		default:
+			fl := flateWriters.Get().(*flate.Writer)
+			fl.Reset(cw.ResponseWriter)
+			cw.writer = fl
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) > 0 {
+		if cw.writer != nil {
+			_, _ = cw.writer.Write(cw.buf)
+		} else {
+			_, _ = cw.ResponseWriter.Write(cw.buf)
+		}
+		cw.buf = nil
+	}
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.start(false)
+	}
+	if flusher, ok := cw.writer.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close дописывает короткий ответ без сжатия или завершает сжатый поток
+func (cw *compressWriter) Close() {
+	if !cw.decided {
+		cw.start(false)
+		return
+	}
+	if cw.writer == nil {
+		return
+	}
+
+	_ = cw.writer.Close()
+	switch w := cw.writer.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(w)
+	case *flate.Writer:
+		flateWriters.Put(w)
+	}
+	cw.writer = nil
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
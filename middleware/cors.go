@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultCORSMaxAge - сколько секунд браузер кеширует ответ на preflight
+const defaultCORSMaxAge = 600
+
+// defaultAllowedOrigins - origins для разработки, разрешены всегда
+var defaultAllowedOrigins = []string{
+	"http://localhost:8080",
+	"http://127.0.0.1:8080",
+	"http://localhost:8030",
+	"http://127.0.0.1:8030",
+	"http://0.0.0.0:8030",
+	"http://192.168.1.126:3000",
+	"http://localhost:3000",
+	"http://72.56.67.17:3000",
+}
+
+// originMatcher проверяет Origin по точным значениям и шаблонам поддоменов
+type originMatcher struct {
+	exact     map[string]bool
+	wildcards []originWildcard
+}
+
+// originWildcard - шаблон вида https://*.example.com: схема и все, что после звездочки
+type originWildcard struct {
+	scheme string // "https://"
+	suffix string // ".example.com" или ".example.com:8443"
+}
+
+// newOriginMatcher разбирает список origins. Звездочка допускается только как
+// крайний левый сегмент хоста, "*" целиком не принимается: с credentials это открыло бы API всем сайтам
+func newOriginMatcher(origins []string) *originMatcher {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range origins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+
+		scheme, host, ok := strings.Cut(origin, "://")
+		if !ok || host == "" || origin == "*" {
+			log.Warn().Str("origin", origin).Msg("invalid allowed origin, skipping")
+			continue
+		}
+		if !strings.Contains(host, "*") {
+			m.exact[origin] = true
+			continue
+		}
+		if !strings.HasPrefix(host, "*.") || strings.Count(host, "*") != 1 {
+			log.Warn().Str("origin", origin).Msg("invalid allowed origin pattern, only *.domain is supported")
+			continue
+		}
+		m.wildcards = append(m.wildcards, originWildcard{scheme: scheme + "://", suffix: host[1:]})
+	}
+	return m
+}
+
+// allowed сообщает, разрешен ли origin. Шаблон *.example.com подходит для любого уровня
+// поддоменов, но не для самого example.com
+func (m *originMatcher) allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if m.exact[origin] {
+		return true
+	}
+	for _, w := range m.wildcards {
+		if !strings.HasPrefix(origin, w.scheme) || !strings.HasSuffix(origin, w.suffix) {
+			continue
+		}
+		sub := origin[len(w.scheme) : len(origin)-len(w.suffix)]
+		if sub != "" && !strings.ContainsAny(sub, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedOrigins - встроенные origins и ALLOWED_ORIGINS, разбираются один раз при первом использовании
+var allowedOrigins = sync.OnceValue(func() *originMatcher {
+	origins := append([]string{}, defaultAllowedOrigins...)
+	if env := os.Getenv("ALLOWED_ORIGINS"); env != "" {
+		origins = append(origins, strings.Split(env, ",")...)
+	}
+	return newOriginMatcher(origins)
+})
+
+// OriginAllowed сообщает, разрешен ли origin настройками CORS. Нужен там, где браузер
+// не применяет CORS сам, например при подключении по WebSocket
+func OriginAllowed(origin string) bool {
+	return allowedOrigins().allowed(origin)
+}
+
+// CORS разрешает кросс-доменные запросы с cookie с разрешенных origins. Список читается
+// один раз при запуске: встроенные origins для разработки и ALLOWED_ORIGINS через запятую,
+// например https://geek.example.com,https://*.geek.example.com. CORS_MAX_AGE задает в секундах,
+// сколько браузер кеширует preflight (по умолчанию 600).
+// На preflight отвечаем сами и для запрещенных origins - без заголовков Allow-*, браузер заблокирует запрос
+func CORS(next http.Handler) http.Handler {
+	matcher := allowedOrigins()
+
+	maxAge := defaultCORSMaxAge
+	if v, err := strconv.Atoi(os.Getenv("CORS_MAX_AGE")); err == nil && v >= 0 {
+		maxAge = v
+	}
+
+	allowMethods := "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	// If-Match обязателен для изменения версионируемых ресурсов, ETag нужен клиенту, чтобы его отправить
+	allowHeaders := "Content-Type, Authorization, If-Match, If-None-Match, " + RequestIDHeader + ", " + CSRFHeader
+	exposeHeaders := RequestIDHeader + ", Retry-After, ETag, " + CSRFHeader
+	maxAgeValue := strconv.Itoa(maxAge)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// Ответ зависит от Origin, кеши не должны отдавать его другим сайтам
+		w.Header().Add("Vary", "Origin")
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if matcher.allowed(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			if preflight {
+				w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				w.Header().Set("Access-Control-Max-Age", maxAgeValue)
+			} else {
+				w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+		}
+
+		if preflight {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
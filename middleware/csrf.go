@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/store"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// CSRFHeader - заголовок, в котором клиент присылает CSRF-токен
+const CSRFHeader = "X-CSRF-Token"
+
+// csrfSecret - ключ подписи токенов. Сессии хранятся в памяти и теряются при перезапуске,
+// поэтому случайного ключа на процесс достаточно; CSRF_SECRET задает его явно.
+// Читается при первом использовании, чтобы учесть переменные из .env
+var csrfSecret = sync.OnceValue(loadCSRFSecret)
+
+func loadCSRFSecret() []byte {
+	if secret := os.Getenv("CSRF_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+// CSRFToken возвращает CSRF-токен сессии: HMAC от ID сессии. Токен нельзя получить,
+// не зная ключа, а чужой сайт не может прочитать ответ с ним из-за CORS
+func CSRFToken(sessionID string) string {
+	mac := hmac.New(sha256.New, csrfSecret())
+	mac.Write([]byte(sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CSRF требует заголовок X-CSRF-Token с токеном сессии у изменяющих запросов (POST, PUT, PATCH, DELETE),
+// аутентифицированных cookie. Cookie с SameSite=Lax не защищает от запросов с поддоменов и
+// при SameSite=None (HTTPS), поэтому одного cookie недостаточно. Запросы без действующей сессии
+// не проверяются: от имени пользователя они ничего не сделают. CSRF=off отключает проверку
+func CSRF(s *store.Store) mux.MiddlewareFunc {
+	if os.Getenv("CSRF") == "off" {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			session, err := r.Cookie("session_id")
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := s.GetUserBySession(session.Value); !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.Header.Get(CSRFHeader)
+			if token == "" || !hmac.Equal([]byte(token), []byte(CSRFToken(session.Value))) {
+				apiutils.WriteError(w, http.StatusForbidden, apiutils.CodeCSRFInvalid, "missing or invalid csrf token", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// ETag добавляет ETag к успешным GET-ответам по хешу тела и отвечает 304 Not Modified,
+// если клиент прислал тот же ETag в If-None-Match. ETag, выставленный обработчиком (версия ресурса),
+// не заменяется. Ответ буферизуется целиком, поэтому подключается только к небольшим
+// часто перезапрашиваемым эндпоинтам (тест, список вопросов)
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Заголовки, уже выставленные внешними middleware (X-Request-ID), видны обработчику
+		rec := &bufferedResponse{header: w.Header().Clone(), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(rec.body.Bytes())
+			etag = `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", etag)
+		}
+		// Ответы зависят от пользователя: кешировать можно только в браузере и с проверкой при каждом запросе
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", "private, no-cache")
+		}
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Length")
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// etagMatches проверяет If-None-Match по слабому сравнению: W/"x" совпадает с "x"
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse собирает ответ обработчика в памяти
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if !b.wroteHeader {
+		b.status = code
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
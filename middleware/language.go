@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"GEEK_back/i18n"
+	"context"
+	"net/http"
+)
+
+// LanguageHeader - заголовок ответа с языком сообщений. apiutils.WriteError переводит ошибки на этот язык
+const LanguageHeader = "Content-Language"
+
+const LanguageKey ctxKey = "language"
+
+// Language выбирает язык сообщений по Accept-Language (или DEFAULT_LANGUAGE), кладет его
+// в контекст и в заголовок Content-Language ответа
+func Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Match(r.Header.Get("Accept-Language"))
+
+		w.Header().Set(LanguageHeader, lang)
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), lang)))
+	})
+}
+
+// WithLanguage кладет язык сообщений в контекст, например для вызова gRPC
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, LanguageKey, lang)
+}
+
+// GetLanguage возвращает язык сообщений текущего запроса; вне запроса - язык по умолчанию
+func GetLanguage(ctx context.Context) string {
+	if lang, ok := ctx.Value(LanguageKey).(string); ok {
+		return lang
+	}
+	return i18n.Default()
+}
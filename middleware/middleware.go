@@ -2,14 +2,14 @@ package middleware
 
 import (
 	"GEEK_back/apiutils"
+	"GEEK_back/reporting"
 	"GEEK_back/store"
 	"context"
 	"errors"
 	"github.com/gorilla/mux"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"net/http"
-	"os"
-	"strings"
+	"strconv"
 )
 
 type ctxKey string
@@ -29,78 +29,127 @@ func GetUserID(ctx context.Context) (uint64, bool) {
 	return id, ok
 }
 
-// getAllowedOrigins возвращает карту разрешенных origins
-func getAllowedOrigins() map[string]bool {
-	// Базовые origins для разработки
-	allowed := map[string]bool{
-		"http://localhost:8080":     true,
-		"http://127.0.0.1:8080":     true,
-		"http://localhost:8030":     true,
-		"http://127.0.0.1:8030":     true,
-		"http://0.0.0.0:8030":       true,
-		"http://192.168.1.126:3000": true,
-		"http://localhost:3000":     true,
-		"http://72.56.67.17:3000":   true,
-	}
+func AuthMiddleware(s *store.Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := r.Cookie("session_id")
+			if errors.Is(err, http.ErrNoCookie) {
+				zerolog.Ctx(r.Context()).Info().Msg("no session cookie found in auth middleware")
+				apiutils.WriteError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "no session cookie", nil)
+				return
+			}
+			if err != nil {
+				zerolog.Ctx(r.Context()).Error().Err(err).Msg("error getting session cookie in auth middleware")
+				apiutils.WriteError(w, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error", nil)
+				return
+			}
 
-	// Добавляем origins из переменной окружения ALLOWED_ORIGINS
-	// Формат: http://example.com:8030,http://192.168.1.100:8030
-	if envOrigins := os.Getenv("ALLOWED_ORIGINS"); envOrigins != "" {
-		for _, origin := range strings.Split(envOrigins, ",") {
-			origin = strings.TrimSpace(origin)
-			if origin != "" {
-				allowed[origin] = true
+			user, ok := s.GetUserBySession(session.Value)
+			if !ok {
+				apiutils.WriteError(w, http.StatusBadRequest, apiutils.CodeUnauthorized, "invalid session", nil)
+				return
 			}
-		}
-	}
 
-	return allowed
+			setAccessUser(r.Context(), user.ID)
+			reporting.SetUser(r.Context(), user.ID)
+			ctx := WithUserID(r.Context(), user.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		allowed := getAllowedOrigins()
-
-		if allowed[origin] {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-		}
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+// RequireRole пропускает только пользователей с одной из указанных ролей.
+// Должен применяться после AuthMiddleware
+func RequireRole(s *store.Store, roles ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				apiutils.WriteError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "unauthorized", nil)
+				return
+			}
+
+			user, ok := s.GetUserByID(userID)
+			if !ok {
+				apiutils.WriteError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "unauthorized", nil)
+				return
+			}
+
+			for _, role := range roles {
+				if user.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			apiutils.WriteError(w, http.StatusForbidden, apiutils.CodeForbidden, "forbidden", nil)
+		})
+	}
 }
 
-func AuthMiddleware(s *store.Store) mux.MiddlewareFunc {
+// RequireTestAccess пропускает к тесту из пути {test_id} только преподавателей его организации
+// и администраторов (см. store.CanManageTest). Для чужого теста отвечает 404, как для несуществующего,
+// чтобы не раскрывать тесты других организаций. Несуществующий тест и неверный test_id
+// передаются обработчику. Должен применяться после RequireRole
+func RequireTestAccess(s *store.Store) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			session, err := r.Cookie("session_id")
-			if errors.Is(err, http.ErrNoCookie) {
-				log.Info().Msg("no session cookie found in auth middleware")
-				apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "no session cookie"})
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				apiutils.WriteError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "unauthorized", nil)
 				return
 			}
+
+			testID, err := strconv.ParseUint(mux.Vars(r)["test_id"], 10, 64)
 			if err != nil {
-				log.Error().Err(err).Msg("error getting session cookie in auth middleware")
-				apiutils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			user, ok := s.GetUserBySession(session.Value)
+			allowed, err := s.CanManageTest(userID, testID)
+			switch {
+			case errors.Is(err, store.ErrTestNotFound):
+				next.ServeHTTP(w, r)
+			case err != nil:
+				apiutils.WriteError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "unauthorized", nil)
+			case !allowed:
+				apiutils.WriteError(w, http.StatusNotFound, apiutils.CodeNotFound, store.ErrTestNotFound.Error(), nil)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// RequireAttemptAccess пропускает к попытке из пути {attempt_id} только преподавателей организации ее теста
+// и администраторов (см. store.CanManageAttempt). Для чужой попытки отвечает 404, как для несуществующей.
+// Несуществующая попытка и неверный attempt_id передаются обработчику. Должен применяться после RequireRole
+func RequireAttemptAccess(s *store.Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
 			if !ok {
-				apiutils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid session"})
+				apiutils.WriteError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "unauthorized", nil)
 				return
 			}
 
-			ctx := WithUserID(r.Context(), user.ID)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			attemptID, err := strconv.ParseUint(mux.Vars(r)["attempt_id"], 10, 64)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, err := s.CanManageAttempt(userID, attemptID)
+			switch {
+			case errors.Is(err, store.ErrAttemptNotFound), errors.Is(err, store.ErrTestNotFound):
+				next.ServeHTTP(w, r)
+			case err != nil:
+				apiutils.WriteError(w, http.StatusUnauthorized, apiutils.CodeUnauthorized, "unauthorized", nil)
+			case !allowed:
+				apiutils.WriteError(w, http.StatusNotFound, apiutils.CodeNotFound, store.ErrAttemptNotFound.Error(), nil)
+			default:
+				next.ServeHTTP(w, r)
+			}
 		})
 	}
 }
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/limiter"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitPolicy - сколько запросов в секунду пропускается с одного IP и какой всплеск допустим
+type RateLimitPolicy struct {
+	Rate  float64
+	Burst int
+}
+
+// Лимиты по умолчанию. Вход и регистрация ограничены строже остального API:
+// это основная цель перебора паролей и массового создания пользователей
+var (
+	defaultRateLimit  = RateLimitPolicy{Rate: 20, Burst: 40}
+	defaultRouteLimit = map[string]RateLimitPolicy{
+		"POST /api/login":    {Rate: 0.2, Burst: 5},
+		"POST /api/register": {Rate: 0.05, Burst: 3},
+	}
+)
+
+// RateLimit ограничивает частоту запросов с одного IP к API (token bucket) и отвечает 429
+// с Retry-After, защищая единственный экземпляр с данными в памяти от случайных и намеренных потоков запросов.
+// Подключается через Use роутера: маршрут должен быть уже найден, чтобы применить его собственный лимит.
+//
+// Настройки из окружения:
+//   - RATE_LIMIT - запросов в секунду с одного IP (по умолчанию 20), 0 отключает ограничение
+//   - RATE_LIMIT_BURST - допустимый всплеск (по умолчанию 40)
+//   - RATE_LIMIT_ROUTES - лимиты отдельных маршрутов через запятую в виде
+//     "POST /api/login=0.2:5", где путь - шаблон маршрута, rate 0 снимает ограничение с маршрута
+func RateLimit() mux.MiddlewareFunc {
+	global := globalRateLimit()
+	if global.Rate == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	routes := make(map[string]*limiter.BucketLimiter)
+	for route, policy := range routeLimitsFromEnv() {
+		if policy.Rate > 0 {
+			routes[route] = limiter.NewBucketLimiter(policy.Rate, policy.Burst)
+		} else {
+			routes[route] = nil
+		}
+	}
+	all := limiter.NewBucketLimiter(global.Rate, global.Burst)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bucket := all
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					if override, ok := routes[r.Method+" "+template]; ok {
+						bucket = override
+					}
+				}
+			}
+			if bucket == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ok, retryAfter := bucket.Allow(apiutils.ClientIP(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				apiutils.WriteError(w, http.StatusTooManyRequests, apiutils.CodeRateLimited, "too many requests", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RouteRateLimit возвращает лимит маршрута с учетом RATE_LIMIT и RATE_LIMIT_ROUTES, false - без ограничения.
+// Нужен, чтобы тот же лимит действовал и в других транспортах, например для входа через gRPC
+func RouteRateLimit(route string) (RateLimitPolicy, bool) {
+	global := globalRateLimit()
+	if global.Rate == 0 {
+		return RateLimitPolicy{}, false
+	}
+
+	policy, ok := routeLimitsFromEnv()[route]
+	if !ok {
+		return global, true
+	}
+	return policy, policy.Rate > 0
+}
+
+// globalRateLimit возвращает общий лимит API из RATE_LIMIT и RATE_LIMIT_BURST
+func globalRateLimit() RateLimitPolicy {
+	global := defaultRateLimit
+	if v, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT"), 64); err == nil && v >= 0 {
+		global.Rate = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST")); err == nil && v > 0 {
+		global.Burst = v
+	}
+	return global
+}
+
+// routeLimitsFromEnv накладывает лимиты маршрутов из RATE_LIMIT_ROUTES на лимиты по умолчанию.
+// Неверные записи пропускаются с предупреждением в логе
+func routeLimitsFromEnv() map[string]RateLimitPolicy {
+	limits := make(map[string]RateLimitPolicy, len(defaultRouteLimit))
+	for route, policy := range defaultRouteLimit {
+		limits[route] = policy
+	}
+
+	for _, entry := range strings.Split(os.Getenv("RATE_LIMIT_ROUTES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, spec, ok := strings.Cut(entry, "=")
+		rateValue, burstValue, _ := strings.Cut(spec, ":")
+		rate, rateErr := strconv.ParseFloat(rateValue, 64)
+		burst := 1
+		var burstErr error
+		if burstValue != "" {
+			burst, burstErr = strconv.Atoi(burstValue)
+		}
+		if !ok || len(strings.Fields(route)) != 2 || rateErr != nil || rate < 0 || burstErr != nil || burst <= 0 {
+			log.Warn().Str("entry", entry).Msg("invalid RATE_LIMIT_ROUTES entry, skipping")
+			continue
+		}
+
+		method, path, _ := strings.Cut(strings.Join(strings.Fields(route), " "), " ")
+		limits[strings.ToUpper(method)+" "+path] = RateLimitPolicy{Rate: rate, Burst: burst}
+	}
+
+	return limits
+}
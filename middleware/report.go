@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"GEEK_back/apiutils"
+	"GEEK_back/reporting"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+// maxReportedBody - сколько байт тела ответа 5xx читается, чтобы достать код и сообщение ошибки
+const maxReportedBody = 4 << 10
+
+// Report восстанавливается после паники обработчика: вместо оборванного соединения клиент получает 500
+// в едином формате ошибок, а паника со стеком уходит в лог и Sentry. Ответы 5xx тоже отправляются
+// в Sentry с кодом и сообщением ошибки, кроме 503: это намеренный отказ (предохранитель, лимит расходов,
+// очередь), а сбои провайдера за ним отправляет reporting.InstrumentHTTP.
+// Подключается через Router.Use, чтобы события группировались по шаблону маршрута, а не по пути с ID
+func Report(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := reporting.NewContext(r.Context(), r)
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tmpl, err := current.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		reporting.SetTag(ctx, "route", route)
+		if id := GetRequestID(ctx); id != "" {
+			reporting.SetTag(ctx, "request_id", id)
+		}
+
+		rec := &reportRecorder{responseRecorder: &responseRecorder{ResponseWriter: w, status: http.StatusOK}}
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			// Так обработчик намеренно обрывает ответ, net/http обработает ее сам
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			zerolog.Ctx(ctx).Error().Interface("panic", value).Str("stack", string(debug.Stack())).
+				Str("method", r.Method).Str("route", route).Msg("handler panicked")
+			reporting.CapturePanic(ctx, value)
+			if !rec.wroteHeader {
+				apiutils.WriteError(rec, http.StatusInternalServerError, apiutils.CodeInternal, "internal server error", nil)
+			}
+		}()
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		if rec.status >= http.StatusInternalServerError && rec.status != http.StatusServiceUnavailable {
+			var body apiutils.ErrorResponse
+			_ = json.Unmarshal(rec.body, &body)
+			if body.Message == "" {
+				body.Message = http.StatusText(rec.status)
+			}
+			status := strconv.Itoa(rec.status)
+			reporting.CaptureError(ctx, fmt.Errorf("%s %s %s: %s", status, r.Method, route, body.Message),
+				map[string]string{"status": status, "code": body.Code},
+				r.Method, route, status, body.Code)
+		}
+	})
+}
+
+// reportRecorder дополнительно запоминает начало тела ответов 5xx
+type reportRecorder struct {
+	*responseRecorder
+	body []byte
+}
+
+func (r *reportRecorder) Write(b []byte) (int, error) {
+	if r.status >= http.StatusInternalServerError && len(r.body) < maxReportedBody {
+		r.body = append(r.body, b[:min(len(b), maxReportedBody-len(r.body))]...)
+	}
+	return r.responseRecorder.Write(b)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestIDHeader - заголовок с ID запроса во входящих запросах и в ответах
+const RequestIDHeader = "X-Request-ID"
+
+const RequestIDKey ctxKey = "requestID"
+
+// maxRequestIDLength - входящий ID длиннее этого заменяется новым
+const maxRequestIDLength = 128
+
+func init() {
+	// zerolog.Ctx без логгера в контексте (фоновые задачи) пишет в общий логгер, а не в никуда
+	zerolog.DefaultContextLogger = &log.Logger
+}
+
+// RequestID берет ID запроса из X-Request-ID (например, от балансировщика) или генерирует новый,
+// кладет его в контекст вместе с логгером, который добавляет request_id к каждой записи,
+// и возвращает в заголовке ответа. apiutils.WriteError добавляет его в тела ошибок
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		logger := log.With().Str("request_id", id).Logger()
+		ctx := context.WithValue(r.Context(), RequestIDKey, id)
+		ctx = logger.WithContext(ctx)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRequestID возвращает ID текущего запроса
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// validRequestID пропускает только короткие ID из букв, цифр и -_.: - чтобы чужой заголовок
+// не ломал логи и ответы
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,366 @@
+// Package openapi отдает описание API в формате OpenAPI 3. Источник - аннотации обработчиков:
+// swag генерирует по ним Swagger 2.0 в docs (go generate в корне модуля), а здесь он переводится
+// в OpenAPI 3, поэтому обе спецификации всегда описывают одни и те же маршруты
+package openapi
+
+import (
+	"GEEK_back/docs"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Version - версия OpenAPI отдаваемой спецификации
+const Version = "3.0.3"
+
+// errorSchema - схема единого формата ошибки API (apiutils.ErrorResponse)
+const errorSchema = "apiutils.ErrorResponse"
+
+// Spec - спецификация API в OpenAPI 3
+type Spec struct {
+	doc map[string]any
+	raw []byte
+}
+
+// Load переводит спецификацию Swagger 2.0 из docs в OpenAPI 3
+func Load() (*Spec, error) {
+	var src map[string]any
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &src); err != nil {
+		return nil, err
+	}
+
+	doc := convert(src)
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Spec{doc: doc, raw: raw}, nil
+}
+
+// Has сообщает, описана ли операция: method - HTTP-метод, path - шаблон пути относительно
+// basePath в формате роутера (/tests/{test_id})
+func (s *Spec) Has(method, path string) bool {
+	paths, _ := s.doc["paths"].(map[string]any)
+	item, _ := paths[path].(map[string]any)
+	_, ok := item[strings.ToLower(method)]
+	return ok
+}
+
+// ServeHTTP отдает спецификацию
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(s.raw)
+}
+
+// convert собирает документ OpenAPI 3 из Swagger 2.0
+func convert(src map[string]any) map[string]any {
+	src = rewriteRefs(src).(map[string]any)
+
+	doc := map[string]any{
+		"openapi": Version,
+		"info":    src["info"],
+		"servers": servers(src),
+		"paths":   map[string]any{},
+	}
+	if tags, ok := src["tags"]; ok {
+		doc["tags"] = tags
+	}
+	if security, ok := src["security"]; ok {
+		doc["security"] = security
+	}
+
+	schemas := map[string]any{}
+	if definitions, ok := src["definitions"].(map[string]any); ok {
+		for name, schema := range definitions {
+			schemas[name] = convertSchema(schema)
+		}
+	}
+	components := map[string]any{"schemas": schemas}
+	if definitions, ok := src["securityDefinitions"].(map[string]any); ok {
+		components["securitySchemes"] = securitySchemes(definitions)
+	}
+	doc["components"] = components
+
+	consumes := stringList(src["consumes"], "application/json")
+	produces := stringList(src["produces"], "application/json")
+	_, hasErrorSchema := schemas[errorSchema]
+
+	paths := doc["paths"].(map[string]any)
+	srcPaths, _ := src["paths"].(map[string]any)
+	for path, item := range srcPaths {
+		operations := map[string]any{}
+		for method, op := range item.(map[string]any) {
+			op, ok := op.(map[string]any)
+			if !ok {
+				continue
+			}
+			operation := convertOperation(op, consumes, produces)
+			// Ошибки middleware (401, 403 CSRF, 413, 429) не описаны у обработчиков, но приходят
+			// в том же формате, что и остальные
+			if hasErrorSchema {
+				responses := operation["responses"].(map[string]any)
+				if _, ok := responses["default"]; !ok {
+					responses["default"] = map[string]any{
+						"description": "Error",
+						"content": map[string]any{
+							"application/json": map[string]any{"schema": schemaRef(errorSchema)},
+						},
+					}
+				}
+			}
+			operations[method] = operation
+		}
+		paths[path] = operations
+	}
+
+	return doc
+}
+
+// servers строит адрес API из host, basePath и schemes; без host адрес относительный
+func servers(src map[string]any) []any {
+	basePath, _ := src["basePath"].(string)
+	host, _ := src["host"].(string)
+	if host == "" {
+		if basePath == "" {
+			basePath = "/"
+		}
+		return []any{map[string]any{"url": basePath}}
+	}
+
+	var result []any
+	for _, scheme := range stringList(src["schemes"], "https") {
+		result = append(result, map[string]any{"url": scheme + "://" + host + basePath})
+	}
+	return result
+}
+
+// convertOperation переводит операцию: body и formData становятся requestBody,
+// схемы параметров и ответов уходят в schema и content
+func convertOperation(op map[string]any, consumes, produces []string) map[string]any {
+	result := map[string]any{}
+	for _, key := range []string{"tags", "summary", "description", "operationId", "deprecated", "security"} {
+		if value, ok := op[key]; ok {
+			result[key] = value
+		}
+	}
+	consumes = stringList(op["consumes"], consumes...)
+	produces = stringList(op["produces"], produces...)
+
+	var parameters []any
+	form := map[string]any{}
+	var formRequired []any
+	formType := "application/x-www-form-urlencoded"
+
+	params, _ := op["parameters"].([]any)
+	for _, param := range params {
+		param, ok := param.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch param["in"] {
+		case "body":
+			content := map[string]any{}
+			for _, mediaType := range consumes {
+				content[mediaType] = map[string]any{"schema": convertSchema(param["schema"])}
+			}
+			body := map[string]any{"content": content}
+			copyKeys(body, param, "description", "required")
+			result["requestBody"] = body
+		case "formData":
+			schema := paramSchema(param)
+			if schema["type"] == "string" && schema["format"] == "binary" {
+				formType = "multipart/form-data"
+			}
+			if description, ok := param["description"]; ok {
+				schema["description"] = description
+			}
+			name, _ := param["name"].(string)
+			form[name] = schema
+			if required, _ := param["required"].(bool); required {
+				formRequired = append(formRequired, name)
+			}
+		default:
+			parameter := map[string]any{"schema": paramSchema(param)}
+			copyKeys(parameter, param, "name", "in", "description", "required")
+			switch param["collectionFormat"] {
+			case "multi":
+				parameter["style"], parameter["explode"] = "form", true
+			case "csv":
+				parameter["style"], parameter["explode"] = "form", false
+			}
+			parameters = append(parameters, parameter)
+		}
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+	if len(form) > 0 {
+		for _, mediaType := range consumes {
+			if mediaType == "multipart/form-data" {
+				formType = mediaType
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": form}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		result["requestBody"] = map[string]any{
+			"required": len(formRequired) > 0,
+			"content":  map[string]any{formType: map[string]any{"schema": schema}},
+		}
+	}
+
+	responses := map[string]any{}
+	srcResponses, _ := op["responses"].(map[string]any)
+	for code, response := range srcResponses {
+		response, _ := response.(map[string]any)
+		converted := map[string]any{"description": response["description"]}
+		if converted["description"] == nil {
+			converted["description"] = ""
+		}
+		if schema, ok := response["schema"]; ok {
+			content := map[string]any{}
+			mediaTypes := produces
+			// Ошибки всегда в JSON, даже у обработчиков, отдающих изображения или поток событий
+			if isRef(schema, errorSchema) {
+				mediaTypes = []string{"application/json"}
+			}
+			for _, mediaType := range mediaTypes {
+				content[mediaType] = map[string]any{"schema": convertSchema(schema)}
+			}
+			converted["content"] = content
+		}
+		if headers, ok := response["headers"].(map[string]any); ok {
+			convertedHeaders := map[string]any{}
+			for name, header := range headers {
+				header, _ := header.(map[string]any)
+				h := map[string]any{"schema": paramSchema(header)}
+				copyKeys(h, header, "description")
+				convertedHeaders[name] = h
+			}
+			converted["headers"] = convertedHeaders
+		}
+		responses[code] = converted
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+// paramSchema собирает schema из полей параметра Swagger 2.0 (type, format, items, enum...)
+func paramSchema(param map[string]any) map[string]any {
+	schema := map[string]any{}
+	copyKeys(schema, param, "type", "format", "enum", "default", "minimum", "maximum",
+		"minLength", "maxLength", "pattern", "minItems", "maxItems")
+	if items, ok := param["items"].(map[string]any); ok {
+		schema["items"] = paramSchema(items)
+	}
+	if schema["type"] == "file" {
+		schema["type"], schema["format"] = "string", "binary"
+	}
+	return schema
+}
+
+// convertSchema переводит схему: x-nullable становится nullable, тип file - бинарной строкой
+func convertSchema(schema any) any {
+	switch schema := schema.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(schema))
+		for key, value := range schema {
+			switch key {
+			case "x-nullable":
+				result["nullable"] = value
+			default:
+				result[key] = convertSchema(value)
+			}
+		}
+		if result["type"] == "file" {
+			result["type"], result["format"] = "string", "binary"
+		}
+		return result
+	case []any:
+		result := make([]any, len(schema))
+		for i, value := range schema {
+			result[i] = convertSchema(value)
+		}
+		return result
+	default:
+		return schema
+	}
+}
+
+// securitySchemes переводит securityDefinitions: apiKey не меняется, basic становится http basic
+func securitySchemes(definitions map[string]any) map[string]any {
+	result := map[string]any{}
+	for name, definition := range definitions {
+		definition, _ := definition.(map[string]any)
+		switch definition["type"] {
+		case "basic":
+			scheme := map[string]any{"type": "http", "scheme": "basic"}
+			copyKeys(scheme, definition, "description")
+			result[name] = scheme
+		case "apiKey":
+			scheme := map[string]any{}
+			copyKeys(scheme, definition, "type", "name", "in", "description")
+			result[name] = scheme
+		}
+	}
+	return result
+}
+
+// rewriteRefs заменяет ссылки #/definitions/ на #/components/schemas/
+func rewriteRefs(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, v := range value {
+			if ref, ok := v.(string); ok && key == "$ref" {
+				value[key] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+				continue
+			}
+			value[key] = rewriteRefs(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = rewriteRefs(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// isRef сообщает, что схема - ссылка на схему name
+func isRef(schema any, name string) bool {
+	m, _ := schema.(map[string]any)
+	ref, _ := m["$ref"].(string)
+	return ref == "#/components/schemas/"+name
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// stringList возвращает список строк или fallback, если список пуст
+func stringList(value any, fallback ...string) []string {
+	list, _ := value.([]any)
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		return fallback
+	}
+	return result
+}
+
+func copyKeys(dst, src map[string]any, keys ...string) {
+	for _, key := range keys {
+		if value, ok := src[key]; ok {
+			dst[key] = value
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package prompts
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Переменные, доступные в шаблонах системного промпта
+const (
+	VarTest       = "test"        // название теста
+	VarQuestion   = "question"    // текст вопроса
+	VarPosition   = "position"    // номер вопроса в попытке
+	VarSubject    = "subject"     // предмет, задается при назначении шаблона тесту
+	VarLanguage   = "language"    // язык ответов ассистента, задается при назначении шаблона тесту
+	VarHelpPolicy = "help_policy" // правила помощи теста или вопроса
+)
+
+// maxTemplateLength ограничивает размер шаблона
+const maxTemplateLength = 8000
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+var knownVariables = map[string]bool{
+	VarTest:       true,
+	VarQuestion:   true,
+	VarPosition:   true,
+	VarSubject:    true,
+	VarLanguage:   true,
+	VarHelpPolicy: true,
+}
+
+// Validate проверяет шаблон: непустой, не слишком длинный и без неизвестных переменных
+func Validate(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("prompt template is empty")
+	}
+	if len(text) > maxTemplateLength {
+		return errors.New("prompt template is too long")
+	}
+
+	for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+		if !knownVariables[match[1]] {
+			return errors.New("unknown prompt variable: " + match[1])
+		}
+	}
+	return nil
+}
+
+// Render подставляет значения переменных вида {{question}}; переменные без значения заменяются пустой строкой
+func Render(text string, vars map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		return vars[name]
+	})
+}
@@ -0,0 +1,789 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: geek/v1/attempts.proto
+
+package geekv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Attempt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TestId        uint64                 `protobuf:"varint,3,opt,name=test_id,json=testId,proto3" json:"test_id,omitempty"`
+	GroupId       uint64                 `protobuf:"varint,4,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // команда для командной попытки, 0 = личная попытка
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`                   // started, grading (ждет ручной проверки) или submitted
+	QuestionIds   []uint64               `protobuf:"varint,6,rep,packed,name=question_ids,json=questionIds,proto3" json:"question_ids,omitempty"`
+	Answers       []*Answer              `protobuf:"bytes,7,rep,name=answers,proto3" json:"answers,omitempty"` // по позициям вопросов
+	Result        uint64                 `protobuf:"varint,8,opt,name=result,proto3" json:"result,omitempty"`
+	Late          bool                   `protobuf:"varint,9,opt,name=late,proto3" json:"late,omitempty"`                    // сдана в льготный период после дедлайна
+	Penalty       uint64                 `protobuf:"varint,10,opt,name=penalty,proto3" json:"penalty,omitempty"`             // сколько баллов снято за опоздание
+	AiCost        uint64                 `protobuf:"varint,11,opt,name=ai_cost,json=aiCost,proto3" json:"ai_cost,omitempty"` // сколько баллов снято за подсказки ассистента
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"` // не задано, пока попытка не сдана
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attempt) Reset() {
+	*x = Attempt{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attempt) ProtoMessage() {}
+
+func (x *Attempt) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attempt.ProtoReflect.Descriptor instead.
+func (*Attempt) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Attempt) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Attempt) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Attempt) GetTestId() uint64 {
+	if x != nil {
+		return x.TestId
+	}
+	return 0
+}
+
+func (x *Attempt) GetGroupId() uint64 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *Attempt) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Attempt) GetQuestionIds() []uint64 {
+	if x != nil {
+		return x.QuestionIds
+	}
+	return nil
+}
+
+func (x *Attempt) GetAnswers() []*Answer {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+func (x *Attempt) GetResult() uint64 {
+	if x != nil {
+		return x.Result
+	}
+	return 0
+}
+
+func (x *Attempt) GetLate() bool {
+	if x != nil {
+		return x.Late
+	}
+	return false
+}
+
+func (x *Attempt) GetPenalty() uint64 {
+	if x != nil {
+		return x.Penalty
+	}
+	return 0
+}
+
+func (x *Attempt) GetAiCost() uint64 {
+	if x != nil {
+		return x.AiCost
+	}
+	return 0
+}
+
+func (x *Attempt) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Attempt) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+type Answer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	QuestionId    uint64                 `protobuf:"varint,2,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Right         bool                   `protobuf:"varint,4,opt,name=right,proto3" json:"right,omitempty"`
+	Score         uint64                 `protobuf:"varint,5,opt,name=score,proto3" json:"score,omitempty"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`                 // graded или pending_review
+	Similarity    *float64               `protobuf:"fixed64,7,opt,name=similarity,proto3,oneof" json:"similarity,omitempty"` // близость к эталонному ответу в режиме semantic
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Answer) Reset() {
+	*x = Answer{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Answer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Answer) ProtoMessage() {}
+
+func (x *Answer) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Answer.ProtoReflect.Descriptor instead.
+func (*Answer) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Answer) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Answer) GetQuestionId() uint64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+func (x *Answer) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Answer) GetRight() bool {
+	if x != nil {
+		return x.Right
+	}
+	return false
+}
+
+func (x *Answer) GetScore() uint64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Answer) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Answer) GetSimilarity() float64 {
+	if x != nil && x.Similarity != nil {
+		return *x.Similarity
+	}
+	return 0
+}
+
+func (x *Answer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// Question - вопрос в том виде, в котором его видит студент, без эталонного ответа
+type Question struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Position      uint64                 `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Text          string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	MaxScore      uint64                 `protobuf:"varint,5,opt,name=max_score,json=maxScore,proto3" json:"max_score,omitempty"`
+	GradingMode   string                 `protobuf:"bytes,6,opt,name=grading_mode,json=gradingMode,proto3" json:"grading_mode,omitempty"` // auto, manual или semantic
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Question) Reset() {
+	*x = Question{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Question) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Question) ProtoMessage() {}
+
+func (x *Question) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Question.ProtoReflect.Descriptor instead.
+func (*Question) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Question) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Question) GetPosition() uint64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Question) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Question) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Question) GetMaxScore() uint64 {
+	if x != nil {
+		return x.MaxScore
+	}
+	return 0
+}
+
+func (x *Question) GetGradingMode() string {
+	if x != nil {
+		return x.GradingMode
+	}
+	return ""
+}
+
+type StartAttemptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TestId        uint64                 `protobuf:"varint,1,opt,name=test_id,json=testId,proto3" json:"test_id,omitempty"`
+	AccessCode    string                 `protobuf:"bytes,2,opt,name=access_code,json=accessCode,proto3" json:"access_code,omitempty"` // не нужен для тестов со свободным доступом и тестов, назначенных классу
+	GroupId       uint64                 `protobuf:"varint,3,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`         // обязателен для командных тестов
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartAttemptRequest) Reset() {
+	*x = StartAttemptRequest{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartAttemptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartAttemptRequest) ProtoMessage() {}
+
+func (x *StartAttemptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartAttemptRequest.ProtoReflect.Descriptor instead.
+func (*StartAttemptRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StartAttemptRequest) GetTestId() uint64 {
+	if x != nil {
+		return x.TestId
+	}
+	return 0
+}
+
+func (x *StartAttemptRequest) GetAccessCode() string {
+	if x != nil {
+		return x.AccessCode
+	}
+	return ""
+}
+
+func (x *StartAttemptRequest) GetGroupId() uint64 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+type GetAttemptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttemptId     uint64                 `protobuf:"varint,1,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAttemptRequest) Reset() {
+	*x = GetAttemptRequest{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAttemptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAttemptRequest) ProtoMessage() {}
+
+func (x *GetAttemptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAttemptRequest.ProtoReflect.Descriptor instead.
+func (*GetAttemptRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetAttemptRequest) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+type ListQuestionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttemptId     uint64                 `protobuf:"varint,1,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuestionsRequest) Reset() {
+	*x = ListQuestionsRequest{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuestionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuestionsRequest) ProtoMessage() {}
+
+func (x *ListQuestionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuestionsRequest.ProtoReflect.Descriptor instead.
+func (*ListQuestionsRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListQuestionsRequest) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+type ListQuestionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Questions     []*Question            `protobuf:"bytes,1,rep,name=questions,proto3" json:"questions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuestionsResponse) Reset() {
+	*x = ListQuestionsResponse{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuestionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuestionsResponse) ProtoMessage() {}
+
+func (x *ListQuestionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuestionsResponse.ProtoReflect.Descriptor instead.
+func (*ListQuestionsResponse) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListQuestionsResponse) GetQuestions() []*Question {
+	if x != nil {
+		return x.Questions
+	}
+	return nil
+}
+
+type SubmitAnswerRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AttemptId        uint64                 `protobuf:"varint,1,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	QuestionPosition uint64                 `protobuf:"varint,2,opt,name=question_position,json=questionPosition,proto3" json:"question_position,omitempty"` // с 1
+	Text             string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SubmitAnswerRequest) Reset() {
+	*x = SubmitAnswerRequest{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitAnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAnswerRequest) ProtoMessage() {}
+
+func (x *SubmitAnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAnswerRequest.ProtoReflect.Descriptor instead.
+func (*SubmitAnswerRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SubmitAnswerRequest) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+func (x *SubmitAnswerRequest) GetQuestionPosition() uint64 {
+	if x != nil {
+		return x.QuestionPosition
+	}
+	return 0
+}
+
+func (x *SubmitAnswerRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type SubmitAttemptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttemptId     uint64                 `protobuf:"varint,1,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitAttemptRequest) Reset() {
+	*x = SubmitAttemptRequest{}
+	mi := &file_geek_v1_attempts_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitAttemptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAttemptRequest) ProtoMessage() {}
+
+func (x *SubmitAttemptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_attempts_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAttemptRequest.ProtoReflect.Descriptor instead.
+func (*SubmitAttemptRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_attempts_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SubmitAttemptRequest) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+var File_geek_v1_attempts_proto protoreflect.FileDescriptor
+
+const file_geek_v1_attempts_proto_rawDesc = "" +
+	"\n" +
+	"\x16geek/v1/attempts.proto\x12\ageek.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa3\x03\n" +
+	"\aAttempt\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x17\n" +
+	"\atest_id\x18\x03 \x01(\x04R\x06testId\x12\x19\n" +
+	"\bgroup_id\x18\x04 \x01(\x04R\agroupId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12!\n" +
+	"\fquestion_ids\x18\x06 \x03(\x04R\vquestionIds\x12)\n" +
+	"\aanswers\x18\a \x03(\v2\x0f.geek.v1.AnswerR\aanswers\x12\x16\n" +
+	"\x06result\x18\b \x01(\x04R\x06result\x12\x12\n" +
+	"\x04late\x18\t \x01(\bR\x04late\x12\x18\n" +
+	"\apenalty\x18\n" +
+	" \x01(\x04R\apenalty\x12\x17\n" +
+	"\aai_cost\x18\v \x01(\x04R\x06aiCost\x129\n" +
+	"\n" +
+	"started_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"\x80\x02\n" +
+	"\x06Answer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1f\n" +
+	"\vquestion_id\x18\x02 \x01(\x04R\n" +
+	"questionId\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x14\n" +
+	"\x05right\x18\x04 \x01(\bR\x05right\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x04R\x05score\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12#\n" +
+	"\n" +
+	"similarity\x18\a \x01(\x01H\x00R\n" +
+	"similarity\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\r\n" +
+	"\v_similarity\"\x9e\x01\n" +
+	"\bQuestion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x04R\bposition\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04text\x18\x04 \x01(\tR\x04text\x12\x1b\n" +
+	"\tmax_score\x18\x05 \x01(\x04R\bmaxScore\x12!\n" +
+	"\fgrading_mode\x18\x06 \x01(\tR\vgradingMode\"j\n" +
+	"\x13StartAttemptRequest\x12\x17\n" +
+	"\atest_id\x18\x01 \x01(\x04R\x06testId\x12\x1f\n" +
+	"\vaccess_code\x18\x02 \x01(\tR\n" +
+	"accessCode\x12\x19\n" +
+	"\bgroup_id\x18\x03 \x01(\x04R\agroupId\"2\n" +
+	"\x11GetAttemptRequest\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x01 \x01(\x04R\tattemptId\"5\n" +
+	"\x14ListQuestionsRequest\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x01 \x01(\x04R\tattemptId\"H\n" +
+	"\x15ListQuestionsResponse\x12/\n" +
+	"\tquestions\x18\x01 \x03(\v2\x11.geek.v1.QuestionR\tquestions\"u\n" +
+	"\x13SubmitAnswerRequest\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x01 \x01(\x04R\tattemptId\x12+\n" +
+	"\x11question_position\x18\x02 \x01(\x04R\x10questionPosition\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"5\n" +
+	"\x14SubmitAttemptRequest\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x01 \x01(\x04R\tattemptId2\xdd\x02\n" +
+	"\x0eAttemptService\x12>\n" +
+	"\fStartAttempt\x12\x1c.geek.v1.StartAttemptRequest\x1a\x10.geek.v1.Attempt\x12:\n" +
+	"\n" +
+	"GetAttempt\x12\x1a.geek.v1.GetAttemptRequest\x1a\x10.geek.v1.Attempt\x12N\n" +
+	"\rListQuestions\x12\x1d.geek.v1.ListQuestionsRequest\x1a\x1e.geek.v1.ListQuestionsResponse\x12=\n" +
+	"\fSubmitAnswer\x12\x1c.geek.v1.SubmitAnswerRequest\x1a\x0f.geek.v1.Answer\x12@\n" +
+	"\rSubmitAttempt\x12\x1d.geek.v1.SubmitAttemptRequest\x1a\x10.geek.v1.AttemptB Z\x1eGEEK_back/proto/geek/v1;geekv1b\x06proto3"
+
+var (
+	file_geek_v1_attempts_proto_rawDescOnce sync.Once
+	file_geek_v1_attempts_proto_rawDescData []byte
+)
+
+func file_geek_v1_attempts_proto_rawDescGZIP() []byte {
+	file_geek_v1_attempts_proto_rawDescOnce.Do(func() {
+		file_geek_v1_attempts_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_geek_v1_attempts_proto_rawDesc), len(file_geek_v1_attempts_proto_rawDesc)))
+	})
+	return file_geek_v1_attempts_proto_rawDescData
+}
+
+var file_geek_v1_attempts_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_geek_v1_attempts_proto_goTypes = []any{
+	(*Attempt)(nil),               // 0: geek.v1.Attempt
+	(*Answer)(nil),                // 1: geek.v1.Answer
+	(*Question)(nil),              // 2: geek.v1.Question
+	(*StartAttemptRequest)(nil),   // 3: geek.v1.StartAttemptRequest
+	(*GetAttemptRequest)(nil),     // 4: geek.v1.GetAttemptRequest
+	(*ListQuestionsRequest)(nil),  // 5: geek.v1.ListQuestionsRequest
+	(*ListQuestionsResponse)(nil), // 6: geek.v1.ListQuestionsResponse
+	(*SubmitAnswerRequest)(nil),   // 7: geek.v1.SubmitAnswerRequest
+	(*SubmitAttemptRequest)(nil),  // 8: geek.v1.SubmitAttemptRequest
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_geek_v1_attempts_proto_depIdxs = []int32{
+	1,  // 0: geek.v1.Attempt.answers:type_name -> geek.v1.Answer
+	9,  // 1: geek.v1.Attempt.started_at:type_name -> google.protobuf.Timestamp
+	9,  // 2: geek.v1.Attempt.finished_at:type_name -> google.protobuf.Timestamp
+	9,  // 3: geek.v1.Answer.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: geek.v1.ListQuestionsResponse.questions:type_name -> geek.v1.Question
+	3,  // 5: geek.v1.AttemptService.StartAttempt:input_type -> geek.v1.StartAttemptRequest
+	4,  // 6: geek.v1.AttemptService.GetAttempt:input_type -> geek.v1.GetAttemptRequest
+	5,  // 7: geek.v1.AttemptService.ListQuestions:input_type -> geek.v1.ListQuestionsRequest
+	7,  // 8: geek.v1.AttemptService.SubmitAnswer:input_type -> geek.v1.SubmitAnswerRequest
+	8,  // 9: geek.v1.AttemptService.SubmitAttempt:input_type -> geek.v1.SubmitAttemptRequest
+	0,  // 10: geek.v1.AttemptService.StartAttempt:output_type -> geek.v1.Attempt
+	0,  // 11: geek.v1.AttemptService.GetAttempt:output_type -> geek.v1.Attempt
+	6,  // 12: geek.v1.AttemptService.ListQuestions:output_type -> geek.v1.ListQuestionsResponse
+	1,  // 13: geek.v1.AttemptService.SubmitAnswer:output_type -> geek.v1.Answer
+	0,  // 14: geek.v1.AttemptService.SubmitAttempt:output_type -> geek.v1.Attempt
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_geek_v1_attempts_proto_init() }
+func file_geek_v1_attempts_proto_init() {
+	if File_geek_v1_attempts_proto != nil {
+		return
+	}
+	file_geek_v1_attempts_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_geek_v1_attempts_proto_rawDesc), len(file_geek_v1_attempts_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_geek_v1_attempts_proto_goTypes,
+		DependencyIndexes: file_geek_v1_attempts_proto_depIdxs,
+		MessageInfos:      file_geek_v1_attempts_proto_msgTypes,
+	}.Build()
+	File_geek_v1_attempts_proto = out.File
+	file_geek_v1_attempts_proto_goTypes = nil
+	file_geek_v1_attempts_proto_depIdxs = nil
+}
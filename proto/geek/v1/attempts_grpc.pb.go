@@ -0,0 +1,289 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: geek/v1/attempts.proto
+
+package geekv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AttemptService_StartAttempt_FullMethodName  = "/geek.v1.AttemptService/StartAttempt"
+	AttemptService_GetAttempt_FullMethodName    = "/geek.v1.AttemptService/GetAttempt"
+	AttemptService_ListQuestions_FullMethodName = "/geek.v1.AttemptService/ListQuestions"
+	AttemptService_SubmitAnswer_FullMethodName  = "/geek.v1.AttemptService/SubmitAnswer"
+	AttemptService_SubmitAttempt_FullMethodName = "/geek.v1.AttemptService/SubmitAttempt"
+)
+
+// AttemptServiceClient is the client API for AttemptService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AttemptService - прохождение теста: начало попытки, вопросы, ответы и сдача.
+// Правила те же, что в HTTP API: коды доступа, пауза между попытками, дедлайн и командные тесты
+type AttemptServiceClient interface {
+	// StartAttempt начинает попытку теста
+	StartAttempt(ctx context.Context, in *StartAttemptRequest, opts ...grpc.CallOption) (*Attempt, error)
+	// GetAttempt возвращает попытку участнику
+	GetAttempt(ctx context.Context, in *GetAttemptRequest, opts ...grpc.CallOption) (*Attempt, error)
+	// ListQuestions возвращает вопросы попытки в порядке показа студенту
+	ListQuestions(ctx context.Context, in *ListQuestionsRequest, opts ...grpc.CallOption) (*ListQuestionsResponse, error)
+	// SubmitAnswer сохраняет и оценивает ответ на вопрос
+	SubmitAnswer(ctx context.Context, in *SubmitAnswerRequest, opts ...grpc.CallOption) (*Answer, error)
+	// SubmitAttempt сдает попытку
+	SubmitAttempt(ctx context.Context, in *SubmitAttemptRequest, opts ...grpc.CallOption) (*Attempt, error)
+}
+
+type attemptServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAttemptServiceClient(cc grpc.ClientConnInterface) AttemptServiceClient {
+	return &attemptServiceClient{cc}
+}
+
+func (c *attemptServiceClient) StartAttempt(ctx context.Context, in *StartAttemptRequest, opts ...grpc.CallOption) (*Attempt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Attempt)
+	err := c.cc.Invoke(ctx, AttemptService_StartAttempt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attemptServiceClient) GetAttempt(ctx context.Context, in *GetAttemptRequest, opts ...grpc.CallOption) (*Attempt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Attempt)
+	err := c.cc.Invoke(ctx, AttemptService_GetAttempt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attemptServiceClient) ListQuestions(ctx context.Context, in *ListQuestionsRequest, opts ...grpc.CallOption) (*ListQuestionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQuestionsResponse)
+	err := c.cc.Invoke(ctx, AttemptService_ListQuestions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attemptServiceClient) SubmitAnswer(ctx context.Context, in *SubmitAnswerRequest, opts ...grpc.CallOption) (*Answer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Answer)
+	err := c.cc.Invoke(ctx, AttemptService_SubmitAnswer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attemptServiceClient) SubmitAttempt(ctx context.Context, in *SubmitAttemptRequest, opts ...grpc.CallOption) (*Attempt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Attempt)
+	err := c.cc.Invoke(ctx, AttemptService_SubmitAttempt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AttemptServiceServer is the server API for AttemptService service.
+// All implementations must embed UnimplementedAttemptServiceServer
+// for forward compatibility.
+//
+// AttemptService - прохождение теста: начало попытки, вопросы, ответы и сдача.
+// Правила те же, что в HTTP API: коды доступа, пауза между попытками, дедлайн и командные тесты
+type AttemptServiceServer interface {
+	// StartAttempt начинает попытку теста
+	StartAttempt(context.Context, *StartAttemptRequest) (*Attempt, error)
+	// GetAttempt возвращает попытку участнику
+	GetAttempt(context.Context, *GetAttemptRequest) (*Attempt, error)
+	// ListQuestions возвращает вопросы попытки в порядке показа студенту
+	ListQuestions(context.Context, *ListQuestionsRequest) (*ListQuestionsResponse, error)
+	// SubmitAnswer сохраняет и оценивает ответ на вопрос
+	SubmitAnswer(context.Context, *SubmitAnswerRequest) (*Answer, error)
+	// SubmitAttempt сдает попытку
+	SubmitAttempt(context.Context, *SubmitAttemptRequest) (*Attempt, error)
+	mustEmbedUnimplementedAttemptServiceServer()
+}
+
+// UnimplementedAttemptServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAttemptServiceServer struct{}
+
+func (UnimplementedAttemptServiceServer) StartAttempt(context.Context, *StartAttemptRequest) (*Attempt, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartAttempt not implemented")
+}
+func (UnimplementedAttemptServiceServer) GetAttempt(context.Context, *GetAttemptRequest) (*Attempt, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAttempt not implemented")
+}
+func (UnimplementedAttemptServiceServer) ListQuestions(context.Context, *ListQuestionsRequest) (*ListQuestionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListQuestions not implemented")
+}
+func (UnimplementedAttemptServiceServer) SubmitAnswer(context.Context, *SubmitAnswerRequest) (*Answer, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitAnswer not implemented")
+}
+func (UnimplementedAttemptServiceServer) SubmitAttempt(context.Context, *SubmitAttemptRequest) (*Attempt, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitAttempt not implemented")
+}
+func (UnimplementedAttemptServiceServer) mustEmbedUnimplementedAttemptServiceServer() {}
+func (UnimplementedAttemptServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeAttemptServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AttemptServiceServer will
+// result in compilation errors.
+type UnsafeAttemptServiceServer interface {
+	mustEmbedUnimplementedAttemptServiceServer()
+}
+
+func RegisterAttemptServiceServer(s grpc.ServiceRegistrar, srv AttemptServiceServer) {
+	// If the following call panics, it indicates UnimplementedAttemptServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AttemptService_ServiceDesc, srv)
+}
+
+func _AttemptService_StartAttempt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartAttemptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttemptServiceServer).StartAttempt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttemptService_StartAttempt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttemptServiceServer).StartAttempt(ctx, req.(*StartAttemptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttemptService_GetAttempt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAttemptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttemptServiceServer).GetAttempt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttemptService_GetAttempt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttemptServiceServer).GetAttempt(ctx, req.(*GetAttemptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttemptService_ListQuestions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQuestionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttemptServiceServer).ListQuestions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttemptService_ListQuestions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttemptServiceServer).ListQuestions(ctx, req.(*ListQuestionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttemptService_SubmitAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitAnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttemptServiceServer).SubmitAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttemptService_SubmitAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttemptServiceServer).SubmitAnswer(ctx, req.(*SubmitAnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttemptService_SubmitAttempt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitAttemptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttemptServiceServer).SubmitAttempt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttemptService_SubmitAttempt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttemptServiceServer).SubmitAttempt(ctx, req.(*SubmitAttemptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AttemptService_ServiceDesc is the grpc.ServiceDesc for AttemptService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AttemptService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "geek.v1.AttemptService",
+	HandlerType: (*AttemptServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartAttempt",
+			Handler:    _AttemptService_StartAttempt_Handler,
+		},
+		{
+			MethodName: "GetAttempt",
+			Handler:    _AttemptService_GetAttempt_Handler,
+		},
+		{
+			MethodName: "ListQuestions",
+			Handler:    _AttemptService_ListQuestions_Handler,
+		},
+		{
+			MethodName: "SubmitAnswer",
+			Handler:    _AttemptService_SubmitAnswer_Handler,
+		},
+		{
+			MethodName: "SubmitAttempt",
+			Handler:    _AttemptService_SubmitAttempt_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "geek/v1/attempts.proto",
+}
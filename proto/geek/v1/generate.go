@@ -0,0 +1,5 @@
+// Package geekv1 - gRPC-сервисы для внутренних инструментов и сервисов: авторизация, попытки и ручная проверка.
+// *.pb.go генерируются из *.proto в этом каталоге; после изменения схемы выполните go generate
+package geekv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative geek/v1/auth.proto geek/v1/attempts.proto geek/v1/grading.proto
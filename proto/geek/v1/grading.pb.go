@@ -0,0 +1,544 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: geek/v1/grading.proto
+
+package geekv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReviewItem struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AttemptId        uint64                 `protobuf:"varint,2,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	TestId           uint64                 `protobuf:"varint,3,opt,name=test_id,json=testId,proto3" json:"test_id,omitempty"`
+	QuestionId       uint64                 `protobuf:"varint,4,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	QuestionPosition uint64                 `protobuf:"varint,5,opt,name=question_position,json=questionPosition,proto3" json:"question_position,omitempty"`
+	QuestionText     string                 `protobuf:"bytes,6,opt,name=question_text,json=questionText,proto3" json:"question_text,omitempty"`
+	ReferenceAnswer  string                 `protobuf:"bytes,7,opt,name=reference_answer,json=referenceAnswer,proto3" json:"reference_answer,omitempty"`
+	AnswerText       string                 `protobuf:"bytes,8,opt,name=answer_text,json=answerText,proto3" json:"answer_text,omitempty"`
+	MaxScore         uint64                 `protobuf:"varint,9,opt,name=max_score,json=maxScore,proto3" json:"max_score,omitempty"`
+	Reason           string                 `protobuf:"bytes,10,opt,name=reason,proto3" json:"reason,omitempty"`                         // manual или low_confidence
+	Status           string                 `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`                         // pending, claimed или graded
+	ClaimedBy        uint64                 `protobuf:"varint,12,opt,name=claimed_by,json=claimedBy,proto3" json:"claimed_by,omitempty"` // 0 = не закреплен
+	ClaimedAt        *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=claimed_at,json=claimedAt,proto3" json:"claimed_at,omitempty"`
+	GradedBy         uint64                 `protobuf:"varint,14,opt,name=graded_by,json=gradedBy,proto3" json:"graded_by,omitempty"` // 0 = не проверен
+	GradedAt         *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=graded_at,json=gradedAt,proto3" json:"graded_at,omitempty"`
+	Score            uint64                 `protobuf:"varint,16,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ReviewItem) Reset() {
+	*x = ReviewItem{}
+	mi := &file_geek_v1_grading_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewItem) ProtoMessage() {}
+
+func (x *ReviewItem) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_grading_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewItem.ProtoReflect.Descriptor instead.
+func (*ReviewItem) Descriptor() ([]byte, []int) {
+	return file_geek_v1_grading_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReviewItem) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetAttemptId() uint64 {
+	if x != nil {
+		return x.AttemptId
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetTestId() uint64 {
+	if x != nil {
+		return x.TestId
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetQuestionId() uint64 {
+	if x != nil {
+		return x.QuestionId
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetQuestionPosition() uint64 {
+	if x != nil {
+		return x.QuestionPosition
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetQuestionText() string {
+	if x != nil {
+		return x.QuestionText
+	}
+	return ""
+}
+
+func (x *ReviewItem) GetReferenceAnswer() string {
+	if x != nil {
+		return x.ReferenceAnswer
+	}
+	return ""
+}
+
+func (x *ReviewItem) GetAnswerText() string {
+	if x != nil {
+		return x.AnswerText
+	}
+	return ""
+}
+
+func (x *ReviewItem) GetMaxScore() uint64 {
+	if x != nil {
+		return x.MaxScore
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ReviewItem) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ReviewItem) GetClaimedBy() uint64 {
+	if x != nil {
+		return x.ClaimedBy
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetClaimedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClaimedAt
+	}
+	return nil
+}
+
+func (x *ReviewItem) GetGradedBy() uint64 {
+	if x != nil {
+		return x.GradedBy
+	}
+	return 0
+}
+
+func (x *ReviewItem) GetGradedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GradedAt
+	}
+	return nil
+}
+
+func (x *ReviewItem) GetScore() uint64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type ListReviewItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TestId        uint64                 `protobuf:"varint,1,opt,name=test_id,json=testId,proto3" json:"test_id,omitempty"` // 0 = все тесты
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                // pending или claimed, пусто = все
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                // manual или low_confidence, пусто = все
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReviewItemsRequest) Reset() {
+	*x = ListReviewItemsRequest{}
+	mi := &file_geek_v1_grading_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReviewItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReviewItemsRequest) ProtoMessage() {}
+
+func (x *ListReviewItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_grading_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReviewItemsRequest.ProtoReflect.Descriptor instead.
+func (*ListReviewItemsRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_grading_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListReviewItemsRequest) GetTestId() uint64 {
+	if x != nil {
+		return x.TestId
+	}
+	return 0
+}
+
+func (x *ListReviewItemsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListReviewItemsRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ListReviewItemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ReviewItem          `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReviewItemsResponse) Reset() {
+	*x = ListReviewItemsResponse{}
+	mi := &file_geek_v1_grading_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReviewItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReviewItemsResponse) ProtoMessage() {}
+
+func (x *ListReviewItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_grading_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReviewItemsResponse.ProtoReflect.Descriptor instead.
+func (*ListReviewItemsResponse) Descriptor() ([]byte, []int) {
+	return file_geek_v1_grading_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListReviewItemsResponse) GetItems() []*ReviewItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ClaimReviewItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        uint64                 `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimReviewItemRequest) Reset() {
+	*x = ClaimReviewItemRequest{}
+	mi := &file_geek_v1_grading_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimReviewItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimReviewItemRequest) ProtoMessage() {}
+
+func (x *ClaimReviewItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_grading_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimReviewItemRequest.ProtoReflect.Descriptor instead.
+func (*ClaimReviewItemRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_grading_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClaimReviewItemRequest) GetItemId() uint64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+type ReleaseReviewItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        uint64                 `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReviewItemRequest) Reset() {
+	*x = ReleaseReviewItemRequest{}
+	mi := &file_geek_v1_grading_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReviewItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReviewItemRequest) ProtoMessage() {}
+
+func (x *ReleaseReviewItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_grading_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReviewItemRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseReviewItemRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_grading_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReleaseReviewItemRequest) GetItemId() uint64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+type GradeReviewItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        uint64                 `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Score         uint64                 `protobuf:"varint,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GradeReviewItemRequest) Reset() {
+	*x = GradeReviewItemRequest{}
+	mi := &file_geek_v1_grading_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GradeReviewItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GradeReviewItemRequest) ProtoMessage() {}
+
+func (x *GradeReviewItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geek_v1_grading_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GradeReviewItemRequest.ProtoReflect.Descriptor instead.
+func (*GradeReviewItemRequest) Descriptor() ([]byte, []int) {
+	return file_geek_v1_grading_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GradeReviewItemRequest) GetItemId() uint64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *GradeReviewItemRequest) GetScore() uint64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+var File_geek_v1_grading_proto protoreflect.FileDescriptor
+
+const file_geek_v1_grading_proto_rawDesc = "" +
+	"\n" +
+	"\x15geek/v1/grading.proto\x12\ageek.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa6\x04\n" +
+	"\n" +
+	"ReviewItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x02 \x01(\x04R\tattemptId\x12\x17\n" +
+	"\atest_id\x18\x03 \x01(\x04R\x06testId\x12\x1f\n" +
+	"\vquestion_id\x18\x04 \x01(\x04R\n" +
+	"questionId\x12+\n" +
+	"\x11question_position\x18\x05 \x01(\x04R\x10questionPosition\x12#\n" +
+	"\rquestion_text\x18\x06 \x01(\tR\fquestionText\x12)\n" +
+	"\x10reference_answer\x18\a \x01(\tR\x0freferenceAnswer\x12\x1f\n" +
+	"\vanswer_text\x18\b \x01(\tR\n" +
+	"answerText\x12\x1b\n" +
+	"\tmax_score\x18\t \x01(\x04R\bmaxScore\x12\x16\n" +
+	"\x06reason\x18\n" +
+	" \x01(\tR\x06reason\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"claimed_by\x18\f \x01(\x04R\tclaimedBy\x129\n" +
+	"\n" +
+	"claimed_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tclaimedAt\x12\x1b\n" +
+	"\tgraded_by\x18\x0e \x01(\x04R\bgradedBy\x127\n" +
+	"\tgraded_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\bgradedAt\x12\x14\n" +
+	"\x05score\x18\x10 \x01(\x04R\x05score\"a\n" +
+	"\x16ListReviewItemsRequest\x12\x17\n" +
+	"\atest_id\x18\x01 \x01(\x04R\x06testId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"D\n" +
+	"\x17ListReviewItemsResponse\x12)\n" +
+	"\x05items\x18\x01 \x03(\v2\x13.geek.v1.ReviewItemR\x05items\"1\n" +
+	"\x16ClaimReviewItemRequest\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x04R\x06itemId\"3\n" +
+	"\x18ReleaseReviewItemRequest\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x04R\x06itemId\"G\n" +
+	"\x16GradeReviewItemRequest\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x04R\x06itemId\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x04R\x05score2\xc5\x02\n" +
+	"\x0eGradingService\x12T\n" +
+	"\x0fListReviewItems\x12\x1f.geek.v1.ListReviewItemsRequest\x1a .geek.v1.ListReviewItemsResponse\x12G\n" +
+	"\x0fClaimReviewItem\x12\x1f.geek.v1.ClaimReviewItemRequest\x1a\x13.geek.v1.ReviewItem\x12K\n" +
+	"\x11ReleaseReviewItem\x12!.geek.v1.ReleaseReviewItemRequest\x1a\x13.geek.v1.ReviewItem\x12G\n" +
+	"\x0fGradeReviewItem\x12\x1f.geek.v1.GradeReviewItemRequest\x1a\x13.geek.v1.ReviewItemB Z\x1eGEEK_back/proto/geek/v1;geekv1b\x06proto3"
+
+var (
+	file_geek_v1_grading_proto_rawDescOnce sync.Once
+	file_geek_v1_grading_proto_rawDescData []byte
+)
+
+func file_geek_v1_grading_proto_rawDescGZIP() []byte {
+	file_geek_v1_grading_proto_rawDescOnce.Do(func() {
+		file_geek_v1_grading_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_geek_v1_grading_proto_rawDesc), len(file_geek_v1_grading_proto_rawDesc)))
+	})
+	return file_geek_v1_grading_proto_rawDescData
+}
+
+var file_geek_v1_grading_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_geek_v1_grading_proto_goTypes = []any{
+	(*ReviewItem)(nil),               // 0: geek.v1.ReviewItem
+	(*ListReviewItemsRequest)(nil),   // 1: geek.v1.ListReviewItemsRequest
+	(*ListReviewItemsResponse)(nil),  // 2: geek.v1.ListReviewItemsResponse
+	(*ClaimReviewItemRequest)(nil),   // 3: geek.v1.ClaimReviewItemRequest
+	(*ReleaseReviewItemRequest)(nil), // 4: geek.v1.ReleaseReviewItemRequest
+	(*GradeReviewItemRequest)(nil),   // 5: geek.v1.GradeReviewItemRequest
+	(*timestamppb.Timestamp)(nil),    // 6: google.protobuf.Timestamp
+}
+var file_geek_v1_grading_proto_depIdxs = []int32{
+	6, // 0: geek.v1.ReviewItem.claimed_at:type_name -> google.protobuf.Timestamp
+	6, // 1: geek.v1.ReviewItem.graded_at:type_name -> google.protobuf.Timestamp
+	0, // 2: geek.v1.ListReviewItemsResponse.items:type_name -> geek.v1.ReviewItem
+	1, // 3: geek.v1.GradingService.ListReviewItems:input_type -> geek.v1.ListReviewItemsRequest
+	3, // 4: geek.v1.GradingService.ClaimReviewItem:input_type -> geek.v1.ClaimReviewItemRequest
+	4, // 5: geek.v1.GradingService.ReleaseReviewItem:input_type -> geek.v1.ReleaseReviewItemRequest
+	5, // 6: geek.v1.GradingService.GradeReviewItem:input_type -> geek.v1.GradeReviewItemRequest
+	2, // 7: geek.v1.GradingService.ListReviewItems:output_type -> geek.v1.ListReviewItemsResponse
+	0, // 8: geek.v1.GradingService.ClaimReviewItem:output_type -> geek.v1.ReviewItem
+	0, // 9: geek.v1.GradingService.ReleaseReviewItem:output_type -> geek.v1.ReviewItem
+	0, // 10: geek.v1.GradingService.GradeReviewItem:output_type -> geek.v1.ReviewItem
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_geek_v1_grading_proto_init() }
+func file_geek_v1_grading_proto_init() {
+	if File_geek_v1_grading_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_geek_v1_grading_proto_rawDesc), len(file_geek_v1_grading_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_geek_v1_grading_proto_goTypes,
+		DependencyIndexes: file_geek_v1_grading_proto_depIdxs,
+		MessageInfos:      file_geek_v1_grading_proto_msgTypes,
+	}.Build()
+	File_geek_v1_grading_proto = out.File
+	file_geek_v1_grading_proto_goTypes = nil
+	file_geek_v1_grading_proto_depIdxs = nil
+}
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: geek/v1/grading.proto
+
+package geekv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GradingService_ListReviewItems_FullMethodName   = "/geek.v1.GradingService/ListReviewItems"
+	GradingService_ClaimReviewItem_FullMethodName   = "/geek.v1.GradingService/ClaimReviewItem"
+	GradingService_ReleaseReviewItem_FullMethodName = "/geek.v1.GradingService/ReleaseReviewItem"
+	GradingService_GradeReviewItem_FullMethodName   = "/geek.v1.GradingService/GradeReviewItem"
+)
+
+// GradingServiceClient is the client API for GradingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GradingService - очередь ручной проверки ответов. Доступен преподавателям и администраторам
+type GradingServiceClient interface {
+	// ListReviewItems возвращает ответы, ожидающие проверки, от старых к новым
+	ListReviewItems(ctx context.Context, in *ListReviewItemsRequest, opts ...grpc.CallOption) (*ListReviewItemsResponse, error)
+	// ClaimReviewItem закрепляет ответ за преподавателем, чтобы его не проверяли одновременно
+	ClaimReviewItem(ctx context.Context, in *ClaimReviewItemRequest, opts ...grpc.CallOption) (*ReviewItem, error)
+	// ReleaseReviewItem возвращает закрепленный ответ в очередь
+	ReleaseReviewItem(ctx context.Context, in *ReleaseReviewItemRequest, opts ...grpc.CallOption) (*ReviewItem, error)
+	// GradeReviewItem выставляет оценку; попытка завершается, когда проверены все ее ответы
+	GradeReviewItem(ctx context.Context, in *GradeReviewItemRequest, opts ...grpc.CallOption) (*ReviewItem, error)
+}
+
+type gradingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGradingServiceClient(cc grpc.ClientConnInterface) GradingServiceClient {
+	return &gradingServiceClient{cc}
+}
+
+func (c *gradingServiceClient) ListReviewItems(ctx context.Context, in *ListReviewItemsRequest, opts ...grpc.CallOption) (*ListReviewItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReviewItemsResponse)
+	err := c.cc.Invoke(ctx, GradingService_ListReviewItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gradingServiceClient) ClaimReviewItem(ctx context.Context, in *ClaimReviewItemRequest, opts ...grpc.CallOption) (*ReviewItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewItem)
+	err := c.cc.Invoke(ctx, GradingService_ClaimReviewItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gradingServiceClient) ReleaseReviewItem(ctx context.Context, in *ReleaseReviewItemRequest, opts ...grpc.CallOption) (*ReviewItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewItem)
+	err := c.cc.Invoke(ctx, GradingService_ReleaseReviewItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gradingServiceClient) GradeReviewItem(ctx context.Context, in *GradeReviewItemRequest, opts ...grpc.CallOption) (*ReviewItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewItem)
+	err := c.cc.Invoke(ctx, GradingService_GradeReviewItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GradingServiceServer is the server API for GradingService service.
+// All implementations must embed UnimplementedGradingServiceServer
+// for forward compatibility.
+//
+// GradingService - очередь ручной проверки ответов. Доступен преподавателям и администраторам
+type GradingServiceServer interface {
+	// ListReviewItems возвращает ответы, ожидающие проверки, от старых к новым
+	ListReviewItems(context.Context, *ListReviewItemsRequest) (*ListReviewItemsResponse, error)
+	// ClaimReviewItem закрепляет ответ за преподавателем, чтобы его не проверяли одновременно
+	ClaimReviewItem(context.Context, *ClaimReviewItemRequest) (*ReviewItem, error)
+	// ReleaseReviewItem возвращает закрепленный ответ в очередь
+	ReleaseReviewItem(context.Context, *ReleaseReviewItemRequest) (*ReviewItem, error)
+	// GradeReviewItem выставляет оценку; попытка завершается, когда проверены все ее ответы
+	GradeReviewItem(context.Context, *GradeReviewItemRequest) (*ReviewItem, error)
+	mustEmbedUnimplementedGradingServiceServer()
+}
+
+// UnimplementedGradingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGradingServiceServer struct{}
+
+func (UnimplementedGradingServiceServer) ListReviewItems(context.Context, *ListReviewItemsRequest) (*ListReviewItemsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListReviewItems not implemented")
+}
+func (UnimplementedGradingServiceServer) ClaimReviewItem(context.Context, *ClaimReviewItemRequest) (*ReviewItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method ClaimReviewItem not implemented")
+}
+func (UnimplementedGradingServiceServer) ReleaseReviewItem(context.Context, *ReleaseReviewItemRequest) (*ReviewItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReleaseReviewItem not implemented")
+}
+func (UnimplementedGradingServiceServer) GradeReviewItem(context.Context, *GradeReviewItemRequest) (*ReviewItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method GradeReviewItem not implemented")
+}
+func (UnimplementedGradingServiceServer) mustEmbedUnimplementedGradingServiceServer() {}
+func (UnimplementedGradingServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeGradingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GradingServiceServer will
+// result in compilation errors.
+type UnsafeGradingServiceServer interface {
+	mustEmbedUnimplementedGradingServiceServer()
+}
+
+func RegisterGradingServiceServer(s grpc.ServiceRegistrar, srv GradingServiceServer) {
+	// If the following call panics, it indicates UnimplementedGradingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GradingService_ServiceDesc, srv)
+}
+
+func _GradingService_ListReviewItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReviewItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GradingServiceServer).ListReviewItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GradingService_ListReviewItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GradingServiceServer).ListReviewItems(ctx, req.(*ListReviewItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GradingService_ClaimReviewItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimReviewItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GradingServiceServer).ClaimReviewItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GradingService_ClaimReviewItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GradingServiceServer).ClaimReviewItem(ctx, req.(*ClaimReviewItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GradingService_ReleaseReviewItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseReviewItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GradingServiceServer).ReleaseReviewItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GradingService_ReleaseReviewItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GradingServiceServer).ReleaseReviewItem(ctx, req.(*ReleaseReviewItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GradingService_GradeReviewItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GradeReviewItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GradingServiceServer).GradeReviewItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GradingService_GradeReviewItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GradingServiceServer).GradeReviewItem(ctx, req.(*GradeReviewItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GradingService_ServiceDesc is the grpc.ServiceDesc for GradingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GradingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "geek.v1.GradingService",
+	HandlerType: (*GradingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListReviewItems",
+			Handler:    _GradingService_ListReviewItems_Handler,
+		},
+		{
+			MethodName: "ClaimReviewItem",
+			Handler:    _GradingService_ClaimReviewItem_Handler,
+		},
+		{
+			MethodName: "ReleaseReviewItem",
+			Handler:    _GradingService_ReleaseReviewItem_Handler,
+		},
+		{
+			MethodName: "GradeReviewItem",
+			Handler:    _GradingService_GradeReviewItem_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "geek/v1/grading.proto",
+}
@@ -0,0 +1,89 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+// События попытки для потока SSE
+const (
+	EventAnswerAccepted   = "answer_accepted"   // ответ на вопрос сохранен
+	EventAttemptSubmitted = "attempt_submitted" // попытка сдана студентом или автоматически по истечении времени
+)
+
+// attemptBuffer - сколько событий попытки копится для медленного подписчика до отключения
+const attemptBuffer = 16
+
+// Subscription - подписка на события одной попытки, например поток SSE
+type Subscription struct {
+	attemptID uint64
+	sessionID string
+
+	events    chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Events возвращает события попытки
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done закрывается, когда подписку нужно завершить: после выхода из сессии или если подписчик не успевает читать
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// SubscribeAttempt подписывает на события попытки. Подписку нужно завершить через Unsubscribe
+func (h *Hub) SubscribeAttempt(attemptID uint64, sessionID string) *Subscription {
+	sub := &Subscription{
+		attemptID: attemptID,
+		sessionID: sessionID,
+		events:    make(chan Event, attemptBuffer),
+		done:      make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.attempts[attemptID] == nil {
+		h.attempts[attemptID] = make(map[*Subscription]bool)
+	}
+	h.attempts[attemptID][sub] = true
+	return sub
+}
+
+// Unsubscribe завершает подписку на события попытки
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	sub.close()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.attempts[sub.attemptID], sub)
+	if len(h.attempts[sub.attemptID]) == 0 {
+		delete(h.attempts, sub.attemptID)
+	}
+}
+
+// PublishAttempt отправляет событие подписчикам попытки. Не блокируется, как и Publish
+func (h *Hub) PublishAttempt(attemptID uint64, eventType string, data any) {
+	event := Event{Type: eventType, Data: data, At: time.Now().UTC()}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.attempts[attemptID] {
+		select {
+		case sub.events <- event:
+		default:
+			sub.close()
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Настройки соединения
+const (
+	writeWait      = 10 * time.Second  // сколько ждать записи сообщения клиенту
+	pongWait       = 60 * time.Second  // через сколько без ответа на ping соединение считается мертвым
+	pingPeriod     = pongWait * 9 / 10 // как часто отправлять ping
+	maxMessageSize = 4 << 10           // клиент ничего не присылает, кроме служебных сообщений
+	sendBuffer     = 32                // сколько событий копится для медленного клиента до отключения
+	closeGrace     = 100 * time.Millisecond
+)
+
+// Client - WebSocket-соединение пользователя в рамках сессии
+type Client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	userID    uint64
+	sessionID string
+	alive     func() bool // проверяет, что сессия еще действует
+
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Serve обслуживает соединение до его закрытия: отправляет события пользователя, отвечает на ping
+// и раз в pingPeriod проверяет через alive, что сессия не завершилась
+func (h *Hub) Serve(conn *websocket.Conn, userID uint64, sessionID string, alive func() bool) {
+	client := &Client{
+		hub:       h,
+		conn:      conn,
+		userID:    userID,
+		sessionID: sessionID,
+		alive:     alive,
+		send:      make(chan []byte, sendBuffer),
+		done:      make(chan struct{}),
+	}
+
+	h.register(client)
+	defer h.unregister(client)
+
+	go client.readPump()
+	client.writePump()
+}
+
+// close завершает соединение; безопасно вызывать несколько раз и из разных горутин
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// readPump читает сообщения клиента, чтобы получать pong и узнавать о закрытии соединения
+func (c *Client) readPump() {
+	defer c.close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump - единственная горутина, которая пишет в соединение
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if !c.alive() {
+				c.writeClose(websocket.ClosePolicyViolation, "session expired")
+				return
+			}
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			c.writeClose(websocket.CloseNormalClosure, "")
+			return
+		}
+	}
+}
+
+// writeClose вежливо сообщает клиенту о закрытии соединения
+func (c *Client) writeClose(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(closeGrace))
+}
@@ -0,0 +1,132 @@
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Типы событий
+const (
+	EventTimeWarning    = "time_warning"    // до конца попытки осталось мало времени
+	EventTeacherMessage = "teacher_message" // сообщение преподавателя студентам
+	EventAttemptGraded  = "attempt_graded"  // попытка оценена: проверка преподавателем закончена или попытка сдана автоматически
+)
+
+// Event - сообщение, которое сервер отправляет клиенту
+type Event struct {
+	Type string    `json:"type"`
+	Data any       `json:"data"`
+	At   time.Time `json:"at"`
+}
+
+// Hub хранит WebSocket-соединения пользователей и подписки на попытки и рассылает им события.
+// У пользователя может быть несколько соединений: вкладки, устройства
+type Hub struct {
+	mu       sync.RWMutex
+	clients  map[uint64]map[*Client]bool
+	attempts map[uint64]map[*Subscription]bool
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients:  make(map[uint64]map[*Client]bool),
+		attempts: make(map[uint64]map[*Subscription]bool),
+	}
+}
+
+// Publish отправляет событие всем соединениям пользователей. Не блокируется: соединение,
+// которое не успевает принимать события, закрывается, клиент переподключится и перечитает состояние
+func (h *Hub) Publish(userIDs []uint64, eventType string, data any) {
+	payload, err := json.Marshal(Event{Type: eventType, Data: data, At: time.Now().UTC()})
+	if err != nil {
+		log.Error().Err(err).Str("event", eventType).Msg("failed to encode realtime event")
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, userID := range userIDs {
+		for client := range h.clients[userID] {
+			select {
+			case client.send <- payload:
+			default:
+				client.close()
+			}
+		}
+	}
+}
+
+// CloseSession закрывает соединения и подписки, открытые в сессии, например после выхода
+func (h *Hub) CloseSession(sessionID string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, clients := range h.clients {
+		for client := range clients {
+			if client.sessionID == sessionID {
+				client.close()
+			}
+		}
+	}
+	for _, subs := range h.attempts {
+		for sub := range subs {
+			if sub.sessionID == sessionID {
+				sub.close()
+			}
+		}
+	}
+}
+
+// CloseAll закрывает все соединения и подписки, например при остановке сервера: иначе потоки SSE
+// держали бы http.Server.Shutdown до конца срока остановки
+func (h *Hub) CloseAll() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, clients := range h.clients {
+		for client := range clients {
+			client.close()
+		}
+	}
+	for _, subs := range h.attempts {
+		for sub := range subs {
+			sub.close()
+		}
+	}
+}
+
+// Connections возвращает число открытых соединений
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := 0
+	for _, clients := range h.clients {
+		count += len(clients)
+	}
+	return count
+}
+
+func (h *Hub) register(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[client.userID] == nil {
+		h.clients[client.userID] = make(map[*Client]bool)
+	}
+	h.clients[client.userID][client] = true
+}
+
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients[client.userID], client)
+	if len(h.clients[client.userID]) == 0 {
+		delete(h.clients, client.userID)
+	}
+}
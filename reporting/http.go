@@ -0,0 +1,52 @@
+package reporting
+
+import (
+	"GEEK_back/client/llm"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// InstrumentHTTP отправляет в Sentry неудачные запросы HTTP-клиента провайдера: сетевые ошибки,
+// ответы 5xx и 401/403 (неверный ключ или нет доступа). Событие получает пользователя и запрос
+// из контекста, в котором выполнялся вызов
+func InstrumentHTTP(client *http.Client, provider string) {
+	if client == nil || !enabled {
+		return
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &transport{base: base, provider: provider}
+}
+
+type transport struct {
+	base     http.RoundTripper
+	provider string
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+
+	endpoint := llm.EndpointPath(r.URL.Path)
+	tags := map[string]string{"provider": t.provider, "provider.endpoint": endpoint}
+	switch {
+	case err != nil:
+		// Запрос отменил клиент или сервер при остановке - это не сбой провайдера
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		CaptureError(r.Context(), fmt.Errorf("%s %s %s: %w", t.provider, r.Method, endpoint, err), tags,
+			t.provider, r.Method, endpoint, "error")
+	case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		tags["provider.status"] = strconv.Itoa(resp.StatusCode)
+		CaptureError(r.Context(), fmt.Errorf("%s %s %s: %s", t.provider, r.Method, endpoint, resp.Status), tags,
+			t.provider, r.Method, endpoint, strconv.Itoa(resp.StatusCode))
+	}
+
+	return resp, err
+}
@@ -0,0 +1,107 @@
+// Package reporting отправляет ошибки сервера в Sentry (или совместимый сервис, например GlitchTip):
+// паники и ответы 5xx обработчиков, сбои запросов к провайдерам ассистента. У каждого запроса свой hub
+// с пользователем, запросом и request_id, поэтому событие сразу показывает, у кого и где случилась ошибка.
+// Без SENTRY_DSN все функции пакета ничего не делают
+package reporting
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+var enabled bool
+
+// Init подключает Sentry, если задан SENTRY_DSN. SENTRY_ENVIRONMENT и SENTRY_RELEASE попадают
+// в события как есть. Возвращает функцию, которая дожидается отправки накопленных событий
+// при остановке сервера
+func Init() (func(time.Duration), error) {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return func(time.Duration) {}, nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: os.Getenv("SENTRY_ENVIRONMENT"),
+		Release:     os.Getenv("SENTRY_RELEASE"),
+		// Адреса, cookie и заголовки авторизации не отправляются, пользователь известен по ID
+		SendDefaultPII: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	enabled = true
+
+	return func(timeout time.Duration) { sentry.Flush(timeout) }, nil
+}
+
+// NewContext кладет в контекст отдельный hub, чтобы пользователь и теги одного запроса
+// не попали в события другого. r (может быть nil) добавляется к событиям без тела и cookie
+func NewContext(ctx context.Context, r *http.Request) context.Context {
+	if !enabled {
+		return ctx
+	}
+
+	hub := sentry.CurrentHub().Clone()
+	if r != nil {
+		request := sentry.NewRequest(r)
+		hub.Scope().AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			event.Request = request
+			return event
+		})
+	}
+	return sentry.SetHubOnContext(ctx, hub)
+}
+
+// SetUser задает пользователя событий контекста
+func SetUser(ctx context.Context, userID uint64) {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.Scope().SetUser(sentry.User{ID: strconv.FormatUint(userID, 10)})
+	}
+}
+
+// SetTag добавляет тег к событиям контекста
+func SetTag(ctx context.Context, key, value string) {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.Scope().SetTag(key, value)
+	}
+}
+
+// CapturePanic отправляет восстановленную панику со стеком
+func CapturePanic(ctx context.Context, value any) {
+	if hub := hubFromContext(ctx); hub != nil {
+		hub.RecoverWithContext(ctx, value)
+	}
+}
+
+// CaptureError отправляет ошибку с тегами только этого события. fingerprint, если задан,
+// группирует события вместо стека: например, по маршруту и коду ответа
+func CaptureError(ctx context.Context, err error, tags map[string]string, fingerprint ...string) {
+	hub := hubFromContext(ctx)
+	if hub == nil {
+		return
+	}
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		if len(fingerprint) > 0 {
+			scope.SetFingerprint(fingerprint)
+		}
+		hub.CaptureException(err)
+	})
+}
+
+// hubFromContext возвращает hub запроса или общий hub для кода вне запросов
+func hubFromContext(ctx context.Context) *sentry.Hub {
+	if !enabled {
+		return nil
+	}
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		return hub
+	}
+	return sentry.CurrentHub()
+}
@@ -1,51 +1,240 @@
 package router
 
 import (
-	"GEEK_back/client/openAI"
 	"GEEK_back/handler"
+	"GEEK_back/metrics"
 	mw "GEEK_back/middleware"
+	"GEEK_back/openapi"
+	"GEEK_back/storage"
 	"GEEK_back/store"
+	"GEEK_back/tracing"
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"net/http"
+	"strings"
 )
 
-func NewRouter(s *store.Store, o *openai.Client) http.Handler {
-	h := handler.NewHandler(s, o)
-
+// NewRouter собирает маршруты API. frontend, если не nil, отдает собранный фронтенд на всех путях вне /api
+func NewRouter(s *store.Store, h *handler.Handler, frontend http.Handler) http.Handler {
 	r := mux.NewRouter()
+	r.Use(tracing.Middleware, metrics.Middleware, mw.Report)
 
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	// Файлы локального хранилища по подписанным ссылкам; ссылки S3 ведут прямо в бакет
+	if local, ok := h.Files.(*storage.Local); ok {
+		r.PathPrefix(storage.LocalPath).Handler(local).Methods("GET", "HEAD")
+	}
 
 	api := r.PathPrefix("/api").Subrouter()
+	api.Use(mw.RateLimit(), mw.BodyLimit(), mw.CSRF(s))
 	protected := api.PathPrefix("").Subrouter()
 	protected.Use(mw.AuthMiddleware(s))
+	teacherOnly := mw.RequireRole(s, store.RoleTeacher, store.RoleAdmin)
+	// attemptTeacher - преподаватель организации теста попытки или администратор
+	attemptTeacher := func(next http.Handler) http.Handler {
+		return teacherOnly(mw.RequireAttemptAccess(s)(next))
+	}
 
 	// user routes
 	api.HandleFunc("/register", h.Register).Methods("POST")
 	api.HandleFunc("/login", h.Login).Methods("POST")
 	api.HandleFunc("/logout", h.Logout).Methods("POST")
 	api.HandleFunc("/session", h.CheckSession).Methods("GET")
+	protected.HandleFunc("/csrf", h.GetCSRFToken).Methods("GET")
+	protected.HandleFunc("/ws", h.ServeWS).Methods("GET")
 
 	// tests routes
-	//protected.HandleFunc("/test", h.ListTests).Methods("GET")  // закомментировано
-	protected.HandleFunc("/test/{test_id}", h.TestById).Methods("GET")
+	protected.HandleFunc("/tests", h.ListTests).Methods("GET")
+	protected.Handle("/test/{test_id}", mw.ETag(http.HandlerFunc(h.TestById))).Methods("GET")
 	protected.HandleFunc("/tests/{test_id}/attempt", h.StartAttempt).Methods("POST")
 	protected.HandleFunc("/tests/{test_id}/attempts/history", h.GetAttemptHistory).Methods("GET")
+	protected.Handle("/tests/deleted", teacherOnly(http.HandlerFunc(h.ListDeletedTests))).Methods("GET")
 
 	// attempts routes
-	protected.HandleFunc("/attempt/{attempt_id}/question", h.GetAttemptQuestions).Methods("GET")
-	protected.HandleFunc("/attempt/{attempt_id}/question/{question_position}", h.GetAttemptQuestions).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/question", mw.ETag(http.HandlerFunc(h.GetAttemptQuestions))).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/question/{question_position}", mw.ETag(http.HandlerFunc(h.GetAttemptQuestions))).Methods("GET")
 	//protected.HandleFunc("/attempts/{attempt_id}/answers", h.ListAnswers).Methods("GET") // закомментировано
 	//protected.HandleFunc("/attempts/{attempt_id}/answers/{question_id}", h.GetQuestionAnswer).Methods("GET") // закомментировано
 	protected.HandleFunc("/attempt/{attempt_id}/question/{question_position}/submit", h.PostQuestionAnswer).Methods("POST")
 	protected.HandleFunc("/attempt/{attempt_id}/submit", h.SubmitAttempt).Methods("POST")
 	protected.HandleFunc("/attempt/{attempt_id}/result", h.GetAttemptResults).Methods("GET")
+	protected.HandleFunc("/attempt/{attempt_id}/heartbeat", h.Heartbeat).Methods("POST")
+	protected.HandleFunc("/attempt/{attempt_id}/events", h.StreamAttemptEvents).Methods("GET")
+	protected.HandleFunc("/attempt/{attempt_id}/answers:batch", h.SyncAnswers).Methods("POST")
+	protected.HandleFunc("/attempt/{attempt_id}/resources", h.ListAttemptResources).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/feedback", attemptTeacher(http.HandlerFunc(h.AddFeedback))).Methods("POST")
+	protected.Handle("/attempt/{attempt_id}/proctoring", attemptTeacher(http.HandlerFunc(h.ListProctoringEvents))).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/ai-transcript", attemptTeacher(http.HandlerFunc(h.GetAttemptAITranscript))).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/timeline", attemptTeacher(http.HandlerFunc(h.GetAttemptTimeline))).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/suspicion", attemptTeacher(http.HandlerFunc(h.GetAttemptSuspicion))).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/paraphrases", attemptTeacher(http.HandlerFunc(h.ListParaphrases))).Methods("GET")
+	protected.Handle("/attempt/{attempt_id}/messages", attemptTeacher(http.HandlerFunc(h.SendAttemptMessage))).Methods("POST")
+
+	// notifications routes
+	protected.HandleFunc("/notifications", h.ListNotifications).Methods("GET")
+	protected.HandleFunc("/notifications/{notification_id}/read", h.MarkNotificationRead).Methods("POST")
+
+	// manual grading routes
+	review := protected.PathPrefix("/review").Subrouter()
+	review.Use(teacherOnly)
+	review.HandleFunc("", h.ListReviewQueue).Methods("GET")
+	review.HandleFunc("/{item_id}/claim", h.ClaimReviewItem).Methods("POST")
+	review.HandleFunc("/{item_id}/release", h.ReleaseReviewItem).Methods("POST")
+	review.HandleFunc("/{item_id}/grade", h.GradeReviewItem).Methods("POST")
+
+	// teacher test management routes
+	teacher := protected.PathPrefix("/tests/{test_id}").Subrouter()
+	teacher.Use(teacherOnly, mw.RequireTestAccess(s))
+	teacher.HandleFunc("", h.DeleteTest).Methods("DELETE")
+	teacher.HandleFunc("/restore", h.RestoreTest).Methods("POST")
+	teacher.Handle("/questions", mw.ETag(http.HandlerFunc(h.ListTestQuestions))).Methods("GET")
+	teacher.HandleFunc("/questions/{question_id}", h.DeleteQuestion).Methods("DELETE")
+	teacher.HandleFunc("/questions/{question_id}/restore", h.RestoreQuestion).Methods("POST")
+	teacher.HandleFunc("/questions/{question_id}/answer", h.UpdateQuestionAnswer).Methods("PUT")
+	teacher.HandleFunc("/questions/{question_id}/ai-config", h.SetQuestionAIConfig).Methods("PUT")
+	teacher.HandleFunc("/questions/{question_id}/materials", h.SetQuestionMaterials).Methods("PUT")
+	teacher.HandleFunc("/questions/{question_id}/grading", h.SetQuestionGrading).Methods("PUT")
+	teacher.HandleFunc("/ai-config", h.SetTestAIConfig).Methods("PUT")
+	teacher.HandleFunc("/resources", h.UploadTestResource).Methods("POST")
+	teacher.HandleFunc("/resources", h.ListTestResources).Methods("GET")
+	teacher.HandleFunc("/resources/{resource_id}", h.DownloadTestResource).Methods("GET")
+	teacher.HandleFunc("/resources/{resource_id}", h.DeleteTestResource).Methods("DELETE")
+	teacher.HandleFunc("/regrade", h.RegradeTest).Methods("POST")
+	teacher.HandleFunc("/attempts/live", h.ListLiveAttempts).Methods("GET")
+	teacher.HandleFunc("/messages", h.SendTestMessage).Methods("POST")
+	teacher.HandleFunc("/analytics/ai", h.GetAIAnalytics).Methods("GET")
+	teacher.HandleFunc("/stats", h.GetTestStats).Methods("GET")
+	teacher.HandleFunc("/stats/distribution", h.GetScoreDistribution).Methods("GET")
+	teacher.HandleFunc("/results.csv", h.ExportResultsCSV).Methods("GET")
+	teacher.HandleFunc("/results.xlsx", h.ExportResultsXLSX).Methods("GET")
+	teacher.HandleFunc("/similarity", h.GetAnswerSimilarity).Methods("GET")
+	teacher.HandleFunc("/similarity/check", h.CheckAnswerSimilarity).Methods("POST")
+	teacher.HandleFunc("/suspicion", h.GetTestSuspicion).Methods("GET")
+	teacher.HandleFunc("/codes", h.CreateAccessCode).Methods("POST")
+	teacher.HandleFunc("/codes/{code}/suspend", h.SuspendAccessCode).Methods("POST")
+	teacher.HandleFunc("/codes/{code}/reactivate", h.ReactivateAccessCode).Methods("POST")
+	teacher.HandleFunc("/codes/{code}/usages", h.ListCodeUsages).Methods("GET")
+	teacher.HandleFunc("/codes/{code}/qr", h.AccessCodeQR).Methods("GET")
+
+	// dashboard routes
+	protected.HandleFunc("/dashboard/me", h.GetStudentDashboard).Methods("GET")
+	protected.Handle("/dashboard/teacher", teacherOnly(http.HandlerFunc(h.GetTeacherDashboard))).Methods("GET")
+
+	// weekly report routes
+	protected.Handle("/reports/subscriptions", teacherOnly(http.HandlerFunc(h.CreateReportSubscription))).Methods("POST")
+	protected.Handle("/reports/subscriptions", teacherOnly(http.HandlerFunc(h.ListReportSubscriptions))).Methods("GET")
+	protected.Handle("/reports/subscriptions/{subscription_id}", teacherOnly(http.HandlerFunc(h.GetReportSubscription))).Methods("GET")
+	protected.Handle("/reports/subscriptions/{subscription_id}", teacherOnly(http.HandlerFunc(h.UpdateReportSubscription))).Methods("PUT")
+	protected.Handle("/reports/subscriptions/{subscription_id}", teacherOnly(http.HandlerFunc(h.DeleteReportSubscription))).Methods("DELETE")
+	protected.Handle("/reports/subscriptions/{subscription_id}/send", teacherOnly(http.HandlerFunc(h.SendReportNow))).Methods("POST")
+
+	// group routes
+	groups := protected.PathPrefix("/groups").Subrouter()
+	groups.Use(teacherOnly)
+	groups.HandleFunc("", h.CreateGroup).Methods("POST")
+	groups.HandleFunc("/{group_id}", h.GetGroup).Methods("GET")
+	groups.HandleFunc("/{group_id}/members", h.AddGroupMember).Methods("POST")
+	groups.HandleFunc("/{group_id}/tests", h.AssignGroupTest).Methods("POST")
+	groups.HandleFunc("/{group_id}/codes", h.CreateClassCode).Methods("POST")
+	groups.HandleFunc("/{group_id}/codes/{code}", h.GetClassCode).Methods("GET")
+	groups.HandleFunc("/{group_id}/codes/{code}/suspend", h.SuspendClassCode).Methods("POST")
+	groups.HandleFunc("/{group_id}/codes/{code}/reactivate", h.ReactivateClassCode).Methods("POST")
+	groups.HandleFunc("/{group_id}/codes/{code}/usages", h.ListClassCodeUsages).Methods("GET")
+	protected.HandleFunc("/enroll", h.Enroll).Methods("POST")
+
+	// admin routes
+	admin := protected.PathPrefix("/admin").Subrouter()
+	admin.Use(mw.RequireRole(s, store.RoleAdmin))
+	admin.HandleFunc("/attempts", h.ListAttempts).Methods("GET")
+	admin.HandleFunc("/activity", h.ActivityReport).Methods("GET")
+	if h.DevMode {
+		admin.HandleFunc("/dev/synthetic", h.GenerateSyntheticData).Methods("POST")
+	}
+	admin.HandleFunc("/jobs", h.ListJobs).Methods("GET")
+	admin.HandleFunc("/log-level", h.GetLogLevel).Methods("GET")
+	admin.HandleFunc("/log-level", h.SetLogLevel).Methods("PUT")
+	admin.HandleFunc("/users", h.CreateUser).Methods("POST")
+	admin.HandleFunc("/users/{user_id}", h.DeleteUser).Methods("DELETE")
+	admin.HandleFunc("/users/{user_id}/restore", h.RestoreUser).Methods("POST")
+	admin.HandleFunc("/snapshot", h.GetSnapshot).Methods("GET")
+	admin.HandleFunc("/snapshot", h.ImportSnapshot).Methods("POST")
+	admin.HandleFunc("/organizations", h.CreateOrganization).Methods("POST")
+	admin.HandleFunc("/organizations", h.ListOrganizations).Methods("GET")
+	admin.HandleFunc("/users/{user_id}/organization", h.SetUserOrganization).Methods("PUT")
+	admin.HandleFunc("/tests/{test_id}/organization", h.SetTestOrganization).Methods("PUT")
+	admin.HandleFunc("/ai/usage", h.AIUsageReport).Methods("GET")
+	admin.HandleFunc("/ai/budget", h.GetAIBudget).Methods("GET")
+	admin.HandleFunc("/ai/budget", h.SetAIBudget).Methods("PUT")
+	admin.HandleFunc("/prompts", h.CreatePromptTemplate).Methods("POST")
+	admin.HandleFunc("/prompts", h.ListPromptTemplates).Methods("GET")
+	admin.HandleFunc("/prompts/{prompt_id}", h.GetPromptTemplate).Methods("GET")
+	admin.HandleFunc("/prompts/{prompt_id}", h.UpdatePromptTemplate).Methods("PUT")
+	admin.HandleFunc("/tests/{test_id}/prompt", h.SetTestPrompt).Methods("PUT")
+	admin.HandleFunc("/webhooks", h.CreateWebhook).Methods("POST")
+	admin.HandleFunc("/webhooks", h.ListWebhooks).Methods("GET")
+	admin.HandleFunc("/webhooks/{webhook_id}", h.DeleteWebhook).Methods("DELETE")
+	admin.HandleFunc("/webhooks/{webhook_id}/deliveries", h.ListWebhookDeliveries).Methods("GET")
 
 	ai := protected.PathPrefix("/attempt/{attempt_id}/question/{question_position}/ai").Subrouter()
 
+	protected.HandleFunc("/ai/status", h.AIStatus).Methods("GET")
+	ai.HandleFunc("", h.GetQuestionAIThread).Methods("GET")
 	ai.HandleFunc("/start", h.NewDialoge).Methods("POST")
+	ai.HandleFunc("/runs/{run_id}", h.GetAIRun).Methods("GET")
 	ai.HandleFunc("/{thread_id}/send", h.SentMassage).Methods("POST")
+	ai.HandleFunc("/{thread_id}/stream", h.StreamMessage).Methods("GET", "POST")
+	ai.HandleFunc("/{thread_id}/messages", h.GetAIMessages).Methods("GET")
+	ai.HandleFunc("/{thread_id}/images", h.UploadAIImage).Methods("POST")
+	ai.HandleFunc("/{thread_id}/images/{image_id}", h.GetAIImage).Methods("GET")
+	ai.HandleFunc("/{thread_id}/attachments", h.AttachAIResource).Methods("POST")
+	ai.HandleFunc("/{thread_id}", h.GetAIThread).Methods("GET")
+
+	// Спецификация OpenAPI 3, собранная из тех же аннотаций, что и swagger
+	if spec, err := openapi.Load(); err != nil {
+		log.Error().Err(err).Msg("failed to load openapi spec")
+	} else {
+		api.Handle("/openapi.json", spec).Methods("GET")
+		warnUndocumented(r, spec)
+	}
+
+	// Фронтенд регистрируется последним: файлы, swagger и metrics выше имеют приоритет,
+	// а неизвестные пути /api остаются ошибками API, а не страницей приложения
+	if frontend != nil {
+		r.PathPrefix("/").MatcherFunc(outsideAPI).Handler(frontend).Methods("GET", "HEAD")
+	}
+
+	return mw.RequestID(mw.Language(mw.AccessLog(mw.Compress(mw.CORS(r)))))
+}
+
+// outsideAPI не пропускает к фронтенду запросы к /api
+func outsideAPI(r *http.Request, _ *mux.RouteMatch) bool {
+	return r.URL.Path != "/api" && !strings.HasPrefix(r.URL.Path, "/api/")
+}
 
-	return mw.CORS(r)
+// warnUndocumented пишет в лог маршруты /api, которых нет в спецификации: у обработчика
+// нет аннотаций или docs не перегенерированы после их изменения (go generate)
+func warnUndocumented(r *mux.Router, spec *openapi.Spec) {
+	_ = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		path, ok := strings.CutPrefix(template, "/api")
+		if !ok || path == "/openapi.json" {
+			return nil
+		}
+		for _, method := range methods {
+			if !spec.Has(method, path) {
+				log.Warn().Str("method", method).Str("path", template).Msg("route is missing from the openapi spec")
+			}
+		}
+		return nil
+	})
 }
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"GEEK_back/metrics"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job - фоновая задача. Ошибка попадает в статус задачи и метрики, следующий запуск идет по расписанию
+type Job func(ctx context.Context) error
+
+// JobStatus - состояние задачи для администратора
+type JobStatus struct {
+	Name                string     `json:"name"`
+	IntervalSeconds     float64    `json:"interval_seconds"`
+	Running             bool       `json:"running"`
+	Runs                uint64     `json:"runs"`
+	Failures            uint64     `json:"failures"`
+	LastStartedAt       *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt      *time.Time `json:"last_finished_at,omitempty"`
+	LastDurationSeconds float64    `json:"last_duration_seconds"`
+	LastError           string     `json:"last_error,omitempty"` // ошибка последнего запуска, пусто = успешно
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	NextRunAt           *time.Time `json:"next_run_at,omitempty"`
+}
+
+// Scheduler периодически запускает зарегистрированные задачи. У каждой задачи свой цикл,
+// поэтому долгая задача не задерживает остальные, а сама задача не запускается повторно, пока не закончилась
+type Scheduler struct {
+	mu        sync.Mutex
+	jobs      map[string]*entry
+	intervals map[string]time.Duration // интервалы из SCHEDULER_JOBS, 0 = задача отключена
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+type entry struct {
+	run      Job
+	timeout  time.Duration
+	status   JobStatus
+	interval time.Duration
+}
+
+// New создает планировщик. Интервалы задач можно переопределить в SCHEDULER_JOBS через запятую
+// в виде "session_cleanup=10m", где интервал в формате time.ParseDuration, а off отключает задачу
+func New() *Scheduler {
+	return &Scheduler{
+		jobs:      make(map[string]*entry),
+		intervals: intervalsFromEnv(),
+	}
+}
+
+// Register добавляет задачу, которая запускается раз в interval и прерывается через timeout (0 = без ограничения).
+// Задачи регистрируются до Start
+func (s *Scheduler) Register(name string, interval, timeout time.Duration, run Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if override, ok := s.intervals[name]; ok {
+		interval = override
+	}
+	if interval <= 0 {
+		log.Info().Str("job", name).Msg("scheduled job disabled")
+		return
+	}
+
+	s.jobs[name] = &entry{
+		run:      run,
+		timeout:  timeout,
+		interval: interval,
+		status: JobStatus{
+			Name:            name,
+			IntervalSeconds: interval.Seconds(),
+		},
+	}
+}
+
+// Start запускает циклы всех зарегистрированных задач. Первый запуск - через интервал после старта
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for name, job := range s.jobs {
+		next := time.Now().UTC().Add(job.interval)
+		job.status.NextRunAt = &next
+
+		s.wg.Add(1)
+		go s.loop(ctx, name, job)
+	}
+}
+
+// Stop останавливает циклы задач и ждет завершения уже идущих запусков
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// Status возвращает состояние задач, отсортированное по имени
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		result = append(result, job.status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func (s *Scheduler) loop(ctx context.Context, name string, job *entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, name, job)
+		}
+	}
+}
+
+// runOnce выполняет задачу и записывает результат. Паника задачи не роняет сервер, а считается ошибкой
+func (s *Scheduler) runOnce(ctx context.Context, name string, job *entry) {
+	start := time.Now().UTC()
+	s.mu.Lock()
+	job.status.Running = true
+	job.status.LastStartedAt = &start
+	s.mu.Unlock()
+
+	if job.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.timeout)
+		defer cancel()
+	}
+
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+			}
+		}()
+		return job.run(ctx)
+	}()
+
+	finished := time.Now().UTC()
+	duration := finished.Sub(start)
+	metrics.ObserveJob(name, duration, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := finished.Add(job.interval)
+	job.status.Running = false
+	job.status.Runs++
+	job.status.LastFinishedAt = &finished
+	job.status.LastDurationSeconds = duration.Seconds()
+	job.status.NextRunAt = &next
+	if err != nil {
+		job.status.Failures++
+		job.status.LastError = err.Error()
+		log.Error().Err(err).Str("job", name).Dur("duration", duration).Msg("scheduled job failed")
+		return
+	}
+	job.status.LastError = ""
+	job.status.LastSuccessAt = &finished
+}
+
+// intervalsFromEnv читает SCHEDULER_JOBS. Неверные записи пропускаются с предупреждением в логе
+func intervalsFromEnv() map[string]time.Duration {
+	intervals := make(map[string]time.Duration)
+
+	for _, item := range strings.Split(os.Getenv("SCHEDULER_JOBS"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(item, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if value == "off" {
+			intervals[name] = 0
+			continue
+		}
+		interval, err := time.ParseDuration(value)
+		if !ok || name == "" || err != nil || interval <= 0 {
+			log.Warn().Str("entry", item).Msg("invalid SCHEDULER_JOBS entry, skipping")
+			continue
+		}
+		intervals[name] = interval
+	}
+
+	return intervals
+}
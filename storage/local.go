@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"GEEK_back/apiutils"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalPath - путь, по которому сервер отдает файлы локального бэкенда по подписанным ссылкам
+const LocalPath = "/files/"
+
+// Local хранит файлы в каталоге на диске. Подписанные ссылки ведут на LocalPath этого же сервера
+// и проверяются HMAC с секретом Secret, поэтому после смены секрета выданные ссылки перестают работать
+type Local struct {
+	Dir     string // корневой каталог файлов
+	BaseURL string // внешний адрес сервера для ссылок (https://api.example.com), пусто - относительные ссылки
+	Secret  []byte // ключ подписи ссылок
+}
+
+// NewLocal создает локальное хранилище и его каталог
+func NewLocal(dir, baseURL string, secret []byte) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Local{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/"), Secret: secret}, nil
+}
+
+// Name возвращает имя бэкенда
+func (l *Local) Name() string {
+	return "local"
+}
+
+// path возвращает путь к файлу ключа на диске
+func (l *Local) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put записывает файл через временный файл, чтобы читатели не увидели его недописанным
+func (l *Local) Put(ctx context.Context, key, contentType string, data []byte) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get читает файл с диска
+func (l *Local) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Delete удаляет файл с диска
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL возвращает ссылку на LocalPath с сроком действия и подписью
+func (l *Local) SignedURL(ctx context.Context, key string, ttl time.Duration, opts URLOptions) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	if opts.ContentType != "" {
+		query.Set("type", opts.ContentType)
+	}
+	if opts.Filename != "" {
+		query.Set("name", opts.Filename)
+	}
+	query.Set("signature", l.sign(key, expires, opts))
+
+	return l.BaseURL + LocalPath + key + "?" + query.Encode(), nil
+}
+
+// sign подписывает ключ, срок и заголовки ответа: подменить в ссылке можно только все вместе
+func (l *Local) sign(key, expires string, opts URLOptions) string {
+	mac := hmac.New(sha256.New, l.Secret)
+	mac.Write([]byte(key + "\n" + expires + "\n" + opts.ContentType + "\n" + opts.Filename))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP отдает файл по подписанной ссылке на GET и HEAD. Подключается к роутеру на LocalPath, вне /api:
+// ссылки работают без сессии, доступ проверил обработчик, который их выдал
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, LocalPath)
+	query := r.URL.Query()
+	opts := URLOptions{ContentType: query.Get("type"), Filename: query.Get("name")}
+	expires := query.Get("expires")
+
+	deadline, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(query.Get("signature")), []byte(l.sign(key, expires, opts))) {
+		apiutils.WriteError(w, http.StatusForbidden, apiutils.CodeForbidden, "invalid file link", nil)
+		return
+	}
+	if time.Now().Unix() > deadline {
+		apiutils.WriteError(w, http.StatusForbidden, apiutils.CodeForbidden, "file link has expired", nil)
+		return
+	}
+
+	path, err := l.path(key)
+	if err != nil {
+		apiutils.WriteError(w, http.StatusNotFound, apiutils.CodeNotFound, ErrNotFound.Error(), nil)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		apiutils.WriteError(w, http.StatusNotFound, apiutils.CodeNotFound, ErrNotFound.Error(), nil)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		apiutils.WriteError(w, http.StatusNotFound, apiutils.CodeNotFound, ErrNotFound.Error(), nil)
+		return
+	}
+
+	if opts.ContentType != "" {
+		w.Header().Set("Content-Type", opts.ContentType)
+	}
+	if opts.Filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": opts.Filename}))
+	}
+	// Файлы загружают пользователи: браузер не должен угадывать тип и исполнять содержимое
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(max(deadline-time.Now().Unix(), 0), 10))
+
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
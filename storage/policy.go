@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrTooLarge возвращается, если файл больше Policy.MaxSize
+	ErrTooLarge = errors.New("file is too large")
+	// ErrTypeNotAllowed возвращается для типов вне Policy.Types и для активного содержимого
+	ErrTypeNotAllowed = errors.New("file type is not allowed")
+)
+
+// activeTypes - типы, которые браузер исполняет: по подписанной ссылке с нашего домена такой файл
+// стал бы XSS, поэтому они не принимаются ни одной политикой
+var activeTypes = map[string]bool{
+	"text/html":              true,
+	"application/xhtml+xml":  true,
+	"image/svg+xml":          true,
+	"text/xml":               true,
+	"application/xml":        true,
+	"text/javascript":        true,
+	"application/javascript": true,
+}
+
+// Policy - ограничения на загружаемые файлы одного вида
+type Policy struct {
+	MaxSize int      // байт, 0 - без ограничения
+	Types   []string // разрешенные типы, "image/*" разрешает все подтипы; пусто - любые, кроме активного содержимого
+	Sniff   bool     // тип определяется только по содержимому, заявленный клиентом игнорируется
+}
+
+// Check проверяет размер и тип файла и возвращает тип, с которым его сохранять: заявленный клиентом
+// или, если он не задан (или Sniff), определенный по содержимому. Тип возвращается и вместе с ошибкой,
+// чтобы его можно было показать пользователю
+func (p Policy) Check(declared string, data []byte) (string, error) {
+	detected := mediaType(http.DetectContentType(data))
+	contentType := detected
+	if declared := mediaType(declared); !p.Sniff && declared != "" && declared != "application/octet-stream" {
+		contentType = declared
+	}
+
+	if p.MaxSize > 0 && len(data) > p.MaxSize {
+		return contentType, ErrTooLarge
+	}
+	// Заявленный тип не спасает файл, который браузер по содержимому распознает как HTML
+	if activeTypes[detected] {
+		return detected, fmt.Errorf("%w: %s", ErrTypeNotAllowed, detected)
+	}
+	if activeTypes[contentType] {
+		return contentType, fmt.Errorf("%w: %s", ErrTypeNotAllowed, contentType)
+	}
+	if len(p.Types) > 0 && !matchType(p.Types, contentType) {
+		return contentType, fmt.Errorf("%w: %s", ErrTypeNotAllowed, contentType)
+	}
+
+	return contentType, nil
+}
+
+// mediaType возвращает тип без параметров в нижнем регистре: "Text/Plain; charset=utf-8" -> "text/plain"
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return t
+}
+
+// matchType проверяет тип по списку, "image/*" совпадает с любым image/...
+func matchType(types []string, contentType string) bool {
+	for _, t := range types {
+		if t == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
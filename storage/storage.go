@@ -0,0 +1,58 @@
+// Package storage хранит файлы пользователей (материалы тестов, изображения для ассистента)
+// на локальном диске или в S3-совместимом хранилище и выдает на них подписанные ссылки с ограниченным
+// сроком действия. Метаданные файлов (имя, тип, владелец) остаются в store, здесь - только содержимое по ключу
+package storage
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound возвращается, если по ключу нет файла
+	ErrNotFound = errors.New("file not found")
+	// ErrInvalidKey возвращается для ключей, которые выходят за пределы хранилища или пустые
+	ErrInvalidKey = errors.New("invalid file key")
+)
+
+// URLOptions - заголовки ответа при скачивании по подписанной ссылке
+type URLOptions struct {
+	ContentType string // пусто - тип определяет хранилище
+	Filename    string // не пусто - файл скачивается (attachment) под этим именем
+}
+
+// Storage - бэкенд хранения файлов
+type Storage interface {
+	Name() string
+	// Put сохраняет файл по ключу, существующий файл перезаписывается
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get возвращает содержимое файла или ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete удаляет файл; удаление несуществующего файла не ошибка
+	Delete(ctx context.Context, key string) error
+	// SignedURL возвращает ссылку на скачивание файла без авторизации, действующую ttl
+	SignedURL(ctx context.Context, key string, ttl time.Duration, opts URLOptions) (string, error)
+}
+
+// NewKey создает уникальный ключ файла с префиксом, например resources/3/<uuid>
+func NewKey(prefix ...string) string {
+	return path.Join(append(prefix, uuid.NewString())...)
+}
+
+// validKey проверяет, что ключ - относительный путь без . и .. (они вывели бы локальный бэкенд за каталог)
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
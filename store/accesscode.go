@@ -0,0 +1,264 @@
+package store
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// accessCodeAlphabet - символы генерируемых кодов без легко путаемых 0/O и 1/I
+const accessCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// AccessCodeOptions - необязательные ограничения кода доступа
+type AccessCodeOptions struct {
+	MaxUses          *uint64       // nil = бесконечный
+	ExpiresAt        *time.Time    // nil = не истекает
+	BoundEmail       string        // персональный одноразовый код для пользователя с этим email
+	ActivationWindow time.Duration // срок действия после первого использования
+	Checksum         bool          // добавить к коду контрольный символ
+}
+
+// newAccessCode собирает код доступа из опций
+func newAccessCode(code string, opts AccessCodeOptions) *AccessCode {
+	accessCode := &AccessCode{
+		Code:             code,
+		MaxUses:          opts.MaxUses,
+		ExpiresAt:        opts.ExpiresAt,
+		BoundEmail:       strings.TrimSpace(opts.BoundEmail),
+		ActivationWindow: opts.ActivationWindow,
+		CreatedAt:        time.Now().UTC(),
+	}
+
+	// Персональный код всегда одноразовый
+	if accessCode.BoundEmail != "" {
+		maxUses := uint64(1)
+		accessCode.MaxUses = &maxUses
+	}
+
+	return accessCode
+}
+
+// GenerateAccessCode генерирует случайный код вида XXXX-XXXX.
+// С withChecksum к коду добавляется контрольный символ: XXXX-XXXX-C
+func GenerateAccessCode(withChecksum bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < 8; i++ {
+		if i == 4 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(accessCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(accessCodeAlphabet[n.Int64()])
+	}
+
+	if withChecksum {
+		return WithChecksum(b.String()), nil
+	}
+	return b.String(), nil
+}
+
+// WithChecksum добавляет к коду контрольный символ
+func WithChecksum(code string) string {
+	return code + "-" + string(checksumChar(code))
+}
+
+// HasChecksumFormat проверяет, что код оканчивается на XXXX-XXXX-C, как генерируемые коды
+// с контрольным символом (перед ними может стоять префикс организации)
+func HasChecksumFormat(code string) bool {
+	parts := strings.Split(code, "-")
+	n := len(parts)
+	return n >= 3 && len(parts[n-3]) == 4 && len(parts[n-2]) == 4 && len(parts[n-1]) == 1
+}
+
+// ValidChecksum проверяет контрольный символ кода. Позволяет отсечь случайно подобранные
+// и опечатанные коды без обращения к хранилищу
+func ValidChecksum(code string) bool {
+	i := strings.LastIndexByte(code, '-')
+	if i < 0 || i != len(code)-2 {
+		return false
+	}
+
+	return checksumChar(code[:i]) == code[i+1]
+}
+
+// checksumConsistent проверяет, что код вида XXXX-XXXX-C несет верный контрольный символ.
+// Коды другого вида контрольного символа не имеют
+func checksumConsistent(code string) bool {
+	return !HasChecksumFormat(code) || ValidChecksum(code)
+}
+
+// checksumChar считает контрольный символ по алгоритму Луна mod N над алфавитом кодов.
+// Символы вне алфавита (разделители) пропускаются
+func checksumChar(payload string) byte {
+	n := len(accessCodeAlphabet)
+	factor := 2
+	sum := 0
+
+	for i := len(payload) - 1; i >= 0; i-- {
+		codePoint := strings.IndexByte(accessCodeAlphabet, payload[i])
+		if codePoint < 0 {
+			continue
+		}
+
+		addend := factor * codePoint
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+		sum += addend/n + addend%n
+	}
+
+	return accessCodeAlphabet[(n-sum%n)%n]
+}
+
+// SetAccessCodeSuspended отзывает код доступа или возвращает его в работу
+func (s *Store) SetAccessCodeSuspended(testID uint64, code string, suspended bool) (*AccessCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accessCode, ok := s.accessCodes[code]
+	if !ok || accessCode.TestID != testID {
+		return nil, errors.New("access code not found")
+	}
+
+	setSuspended(accessCode, suspended)
+	return accessCode, nil
+}
+
+// SetClassCodeSuspended отзывает код класса преподавателя (см. ownedGroup) или возвращает его в работу.
+// Коды класса не привязаны к тесту, поэтому ищутся по группе
+func (s *Store) SetClassCodeSuspended(groupID, teacherID uint64, code string, suspended bool) (*AccessCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accessCode, err := s.classCode(groupID, teacherID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	setSuspended(accessCode, suspended)
+	return accessCode, nil
+}
+
+// classCode возвращает код класса преподавателя, вызывается под блокировкой
+func (s *Store) classCode(groupID, teacherID uint64, code string) (*AccessCode, error) {
+	if _, _, err := s.ownedGroup(groupID, teacherID); err != nil {
+		return nil, err
+	}
+	accessCode, ok := s.accessCodes[code]
+	if !ok || accessCode.GroupID != groupID {
+		return nil, errors.New("access code not found")
+	}
+	return accessCode, nil
+}
+
+// setSuspended отзывает код или возвращает его в работу, вызывается под блокировкой
+func setSuspended(accessCode *AccessCode, suspended bool) {
+	accessCode.Suspended = suspended
+	accessCode.SuspendedAt = nil
+	if suspended {
+		now := time.Now().UTC()
+		accessCode.SuspendedAt = &now
+	}
+}
+
+// CodeUsage - запись об использовании кода доступа
+type CodeUsage struct {
+	UserID     uint64    `json:"user_id"`
+	Email      string    `json:"email"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+
+	enrolled bool // код класса зачислил пользователя, при откате использования зачисление отменяется
+}
+
+// ListCodeUsages возвращает, кто и когда использовал код доступа, в порядке использования
+func (s *Store) ListCodeUsages(testID uint64, code string) ([]*CodeUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accessCode, ok := s.accessCodes[code]
+	if !ok || accessCode.TestID != testID {
+		return nil, errors.New("access code not found")
+	}
+
+	return s.codeUsageList(code), nil
+}
+
+// ListClassCodeUsages возвращает, кто и когда использовал код класса преподавателя, в порядке использования
+func (s *Store) ListClassCodeUsages(groupID, teacherID uint64, code string) ([]*CodeUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.classCode(groupID, teacherID, code); err != nil {
+		return nil, err
+	}
+
+	return s.codeUsageList(code), nil
+}
+
+// codeUsageList копирует список использований кода, вызывается под блокировкой
+func (s *Store) codeUsageList(code string) []*CodeUsage {
+	usages := make([]*CodeUsage, len(s.codeUsages[code]))
+	copy(usages, s.codeUsages[code])
+	return usages
+}
+
+// GetAccessCode возвращает код доступа теста
+func (s *Store) GetAccessCode(testID uint64, code string) (*AccessCode, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accessCode, ok := s.accessCodes[code]
+	if !ok || accessCode.TestID != testID {
+		return nil, false
+	}
+
+	return accessCode, true
+}
+
+// GetClassCode возвращает код класса преподавателя (см. ownedGroup)
+func (s *Store) GetClassCode(groupID, teacherID uint64, code string) (*AccessCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.classCode(groupID, teacherID, code)
+}
+
+// RollbackAccessCode отменяет последнее использование кода пользователем и зачисление в класс по нему,
+// если после успешной валидации попытку создать не удалось
+func (s *Store) RollbackAccessCode(code string, userID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accessCode, ok := s.accessCodes[code]
+	if !ok {
+		return
+	}
+
+	usages := s.codeUsages[code]
+	for i := len(usages) - 1; i >= 0; i-- {
+		usage := usages[i]
+		if usage.UserID != userID {
+			continue
+		}
+
+		s.codeUsages[code] = append(usages[:i], usages[i+1:]...)
+		if accessCode.UsedCount > 0 {
+			accessCode.UsedCount--
+		}
+		accessCode.ConsumedAt = nil
+		if accessCode.UsedCount == 0 {
+			accessCode.ActivatedAt = nil
+		}
+		if group, ok := s.groups[accessCode.GroupID]; ok && usage.enrolled {
+			group.MemberIDs = slices.DeleteFunc(group.MemberIDs, func(id uint64) bool { return id == userID })
+		}
+		return
+	}
+}
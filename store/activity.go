@@ -0,0 +1,212 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Шаг временного ряда активности
+const (
+	ActivityByDay  = "day"
+	ActivityByHour = "hour"
+)
+
+// Разрезы временного ряда активности, пустой - один ряд по всем попыткам
+const (
+	ActivityByTest = "test"
+	ActivityByOrg  = "organization"
+)
+
+// MaxActivityPoints - наибольшее число точек в одном ряду: например, почти три месяца по часам
+const MaxActivityPoints = 2500
+
+// ActivityQuery - параметры временного ряда активности
+type ActivityQuery struct {
+	Interval string         // ActivityByDay или ActivityByHour
+	From     time.Time      // начало периода, округляется вниз до начала дня или часа
+	To       time.Time      // конец периода, не входит
+	Location *time.Location // часовой пояс границ дней, nil = UTC
+	TestID   uint64         // 0 = все тесты
+	OrgID    uint64         // 0 = все организации
+	GroupBy  string         // "", ActivityByTest или ActivityByOrg
+}
+
+// ActivityPoint - число начатых и сданных попыток за день или час, начинающийся в Start
+type ActivityPoint struct {
+	Start     time.Time `json:"start"`
+	Started   int       `json:"started"`
+	Submitted int       `json:"submitted"`
+}
+
+// ActivitySeries - временной ряд одного теста, организации или всех попыток
+type ActivitySeries struct {
+	TestID    uint64           `json:"test_id,omitempty"`
+	OrgID     uint64           `json:"org_id,omitempty"`
+	Started   int              `json:"started"`   // всего за период
+	Submitted int              `json:"submitted"` // всего за период
+	Points    []*ActivityPoint `json:"points"`    // все дни или часы периода, включая пустые
+}
+
+// ActivityReport - временные ряды активности за период
+type ActivityReport struct {
+	Interval string            `json:"interval"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Timezone string            `json:"timezone"`
+	GroupBy  string            `json:"group_by,omitempty"`
+	Series   []*ActivitySeries `json:"series"` // самые активные первыми
+}
+
+// ActivityReport считает начатые и сданные попытки по дням или часам. Сданной считается попытка,
+// завершенная в периоде, в том числе ждущая ручной проверки. Попытка относится к организации своего теста;
+// при разрезе по организациям попытки тестов без организации попадают в ряд с org_id 0
+func (s *Store) ActivityReport(query ActivityQuery) (*ActivityReport, error) {
+	if query.Location == nil {
+		query.Location = time.UTC
+	}
+	if query.GroupBy != "" && query.GroupBy != ActivityByTest && query.GroupBy != ActivityByOrg {
+		return nil, errors.New("group_by must be one of: test, organization")
+	}
+
+	starts, err := activityBuckets(query)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int64]int, len(starts))
+	for i, start := range starts {
+		index[start.Unix()] = i
+	}
+	from, to := starts[0], query.To
+	bucket := func(t time.Time) (int, bool) {
+		if t.IsZero() || t.Before(from) || !t.Before(to) {
+			return 0, false
+		}
+		i, ok := index[truncateActivity(t, query.Interval, query.Location).Unix()]
+		return i, ok
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempts := s.attemptsByTest[query.TestID]
+	if query.TestID == 0 {
+		attempts = make([]*Attempt, 0, len(s.attempts))
+		for _, attempt := range s.attempts {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	newSeries := func() *ActivitySeries {
+		entry := &ActivitySeries{Points: make([]*ActivityPoint, len(starts))}
+		for i, start := range starts {
+			entry.Points[i] = &ActivityPoint{Start: start}
+		}
+		return entry
+	}
+	// Ключ ряда - ID теста или организации, без разреза все попытки в ряду с ключом 0
+	series := make(map[uint64]*ActivitySeries)
+
+	for _, attempt := range attempts {
+		orgID := s.attemptOrgID(attempt)
+		if query.OrgID != 0 && orgID != query.OrgID {
+			continue
+		}
+		started, isStarted := bucket(attempt.StartedAt)
+		submitted, isSubmitted := bucket(attempt.FinishedAt)
+		isSubmitted = isSubmitted && attempt.Status != "started"
+		if !isStarted && !isSubmitted {
+			continue
+		}
+
+		var key uint64
+		switch query.GroupBy {
+		case ActivityByTest:
+			key = attempt.TestID
+		case ActivityByOrg:
+			key = orgID
+		}
+		entry, ok := series[key]
+		if !ok {
+			entry = newSeries()
+			switch query.GroupBy {
+			case ActivityByTest:
+				entry.TestID = key
+			case ActivityByOrg:
+				entry.OrgID = key
+			}
+			series[key] = entry
+		}
+		if isStarted {
+			entry.Started++
+			entry.Points[started].Started++
+		}
+		if isSubmitted {
+			entry.Submitted++
+			entry.Points[submitted].Submitted++
+		}
+	}
+
+	report := &ActivityReport{
+		Interval: query.Interval,
+		From:     from,
+		To:       to,
+		Timezone: query.Location.String(),
+		GroupBy:  query.GroupBy,
+		Series:   make([]*ActivitySeries, 0, len(series)),
+	}
+	for _, entry := range series {
+		report.Series = append(report.Series, entry)
+	}
+	// Без разреза ряд есть всегда, даже если попыток за период не было
+	if query.GroupBy == "" && len(report.Series) == 0 {
+		report.Series = append(report.Series, newSeries())
+	}
+
+	sort.Slice(report.Series, func(i, j int) bool {
+		a, b := report.Series[i], report.Series[j]
+		if a.Started+a.Submitted != b.Started+b.Submitted {
+			return a.Started+a.Submitted > b.Started+b.Submitted
+		}
+		if a.TestID != b.TestID {
+			return a.TestID < b.TestID
+		}
+		return a.OrgID < b.OrgID
+	})
+
+	return report, nil
+}
+
+// activityBuckets возвращает начала всех дней или часов периода
+func activityBuckets(query ActivityQuery) ([]time.Time, error) {
+	if query.Interval != ActivityByDay && query.Interval != ActivityByHour {
+		return nil, errors.New("interval must be one of: day, hour")
+	}
+	if !query.From.Before(query.To) {
+		return nil, errors.New("from must be before to")
+	}
+
+	var starts []time.Time
+	for start := truncateActivity(query.From, query.Interval, query.Location); start.Before(query.To); {
+		if len(starts) == MaxActivityPoints {
+			return nil, fmt.Errorf("range is too long: more than %d points, use a shorter range or a longer interval", MaxActivityPoints)
+		}
+		starts = append(starts, start)
+		if query.Interval == ActivityByDay {
+			start = time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, query.Location)
+		} else {
+			start = start.Add(time.Hour)
+		}
+	}
+	return starts, nil
+}
+
+// truncateActivity возвращает начало дня или часа, в который попадает t, в часовом поясе loc
+func truncateActivity(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	if interval == ActivityByDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	return t.Truncate(time.Hour)
+}
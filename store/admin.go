@@ -0,0 +1,63 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// AttemptFilter - фильтры для просмотра попыток администратором, нулевые значения не фильтруют
+type AttemptFilter struct {
+	UserID uint64
+	TestID uint64
+	Status string
+	From   time.Time
+	To     time.Time
+}
+
+// ListAttempts возвращает попытки по фильтру, новые первыми
+func (s *Store) ListAttempts(filter AttemptFilter) []*Attempt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := make([]*Attempt, 0)
+	for _, attempt := range s.filterCandidates(filter) {
+		if filter.UserID != 0 && attempt.UserID != filter.UserID {
+			continue
+		}
+		if filter.TestID != 0 && attempt.TestID != filter.TestID {
+			continue
+		}
+		if filter.Status != "" && attempt.Status != filter.Status {
+			continue
+		}
+		if !filter.From.IsZero() && attempt.StartedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && attempt.StartedAt.After(filter.To) {
+			continue
+		}
+		matched = append(matched, attempt)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].StartedAt.After(matched[j].StartedAt)
+	})
+
+	return matched
+}
+
+// filterCandidates возвращает попытки, среди которых искать по фильтру: по индексу пользователя
+// или теста, если он задан, иначе все. Вызывается под блокировкой
+func (s *Store) filterCandidates(filter AttemptFilter) []*Attempt {
+	switch {
+	case filter.UserID != 0:
+		return s.attemptsByUser[filter.UserID]
+	case filter.TestID != 0:
+		return s.attemptsByTest[filter.TestID]
+	}
+	result := make([]*Attempt, 0, len(s.attempts))
+	for _, attempt := range s.attempts {
+		result = append(result, attempt)
+	}
+	return result
+}
@@ -0,0 +1,338 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Роли сообщений в диалоге с ассистентом
+const (
+	AIRoleSystem    = "system" // контекст диалога, добавленный сервером
+	AIRoleUser      = "user"
+	AIRoleAssistant = "assistant"
+)
+
+// DefaultAIHelpPolicy - правила помощи ассистента, если тест не задает свои
+const DefaultAIHelpPolicy = "Помогай студенту разобраться в вопросе: объясняй понятия и подсказывай, где искать информацию. " +
+	"Не называй готовый ответ и не решай задание за студента."
+
+// Статусы диалога с ассистентом
+const (
+	AIThreadActive = "active"
+	AIThreadClosed = "closed" // попытка сдана или истекла либо исчерпан лимит ходов, сообщения больше не принимаются
+)
+
+// Причины закрытия диалога
+const (
+	AIThreadClosedAttempt   = "attempt_finished" // попытка сдана или истекла
+	AIThreadClosedTurnLimit = "turn_limit"       // исчерпан лимит ходов диалога
+)
+
+var (
+	ErrAIThreadNotFound = errors.New("thread not found")
+	ErrAIThreadAccess   = errors.New("access denied")
+	ErrAIThreadClosed   = errors.New("thread is closed")
+)
+
+// AIMessage - сообщение в диалоге с ассистентом
+type AIMessage struct {
+	Role             string    `json:"role"`
+	UserID           uint64    `json:"user_id"` // автор сообщения или пользователь, запросивший ответ
+	Text             string    `json:"text"`
+	Images           []uint64  `json:"images,omitempty"` // изображения, приложенные к сообщению студента
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	Model            string    `json:"model,omitempty"`     // модель, ответившая на сообщение
+	CostUSD          float64   `json:"cost_usd,omitempty"`  // оценка стоимости ответа по тарифам модели
+	Cached           bool      `json:"cached,omitempty"`    // ответ взят из кеша
+	HintCost         uint64    `json:"hint_cost,omitempty"` // сколько баллов стоил ответ ассистента
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// AppendAIMessage сохраняет сообщение в истории диалога
+func (s *Store) AppendAIMessage(threadID string, message AIMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok {
+		return ErrAIThreadNotFound
+	}
+
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now().UTC()
+	}
+
+	// Каждый ответ ассистента, в том числе из кеша, стоит баллов по настройкам теста или вопроса
+	attempt, ok := s.attempts[thread.AttemptID]
+	if ok && message.Role == AIRoleAssistant {
+		message.HintCost = s.resolveAIConfig(attempt, thread.QuestionPosition).HintCost
+		attempt.AICost += message.HintCost
+	}
+	thread.Messages = append(thread.Messages, &message)
+
+	if ok && message.Role == AIRoleAssistant && !message.Cached {
+		s.recordAIUsage(attempt, message.UserID, message.PromptTokens, message.CompletionTokens, message.CostUSD)
+	}
+
+	// Ответ на последний разрешенный ход закрывает диалог
+	if ok && message.Role == AIRoleAssistant {
+		if turns := s.aiThreadTurns(attempt, thread, false); turns.exhausted() {
+			closeAIThread(thread, message.CreatedAt, AIThreadClosedTurnLimit)
+		}
+	}
+
+	return nil
+}
+
+// GetAIThread возвращает копию диалога попытки со всей историей.
+// Доступен участникам попытки, преподавателям и администраторам
+func (s *Store) GetAIThread(attemptID uint64, threadID string, userID uint64) (*AIThread, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok || thread.AttemptID != attemptID {
+		return nil, ErrAIThreadNotFound
+	}
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, ErrAIThreadNotFound
+	}
+
+	if !s.canAccessAttempt(attempt, userID) && !s.isStaff(userID) {
+		return nil, ErrAIThreadAccess
+	}
+
+	result := *thread
+	result.Messages = make([]*AIMessage, len(thread.Messages))
+	for i, message := range thread.Messages {
+		m := *message
+		result.Messages[i] = &m
+	}
+
+	return &result, nil
+}
+
+// CheckAIThreadOpen проверяет, что диалог относится к попытке и еще принимает сообщения.
+// Для диалога, исчерпавшего лимит ходов, возвращает ErrAIDialogLimit и закрывает его,
+// если лимит уменьшили уже после начала диалога
+func (s *Store) CheckAIThreadOpen(attemptID uint64, threadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok || thread.AttemptID != attemptID {
+		return ErrAIThreadNotFound
+	}
+	if thread.Status == AIThreadClosed {
+		if thread.CloseReason == AIThreadClosedTurnLimit {
+			return ErrAIDialogLimit
+		}
+		return ErrAIThreadClosed
+	}
+
+	if attempt, ok := s.attempts[attemptID]; ok {
+		if turns := s.aiThreadTurns(attempt, thread, true); turns.exhausted() {
+			// Последний ход еще в работе - диалог закроется с ответом на него
+			if s.activeAIRun(threadID) == nil {
+				closeAIThread(thread, time.Now().UTC(), AIThreadClosedTurnLimit)
+			}
+			return ErrAIDialogLimit
+		}
+	}
+
+	return nil
+}
+
+// CloseAttemptAIThreads закрывает диалоги попытки и возвращает ID тредов, которые нужно удалить у провайдера
+func (s *Store) CloseAttemptAIThreads(attemptID uint64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	threadIDs := make([]string, 0)
+	for _, thread := range s.aiThreadsByID {
+		if thread.AttemptID != attemptID {
+			continue
+		}
+		closeAIThread(thread, now, AIThreadClosedAttempt)
+		if !thread.remoteDeleted {
+			threadIDs = append(threadIDs, thread.ThreadID)
+		}
+	}
+
+	return threadIDs
+}
+
+// CloseExpiredAIThreads закрывает диалоги сданных и истекших попыток. Возвращает ID всех закрытых тредов,
+// которые еще не удалены у провайдера, в том числе после прошлых неудачных попыток удаления
+func (s *Store) CloseExpiredAIThreads() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	threadIDs := make([]string, 0)
+	for _, thread := range s.aiThreadsByID {
+		if thread.Status != AIThreadClosed {
+			attempt, ok := s.attempts[thread.AttemptID]
+			if ok && attempt.Status == "started" {
+				if _, err := s.checkDeadline(attempt); err == nil {
+					continue
+				}
+			}
+			closeAIThread(thread, now, AIThreadClosedAttempt)
+		}
+		if !thread.remoteDeleted {
+			threadIDs = append(threadIDs, thread.ThreadID)
+		}
+	}
+
+	return threadIDs
+}
+
+// MarkAIThreadDeleted отмечает, что тред удален у провайдера
+func (s *Store) MarkAIThreadDeleted(threadID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if thread, ok := s.aiThreadsByID[threadID]; ok {
+		thread.remoteDeleted = true
+	}
+}
+
+// closeAIThread закрывает диалог, вызывается под блокировкой
+func closeAIThread(thread *AIThread, at time.Time, reason string) {
+	if thread.Status == AIThreadClosed {
+		return
+	}
+	thread.Status = AIThreadClosed
+	thread.CloseReason = reason
+	thread.ClosedAt = &at
+}
+
+// QuestionAIThreadID возвращает ID последнего диалога по вопросу попытки
+func (s *Store) QuestionAIThreadID(attemptID, questionPos uint64) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	thread, ok := s.aiThreads[attemptID*1000+questionPos]
+	if !ok {
+		return "", false
+	}
+	return thread.ThreadID, true
+}
+
+// isStaff проверяет, что пользователь преподаватель или администратор, вызывается под блокировкой mu
+func (s *Store) isStaff(userID uint64) bool {
+	user, ok := s.lookupUser(userID)
+	return ok && (user.Role == RoleTeacher || user.Role == RoleAdmin)
+}
+
+// ErrAIQuotaExhausted возвращается, если лимит сообщений или токенов ассистента за попытку исчерпан
+var ErrAIQuotaExhausted = errors.New("ai quota exhausted")
+
+// AIQuota - расход и остаток лимитов ассистента в попытке. Лимит 0 означает отсутствие ограничения
+type AIQuota struct {
+	MessagesUsed      int    `json:"messages_used"`
+	MessageLimit      int    `json:"message_limit"`
+	MessagesRemaining int    `json:"messages_remaining,omitempty"`
+	TokensUsed        int    `json:"tokens_used"`
+	TokenLimit        int    `json:"token_limit"`
+	TokensRemaining   int    `json:"tokens_remaining,omitempty"`
+	AICost            uint64 `json:"ai_cost"` // сколько баллов уже снято за подсказки
+}
+
+// CheckAIQuota возвращает расход ассистента в попытке и ErrAIQuotaExhausted, если новое сообщение отправить нельзя
+func (s *Store) CheckAIQuota(attemptID uint64) (*AIQuota, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+	test, ok := s.tests[attempt.TestID]
+	if !ok {
+		return nil, errors.New("test not found")
+	}
+
+	quota := &AIQuota{
+		MessageLimit: test.AIMessageLimit,
+		TokenLimit:   test.AITokenLimit,
+		AICost:       attempt.AICost,
+	}
+	for _, thread := range s.aiThreadsByID {
+		if thread.AttemptID != attemptID {
+			continue
+		}
+		for _, message := range thread.Messages {
+			if message.Role == AIRoleUser {
+				quota.MessagesUsed++
+			}
+			quota.TokensUsed += message.PromptTokens + message.CompletionTokens
+		}
+	}
+
+	// Сообщения в очереди тоже расходуют лимит, иначе его можно обойти пачкой запросов
+	quota.MessagesUsed += s.pendingAIRuns(attemptID)
+
+	exhausted := false
+	if quota.MessageLimit > 0 {
+		quota.MessagesRemaining = max(quota.MessageLimit-quota.MessagesUsed, 0)
+		exhausted = quota.MessagesRemaining == 0
+	}
+	if quota.TokenLimit > 0 {
+		quota.TokensRemaining = max(quota.TokenLimit-quota.TokensUsed, 0)
+		exhausted = exhausted || quota.TokensRemaining == 0
+	}
+	if exhausted {
+		return quota, ErrAIQuotaExhausted
+	}
+
+	return quota, nil
+}
+
+// aiThreadContext собирает контекст нового диалога по шаблону промпта теста, а без шаблона - из теста,
+// текста вопроса и правил помощи. Возвращает и версию шаблона, если он использован.
+// Эталонный ответ в контекст не попадает. Вызывается под блокировкой
+func (s *Store) aiThreadContext(attempt *Attempt, questionPos uint64) (string, *PromptVersionRef) {
+	test, ok := s.tests[attempt.TestID]
+	if !ok {
+		return "", nil
+	}
+
+	config := s.resolveAIConfig(attempt, questionPos)
+	policy := config.HelpPolicy
+	if policy == "" {
+		policy = DefaultAIHelpPolicy
+	}
+
+	// Шаблон теста заменяет контекст по умолчанию
+	if context, prompt, ok := s.renderTestPrompt(test, attempt, questionPos, policy, config.Language); ok {
+		return context, prompt
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Студент проходит тест «%s» и работает над вопросом №%d.\n", test.Name, questionPos)
+	if question, ok := s.findQuestionByID(test.ID, attempt.Answers[questionPos-1].QuestionID); ok {
+		fmt.Fprintf(&b, "Текст вопроса: %s\n", strings.TrimSpace(s.questionText(attempt, questionPos, question)))
+	}
+	fmt.Fprintf(&b, "Правила помощи: %s", policy)
+
+	return b.String(), nil
+}
+
+// AIThreadContext возвращает контекст диалога или пустую строку, если диалог неизвестен
+func (s *Store) AIThreadContext(threadID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if thread, ok := s.aiThreadsByID[threadID]; ok {
+		return thread.Context
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"math"
+	"sort"
+)
+
+// AIQuestionAnalytics - как часто студенты обращаются к ассистенту по вопросу и как это связано с баллом
+type AIQuestionAnalytics struct {
+	QuestionID        uint64   `json:"question_id"`
+	Name              string   `json:"name,omitempty"`
+	Attempts          int      `json:"attempts"`                          // сданные попытки, в которые попал вопрос
+	AttemptsWithAI    int      `json:"attempts_with_ai"`                  // из них с диалогом по вопросу
+	AIRate            float64  `json:"ai_rate"`                           // доля попыток с диалогом
+	Threads           int      `json:"threads"`                           // диалоги, в которых студент написал хотя бы одно сообщение
+	AvgTurns          float64  `json:"avg_turns"`                         // среднее число сообщений студента в диалоге
+	AvgScoreWithAI    *float64 `json:"avg_score_with_ai,omitempty"`       // средний балл за вопрос с ассистентом
+	AvgScoreWithoutAI *float64 `json:"avg_score_without_ai,omitempty"`    // средний балл за вопрос без ассистента
+	TurnsScoreCorr    *float64 `json:"turns_score_correlation,omitempty"` // корреляция Пирсона числа сообщений и балла
+}
+
+// AITestAnalytics - использование ассистента в тесте по сданным попыткам
+type AITestAnalytics struct {
+	TestID             uint64                 `json:"test_id"`
+	Attempts           int                    `json:"attempts"`
+	AttemptsWithAI     int                    `json:"attempts_with_ai"`
+	AvgTurnsPerAttempt float64                `json:"avg_turns_per_attempt"`
+	AvgResultWithAI    *float64               `json:"avg_result_with_ai,omitempty"`
+	AvgResultWithoutAI *float64               `json:"avg_result_without_ai,omitempty"`
+	TurnsResultCorr    *float64               `json:"turns_result_correlation,omitempty"` // корреляция Пирсона числа сообщений и результата
+	Questions          []*AIQuestionAnalytics `json:"questions"`                          // чаще всего требующие помощи первыми
+}
+
+// aiQuestionSample - сообщения студента по вопросу и балл за него в одной попытке
+type aiQuestionSample struct {
+	turns   int
+	threads int
+	score   float64
+	graded  bool
+}
+
+// AIAnalytics собирает аналитику использования ассистента по тесту и его вопросам.
+// Учитываются только сданные попытки, баллы - только проверенные ответы
+func (s *Store) AIAnalytics(testID uint64) (*AITestAnalytics, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	// Сообщения студента по диалогам: попытка -> позиция вопроса -> ходы в каждом диалоге
+	turns := make(map[uint64]map[uint64][]int)
+	for _, thread := range s.aiThreadsByID {
+		n := 0
+		for _, message := range thread.Messages {
+			if message.Role == AIRoleUser {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		if turns[thread.AttemptID] == nil {
+			turns[thread.AttemptID] = make(map[uint64][]int)
+		}
+		turns[thread.AttemptID][thread.QuestionPosition] = append(turns[thread.AttemptID][thread.QuestionPosition], n)
+	}
+
+	result := &AITestAnalytics{TestID: testID, Questions: make([]*AIQuestionAnalytics, 0)}
+	samples := make(map[uint64][]aiQuestionSample)
+	var attemptTurns, attemptResults, withAI, withoutAI []float64
+
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status != "submitted" {
+			continue
+		}
+		result.Attempts++
+
+		total := 0
+		for i, answer := range attempt.Answers {
+			sample := aiQuestionSample{score: float64(answer.Score), graded: answer.Status == "graded"}
+			for _, n := range turns[attempt.ID][uint64(i+1)] {
+				sample.turns += n
+				sample.threads++
+			}
+			total += sample.turns
+			samples[answer.QuestionID] = append(samples[answer.QuestionID], sample)
+		}
+
+		if total > 0 {
+			result.AttemptsWithAI++
+			withAI = append(withAI, float64(attempt.Result))
+		} else {
+			withoutAI = append(withoutAI, float64(attempt.Result))
+		}
+		attemptTurns = append(attemptTurns, float64(total))
+		attemptResults = append(attemptResults, float64(attempt.Result))
+	}
+
+	if result.Attempts > 0 {
+		result.AvgTurnsPerAttempt = mean(attemptTurns)
+	}
+	result.AvgResultWithAI = optionalMean(withAI)
+	result.AvgResultWithoutAI = optionalMean(withoutAI)
+	result.TurnsResultCorr = pearson(attemptTurns, attemptResults)
+
+	for _, question := range test.Questions {
+		questionSamples, ok := samples[question.ID]
+		if !ok {
+			continue
+		}
+		result.Questions = append(result.Questions, questionAIAnalytics(question, questionSamples))
+	}
+
+	sort.Slice(result.Questions, func(i, j int) bool {
+		if result.Questions[i].AIRate != result.Questions[j].AIRate {
+			return result.Questions[i].AIRate > result.Questions[j].AIRate
+		}
+		return result.Questions[i].QuestionID < result.Questions[j].QuestionID
+	})
+
+	return result, nil
+}
+
+// questionAIAnalytics сводит выборку попыток по одному вопросу
+func questionAIAnalytics(question *Question, samples []aiQuestionSample) *AIQuestionAnalytics {
+	entry := &AIQuestionAnalytics{
+		QuestionID: question.ID,
+		Name:       question.Name,
+		Attempts:   len(samples),
+	}
+
+	totalTurns := 0
+	var turns, scores, withAI, withoutAI []float64
+	for _, sample := range samples {
+		if sample.threads > 0 {
+			entry.AttemptsWithAI++
+			entry.Threads += sample.threads
+			totalTurns += sample.turns
+		}
+		if !sample.graded {
+			continue
+		}
+		turns = append(turns, float64(sample.turns))
+		scores = append(scores, sample.score)
+		if sample.threads > 0 {
+			withAI = append(withAI, sample.score)
+		} else {
+			withoutAI = append(withoutAI, sample.score)
+		}
+	}
+
+	entry.AIRate = float64(entry.AttemptsWithAI) / float64(entry.Attempts)
+	if entry.Threads > 0 {
+		entry.AvgTurns = float64(totalTurns) / float64(entry.Threads)
+	}
+	entry.AvgScoreWithAI = optionalMean(withAI)
+	entry.AvgScoreWithoutAI = optionalMean(withoutAI)
+	entry.TurnsScoreCorr = pearson(turns, scores)
+
+	return entry
+}
+
+// mean возвращает среднее непустой выборки
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// optionalMean возвращает среднее или nil для пустой выборки
+func optionalMean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	m := mean(values)
+	return &m
+}
+
+// pearson возвращает коэффициент корреляции Пирсона или nil, если он не определен
+// (меньше двух наблюдений или одна из величин не меняется)
+func pearson(xs, ys []float64) *float64 {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return nil
+	}
+
+	mx, my := mean(xs), mean(ys)
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return nil
+	}
+
+	r := cov / math.Sqrt(vx*vy)
+	return &r
+}
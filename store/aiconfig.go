@@ -0,0 +1,213 @@
+package store
+
+import (
+	"GEEK_back/aiguard"
+	"GEEK_back/aitools"
+	"errors"
+	"time"
+)
+
+// maxAITemperature - верхняя граница температуры, которую принимают провайдеры
+const maxAITemperature = 2.0
+
+// AIConfig - настройки ассистента теста или вопроса. Пустые поля наследуются:
+// вопрос - от теста, тест - от настроек сервера
+type AIConfig struct {
+	AssistantID string        `json:"assistantId,omitempty"`
+	Model       string        `json:"model,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty" validate:"omitempty,gte=0,lte=2"`
+	HelpPolicy  string        `json:"helpPolicy,omitempty"`                                                // какую помощь ассистент может оказывать, пусто = DefaultAIHelpPolicy
+	Strictness  string        `json:"strictness,omitempty" validate:"omitempty,oneof=off low medium high"` // строгость отказа на просьбы решить вопрос: off, low, medium, high
+	CacheTTL    time.Duration `json:"cacheTtl,omitempty" swaggertype:"integer" validate:"gte=0"`           // сколько переиспользовать одинаковые ответы на одинаковые запросы, 0 = не кешировать
+	Tools       string        `json:"tools,omitempty"`                                                     // серверные функции ассистента через запятую (calculator, materials), off = без функций
+	HintCost    uint64        `json:"hintCost,omitempty"`                                                  // сколько баллов снимается за каждый ответ ассистента, 0 = бесплатно
+	MaxTurns    int           `json:"maxTurns,omitempty" validate:"gte=0"`                                 // сколько сообщений студент может отправить в один диалог, 0 = без ограничения
+	Language    string        `json:"language,omitempty"`                                                  // код языка ISO 639-1, на котором ассистент обязан отвечать, пусто = язык студента
+	Paraphrase  bool          `json:"paraphrase,omitempty"`                                                // перефразировать текст вопроса для каждой попытки, чтобы затруднить обмен ответами
+}
+
+// validate проверяет настройки ассистента
+func (c *AIConfig) validate() error {
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > maxAITemperature) {
+		return errors.New("temperature must be between 0 and 2")
+	}
+	if err := validAILanguage(c.Language); err != nil {
+		return err
+	}
+	if c.MaxTurns < 0 {
+		return errors.New("maxTurns must not be negative")
+	}
+	if c.CacheTTL < 0 {
+		return errors.New("cacheTtl must not be negative")
+	}
+	if !aiguard.ValidStrictness(c.Strictness) {
+		return errors.New("strictness must be one of: off, low, medium, high")
+	}
+	if _, err := aitools.Parse(c.Tools); err != nil {
+		return err
+	}
+	return nil
+}
+
+// overlay накладывает непустые поля override поверх c
+func (c AIConfig) overlay(override *AIConfig) AIConfig {
+	if override == nil {
+		return c
+	}
+	if override.AssistantID != "" {
+		c.AssistantID = override.AssistantID
+	}
+	if override.Model != "" {
+		c.Model = override.Model
+	}
+	if override.Temperature != nil {
+		c.Temperature = override.Temperature
+	}
+	if override.HelpPolicy != "" {
+		c.HelpPolicy = override.HelpPolicy
+	}
+	if override.Strictness != "" {
+		c.Strictness = override.Strictness
+	}
+	if override.CacheTTL != 0 {
+		c.CacheTTL = override.CacheTTL
+	}
+	if override.Tools != "" {
+		c.Tools = override.Tools
+	}
+	if override.HintCost != 0 {
+		c.HintCost = override.HintCost
+	}
+	if override.MaxTurns != 0 {
+		c.MaxTurns = override.MaxTurns
+	}
+	if override.Language != "" {
+		c.Language = override.Language
+	}
+	if override.Paraphrase {
+		c.Paraphrase = true
+	}
+	return c
+}
+
+// SetTestAIConfig задает настройки ассистента теста, nil сбрасывает их.
+// version - версия теста, которую видел клиент, 0 - без проверки
+func (s *Store) SetTestAIConfig(testID uint64, config *AIConfig, version uint64) (*Test, error) {
+	if config != nil {
+		if err := config.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+	if err := checkTestVersion(test, version); err != nil {
+		return nil, err
+	}
+
+	test.AIConfig = config
+	test.Version++
+
+	return test, nil
+}
+
+// SetQuestionAIConfig задает настройки ассистента для отдельного вопроса, nil сбрасывает их.
+// version - версия вопроса, которую видел клиент, 0 - без проверки
+func (s *Store) SetQuestionAIConfig(testID, questionID uint64, config *AIConfig, version uint64) (*Question, error) {
+	if config != nil {
+		if err := config.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, err := s.findActiveQuestion(testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQuestionVersion(question, version); err != nil {
+		return nil, err
+	}
+
+	question.AIConfig = config
+	question.Version++
+
+	return question, nil
+}
+
+// SetQuestionMaterials задает справочные материалы вопроса, в которых ищет функция materials ассистента.
+// version - версия вопроса, которую видел клиент, 0 - без проверки
+func (s *Store) SetQuestionMaterials(testID, questionID uint64, materials []string, version uint64) (*Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, err := s.findActiveQuestion(testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQuestionVersion(question, version); err != nil {
+		return nil, err
+	}
+
+	question.Materials = materials
+	question.Version++
+
+	return question, nil
+}
+
+// ResolveAIConfig возвращает настройки ассистента для вопроса попытки: вопрос переопределяет тест
+func (s *Store) ResolveAIConfig(attemptID, questionPos uint64) (AIConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return AIConfig{}, errors.New("attempt not found")
+	}
+	if questionPos == 0 || questionPos > uint64(len(attempt.Answers)) {
+		return AIConfig{}, errors.New("invalid question position")
+	}
+
+	return s.resolveAIConfig(attempt, questionPos), nil
+}
+
+// resolveAIConfig накладывает настройки вопроса на настройки теста, вызывается под блокировкой
+func (s *Store) resolveAIConfig(attempt *Attempt, questionPos uint64) AIConfig {
+	var config AIConfig
+	if test, ok := s.tests[attempt.TestID]; ok {
+		config = config.overlay(test.AIConfig)
+	}
+	if question, ok := s.findQuestionByID(attempt.TestID, attempt.Answers[questionPos-1].QuestionID); ok {
+		config = config.overlay(question.AIConfig)
+	}
+	return config
+}
+
+// AttemptQuestion возвращает копию вопроса на позиции questionPos попытки
+func (s *Store) AttemptQuestion(attemptID, questionPos uint64) (*Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+	if questionPos == 0 || questionPos > uint64(len(attempt.Answers)) {
+		return nil, errors.New("invalid question position")
+	}
+
+	question, ok := s.findQuestionByID(attempt.TestID, attempt.Answers[questionPos-1].QuestionID)
+	if !ok {
+		return nil, errors.New("question not found")
+	}
+
+	result := *question
+	return &result, nil
+}
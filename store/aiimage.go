@@ -0,0 +1,97 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAIImageNotFound = errors.New("image not found")
+
+// AIImage - изображение, загруженное студентом для отправки ассистенту (например, фото решения).
+// Содержимое лежит в файловом хранилище по ключу Key
+type AIImage struct {
+	ID          uint64    `json:"id"`
+	AttemptID   uint64    `json:"attempt_id"`
+	ThreadID    string    `json:"thread_id"`
+	UserID      uint64    `json:"user_id"`
+	ContentType string    `json:"content_type"`
+	Size        int       `json:"size"`
+	Key         string    `json:"-"` // ключ содержимого в файловом хранилище
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AddAIImage добавляет изображение для диалога попытки, уже сохраненное в файловом хранилище по ключу key.
+// Загружать могут только участники попытки в открытый диалог
+func (s *Store) AddAIImage(attemptID uint64, threadID string, userID uint64, contentType string, size int, key string) (*AIImage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok || thread.AttemptID != attemptID {
+		return nil, ErrAIThreadNotFound
+	}
+	if thread.Status == AIThreadClosed {
+		return nil, ErrAIThreadClosed
+	}
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, ErrAIThreadNotFound
+	}
+	if !s.canAccessAttempt(attempt, userID) {
+		return nil, ErrAIThreadAccess
+	}
+
+	image := &AIImage{
+		ID:          s.nextAIImageID,
+		AttemptID:   attemptID,
+		ThreadID:    threadID,
+		UserID:      userID,
+		ContentType: contentType,
+		Size:        size,
+		Key:         key,
+		CreatedAt:   time.Now().UTC(),
+	}
+	s.aiImages[image.ID] = image
+	s.nextAIImageID++
+
+	return image, nil
+}
+
+// GetAIImage возвращает изображение диалога. Доступно участникам попытки, преподавателям и администраторам
+func (s *Store) GetAIImage(attemptID uint64, threadID string, imageID, userID uint64) (*AIImage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	image, ok := s.aiImages[imageID]
+	if !ok || image.AttemptID != attemptID || image.ThreadID != threadID {
+		return nil, ErrAIImageNotFound
+	}
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, ErrAIImageNotFound
+	}
+	if !s.canAccessAttempt(attempt, userID) && !s.isStaff(userID) {
+		return nil, ErrAIThreadAccess
+	}
+
+	return image, nil
+}
+
+// AIImagesForMessage возвращает изображения, которые пользователь загрузил в диалог, в порядке imageIDs
+func (s *Store) AIImagesForMessage(threadID string, userID uint64, imageIDs []uint64) ([]*AIImage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	images := make([]*AIImage, 0, len(imageIDs))
+	for _, id := range imageIDs {
+		image, ok := s.aiImages[id]
+		if !ok || image.ThreadID != threadID || image.UserID != userID {
+			return nil, ErrAIImageNotFound
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// aiLanguage - название языка для инструкции ассистенту
+type aiLanguage struct {
+	name          string // именительный падеж, для шаблонов промптов
+	prepositional string // предложный падеж, для инструкции «отвечай на ... языке»
+}
+
+// aiLanguages - языки, на которых можно потребовать отвечать ассистента, по кодам ISO 639-1
+var aiLanguages = map[string]aiLanguage{
+	"ru": {"русский", "русском"},
+	"en": {"английский", "английском"},
+	"kk": {"казахский", "казахском"},
+	"uz": {"узбекский", "узбекском"},
+	"ky": {"киргизский", "киргизском"},
+	"tt": {"татарский", "татарском"},
+	"be": {"белорусский", "белорусском"},
+	"uk": {"украинский", "украинском"},
+	"de": {"немецкий", "немецком"},
+	"fr": {"французский", "французском"},
+	"es": {"испанский", "испанском"},
+	"zh": {"китайский", "китайском"},
+}
+
+// validAILanguage проверяет код языка ответов ассистента, пустой код означает отсутствие требования
+func validAILanguage(code string) error {
+	if code == "" {
+		return nil
+	}
+	if _, ok := aiLanguages[code]; ok {
+		return nil
+	}
+
+	codes := make([]string, 0, len(aiLanguages))
+	for c := range aiLanguages {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return fmt.Errorf("language must be one of: %s", strings.Join(codes, ", "))
+}
+
+// AILanguageName возвращает название языка по коду или пустую строку для неизвестного кода
+func AILanguageName(code string) string {
+	return aiLanguages[code].name
+}
+
+// AILanguageInstruction возвращает системную инструкцию отвечать на заданном языке.
+// Без нее ассистент подстраивается под язык студента и смешивает языки на локализованных экзаменах
+func AILanguageInstruction(code string) string {
+	language, ok := aiLanguages[code]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Отвечай только на %s языке, даже если студент пишет на другом языке или просит перейти на другой язык. "+
+		"Термины и фрагменты кода из вопроса можно приводить как есть.", language.prepositional)
+}
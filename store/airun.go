@@ -0,0 +1,182 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// Статусы асинхронного запроса к ассистенту
+const (
+	AIRunQueued    = "queued"
+	AIRunRunning   = "running"
+	AIRunCompleted = "completed"
+	AIRunFailed    = "failed"
+)
+
+var (
+	ErrAIRunNotFound   = errors.New("run not found")
+	ErrAIRunInProgress = errors.New("assistant is still answering the previous message in this thread")
+)
+
+// AIRun - сообщение ассистенту, поставленное в очередь на обработку
+type AIRun struct {
+	ID               uint64     `json:"id"`
+	AttemptID        uint64     `json:"attempt_id"`
+	QuestionPosition uint64     `json:"question_position"`
+	ThreadID         string     `json:"thread_id"`
+	UserID           uint64     `json:"user_id"`
+	Status           string     `json:"status"`
+	Response         string     `json:"response,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	Cached           bool       `json:"cached,omitempty"`     // ответ взят из кеша без запроса к провайдеру
+	MessageID        int        `json:"message_id,omitempty"` // номер ответа ассистента в истории диалога, начиная с 1
+	CreatedAt        time.Time  `json:"created_at"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// CreateAIRun ставит сообщение ассистенту в очередь. В одном треде одновременно идет только один запрос:
+// провайдер не принимает новое сообщение, пока ассистент отвечает на предыдущее
+func (s *Store) CreateAIRun(attemptID, questionPos, userID uint64, threadID string) (*AIRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.attempts[attemptID]; !ok {
+		return nil, errors.New("attempt not found")
+	}
+	if s.activeAIRun(threadID) != nil {
+		return nil, ErrAIRunInProgress
+	}
+
+	run := &AIRun{
+		ID:               s.nextAIRunID,
+		AttemptID:        attemptID,
+		QuestionPosition: questionPos,
+		ThreadID:         threadID,
+		UserID:           userID,
+		Status:           AIRunQueued,
+		CreatedAt:        time.Now().UTC(),
+	}
+	s.aiRuns[run.ID] = run
+	s.nextAIRunID++
+
+	result := *run
+	return &result, nil
+}
+
+// StartAIRun отмечает, что воркер взял запрос в работу
+func (s *Store) StartAIRun(runID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.aiRuns[runID]
+	if !ok {
+		return ErrAIRunNotFound
+	}
+
+	now := time.Now().UTC()
+	run.Status = AIRunRunning
+	run.StartedAt = &now
+
+	return nil
+}
+
+// FinishAIRun сохраняет ответ ассистента или ошибку обработки
+func (s *Store) FinishAIRun(runID uint64, response string, cached bool, runErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.aiRuns[runID]
+	if !ok {
+		return ErrAIRunNotFound
+	}
+
+	now := time.Now().UTC()
+	run.FinishedAt = &now
+	if runErr != nil {
+		run.Status = AIRunFailed
+		run.Error = runErr.Error()
+		return nil
+	}
+
+	run.Status = AIRunCompleted
+	run.Response = response
+	run.Cached = cached
+	// Ответ ассистента сохраняется в историю до завершения запроса и стоит в ней последним
+	if thread, ok := s.aiThreadsByID[run.ThreadID]; ok {
+		run.MessageID = len(thread.Messages)
+	}
+
+	return nil
+}
+
+// GetAIRun возвращает копию запроса к ассистенту; доступен участникам попытки, преподавателям и администраторам
+func (s *Store) GetAIRun(attemptID, runID, userID uint64) (*AIRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	run, ok := s.aiRuns[runID]
+	if !ok || run.AttemptID != attemptID {
+		return nil, ErrAIRunNotFound
+	}
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, ErrAIRunNotFound
+	}
+	if !s.canAccessAttempt(attempt, userID) && !s.isStaff(userID) {
+		return nil, ErrAIThreadAccess
+	}
+
+	result := *run
+	return &result, nil
+}
+
+// pendingAIRuns считает запросы попытки, которые еще в очереди или в работе, вызывается под блокировкой
+func (s *Store) pendingAIRuns(attemptID uint64) int {
+	count := 0
+	for _, run := range s.aiRuns {
+		if run.AttemptID == attemptID && (run.Status == AIRunQueued || run.Status == AIRunRunning) {
+			count++
+		}
+	}
+	return count
+}
+
+// ActiveAIRun возвращает копию запроса, который сейчас в очереди или в работе в треде
+func (s *Store) ActiveAIRun(threadID string) (*AIRun, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	run := s.activeAIRun(threadID)
+	if run == nil {
+		return nil, false
+	}
+
+	result := *run
+	return &result, true
+}
+
+// activeAIRun ищет незавершенный запрос треда, вызывается под блокировкой
+func (s *Store) activeAIRun(threadID string) *AIRun {
+	for _, run := range s.aiRuns {
+		if run.ThreadID == threadID && (run.Status == AIRunQueued || run.Status == AIRunRunning) {
+			return run
+		}
+	}
+	return nil
+}
+
+// AIRunByID возвращает копию запроса к ассистенту без проверки доступа, для внутренних уведомлений
+func (s *Store) AIRunByID(runID uint64) (*AIRun, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	run, ok := s.aiRuns[runID]
+	if !ok {
+		return nil, false
+	}
+
+	result := *run
+	return &result, true
+}
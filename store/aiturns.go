@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAIDialogLimit возвращается, если студент исчерпал лимит ходов диалога. Диалог при этом закрыт,
+// поэтому ошибка также считается ErrAIThreadClosed
+var ErrAIDialogLimit = fmt.Errorf("dialog limit reached: %w", ErrAIThreadClosed)
+
+// AITurns - сколько ходов (сообщений студента) сделано в диалоге. Лимит 0 означает отсутствие ограничения
+type AITurns struct {
+	Used      int `json:"used"`
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining,omitempty"`
+}
+
+// exhausted сообщает, что новое сообщение в диалог отправить нельзя
+func (t AITurns) exhausted() bool {
+	return t.Limit > 0 && t.Used >= t.Limit
+}
+
+// AIThreadTurns возвращает расход ходов диалога по лимиту теста или вопроса
+func (s *Store) AIThreadTurns(threadID string) (*AITurns, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok {
+		return nil, ErrAIThreadNotFound
+	}
+	attempt, ok := s.attempts[thread.AttemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	turns := s.aiThreadTurns(attempt, thread, true)
+	return &turns, nil
+}
+
+// aiThreadTurns считает ходы диалога. С pending учитывается и сообщение, на которое ассистент еще отвечает
+// (оно сохраняется в истории только вместе с ответом). Вызывается под блокировкой
+func (s *Store) aiThreadTurns(attempt *Attempt, thread *AIThread, pending bool) AITurns {
+	turns := AITurns{Limit: s.resolveAIConfig(attempt, thread.QuestionPosition).MaxTurns}
+	for _, message := range thread.Messages {
+		if message.Role == AIRoleUser {
+			turns.Used++
+		}
+	}
+	if pending && s.activeAIRun(thread.ThreadID) != nil {
+		turns.Used++
+	}
+
+	if turns.Limit > 0 {
+		turns.Remaining = max(turns.Limit-turns.Used, 0)
+	}
+	return turns
+}
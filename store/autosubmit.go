@@ -0,0 +1,53 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// SubmitExpiredAttempts сдает начатые попытки, у которых закончились время и льготный период:
+// после этого студент уже не может сдать попытку сам, и без автосдачи она навсегда осталась бы начатой.
+// Попытка закрывается моментом окончания льготного периода и считается сданной с опозданием,
+// если на какой-то вопрос ответили после дедлайна. Возвращает ID сданных попыток
+func (s *Store) SubmitExpiredAttempts() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	submitted := make([]uint64, 0)
+	for _, attempt := range s.attempts {
+		if attempt.Status != "started" {
+			continue
+		}
+		test, ok := s.tests[attempt.TestID]
+		if !ok || test.TimeLimit == 0 {
+			continue
+		}
+
+		deadline := attemptDeadline(attempt, test, now)
+		closedAt := deadline.Add(test.GracePeriod)
+		if !now.After(closedAt) {
+			continue
+		}
+
+		for _, answer := range attempt.Answers {
+			if answer.CreatedAt.After(deadline) {
+				attempt.Late = true
+			}
+		}
+		attempt.FinishedAt = closedAt
+
+		if s.enqueueManualReviews(attempt) > 0 {
+			attempt.Status = "grading"
+		} else {
+			s.finalizeAttempt(attempt)
+		}
+		submitted = append(submitted, attempt.ID)
+	}
+
+	sort.Slice(submitted, func(i, j int) bool {
+		return submitted[i] < submitted[j]
+	})
+
+	return submitted
+}
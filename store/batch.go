@@ -0,0 +1,101 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// BatchSyncWindow - сколько после дедлайна (с льготным периодом) сервер еще принимает буферизованные ответы
+	BatchSyncWindow = 5 * time.Minute
+	// maxClientClockSkew - допустимое расхождение часов клиента и сервера
+	maxClientClockSkew = time.Minute
+)
+
+// BatchAnswer - ответ, сохраненный клиентом офлайн
+type BatchAnswer struct {
+	QuestionPosition uint64    `json:"question_position" validate:"required"`
+	Text             string    `json:"text"`
+	ClientTimestamp  time.Time `json:"client_timestamp" validate:"required"`
+}
+
+// BatchAnswerResult - результат синхронизации одного ответа
+type BatchAnswerResult struct {
+	QuestionPosition uint64  `json:"question_position"`
+	Accepted         bool    `json:"accepted"`
+	Error            string  `json:"error,omitempty"`
+	Answer           *Answer `json:"answer,omitempty"`
+}
+
+// SyncAnswers принимает пачку ответов, сохраненных клиентом без сети. Каждый ответ проверяется
+// по времени клиента относительно дедлайна без текущей автопаузы; более старый ответ не перезаписывает более новый
+func (s *Store) SyncAnswers(attemptID, userID uint64, answers []BatchAnswer) ([]*BatchAnswerResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || !s.canAccessAttempt(attempt, userID) {
+		return nil, errors.New("attempt not found")
+	}
+	if attempt.Status != "started" {
+		return nil, errors.New("attempt closed")
+	}
+
+	test, ok := s.tests[attempt.TestID]
+	if !ok {
+		return nil, errors.New("test not found")
+	}
+
+	// Время без связи - не пауза: студент все это время отвечал, поэтому текущая автопауза
+	// в дедлайн не входит и после синхронизации не засчитывается
+	now := time.Now().UTC()
+	var deadline time.Time
+	if test.TimeLimit > 0 {
+		deadline = pausedDeadline(attempt, test).Add(test.GracePeriod)
+		if now.After(deadline.Add(BatchSyncWindow)) {
+			return nil, errors.New("test attempt timeout")
+		}
+	}
+
+	results := make([]*BatchAnswerResult, 0, len(answers))
+	for _, item := range answers {
+		result := &BatchAnswerResult{QuestionPosition: item.QuestionPosition}
+		results = append(results, result)
+
+		at := item.ClientTimestamp.UTC()
+		switch {
+		case at.IsZero():
+			result.Error = "client_timestamp is required"
+			continue
+		case at.Before(attempt.StartedAt.Add(-maxClientClockSkew)):
+			result.Error = "client_timestamp is before attempt start"
+			continue
+		case at.After(now.Add(maxClientClockSkew)):
+			result.Error = "client_timestamp is in the future"
+			continue
+		case !deadline.IsZero() && at.After(deadline):
+			result.Error = "answer was made after the deadline"
+			continue
+		}
+
+		if item.QuestionPosition > 0 && item.QuestionPosition <= uint64(len(attempt.Answers)) {
+			existing := attempt.Answers[item.QuestionPosition-1]
+			if existing.CreatedAt.After(at) {
+				result.Error = "a newer answer is already saved"
+				continue
+			}
+		}
+
+		answer, err := s.applyAnswer(attempt, userID, item.QuestionPosition, item.Text, at, now)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		result.Accepted = true
+		result.Answer = answer
+		attempt.LastSeenAt = now
+	}
+
+	return results, nil
+}
@@ -0,0 +1,193 @@
+// Нагрузочные замеры хранилища: хранилище в памяти наполняется синтетическими данными
+// (GenerateSynthetic), горячие пути прогоняются параллельно из нескольких горутин. Нужны, чтобы
+// сравнивать изменения блокировок и индексов Store на одном и том же объеме данных:
+//
+//	go test ./store -run '^$' -bench . -benchmem -cpu 1,8
+//
+// Объем данных задается флагами -bench.users, -bench.attempts и -bench.seed, тесты берутся
+// из -bench.fixture (по умолчанию встроенные демонстрационные данные)
+package store_test
+
+import (
+	"GEEK_back/store"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	benchFixture  = flag.String("bench.fixture", "", "seed fixture with tests (JSON or YAML), default is the built-in demo data")
+	benchUsers    = flag.Int("bench.users", 2000, "synthetic students")
+	benchAttempts = flag.Int("bench.attempts", 5, "attempts per student on average")
+	benchSeed     = flag.Int64("bench.seed", 1, "generator seed")
+)
+
+// benchData - данные, на которых идут замеры; собираются один раз на запуск
+type benchData struct {
+	store    *store.Store
+	users    []uint64 // синтетические студенты
+	sessions []string // по одной сессии на студента
+	tests    []uint64
+	next     atomic.Uint64 // раздает горутинам разных студентов
+}
+
+var (
+	benchOnce sync.Once
+	bench     *benchData
+	benchErr  error
+)
+
+func TestMain(m *testing.M) {
+	// Хранилище пишет служебные сообщения в журнал, на замерах они только мешают
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	os.Exit(m.Run())
+}
+
+// benchEnv загружает тесты из фикстуры, генерирует студентов с попытками и открывает им сессии
+func benchEnv(b *testing.B) *benchData {
+	benchOnce.Do(func() {
+		s := store.NewStore()
+		if benchErr = s.InitFillStore(*benchFixture); benchErr != nil {
+			return
+		}
+		generated, err := s.GenerateSynthetic(store.SyntheticOptions{Users: *benchUsers, AttemptsPerUser: *benchAttempts, Seed: *benchSeed})
+		if err != nil {
+			benchErr = err
+			return
+		}
+
+		bench = &benchData{store: s}
+		for id := generated.FirstUserID; id <= generated.LastUserID; id++ {
+			bench.users = append(bench.users, id)
+			bench.sessions = append(bench.sessions, s.CreateSession(id))
+		}
+		for _, test := range s.ListTests() {
+			bench.tests = append(bench.tests, test.ID)
+		}
+		if len(bench.users) == 0 || len(bench.tests) == 0 {
+			benchErr = fmt.Errorf("no synthetic users or tests: %d users, %d tests", len(bench.users), len(bench.tests))
+		}
+	})
+	if benchErr != nil {
+		b.Fatal(benchErr)
+	}
+	return bench
+}
+
+// user возвращает индекс очередного студента: горутины работают с разными пользователями
+func (d *benchData) user() int {
+	return int(d.next.Add(1) % uint64(len(d.users)))
+}
+
+// runParallel повторяет операцию из нескольких горутин; newOp вызывается в каждой горутине
+func runParallel(b *testing.B, newOp func() func()) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		op := newOp()
+		for pb.Next() {
+			op()
+		}
+	})
+}
+
+func BenchmarkGetUserBySession(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		return func() {
+			d.store.GetUserBySession(d.sessions[i])
+			i = (i + 1) % len(d.sessions)
+		}
+	})
+}
+
+// BenchmarkLogin - вход и выход, проверка пароля идет параллельно с записью сессий
+func BenchmarkLogin(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		return func() {
+			user, err := d.store.AuthenticateUser(fmt.Sprintf("student%d@load.test", i+1), "test")
+			if err == nil {
+				d.store.DeleteSession(d.store.CreateSession(user.ID))
+			}
+			i = (i + 1) % len(d.users)
+		}
+	})
+}
+
+func BenchmarkGetUserAttemptHistory(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		return func() {
+			_, _ = d.store.GetUserAttemptHistory(d.users[i], d.tests[i%len(d.tests)])
+			i = (i + 1) % len(d.users)
+		}
+	})
+}
+
+func BenchmarkCheckRetakeCooldown(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		return func() {
+			_ = d.store.CheckRetakeCooldown(d.users[i], d.tests[i%len(d.tests)])
+			i = (i + 1) % len(d.users)
+		}
+	})
+}
+
+func BenchmarkListAttemptsByTest(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		return func() {
+			d.store.ListAttempts(store.AttemptFilter{TestID: d.tests[i%len(d.tests)]})
+			i++
+		}
+	})
+}
+
+// BenchmarkCreateAnswer - каждая горутина отвечает в своей попытке, запись идет под эксклюзивной блокировкой
+func BenchmarkCreateAnswer(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		attempt, err := d.store.CreateAttempt(d.tests[i%len(d.tests)], d.users[i])
+		if err != nil || len(attempt.Answers) == 0 {
+			b.Error("cannot start attempt:", err)
+			return func() {}
+		}
+		position := uint64(0)
+		return func() {
+			_, _ = d.store.CreateAnswer(attempt.ID, d.users[i], position%uint64(len(attempt.Answers))+1, "ответ")
+			position++
+		}
+	})
+}
+
+// BenchmarkSessionsDuringScans - проверка сессии, пока каждая двадцатая операция - полный просмотр попыток
+// администратором: показывает, ждут ли обычные запросы долгих проходов по хранилищу
+func BenchmarkSessionsDuringScans(b *testing.B) {
+	d := benchEnv(b)
+	runParallel(b, func() func() {
+		i := d.user()
+		n := 0
+		return func() {
+			if n%20 == 0 {
+				d.store.ListAttempts(store.AttemptFilter{})
+			} else {
+				d.store.GetUserBySession(d.sessions[i])
+				i = (i + 1) % len(d.sessions)
+			}
+			n++
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var ErrAIBudgetExceeded = errors.New("monthly ai budget exceeded")
+
+// AIBudget - месячный лимит расходов на ассистента. OrgID 0 - общий лимит всех организаций
+type AIBudget struct {
+	OrgID      uint64  `json:"org_id"`
+	MonthlyUSD float64 `json:"monthly_usd"` // 0 = без лимита
+	SpentUSD   float64 `json:"spent_usd"`   // оценка расхода за текущий месяц
+	Exceeded   bool    `json:"exceeded"`    // ассистент отключен до конца месяца или повышения лимита
+}
+
+// AIBudgetReport - расход и лимиты за текущий месяц
+type AIBudgetReport struct {
+	Month   string      `json:"month"` // UTC, в формате 2006-01
+	Budgets []*AIBudget `json:"budgets"`
+}
+
+// currentAIMonth возвращает месяц учета расхода
+func currentAIMonth() string {
+	return time.Now().UTC().Format("2006-01")
+}
+
+// NextAIBudgetReset возвращает время обнуления месячного расхода
+func NextAIBudgetReset() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// attemptOrgID возвращает организацию теста попытки, вызывается под блокировкой
+func (s *Store) attemptOrgID(attempt *Attempt) uint64 {
+	if test, ok := s.tests[attempt.TestID]; ok {
+		return test.OrgID
+	}
+	return 0
+}
+
+// addAISpend добавляет стоимость ответа к расходу месяца, вызывается под блокировкой
+func (s *Store) addAISpend(orgID uint64, cost float64) {
+	if month := currentAIMonth(); s.aiSpendMonth != month {
+		s.aiSpendMonth = month
+		s.aiSpend = make(map[uint64]float64)
+	}
+
+	s.aiSpend[0] += cost
+	if orgID != 0 {
+		s.aiSpend[orgID] += cost
+	}
+}
+
+// aiBudget возвращает лимит и расход за текущий месяц, вызывается под блокировкой
+func (s *Store) aiBudget(orgID uint64) *AIBudget {
+	budget := &AIBudget{OrgID: orgID, MonthlyUSD: s.aiBudgets[orgID]}
+	if s.aiSpendMonth == currentAIMonth() {
+		budget.SpentUSD = s.aiSpend[orgID]
+	}
+	budget.Exceeded = budget.MonthlyUSD > 0 && budget.SpentUSD >= budget.MonthlyUSD
+	return budget
+}
+
+// SetAIBudget задает месячный лимит расходов на ассистента для организации (0 - общий лимит).
+// Нулевой лимит снимает ограничение
+func (s *Store) SetAIBudget(orgID uint64, monthlyUSD float64) (*AIBudget, error) {
+	if monthlyUSD < 0 {
+		return nil, errors.New("budget must not be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if orgID != 0 {
+		if _, ok := s.organizations[orgID]; !ok {
+			return nil, errors.New("organization not found")
+		}
+	}
+
+	if monthlyUSD == 0 {
+		delete(s.aiBudgets, orgID)
+	} else {
+		s.aiBudgets[orgID] = monthlyUSD
+	}
+
+	return s.aiBudget(orgID), nil
+}
+
+// AIBudgetReport возвращает общий лимит и расход, а также организации с лимитом или расходом в этом месяце
+func (s *Store) AIBudgetReport() *AIBudgetReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orgIDs := map[uint64]bool{0: true}
+	for orgID := range s.aiBudgets {
+		orgIDs[orgID] = true
+	}
+	if s.aiSpendMonth == currentAIMonth() {
+		for orgID := range s.aiSpend {
+			orgIDs[orgID] = true
+		}
+	}
+
+	report := &AIBudgetReport{Month: currentAIMonth(), Budgets: make([]*AIBudget, 0, len(orgIDs))}
+	for orgID := range orgIDs {
+		report.Budgets = append(report.Budgets, s.aiBudget(orgID))
+	}
+
+	sort.Slice(report.Budgets, func(i, j int) bool {
+		return report.Budgets[i].OrgID < report.Budgets[j].OrgID
+	})
+
+	return report
+}
+
+// CheckAIBudget проверяет общий лимит и лимит организации теста попытки.
+// Возвращает исчерпанный лимит вместе с ErrAIBudgetExceeded
+func (s *Store) CheckAIBudget(attemptID uint64) (*AIBudget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	if budget := s.aiBudget(0); budget.Exceeded {
+		return budget, ErrAIBudgetExceeded
+	}
+	if orgID := s.attemptOrgID(attempt); orgID != 0 {
+		if budget := s.aiBudget(orgID); budget.Exceeded {
+			return budget, ErrAIBudgetExceeded
+		}
+	}
+
+	return nil, nil
+}
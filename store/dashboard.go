@@ -0,0 +1,377 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultDashboardDays и MaxDashboardDays - за сколько последних дней панель считает недавние сдачи
+const (
+	DefaultDashboardDays = 7
+	MaxDashboardDays     = 365
+)
+
+// TeacherTestSummary - основные показатели теста на панели преподавателя
+type TeacherTestSummary struct {
+	TestID            uint64     `json:"test_id"`
+	Name              string     `json:"name"`
+	OrgID             uint64     `json:"org_id,omitempty"`
+	MaxScore          uint64     `json:"max_score"`
+	OpenAttempts      int        `json:"open_attempts"`              // идут сейчас
+	AwaitingReview    int        `json:"awaiting_review"`            // сданы и ждут ручной проверки
+	RecentSubmissions int        `json:"recent_submissions"`         // сданы после since, включая ждущие проверки
+	AttemptsGraded    int        `json:"attempts_graded"`            // оценены за все время, по ним считается средний результат
+	AvgScore          *float64   `json:"avg_score,omitempty"`        // средний результат оцененных попыток
+	RecentAvgScore    *float64   `json:"recent_avg_score,omitempty"` // средний результат оцененных после since
+	LastSubmissionAt  *time.Time `json:"last_submission_at,omitempty"`
+	AIHintCost        uint64     `json:"ai_hint_cost"` // баллы, снятые за подсказки ассистента во всех попытках
+	AIUsage           AIUsage    `json:"ai_usage"`     // расход токенов ассистента по тесту
+}
+
+// TeacherDashboard - тесты преподавателя с показателями и итоги по ним
+type TeacherDashboard struct {
+	Since             time.Time             `json:"since"`
+	OpenAttempts      int                   `json:"open_attempts"`
+	AwaitingReview    int                   `json:"awaiting_review"`
+	RecentSubmissions int                   `json:"recent_submissions"`
+	AIUsage           AIUsage               `json:"ai_usage"`
+	Tests             []*TeacherTestSummary `json:"tests"` // сначала тесты с недавней сдачей
+}
+
+// TeacherDashboard собирает панель преподавателя за один проход по попыткам его тестов (см. teacherTests)
+func (s *Store) TeacherDashboard(userID uint64, since time.Time) (*TeacherDashboard, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.lookupUser(userID)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	dashboard := &TeacherDashboard{Since: since, Tests: make([]*TeacherTestSummary, 0)}
+	for _, test := range s.teacherTests(user) {
+		summary := s.teacherTestSummary(test, since)
+		dashboard.Tests = append(dashboard.Tests, summary)
+
+		dashboard.OpenAttempts += summary.OpenAttempts
+		dashboard.AwaitingReview += summary.AwaitingReview
+		dashboard.RecentSubmissions += summary.RecentSubmissions
+		dashboard.AIUsage.merge(summary.AIUsage)
+	}
+
+	sort.Slice(dashboard.Tests, func(i, j int) bool {
+		a, b := dashboard.Tests[i].LastSubmissionAt, dashboard.Tests[j].LastSubmissionAt
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && !a.Equal(*b) {
+			return a.After(*b)
+		}
+		return dashboard.Tests[i].TestID < dashboard.Tests[j].TestID
+	})
+
+	return dashboard, nil
+}
+
+// teacherTests возвращает неудаленные тесты преподавателя: у тестов нет автора, поэтому это тесты его организации,
+// а без организации и у администратора - все тесты. Вызывается под блокировкой
+func (s *Store) teacherTests(user *User) []*Test {
+	tests := make([]*Test, 0)
+	for _, test := range s.tests {
+		if test.DeletedAt == nil && managesTest(user, test) {
+			tests = append(tests, test)
+		}
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].ID < tests[j].ID
+	})
+	return tests
+}
+
+// CanManageTest сообщает, относится ли тест, в том числе удаленный, к тестам преподавателя (см. teacherTests).
+// Для несуществующего теста возвращает ErrTestNotFound
+func (s *Store) CanManageTest(userID, testID uint64) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.lookupUser(userID)
+	if !ok {
+		return false, ErrUserNotFound
+	}
+	test, ok := s.tests[testID]
+	if !ok {
+		return false, ErrTestNotFound
+	}
+	return managesTest(user, test), nil
+}
+
+// CanManageAttempt сообщает, относится ли тест попытки к тестам преподавателя (см. CanManageTest).
+// Для несуществующей попытки возвращает ErrAttemptNotFound
+func (s *Store) CanManageAttempt(userID, attemptID uint64) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.lookupUser(userID)
+	if !ok {
+		return false, ErrUserNotFound
+	}
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return false, ErrAttemptNotFound
+	}
+	test, ok := s.tests[attempt.TestID]
+	if !ok {
+		return false, ErrTestNotFound
+	}
+	return managesTest(user, test), nil
+}
+
+// managesTest сообщает, может ли пользователь управлять тестом: администратор и преподаватель без организации -
+// любым, остальные - тестами своей организации
+func managesTest(user *User, test *Test) bool {
+	return user.Role == RoleAdmin || user.OrgID == 0 || test.OrgID == user.OrgID
+}
+
+// teacherTestSummary считает показатели теста, вызывается под блокировкой
+func (s *Store) teacherTestSummary(test *Test, since time.Time) *TeacherTestSummary {
+	summary := &TeacherTestSummary{TestID: test.ID, Name: test.Name, OrgID: test.OrgID, MaxScore: test.MaxScore}
+	if usage, ok := s.aiUsageByTest[test.ID]; ok {
+		summary.AIUsage = *usage
+	}
+
+	var scores, recentScores []float64
+	var last time.Time
+	for _, attempt := range s.attemptsByTest[test.ID] {
+		summary.AIHintCost += attempt.AICost
+		if attempt.Status == "started" {
+			summary.OpenAttempts++
+			continue
+		}
+
+		recent := !attempt.FinishedAt.Before(since)
+		if recent {
+			summary.RecentSubmissions++
+		}
+		if attempt.FinishedAt.After(last) {
+			last = attempt.FinishedAt
+		}
+		switch attempt.Status {
+		case "grading":
+			summary.AwaitingReview++
+		case "submitted":
+			summary.AttemptsGraded++
+			scores = append(scores, float64(attempt.Result))
+			if recent {
+				recentScores = append(recentScores, float64(attempt.Result))
+			}
+		}
+	}
+
+	summary.AvgScore = optionalMean(scores)
+	summary.RecentAvgScore = optionalMean(recentScores)
+	if !last.IsZero() {
+		summary.LastSubmissionAt = &last
+	}
+	return summary
+}
+
+// StudentRecentResults - сколько последних сданных попыток показывает панель студента
+const StudentRecentResults = 10
+
+// StudentTest - тест, который студент может начать без кода доступа
+type StudentTest struct {
+	TestID      uint64        `json:"test_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	TimeLimit   time.Duration `json:"time_limit" swaggertype:"integer"`
+	MaxScore    uint64        `json:"max_score"`
+	TeamMode    bool          `json:"team_mode"`
+	Assigned    bool          `json:"assigned"`              // назначен классу студента, иначе тест со свободным доступом
+	Attempts    int           `json:"attempts"`              // попытки студента по тесту
+	BestResult  *uint64       `json:"best_result,omitempty"` // лучший результат оцененных попыток
+	// Когда закончится пауза между попытками, только у тестов из upcoming
+	AvailableAt *time.Time `json:"available_at,omitempty"`
+}
+
+// StudentAttempt - идущая попытка студента
+type StudentAttempt struct {
+	AttemptID uint64     `json:"attempt_id"`
+	TestID    uint64     `json:"test_id"`
+	TestName  string     `json:"test_name"`
+	GroupID   uint64     `json:"group_id,omitempty"` // командная попытка
+	StartedAt time.Time  `json:"started_at"`
+	Deadline  *time.Time `json:"deadline,omitempty"` // без лимита времени дедлайна нет
+	// Оставшееся время с учетом автопаузы, не меньше 0; только при лимите времени
+	RemainingSeconds *int64 `json:"remaining_seconds,omitempty"`
+	Paused           bool   `json:"paused"`
+	Answered         int    `json:"answered"`
+	Questions        int    `json:"questions"`
+}
+
+// StudentResult - сданная попытка студента
+type StudentResult struct {
+	AttemptID  uint64    `json:"attempt_id"`
+	TestID     uint64    `json:"test_id"`
+	TestName   string    `json:"test_name"`
+	Status     string    `json:"status"` // grading - результат еще не окончательный
+	FinishedAt time.Time `json:"finished_at"`
+	Result     uint64    `json:"result"`
+	MaxScore   uint64    `json:"max_score"`
+	Late       bool      `json:"late"`
+	Penalty    uint64    `json:"penalty"`
+}
+
+// StudentDashboard - главная страница студента
+type StudentDashboard struct {
+	Available     []*StudentTest    `json:"available"`      // можно начать сейчас
+	Upcoming      []*StudentTest    `json:"upcoming"`       // можно будет начать после паузы между попытками, ближайшие первыми
+	InProgress    []*StudentAttempt `json:"in_progress"`    // меньше всего времени осталось первыми
+	RecentResults []*StudentResult  `json:"recent_results"` // последние сданные первыми
+}
+
+// StudentDashboard собирает главную страницу студента: тесты со свободным доступом и назначенные его классам,
+// идущие попытки, включая командные, и последние результаты. Тесты с идущей попыткой не повторяются в available
+func (s *Store) StudentDashboard(userID uint64) (*StudentDashboard, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.lookupUser(userID); !ok {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now().UTC()
+	dashboard := &StudentDashboard{
+		Available:     make([]*StudentTest, 0),
+		Upcoming:      make([]*StudentTest, 0),
+		InProgress:    make([]*StudentAttempt, 0),
+		RecentResults: make([]*StudentResult, 0),
+	}
+
+	// Попытки студента и командные попытки его классов
+	assigned := make(map[uint64]bool)
+	attempts := append([]*Attempt{}, s.attemptsByUser[userID]...)
+	for _, group := range s.groups {
+		if !group.hasMember(userID) {
+			continue
+		}
+		for _, testID := range group.TestIDs {
+			assigned[testID] = true
+			for _, attempt := range s.attemptsByTest[testID] {
+				if attempt.GroupID == group.ID && attempt.UserID != userID {
+					attempts = append(attempts, attempt)
+				}
+			}
+		}
+	}
+
+	inProgress := make(map[uint64]bool)
+	for _, attempt := range attempts {
+		test, ok := s.activeTest(attempt.TestID)
+		if !ok {
+			continue
+		}
+		switch attempt.Status {
+		case "started":
+			inProgress[test.ID] = true
+			dashboard.InProgress = append(dashboard.InProgress, studentAttempt(attempt, test, now))
+		case "grading", "submitted":
+			dashboard.RecentResults = append(dashboard.RecentResults, &StudentResult{
+				AttemptID:  attempt.ID,
+				TestID:     test.ID,
+				TestName:   test.Name,
+				Status:     attempt.Status,
+				FinishedAt: attempt.FinishedAt,
+				Result:     attempt.Result,
+				MaxScore:   test.MaxScore,
+				Late:       attempt.Late,
+				Penalty:    attempt.Penalty,
+			})
+		}
+	}
+
+	for _, test := range s.tests {
+		if test.DeletedAt != nil || inProgress[test.ID] || !(test.OpenEnrollment || assigned[test.ID]) {
+			continue
+		}
+		entry := &StudentTest{
+			TestID:      test.ID,
+			Name:        test.Name,
+			Description: test.Description,
+			TimeLimit:   test.TimeLimit,
+			MaxScore:    test.MaxScore,
+			TeamMode:    test.TeamMode,
+			Assigned:    assigned[test.ID],
+		}
+		for _, attempt := range s.attemptsByUser[userID] {
+			if attempt.TestID != test.ID {
+				continue
+			}
+			entry.Attempts++
+			if attempt.Status == "submitted" && (entry.BestResult == nil || attempt.Result > *entry.BestResult) {
+				result := attempt.Result
+				entry.BestResult = &result
+			}
+		}
+		if next := s.retakeAvailableAt(userID, test); now.Before(next) {
+			entry.AvailableAt = &next
+			dashboard.Upcoming = append(dashboard.Upcoming, entry)
+		} else {
+			dashboard.Available = append(dashboard.Available, entry)
+		}
+	}
+
+	sort.Slice(dashboard.Available, func(i, j int) bool {
+		return dashboard.Available[i].TestID < dashboard.Available[j].TestID
+	})
+	sort.Slice(dashboard.Upcoming, func(i, j int) bool {
+		return dashboard.Upcoming[i].AvailableAt.Before(*dashboard.Upcoming[j].AvailableAt)
+	})
+	// Попытки без лимита времени идут после попыток с дедлайном
+	sort.Slice(dashboard.InProgress, func(i, j int) bool {
+		a, b := dashboard.InProgress[i].Deadline, dashboard.InProgress[j].Deadline
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return dashboard.InProgress[i].AttemptID < dashboard.InProgress[j].AttemptID
+	})
+	sort.Slice(dashboard.RecentResults, func(i, j int) bool {
+		a, b := dashboard.RecentResults[i], dashboard.RecentResults[j]
+		if !a.FinishedAt.Equal(b.FinishedAt) {
+			return a.FinishedAt.After(b.FinishedAt)
+		}
+		return a.AttemptID > b.AttemptID
+	})
+	if len(dashboard.RecentResults) > StudentRecentResults {
+		dashboard.RecentResults = dashboard.RecentResults[:StudentRecentResults]
+	}
+
+	return dashboard, nil
+}
+
+// studentAttempt описывает идущую попытку, вызывается под блокировкой
+func studentAttempt(attempt *Attempt, test *Test, now time.Time) *StudentAttempt {
+	entry := &StudentAttempt{
+		AttemptID: attempt.ID,
+		TestID:    test.ID,
+		TestName:  test.Name,
+		GroupID:   attempt.GroupID,
+		StartedAt: attempt.StartedAt,
+		Paused:    currentPause(attempt, test, now) > 0,
+		Questions: len(attempt.Answers),
+	}
+	for _, answer := range attempt.Answers {
+		if !answer.CreatedAt.IsZero() {
+			entry.Answered++
+		}
+	}
+	if test.TimeLimit > 0 {
+		deadline := attemptDeadline(attempt, test, now)
+		remaining := int64(max(deadline.Sub(now), 0).Seconds())
+		entry.Deadline = &deadline
+		entry.RemainingSeconds = &remaining
+	}
+	return entry
+}
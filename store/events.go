@@ -0,0 +1,165 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// TimeWarning - предупреждение участникам попытки, что время заканчивается
+type TimeWarning struct {
+	AttemptID        uint64    `json:"attempt_id"`
+	TestID           uint64    `json:"test_id"`
+	Deadline         time.Time `json:"deadline"`
+	RemainingSeconds int64     `json:"remaining_seconds"`
+	UserIDs          []uint64  `json:"-"` // кому отправить: студент или вся команда
+}
+
+// DueTimeWarnings находит начатые попытки, у которых оставшееся время впервые опустилось
+// ниже одного из порогов thresholds, и запоминает, что о пороге предупредили.
+// О каждом пороге попытки предупреждают один раз; если сразу пройдено несколько порогов, предупреждение одно
+func (s *Store) DueTimeWarnings(thresholds []time.Duration) []*TimeWarning {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	warnings := make([]*TimeWarning, 0)
+	for _, attempt := range s.attempts {
+		if attempt.Status != "started" {
+			continue
+		}
+		test, ok := s.tests[attempt.TestID]
+		if !ok || test.TimeLimit == 0 {
+			continue
+		}
+
+		deadline := attemptDeadline(attempt, test, now)
+		remaining := deadline.Sub(now)
+		if remaining <= 0 {
+			continue
+		}
+
+		// Наименьший пройденный порог: при остатке 50 секунд из порогов 5m и 1m это 1m
+		var crossed time.Duration
+		for _, threshold := range thresholds {
+			if remaining <= threshold && (crossed == 0 || threshold < crossed) {
+				crossed = threshold
+			}
+		}
+		if crossed == 0 || (attempt.timeWarning != 0 && crossed >= attempt.timeWarning) {
+			continue
+		}
+		attempt.timeWarning = crossed
+
+		warnings = append(warnings, &TimeWarning{
+			AttemptID:        attempt.ID,
+			TestID:           attempt.TestID,
+			Deadline:         deadline,
+			RemainingSeconds: int64(remaining.Seconds()),
+			UserIDs:          s.attemptUserIDs(attempt),
+		})
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].AttemptID < warnings[j].AttemptID
+	})
+
+	return warnings
+}
+
+// AttemptUserIDs возвращает участников попытки: студента или всю команду
+func (s *Store) AttemptUserIDs(attemptID uint64) []uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil
+	}
+	return s.attemptUserIDs(attempt)
+}
+
+// attemptUserIDs возвращает участников попытки, вызывается под блокировкой
+func (s *Store) attemptUserIDs(attempt *Attempt) []uint64 {
+	if attempt.GroupID != 0 {
+		if group, ok := s.groups[attempt.GroupID]; ok {
+			userIDs := append([]uint64{}, group.MemberIDs...)
+			if !group.hasMember(attempt.UserID) {
+				userIDs = append(userIDs, attempt.UserID)
+			}
+			return userIDs
+		}
+	}
+	return []uint64{attempt.UserID}
+}
+
+// AttemptGrade - итог сданной попытки для участников
+type AttemptGrade struct {
+	AttemptID  uint64    `json:"attempt_id"`
+	TestID     uint64    `json:"test_id"`
+	Status     string    `json:"status"` // grading, пока преподаватель не проверил все ответы, затем submitted
+	Result     uint64    `json:"result"`
+	Penalty    uint64    `json:"penalty"`
+	Late       bool      `json:"late"`
+	FinishedAt time.Time `json:"finished_at"`
+	UserIDs    []uint64  `json:"-"`
+}
+
+// GradedAttempt возвращает итог попытки, если она сдана и полностью оценена
+func (s *Store) GradedAttempt(attemptID uint64) (*AttemptGrade, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || attempt.Status != "submitted" {
+		return nil, false
+	}
+	return s.attemptGrade(attempt), true
+}
+
+// SubmittedAttempt возвращает итог сданной попытки, в том числе ожидающей ручной проверки
+func (s *Store) SubmittedAttempt(attemptID uint64) (*AttemptGrade, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || attempt.Status == "started" {
+		return nil, false
+	}
+	return s.attemptGrade(attempt), true
+}
+
+// attemptGrade собирает итог попытки, вызывается под блокировкой
+func (s *Store) attemptGrade(attempt *Attempt) *AttemptGrade {
+	return &AttemptGrade{
+		AttemptID:  attempt.ID,
+		TestID:     attempt.TestID,
+		Status:     attempt.Status,
+		Result:     attempt.Result,
+		Penalty:    attempt.Penalty,
+		Late:       attempt.Late,
+		FinishedAt: attempt.FinishedAt,
+		UserIDs:    s.attemptUserIDs(attempt),
+	}
+}
+
+// ErrAttemptClosed - попытка уже сдана
+var ErrAttemptClosed = errors.New("attempt closed")
+
+// ErrAttemptNotFound - попытки нет
+var ErrAttemptNotFound = errors.New("attempt not found")
+
+// CheckAttemptOpen проверяет, что пользователь участвует в попытке и она еще не сдана
+func (s *Store) CheckAttemptOpen(attemptID, userID uint64) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || !s.canAccessAttempt(attempt, userID) {
+		return errors.New("attempt not found")
+	}
+	if attempt.Status != "started" {
+		return ErrAttemptClosed
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Feedback - комментарий преподавателя к попытке целиком или к отдельному ответу
+type Feedback struct {
+	ID               uint64    `json:"id"`
+	AttemptID        uint64    `json:"attempt_id"`
+	QuestionPosition *uint64   `json:"question_position,omitempty"` // nil = комментарий ко всей попытке
+	AuthorID         uint64    `json:"author_id"`
+	Text             string    `json:"text"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// AddFeedback добавляет комментарий к попытке и уведомляет студента
+func (s *Store) AddFeedback(attemptID, authorID uint64, questionPos *uint64, text string) (*Feedback, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	if questionPos != nil && (*questionPos == 0 || *questionPos > uint64(len(attempt.Answers))) {
+		return nil, errors.New("question position out of range")
+	}
+
+	feedback := &Feedback{
+		ID:               s.nextFeedbackID,
+		AttemptID:        attemptID,
+		QuestionPosition: questionPos,
+		AuthorID:         authorID,
+		Text:             text,
+		CreatedAt:        time.Now().UTC(),
+	}
+	attempt.Feedback = append(attempt.Feedback, feedback)
+	s.nextFeedbackID++
+
+	message := fmt.Sprintf("new feedback on attempt %d", attemptID)
+	if questionPos != nil {
+		message = fmt.Sprintf("new feedback on question %d of attempt %d", *questionPos, attemptID)
+	}
+	s.notify(attempt.UserID, "feedback", message, attemptID)
+
+	return feedback, nil
+}
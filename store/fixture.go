@@ -0,0 +1,450 @@
+package store
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// demoFixture - демонстрационные данные, которые загружаются без SEED_FIXTURE
+//
+//go:embed fixtures/demo.yaml
+var demoFixture []byte
+
+// fixture - начальные данные хранилища: пользователи, тесты с вопросами и коды доступа.
+// Поля тестов и вопросов называются так же, как в JSON ответов API
+type fixture struct {
+	Users       []fixtureUser       `json:"users"`
+	Tests       []fixtureTest       `json:"tests"`
+	AccessCodes []fixtureAccessCode `json:"accessCodes"`
+}
+
+type fixtureUser struct {
+	Email        string     `json:"email"`
+	Password     string     `json:"password,omitempty"`
+	PasswordHash string     `json:"passwordHash,omitempty"` // bcrypt-хеш из снимка, заменяет password
+	Role         string     `json:"role,omitempty"`         // student по умолчанию
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`    // удаленный пользователь из снимка
+}
+
+type fixtureTest struct {
+	ID             uint64          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	TimeLimit      fixtureDuration `json:"timeLimit,omitempty"`
+	MaxScore       uint64          `json:"maxScore"`
+	NumOfQuestions uint64          `json:"numOfQuestions,omitempty"` // 0 = все вопросы теста
+	GracePeriod    fixtureDuration `json:"gracePeriod,omitempty"`
+	LatePenalty    uint64          `json:"latePenalty,omitempty"`
+	RetakeCooldown fixtureDuration `json:"retakeCooldown,omitempty"`
+	AutoPauseAfter fixtureDuration `json:"autoPauseAfter,omitempty"`
+	TeamMode       bool            `json:"teamMode,omitempty"`
+	OpenEnrollment bool            `json:"openEnrollment,omitempty"`
+	AIMessageLimit int             `json:"aiMessageLimit,omitempty"`
+	AITokenLimit   int             `json:"aiTokenLimit,omitempty"`
+	AIConfig       *AIConfig       `json:"aiConfig,omitempty"`
+	Questions      []*Question     `json:"questions"` // у удаленных вопросов задан deletedAt
+	DeletedAt      *time.Time      `json:"deletedAt,omitempty"`
+	Version        uint64          `json:"version,omitempty"` // версия из снимка, 1 по умолчанию
+}
+
+type fixtureAccessCode struct {
+	Code             string          `json:"code"`
+	TestID           uint64          `json:"testId"`
+	MaxUses          *uint64         `json:"maxUses,omitempty"`   // не задано = бесконечный
+	ExpiresAt        *time.Time      `json:"expiresAt,omitempty"` // не задано = не истекает
+	BoundEmail       string          `json:"boundEmail,omitempty"`
+	ActivationWindow fixtureDuration `json:"activationWindow,omitempty"`
+
+	// Состояние кода из снимка: без него восстановленный одноразовый код можно было бы использовать снова
+	UsedCount   uint64     `json:"usedCount,omitempty"`
+	ActivatedAt *time.Time `json:"activatedAt,omitempty"`
+	ConsumedAt  *time.Time `json:"consumedAt,omitempty"`
+	Suspended   bool       `json:"suspended,omitempty"`
+}
+
+// fixtureDuration - длительность в фикстуре, записывается строкой в формате time.ParseDuration ("1h30m")
+type fixtureDuration time.Duration
+
+func (d fixtureDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *fixtureDuration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.New("duration must be a string like \"1h30m\"")
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if parsed < 0 {
+		return errors.New("duration must not be negative")
+	}
+	*d = fixtureDuration(parsed)
+	return nil
+}
+
+// InitFillStore заполняет хранилище начальными данными из файла фикстуры path (JSON или YAML
+// по расширению .yaml/.yml). Пустой path загружает встроенные демонстрационные данные
+func (s *Store) InitFillStore(path string) error {
+	data, name := demoFixture, "fixtures/demo.yaml"
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("init fill store: %w", err)
+		}
+		name = path
+	}
+
+	f, err := parseFixture(data, name)
+	if err != nil {
+		return fmt.Errorf("init fill store: %s: %w", name, err)
+	}
+	if _, err := s.loadFixture(f, false); err != nil {
+		return fmt.Errorf("init fill store: %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// parseFixture читает фикстуру. YAML сначала переводится в JSON, чтобы у обоих форматов
+// были одни имена полей и одна строгая проверка неизвестных полей
+func parseFixture(data []byte, name string) (*fixture, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var f fixture
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// loadFixture добавляет в хранилище пользователей, тесты и коды доступа фикстуры по порядку.
+// Коды ссылаются на тесты, поэтому тесты создаются раньше. Фикстура проверяется целиком до изменений:
+// поля, повторы внутри нее, занятые email, ID тестов и коды, тесты кодов. Проверка и добавление идут
+// под одной блокировкой, поэтому при ошибке хранилище не меняется.
+// С merge уже существующие пользователи (по email), тесты (по ID) и коды пропускаются, без него это ошибка
+func (s *Store) loadFixture(f *fixture, merge bool) (*ImportResult, error) {
+	emails := make(map[string]bool, len(f.Users))
+	for i, u := range f.Users {
+		if err := u.validate(); err != nil {
+			return nil, fmt.Errorf("users[%d]: %w", i, err)
+		}
+		if emails[u.Email] {
+			return nil, fmt.Errorf("users[%d]: duplicate email %s", i, u.Email)
+		}
+		emails[u.Email] = true
+	}
+	tests := make([]*Test, 0, len(f.Tests))
+	testIDs := make(map[uint64]bool, len(f.Tests))
+	for i, t := range f.Tests {
+		test, err := t.test()
+		if err != nil {
+			return nil, fmt.Errorf("tests[%d]: %w", i, err)
+		}
+		if testIDs[test.ID] {
+			return nil, fmt.Errorf("tests[%d]: duplicate test id %d", i, test.ID)
+		}
+		testIDs[test.ID] = true
+		tests = append(tests, test)
+	}
+	for i, c := range f.AccessCodes {
+		if c.Code == "" {
+			return nil, fmt.Errorf("accessCodes[%d]: code is required", i)
+		}
+	}
+
+	// bcrypt медленный, поэтому пароли хешируются до блокировки
+	hashes := make([]string, len(f.Users))
+	for i, u := range f.Users {
+		hashes[i] = u.PasswordHash
+		if hashes[i] == "" {
+			hashed, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return nil, fmt.Errorf("users[%d]: cannot hash password: %w", i, err)
+			}
+			hashes[i] = string(hashed)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	if err := s.checkFixtureConflicts(f, testIDs, merge); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Skipped: make([]string, 0)}
+
+	for i, u := range f.Users {
+		role := u.Role
+		if role == "" {
+			role = RoleStudent
+		}
+
+		user, err := s.addUserLocked(u.Email, hashes[i], role)
+		if merge && errors.Is(err, ErrUserExists) {
+			result.Skipped = append(result.Skipped, "user "+u.Email)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("users[%d]: %w", i, err)
+		}
+		user.DeletedAt = u.DeletedAt
+		result.Users++
+	}
+
+	for i, test := range tests {
+		added, err := s.addFixtureTest(test, merge)
+		if err != nil {
+			return nil, fmt.Errorf("tests[%d]: %w", i, err)
+		}
+		if !added {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("test %d", test.ID))
+			continue
+		}
+		result.Tests++
+	}
+
+	for i, c := range f.AccessCodes {
+		accessCode := newAccessCode(c.Code, AccessCodeOptions{
+			MaxUses:          c.MaxUses,
+			ExpiresAt:        c.ExpiresAt,
+			BoundEmail:       c.BoundEmail,
+			ActivationWindow: time.Duration(c.ActivationWindow),
+		})
+		accessCode.TestID = c.TestID
+		accessCode.UsedCount = c.UsedCount
+		accessCode.ActivatedAt = c.ActivatedAt
+		accessCode.ConsumedAt = c.ConsumedAt
+		if c.Suspended {
+			accessCode.Suspended = true
+			accessCode.SuspendedAt = &accessCode.CreatedAt
+		}
+
+		added, err := s.addFixtureAccessCode(accessCode, merge)
+		if err != nil {
+			return nil, fmt.Errorf("accessCodes[%d]: %w", i, err)
+		}
+		if !added {
+			result.Skipped = append(result.Skipped, "access code "+c.Code)
+			continue
+		}
+		result.AccessCodes++
+	}
+
+	return result, nil
+}
+
+// checkFixtureConflicts сверяет фикстуру с хранилищем до изменений: без merge занятые email, ID тестов
+// и коды - ошибка. Код должен ссылаться на тест хранилища или фикстуры (testIDs), а после префикса
+// организации не совпадать с другим кодом фикстуры. Новый код вида XXXX-XXXX-C должен нести верный
+// контрольный символ. Вызывается под блокировками mu и authMu
+func (s *Store) checkFixtureConflicts(f *fixture, testIDs map[uint64]bool, merge bool) error {
+	if !merge {
+		for i, u := range f.Users {
+			if _, ok := s.usersByEmail[u.Email]; ok {
+				return fmt.Errorf("users[%d]: %w", i, ErrUserExists)
+			}
+		}
+		for i, t := range f.Tests {
+			if _, exists := s.tests[t.ID]; exists {
+				return fmt.Errorf("tests[%d]: duplicate test id %d", i, t.ID)
+			}
+		}
+	}
+
+	codes := make(map[string]bool, len(f.AccessCodes))
+	for i, c := range f.AccessCodes {
+		// Тест хранилища остается при merge, тест фикстуры создается без организации
+		var orgID uint64
+		if test, ok := s.tests[c.TestID]; ok {
+			orgID = test.OrgID
+		} else if !testIDs[c.TestID] {
+			return fmt.Errorf("accessCodes[%d]: test %d not found", i, c.TestID)
+		}
+
+		code := namespacedCode(s.codePrefix(orgID), c.Code)
+		if codes[code] {
+			return fmt.Errorf("accessCodes[%d]: duplicate code %s", i, code)
+		}
+		codes[code] = true
+		if _, ok := s.accessCodes[code]; ok {
+			if !merge {
+				return fmt.Errorf("accessCodes[%d]: %w", i, errAccessCodeExists)
+			}
+			continue
+		}
+		if !checksumConsistent(code) {
+			return fmt.Errorf("accessCodes[%d]: %w", i, errAccessCodeChecksum)
+		}
+	}
+
+	return nil
+}
+
+// validate проверяет пользователя фикстуры: нужен email и пароль или его хеш
+func (u *fixtureUser) validate() error {
+	if u.Email == "" {
+		return errors.New("email is required")
+	}
+	switch {
+	case u.Password == "" && u.PasswordHash == "":
+		return errors.New("password or passwordHash is required")
+	case u.Password != "" && u.PasswordHash != "":
+		return errors.New("password and passwordHash cannot be used together")
+	case u.PasswordHash != "":
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			return fmt.Errorf("passwordHash: %w", err)
+		}
+	}
+	switch u.Role {
+	case "", RoleStudent, RoleTeacher, RoleAdmin:
+	default:
+		return errors.New("role must be one of: student, teacher, admin")
+	}
+	return nil
+}
+
+// test проверяет тест фикстуры и собирает из него тест хранилища
+func (t *fixtureTest) test() (*Test, error) {
+	if t.ID == 0 {
+		return nil, errors.New("id is required")
+	}
+	if t.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if t.LatePenalty > 100 {
+		return nil, errors.New("latePenalty must be between 0 and 100")
+	}
+	if t.AIConfig != nil {
+		if err := t.AIConfig.validate(); err != nil {
+			return nil, fmt.Errorf("aiConfig: %w", err)
+		}
+	}
+
+	questionIDs := make(map[uint64]bool, len(t.Questions))
+	for i, question := range t.Questions {
+		if err := validateFixtureQuestion(question); err != nil {
+			return nil, fmt.Errorf("questions[%d]: %w", i, err)
+		}
+		if questionIDs[question.ID] {
+			return nil, fmt.Errorf("questions[%d]: duplicate id %d", i, question.ID)
+		}
+		questionIDs[question.ID] = true
+		question.Version = max(question.Version, 1)
+	}
+
+	numOfQuestions := t.NumOfQuestions
+	if numOfQuestions == 0 {
+		numOfQuestions = uint64(len(t.Questions))
+	}
+
+	return &Test{
+		ID:             t.ID,
+		Name:           t.Name,
+		Description:    t.Description,
+		TimeLimit:      time.Duration(t.TimeLimit),
+		MaxScore:       t.MaxScore,
+		Questions:      t.Questions,
+		NumOfQuestions: numOfQuestions,
+		GracePeriod:    time.Duration(t.GracePeriod),
+		LatePenalty:    t.LatePenalty,
+		RetakeCooldown: time.Duration(t.RetakeCooldown),
+		AutoPauseAfter: time.Duration(t.AutoPauseAfter),
+		TeamMode:       t.TeamMode,
+		OpenEnrollment: t.OpenEnrollment,
+		AIMessageLimit: t.AIMessageLimit,
+		AITokenLimit:   t.AITokenLimit,
+		AIConfig:       t.AIConfig,
+		DeletedAt:      t.DeletedAt,
+		Version:        max(t.Version, 1),
+	}, nil
+}
+
+// validateFixtureQuestion проверяет вопрос фикстуры так же, как при изменении через API
+func validateFixtureQuestion(question *Question) error {
+	if question == nil {
+		return errors.New("question is empty")
+	}
+	if question.ID == 0 {
+		return errors.New("id is required")
+	}
+	if question.Text == "" {
+		return errors.New("text is required")
+	}
+
+	switch question.GradingMode {
+	case "", GradingAuto, GradingManual, GradingSemantic:
+	default:
+		return errors.New("gradingMode must be one of: auto, manual, semantic")
+	}
+	if question.Semantic != nil {
+		if question.GradingMode != GradingSemantic {
+			return errors.New("semantic thresholds are allowed only for semantic grading")
+		}
+		if err := question.Semantic.validate(); err != nil {
+			return fmt.Errorf("semantic: %w", err)
+		}
+	}
+	if question.AIConfig != nil {
+		if err := question.AIConfig.validate(); err != nil {
+			return fmt.Errorf("aiConfig: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addFixtureTest сохраняет тест, если его ID еще не занят. С merge занятый ID не ошибка: тест пропускается.
+// Вызывается под блокировкой
+func (s *Store) addFixtureTest(test *Test, merge bool) (bool, error) {
+	if _, exists := s.tests[test.ID]; exists {
+		if merge {
+			return false, nil
+		}
+		return false, fmt.Errorf("duplicate test id %d", test.ID)
+	}
+	s.tests[test.ID] = test
+
+	return true, nil
+}
+
+// addFixtureAccessCode сохраняет код доступа фикстуры. С merge существующий код пропускается.
+// Вызывается под блокировкой
+func (s *Store) addFixtureAccessCode(accessCode *AccessCode, merge bool) (bool, error) {
+	err := s.addAccessCode(accessCode, false)
+	if merge && errors.Is(err, errAccessCodeExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,258 @@
+package store
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Group - учебная группа (команда) пользователей
+type Group struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	MemberIDs []uint64  `json:"member_ids"`
+	TestIDs   []uint64  `json:"test_ids"` // тесты, назначенные классу
+	OrgID     uint64    `json:"org_id,omitempty"`
+	CreatedBy uint64    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// copyGroup копирует группу для ответа API, см. copyAttempt. Вызывается под блокировкой
+func copyGroup(group *Group) *Group {
+	result := *group
+	result.MemberIDs = slices.Clone(group.MemberIDs)
+	result.TestIDs = slices.Clone(group.TestIDs)
+	return &result
+}
+
+// ErrGroupNotFound - группы нет или она принадлежит другому преподавателю
+var ErrGroupNotFound = errors.New("group not found")
+
+// ParticipantEntry - запись журнала участников командной попытки: кто и когда ответил на вопрос
+type ParticipantEntry struct {
+	UserID           uint64    `json:"user_id"`
+	QuestionPosition uint64    `json:"question_position"`
+	Text             string    `json:"text"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// CreateGroup создает группу с указанными участниками
+func (s *Store) CreateGroup(name string, createdBy uint64, memberIDs []uint64) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range memberIDs {
+		if _, ok := s.lookupUser(id); !ok {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	// Класс принадлежит организации создавшего его преподавателя
+	var orgID uint64
+	if creator, ok := s.lookupUser(createdBy); ok {
+		orgID = creator.OrgID
+	}
+
+	group := &Group{
+		ID:        s.nextGroupID,
+		OrgID:     orgID,
+		Name:      name,
+		MemberIDs: append([]uint64{}, memberIDs...),
+		TestIDs:   []uint64{},
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.groups[group.ID] = group
+	s.nextGroupID++
+
+	return copyGroup(group), nil
+}
+
+// GetGroup возвращает группу по ID
+func (s *Store) GetGroup(groupID uint64) (*Group, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, ok := s.groups[groupID]
+	if !ok {
+		return nil, false
+	}
+	return copyGroup(group), true
+}
+
+// AddGroupMember добавляет пользователя в группу преподавателя (см. ownedGroup)
+func (s *Store) AddGroupMember(groupID, teacherID, userID uint64) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, _, err := s.ownedGroup(groupID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.lookupUser(userID); !ok {
+		return nil, ErrUserNotFound
+	}
+
+	if !group.hasMember(userID) {
+		group.MemberIDs = append(group.MemberIDs, userID)
+	}
+
+	return copyGroup(group), nil
+}
+
+// ownedGroup возвращает группу, которой управляет пользователь: ее создатель или администратор.
+// Чужие группы не отличаются от несуществующих. Вызывается под блокировкой
+func (s *Store) ownedGroup(groupID, userID uint64) (*Group, *User, error) {
+	group, ok := s.groups[groupID]
+	if !ok {
+		return nil, nil, ErrGroupNotFound
+	}
+	user, ok := s.lookupUser(userID)
+	if !ok || (user.Role != RoleAdmin && group.CreatedBy != userID) {
+		return nil, nil, ErrGroupNotFound
+	}
+	return group, user, nil
+}
+
+func (g *Group) hasMember(userID uint64) bool {
+	for _, id := range g.MemberIDs {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Group) hasTest(testID uint64) bool {
+	for _, id := range g.TestIDs {
+		if id == testID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AssignGroupTest назначает тест классу, участники получают доступ к нему без кода. Назначить можно
+// только тест преподавателя (см. teacherTests) и только своему классу: иначе класс получил бы
+// доступ к чужому тесту в обход его кодов доступа
+func (s *Store) AssignGroupTest(groupID, teacherID, testID uint64) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, user, err := s.ownedGroup(groupID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	test, ok := s.activeTest(testID)
+	if !ok || !managesTest(user, test) {
+		return nil, ErrTestNotFound
+	}
+
+	if !group.hasTest(testID) {
+		group.TestIDs = append(group.TestIDs, testID)
+	}
+
+	return copyGroup(group), nil
+}
+
+// HasTestAccess проверяет, что пользователь состоит в классе, которому назначен тест
+func (s *Store) HasTestAccess(userID, testID uint64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, group := range s.groups {
+		if group.hasMember(userID) && group.hasTest(testID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateClassCode создает код, который зачисляет пользователя в класс преподавателя (см. ownedGroup)
+func (s *Store) CreateClassCode(code string, groupID, teacherID uint64, opts AccessCodeOptions) (*AccessCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, _, err := s.ownedGroup(groupID, teacherID); err != nil {
+		return nil, err
+	}
+
+	accessCode := newAccessCode(code, opts)
+	accessCode.GroupID = groupID
+
+	if err := s.addAccessCode(accessCode, opts.Checksum); err != nil {
+		return nil, err
+	}
+
+	return accessCode, nil
+}
+
+// EnrollWithCode зачисляет пользователя в класс по коду класса
+func (s *Store) EnrollWithCode(code string, userID uint64) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accessCode, ok := s.accessCodes[code]
+	if !ok || accessCode.GroupID == 0 {
+		return nil, errors.New("invalid access code")
+	}
+
+	if err := s.redeemAccessCode(accessCode, userID); err != nil {
+		return nil, err
+	}
+
+	return copyGroup(s.groups[accessCode.GroupID]), nil
+}
+
+// canAccessAttempt проверяет, что пользователь - владелец попытки или участник ее команды.
+// Вызывается под блокировкой
+func (s *Store) canAccessAttempt(attempt *Attempt, userID uint64) bool {
+	if attempt.UserID == userID {
+		return true
+	}
+	if attempt.GroupID == 0 {
+		return false
+	}
+
+	group, ok := s.groups[attempt.GroupID]
+	return ok && group.hasMember(userID)
+}
+
+// StartTeamAttempt начинает командную попытку или возвращает уже начатую попытку команды
+func (s *Store) StartTeamAttempt(testID, groupID, userID uint64) (*Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+	if !test.TeamMode {
+		return nil, errors.New("test is not a team test")
+	}
+
+	group, ok := s.groups[groupID]
+	if !ok || !group.hasMember(userID) {
+		return nil, errors.New("user is not a member of the group")
+	}
+
+	// Результат общий: все участники работают в одной попытке. Поиск и создание под одной блокировкой,
+	// иначе участники, начавшие одновременно, получили бы разные попытки
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.GroupID == groupID && attempt.Status == "started" {
+			return copyAttempt(attempt), nil
+		}
+	}
+
+	// Новую попытку команды начинает участник, у которого не идет пауза между попытками (см. CreateAttempt)
+	if err := s.checkRetakeCooldown(userID, test); err != nil {
+		return nil, err
+	}
+	attempt := s.createAttemptLocked(test, userID)
+	attempt.GroupID = groupID
+
+	return copyAttempt(attempt), nil
+}
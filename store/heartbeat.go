@@ -0,0 +1,124 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// LivenessWindow - если heartbeat не приходил дольше, студент считается неактивным
+const LivenessWindow = 90 * time.Second
+
+// AttemptLiveness - состояние активности попытки для проктора
+type AttemptLiveness struct {
+	AttemptID  uint64    `json:"attempt_id"`
+	UserID     uint64    `json:"user_id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Active     bool      `json:"active"`
+	Paused     bool      `json:"paused"`
+	Deadline   time.Time `json:"deadline,omitempty"`
+}
+
+// currentPause возвращает длительность текущей автопаузы, если студент молчит дольше порога теста.
+// Все паузы попытки вместе не длиннее лимита времени теста: иначе у брошенной попытки дедлайн
+// сдвигался бы вместе с часами, и она никогда не была бы сдана автоматически
+func currentPause(attempt *Attempt, test *Test, now time.Time) time.Duration {
+	if test.AutoPauseAfter == 0 || attempt.LastSeenAt.IsZero() {
+		return 0
+	}
+
+	silence := now.Sub(attempt.LastSeenAt)
+	if silence <= test.AutoPauseAfter {
+		return 0
+	}
+
+	pause := silence - test.AutoPauseAfter
+	if test.TimeLimit > 0 {
+		pause = min(pause, max(test.TimeLimit-attempt.PausedFor, 0))
+	}
+	return pause
+}
+
+// attemptDeadline возвращает дедлайн попытки с учетом времени на паузе
+func attemptDeadline(attempt *Attempt, test *Test, now time.Time) time.Time {
+	return pausedDeadline(attempt, test).Add(currentPause(attempt, test, now))
+}
+
+// pausedDeadline возвращает дедлайн попытки с учетом только завершенных пауз, без текущей
+func pausedDeadline(attempt *Attempt, test *Test) time.Time {
+	return attempt.StartedAt.Add(test.TimeLimit + attempt.PausedFor)
+}
+
+// touchAttempt отмечает активность в попытке: текущая автопауза переносится в PausedFor и больше
+// не растет. Вызывается под блокировкой на heartbeat и на каждый принятый ответ, иначе студент,
+// который не шлет heartbeat, отвечал бы на паузе без дедлайна
+func touchAttempt(attempt *Attempt, test *Test, now time.Time) {
+	attempt.PausedFor += currentPause(attempt, test, now)
+	attempt.LastSeenAt = now
+}
+
+// Heartbeat отмечает, что студент работает над попыткой. Если попытка была на автопаузе,
+// время паузы добавляется к дедлайну
+func (s *Store) Heartbeat(attemptID, userID uint64) (*AttemptLiveness, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || !s.canAccessAttempt(attempt, userID) {
+		return nil, errors.New("attempt not found")
+	}
+	if attempt.Status != "started" {
+		return nil, errors.New("attempt closed")
+	}
+
+	test, ok := s.tests[attempt.TestID]
+	if !ok {
+		return nil, errors.New("test not found")
+	}
+
+	now := time.Now().UTC()
+	touchAttempt(attempt, test, now)
+
+	return s.liveness(attempt, test, now), nil
+}
+
+// ListLiveAttempts возвращает состояние активности всех незавершенных попыток теста
+func (s *Store) ListLiveAttempts(testID uint64) ([]*AttemptLiveness, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	now := time.Now().UTC()
+	result := make([]*AttemptLiveness, 0)
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status == "started" {
+			result = append(result, s.liveness(attempt, test, now))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AttemptID < result[j].AttemptID
+	})
+
+	return result, nil
+}
+
+// liveness собирает состояние активности попытки, вызывается под блокировкой
+func (s *Store) liveness(attempt *Attempt, test *Test, now time.Time) *AttemptLiveness {
+	result := &AttemptLiveness{
+		AttemptID:  attempt.ID,
+		UserID:     attempt.UserID,
+		LastSeenAt: attempt.LastSeenAt,
+		Active:     now.Sub(attempt.LastSeenAt) <= LivenessWindow,
+		Paused:     currentPause(attempt, test, now) > 0,
+	}
+	if test.TimeLimit > 0 {
+		result.Deadline = attemptDeadline(attempt, test, now)
+	}
+
+	return result
+}
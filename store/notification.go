@@ -0,0 +1,120 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// NotificationTeacherMessage - тип уведомления с сообщением преподавателя
+const NotificationTeacherMessage = "teacher_message"
+
+// Notification - уведомление пользователю о событии в системе
+type Notification struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	AttemptID uint64    `json:"attempt_id,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// notify создает уведомление для пользователя, вызывается под блокировкой
+func (s *Store) notify(userID uint64, notificationType, message string, attemptID uint64) *Notification {
+	notification := &Notification{
+		ID:        s.nextNotificationID,
+		UserID:    userID,
+		Type:      notificationType,
+		Message:   message,
+		AttemptID: attemptID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.notifications[notification.ID] = notification
+	s.nextNotificationID++
+
+	return notification
+}
+
+// ListNotifications возвращает уведомления пользователя, новые первыми
+func (s *Store) ListNotifications(userID uint64, unreadOnly bool) []*Notification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Notification, 0)
+	for _, notification := range s.notifications {
+		if notification.UserID != userID || (unreadOnly && notification.Read) {
+			continue
+		}
+		result = append(result, notification)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID > result[j].ID
+	})
+
+	return result
+}
+
+// MarkNotificationRead отмечает уведомление пользователя прочитанным
+func (s *Store) MarkNotificationRead(userID, notificationID uint64) (*Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notification, ok := s.notifications[notificationID]
+	if !ok || notification.UserID != userID {
+		return nil, errors.New("notification not found")
+	}
+
+	notification.Read = true
+
+	return notification, nil
+}
+
+// NotifyAttempt создает уведомление каждому участнику попытки
+func (s *Store) NotifyAttempt(attemptID uint64, notificationType, message string) ([]*Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	notifications := make([]*Notification, 0)
+	for _, userID := range s.attemptUserIDs(attempt) {
+		notifications = append(notifications, s.notify(userID, notificationType, message, attemptID))
+	}
+
+	return notifications, nil
+}
+
+// NotifyActiveAttempts создает уведомление участникам всех начатых попыток теста
+func (s *Store) NotifyActiveAttempts(testID uint64, notificationType, message string) ([]*Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
+	}
+
+	attemptIDs := make([]uint64, 0)
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status == "started" {
+			attemptIDs = append(attemptIDs, attempt.ID)
+		}
+	}
+	sort.Slice(attemptIDs, func(i, j int) bool {
+		return attemptIDs[i] < attemptIDs[j]
+	})
+
+	notifications := make([]*Notification, 0)
+	for _, attemptID := range attemptIDs {
+		for _, userID := range s.attemptUserIDs(s.attempts[attemptID]) {
+			notifications = append(notifications, s.notify(userID, notificationType, message, attemptID))
+		}
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// codePrefixPattern - допустимый префикс кодов организации
+var codePrefixPattern = regexp.MustCompile(`^[A-Z0-9]{2,8}$`)
+
+// maxCodeGenerationAttempts - сколько раз пробуем сгенерировать незанятый код
+const maxCodeGenerationAttempts = 20
+
+// Organization - организация (школа), владеющая своими тестами, классами и кодами доступа
+type Organization struct {
+	ID         uint64    `json:"id"`
+	Name       string    `json:"name"`
+	CodePrefix string    `json:"code_prefix"` // обязательный префикс всех кодов организации
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateOrganization создает организацию с уникальным префиксом кодов
+func (s *Store) CreateOrganization(name, codePrefix string) (*Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	codePrefix = strings.ToUpper(strings.TrimSpace(codePrefix))
+	if !codePrefixPattern.MatchString(codePrefix) {
+		return nil, errors.New("code prefix must be 2-8 latin letters or digits")
+	}
+
+	for _, org := range s.organizations {
+		if org.CodePrefix == codePrefix {
+			return nil, errors.New("code prefix already taken")
+		}
+	}
+
+	org := &Organization{
+		ID:         s.nextOrganizationID,
+		Name:       name,
+		CodePrefix: codePrefix,
+		CreatedAt:  time.Now().UTC(),
+	}
+	s.organizations[org.ID] = org
+	s.nextOrganizationID++
+
+	return org, nil
+}
+
+// ListOrganizations возвращает все организации
+func (s *Store) ListOrganizations() []*Organization {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Organization, 0, len(s.organizations))
+	for _, org := range s.organizations {
+		result = append(result, org)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// SetUserOrganization привязывает пользователя к организации, 0 = отвязать
+func (s *Store) SetUserOrganization(userID, orgID uint64) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	user, ok := s.activeUser(userID)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	if _, ok := s.organizations[orgID]; !ok && orgID != 0 {
+		return nil, errors.New("organization not found")
+	}
+
+	user.OrgID = orgID
+
+	return user, nil
+}
+
+// SetTestOrganization привязывает тест к организации, 0 = отвязать.
+// version - версия теста, которую видел клиент, 0 - без проверки
+func (s *Store) SetTestOrganization(testID, orgID, version uint64) (*Test, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+	if err := checkTestVersion(test, version); err != nil {
+		return nil, err
+	}
+	if _, ok := s.organizations[orgID]; !ok && orgID != 0 {
+		return nil, errors.New("organization not found")
+	}
+
+	test.OrgID = orgID
+	test.Version++
+
+	return test, nil
+}
+
+// codePrefix возвращает префикс кодов организации или пустую строку, вызывается под блокировкой
+func (s *Store) codePrefix(orgID uint64) string {
+	if org, ok := s.organizations[orgID]; ok {
+		return org.CodePrefix
+	}
+	return ""
+}
+
+// namespacedCode добавляет к коду префикс организации, если его еще нет
+func namespacedCode(prefix, code string) string {
+	if prefix == "" || strings.HasPrefix(code, prefix+"-") {
+		return code
+	}
+	return prefix + "-" + code
+}
+
+// generateUniqueCode генерирует код с префиксом организации, не пересекающийся с существующими.
+// Вызывается под блокировкой
+func (s *Store) generateUniqueCode(prefix string, withChecksum bool) (string, error) {
+	for i := 0; i < maxCodeGenerationAttempts; i++ {
+		random, err := GenerateAccessCode(false)
+		if err != nil {
+			return "", err
+		}
+
+		code := namespacedCode(prefix, random)
+		if withChecksum {
+			code = WithChecksum(code)
+		}
+
+		if _, ok := s.accessCodes[code]; !ok {
+			return code, nil
+		}
+	}
+
+	return "", errors.New("failed to generate unique access code")
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Paraphrase - перефразированный ассистентом текст вопроса попытки. Хранится с попыткой для аудита;
+// при ошибке студент видит исходный текст, а Error объясняет почему
+type Paraphrase struct {
+	QuestionPosition uint64    `json:"question_position"`
+	QuestionID       uint64    `json:"question_id"`
+	Original         string    `json:"original"`
+	Text             string    `json:"text,omitempty"`
+	Model            string    `json:"model,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ParaphraseTarget - вопрос попытки, который нужно перефразировать
+type ParaphraseTarget struct {
+	QuestionPosition uint64
+	QuestionID       uint64
+	Text             string
+	Answer           string // эталонный ответ, не должен появиться в новом тексте
+}
+
+// ParaphraseTargets возвращает вопросы попытки с включенным перефразированием, которые еще не обработаны
+func (s *Store) ParaphraseTargets(attemptID uint64) ([]ParaphraseTarget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	targets := make([]ParaphraseTarget, 0)
+	for i, answer := range attempt.Answers {
+		pos := uint64(i + 1)
+		if !s.resolveAIConfig(attempt, pos).Paraphrase || s.paraphrase(attempt, pos) != nil {
+			continue
+		}
+		question, ok := s.findQuestionByID(attempt.TestID, answer.QuestionID)
+		if !ok {
+			continue
+		}
+		targets = append(targets, ParaphraseTarget{
+			QuestionPosition: pos,
+			QuestionID:       question.ID,
+			Text:             question.Text,
+			Answer:           question.TrueAnswer,
+		})
+	}
+
+	return targets, nil
+}
+
+// SaveParaphrase сохраняет результат перефразирования вопроса попытки. Уже сохраненный текст
+// не заменяется, чтобы формулировка не менялась на глазах у студента
+func (s *Store) SaveParaphrase(attemptID uint64, paraphrase Paraphrase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return errors.New("attempt not found")
+	}
+	if s.paraphrase(attempt, paraphrase.QuestionPosition) != nil {
+		return nil
+	}
+
+	if paraphrase.CreatedAt.IsZero() {
+		paraphrase.CreatedAt = time.Now().UTC()
+	}
+	attempt.Paraphrases = append(attempt.Paraphrases, &paraphrase)
+
+	return nil
+}
+
+// ListParaphrases возвращает перефразированные вопросы попытки по позициям
+func (s *Store) ListParaphrases(attemptID uint64) ([]*Paraphrase, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	result := make([]*Paraphrase, len(attempt.Paraphrases))
+	for i, paraphrase := range attempt.Paraphrases {
+		p := *paraphrase
+		result[i] = &p
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].QuestionPosition < result[j].QuestionPosition
+	})
+
+	return result, nil
+}
+
+// paraphrase возвращает запись перефразирования вопроса на позиции, вызывается под блокировкой
+func (s *Store) paraphrase(attempt *Attempt, questionPos uint64) *Paraphrase {
+	for _, paraphrase := range attempt.Paraphrases {
+		if paraphrase.QuestionPosition == questionPos {
+			return paraphrase
+		}
+	}
+	return nil
+}
+
+// questionText возвращает текст вопроса, который видит участник попытки: перефразированный,
+// если он есть, иначе исходный. Вызывается под блокировкой
+func (s *Store) questionText(attempt *Attempt, questionPos uint64, question *Question) string {
+	if paraphrase := s.paraphrase(attempt, questionPos); paraphrase != nil && paraphrase.Text != "" {
+		return paraphrase.Text
+	}
+	return question.Text
+}
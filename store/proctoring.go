@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// Типы событий прокторинга
+const (
+	ProctoringAIModeration = "ai_moderation" // сообщение ассистенту заблокировано модерацией
+	ProctoringAIRefusal    = "ai_refusal"    // отказ на просьбу решить вопрос за студента
+)
+
+// ProctoringEvent - нарушение или подозрительное действие во время попытки
+type ProctoringEvent struct {
+	Type       string    `json:"type"`
+	UserID     uint64    `json:"user_id"`
+	ThreadID   string    `json:"thread_id,omitempty"`
+	Message    string    `json:"message,omitempty"`    // текст, вызвавший событие
+	Source     string    `json:"source,omitempty"`     // кто зафиксировал событие
+	Categories []string  `json:"categories,omitempty"` // сработавшие категории или правила
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RecordProctoringEvent добавляет событие в журнал прокторинга попытки
+func (s *Store) RecordProctoringEvent(attemptID uint64, event ProctoringEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return errors.New("attempt not found")
+	}
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	attempt.ProctoringEvents = append(attempt.ProctoringEvents, &event)
+
+	return nil
+}
+
+// ListProctoringEvents возвращает журнал прокторинга попытки
+func (s *Store) ListProctoringEvents(attemptID uint64) ([]*ProctoringEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	events := make([]*ProctoringEvent, len(attempt.ProctoringEvents))
+	copy(events, attempt.ProctoringEvents)
+
+	return events, nil
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"GEEK_back/prompts"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrPromptNotFound = errors.New("prompt template not found")
+
+// PromptTemplate - шаблон системного промпта ассистента. Изменение текста создает новую версию,
+// старые версии сохраняются, чтобы было видно, с каким промптом шли диалоги
+type PromptTemplate struct {
+	ID        uint64           `json:"id"`
+	Name      string           `json:"name"`
+	Versions  []*PromptVersion `json:"versions"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// PromptVersion - версия текста шаблона
+type PromptVersion struct {
+	Version   int       `json:"version"`
+	Text      string    `json:"text"`
+	CreatedBy uint64    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TestPrompt - назначение шаблона тесту
+type TestPrompt struct {
+	TemplateID uint64 `json:"templateId"`
+	Version    int    `json:"version,omitempty" validate:"gte=0"` // 0 = всегда последняя версия
+	Subject    string `json:"subject,omitempty"`
+	Language   string `json:"language,omitempty"`
+}
+
+// latest возвращает последнюю версию шаблона
+func (t *PromptTemplate) latest() *PromptVersion {
+	return t.Versions[len(t.Versions)-1]
+}
+
+// version возвращает версию шаблона, 0 = последняя
+func (t *PromptTemplate) version(v int) (*PromptVersion, bool) {
+	if v == 0 {
+		return t.latest(), true
+	}
+	if v < 0 || v > len(t.Versions) {
+		return nil, false
+	}
+	return t.Versions[v-1], true
+}
+
+// CreatePromptTemplate создает шаблон с первой версией текста
+func (s *Store) CreatePromptTemplate(name, text string, userID uint64) (*PromptTemplate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := prompts.Validate(text); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	template := &PromptTemplate{
+		ID:        s.nextPromptID,
+		Name:      name,
+		Versions:  []*PromptVersion{{Version: 1, Text: text, CreatedBy: userID, CreatedAt: now}},
+		CreatedAt: now,
+	}
+	s.promptTemplates[template.ID] = template
+	s.nextPromptID++
+
+	return template, nil
+}
+
+// UpdatePromptTemplate добавляет новую версию текста шаблона
+func (s *Store) UpdatePromptTemplate(templateID uint64, text string, userID uint64) (*PromptTemplate, error) {
+	if err := prompts.Validate(text); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.promptTemplates[templateID]
+	if !ok {
+		return nil, ErrPromptNotFound
+	}
+
+	template.Versions = append(template.Versions, &PromptVersion{
+		Version:   len(template.Versions) + 1,
+		Text:      text,
+		CreatedBy: userID,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	return template, nil
+}
+
+// GetPromptTemplate возвращает шаблон со всеми версиями
+func (s *Store) GetPromptTemplate(templateID uint64) (*PromptTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	template, ok := s.promptTemplates[templateID]
+	if !ok {
+		return nil, ErrPromptNotFound
+	}
+	return template, nil
+}
+
+// ListPromptTemplates возвращает все шаблоны
+func (s *Store) ListPromptTemplates() []*PromptTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*PromptTemplate, 0, len(s.promptTemplates))
+	for _, template := range s.promptTemplates {
+		result = append(result, template)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// SetTestPrompt назначает тесту шаблон промпта, nil возвращает контекст по умолчанию.
+// version - версия теста, которую видел клиент, 0 - без проверки
+func (s *Store) SetTestPrompt(testID uint64, prompt *TestPrompt, version uint64) (*Test, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+	if err := checkTestVersion(test, version); err != nil {
+		return nil, err
+	}
+
+	if prompt != nil {
+		template, ok := s.promptTemplates[prompt.TemplateID]
+		if !ok {
+			return nil, ErrPromptNotFound
+		}
+		if _, ok := template.version(prompt.Version); !ok {
+			return nil, errors.New("prompt template version not found")
+		}
+	}
+
+	test.Prompt = prompt
+	test.Version++
+
+	return test, nil
+}
+
+// renderTestPrompt собирает контекст диалога по шаблону теста, вызывается под блокировкой.
+// Язык шаблона по умолчанию берется из настроек ассистента. Возвращает false, если шаблон тесту не назначен
+func (s *Store) renderTestPrompt(test *Test, attempt *Attempt, questionPos uint64, policy, languageCode string) (string, *PromptVersionRef, bool) {
+	if test.Prompt == nil {
+		return "", nil, false
+	}
+	template, ok := s.promptTemplates[test.Prompt.TemplateID]
+	if !ok {
+		return "", nil, false
+	}
+	version, ok := template.version(test.Prompt.Version)
+	if !ok {
+		return "", nil, false
+	}
+
+	vars := map[string]string{
+		prompts.VarTest:       test.Name,
+		prompts.VarPosition:   strconv.FormatUint(questionPos, 10),
+		prompts.VarSubject:    test.Prompt.Subject,
+		prompts.VarLanguage:   test.Prompt.Language,
+		prompts.VarHelpPolicy: policy,
+	}
+	if vars[prompts.VarLanguage] == "" {
+		vars[prompts.VarLanguage] = AILanguageName(languageCode)
+	}
+	if question, ok := s.findQuestionByID(test.ID, attempt.Answers[questionPos-1].QuestionID); ok {
+		vars[prompts.VarQuestion] = strings.TrimSpace(s.questionText(attempt, questionPos, question))
+	}
+
+	return prompts.Render(version.Text, vars), &PromptVersionRef{TemplateID: template.ID, Version: version.Version}, true
+}
+
+// PromptVersionRef - версия шаблона, по которой собран контекст диалога
+type PromptVersionRef struct {
+	TemplateID uint64 `json:"template_id"`
+	Version    int    `json:"version"`
+}
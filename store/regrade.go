@@ -0,0 +1,108 @@
+package store
+
+import "time"
+
+// RegradeEvent - запись о перепроверке попытки после исправления вопросов
+type RegradeEvent struct {
+	RegradedBy uint64    `json:"regraded_by"`
+	OldResult  uint64    `json:"old_result"`
+	NewResult  uint64    `json:"new_result"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RegradeSummary - итог перепроверки теста
+type RegradeSummary struct {
+	TestID          uint64 `json:"test_id"`
+	AttemptsChecked uint64 `json:"attempts_checked"`
+	AttemptsChanged uint64 `json:"attempts_changed"`
+	AnswersRegraded uint64 `json:"answers_regraded"`
+}
+
+// UpdateQuestionAnswer исправляет эталонный ответ на вопрос теста.
+// version - версия вопроса, которую видел клиент, 0 - без проверки
+func (s *Store) UpdateQuestionAnswer(testID, questionID uint64, trueAnswer string, version uint64) (*Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, err := s.findActiveQuestion(testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQuestionVersion(question, version); err != nil {
+		return nil, err
+	}
+
+	question.TrueAnswer = trueAnswer
+	question.Version++
+
+	return question, nil
+}
+
+// RegradeTest заново проверяет автоматически оцениваемые ответы во всех сданных попытках теста.
+// Если questionIDs не пустой, перепроверяются только указанные вопросы
+func (s *Store) RegradeTest(testID, regradedBy uint64, questionIDs []uint64) (*RegradeSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
+	}
+
+	filter := make(map[uint64]bool, len(questionIDs))
+	for _, id := range questionIDs {
+		filter[id] = true
+	}
+
+	summary := &RegradeSummary{TestID: testID}
+	now := time.Now().UTC()
+
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status != "submitted" && attempt.Status != "grading" {
+			continue
+		}
+		summary.AttemptsChecked++
+
+		regraded := false
+		for _, answer := range attempt.Answers {
+			if len(filter) > 0 && !filter[answer.QuestionID] {
+				continue
+			}
+			if answer.Status != "graded" {
+				continue
+			}
+
+			question, ok := s.findQuestionByID(testID, answer.QuestionID)
+			// Оценки по близости и оценки преподавателя точным сравнением не пересчитываются
+			if !ok || question.GradingMode == GradingManual || question.GradingMode == GradingSemantic {
+				continue
+			}
+
+			answer.RightOrNot = answer.Text == question.TrueAnswer
+			answer.Score = 0
+			if answer.RightOrNot {
+				answer.Score = question.MaxScore
+			}
+			summary.AnswersRegraded++
+			regraded = true
+		}
+
+		if !regraded {
+			continue
+		}
+
+		oldResult := attempt.Result
+		s.recalculateResult(attempt)
+
+		attempt.Regrades = append(attempt.Regrades, &RegradeEvent{
+			RegradedBy: regradedBy,
+			OldResult:  oldResult,
+			NewResult:  attempt.Result,
+			CreatedAt:  now,
+		})
+		if attempt.Result != oldResult {
+			summary.AttemptsChanged++
+		}
+	}
+
+	return summary, nil
+}
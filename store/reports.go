@@ -0,0 +1,300 @@
+package store
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+var ErrReportSubscriptionNotFound = errors.New("report subscription not found")
+
+// ReportPeriod - за какой период собирается еженедельный отчет
+const ReportPeriod = 7 * 24 * time.Hour
+
+// ReportSubscription - подписка преподавателя на еженедельное письмо с активностью и результатами его тестов
+type ReportSubscription struct {
+	ID          uint64       `json:"id"`
+	UserID      uint64       `json:"user_id"`
+	TestIDs     []uint64     `json:"test_ids"`                      // пусто = все тесты преподавателя
+	Weekday     time.Weekday `json:"weekday" swaggertype:"integer"` // день отправки, 0 = воскресенье
+	Hour        int          `json:"hour"`                          // час отправки в часовом поясе Timezone
+	Timezone    string       `json:"timezone"`                      // IANA, например Europe/Moscow
+	Lang        string       `json:"lang"`                          // язык письма
+	PassPercent uint64       `json:"pass_percent"`                  // проходной балл в процентах от максимального
+	Paused      bool         `json:"paused"`                        // письма не отправляются, настройки сохраняются
+	NextSendAt  time.Time    `json:"next_send_at"`                  // когда будет отправлено следующее письмо
+	LastSentAt  *time.Time   `json:"last_sent_at,omitempty"`        // когда письмо последний раз ушло в очередь
+	LastError   string       `json:"last_error,omitempty"`          // ошибка последней отправки, пусто = успешно
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// ReportSubscriptionOptions - настройки подписки, которые задает преподаватель
+type ReportSubscriptionOptions struct {
+	TestIDs     []uint64
+	Weekday     time.Weekday
+	Hour        int
+	Timezone    string // пусто = UTC
+	Lang        string
+	PassPercent uint64
+	Paused      bool
+}
+
+// TestReport - активность и результаты теста за период отчета
+type TestReport struct {
+	TestID         uint64
+	Name           string
+	MaxScore       uint64
+	Started        int      // попытки, начатые за период
+	Submitted      int      // попытки, сданные за период, включая ждущие проверки
+	Graded         int      // из сданных за период оценены, по ним считаются средний балл и доля прошедших
+	AvgScore       *float64 // средний результат оцененных
+	PassRate       *float64 // доля оцененных с результатом не ниже проходного
+	OpenAttempts   int      // идут сейчас
+	AwaitingReview int      // ждут ручной проверки сейчас, включая сданные раньше периода
+}
+
+// Report - еженедельный отчет преподавателю
+type Report struct {
+	Email string
+	From  time.Time
+	To    time.Time
+	Tests []*TestReport // тесты с активностью за период или ждущими проверки попытками
+}
+
+// CreateReportSubscription подписывает преподавателя на еженедельный отчет
+func (s *Store) CreateReportSubscription(userID uint64, opts ReportSubscriptionOptions) (*ReportSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	location, err := s.checkReportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	subscription := &ReportSubscription{ID: s.nextReportSubscriptionID, UserID: userID, CreatedAt: now}
+	applyReportOptions(subscription, opts, location, now)
+	s.reportSubscriptions[subscription.ID] = subscription
+	s.nextReportSubscriptionID++
+
+	return copyReportSubscription(subscription), nil
+}
+
+// ListReportSubscriptions возвращает подписки пользователя
+func (s *Store) ListReportSubscriptions(userID uint64) []*ReportSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*ReportSubscription, 0)
+	for _, subscription := range s.reportSubscriptions {
+		if subscription.UserID == userID {
+			result = append(result, copyReportSubscription(subscription))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// GetReportSubscription возвращает подписку пользователя. Чужая подписка не находится
+func (s *Store) GetReportSubscription(userID, subscriptionID uint64) (*ReportSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscription, ok := s.reportSubscriptions[subscriptionID]
+	if !ok || subscription.UserID != userID {
+		return nil, ErrReportSubscriptionNotFound
+	}
+	return copyReportSubscription(subscription), nil
+}
+
+// UpdateReportSubscription заменяет настройки подписки и пересчитывает время следующего письма
+func (s *Store) UpdateReportSubscription(userID, subscriptionID uint64, opts ReportSubscriptionOptions) (*ReportSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.reportSubscriptions[subscriptionID]
+	if !ok || subscription.UserID != userID {
+		return nil, ErrReportSubscriptionNotFound
+	}
+	location, err := s.checkReportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	applyReportOptions(subscription, opts, location, time.Now().UTC())
+	return copyReportSubscription(subscription), nil
+}
+
+// DeleteReportSubscription отписывает пользователя от отчета
+func (s *Store) DeleteReportSubscription(userID, subscriptionID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.reportSubscriptions[subscriptionID]
+	if !ok || subscription.UserID != userID {
+		return ErrReportSubscriptionNotFound
+	}
+	delete(s.reportSubscriptions, subscriptionID)
+	return nil
+}
+
+// DueReportSubscriptions возвращает включенные подписки, письмо по которым пора отправить
+func (s *Store) DueReportSubscriptions(now time.Time) []*ReportSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	due := make([]*ReportSubscription, 0)
+	for _, subscription := range s.reportSubscriptions {
+		if !subscription.Paused && !subscription.NextSendAt.After(now) {
+			due = append(due, copyReportSubscription(subscription))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].ID < due[j].ID
+	})
+	return due
+}
+
+// MarkReportSent записывает результат отправки и переносит следующее письмо на ближайшее время по расписанию
+// после now. Неудачная отправка тоже переносится: повтор через минуту прислал бы то же самое несколько раз
+func (s *Store) MarkReportSent(subscriptionID uint64, now time.Time, sendErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.reportSubscriptions[subscriptionID]
+	if !ok {
+		return
+	}
+	subscription.LastError = ""
+	if sendErr != nil {
+		subscription.LastError = sendErr.Error()
+	} else {
+		sentAt := now
+		subscription.LastSentAt = &sentAt
+	}
+	location, err := time.LoadLocation(subscription.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	subscription.NextSendAt = nextReportTime(subscription.Weekday, subscription.Hour, location, now)
+}
+
+// BuildReport собирает отчет за период [from, to) по тестам подписки. Тесты, удаленные или ставшие
+// чужими после подписки, пропускаются
+func (s *Store) BuildReport(subscription *ReportSubscription, from, to time.Time) (*Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.lookupUser(subscription.UserID)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	// Роль могли понизить после подписки, студенту отчет по тестам не положен
+	if user.Role != RoleTeacher && user.Role != RoleAdmin {
+		return nil, errors.New("user is not a teacher")
+	}
+
+	report := &Report{Email: user.Email, From: from, To: to, Tests: make([]*TestReport, 0)}
+	for _, test := range s.teacherTests(user) {
+		if len(subscription.TestIDs) > 0 && !slices.Contains(subscription.TestIDs, test.ID) {
+			continue
+		}
+		entry := s.testReport(test, from, to, subscription.PassPercent)
+		if entry.Started > 0 || entry.Submitted > 0 || entry.AwaitingReview > 0 {
+			report.Tests = append(report.Tests, entry)
+		}
+	}
+
+	return report, nil
+}
+
+// testReport считает активность и результаты теста за период, вызывается под блокировкой
+func (s *Store) testReport(test *Test, from, to time.Time, passPercent uint64) *TestReport {
+	entry := &TestReport{TestID: test.ID, Name: test.Name, MaxScore: test.MaxScore}
+	passScore := (test.MaxScore*passPercent + 99) / 100
+	inPeriod := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+
+	var scores []float64
+	passed := 0
+	for _, attempt := range s.attemptsByTest[test.ID] {
+		if inPeriod(attempt.StartedAt) {
+			entry.Started++
+		}
+		switch attempt.Status {
+		case "started":
+			entry.OpenAttempts++
+			continue
+		case "grading":
+			entry.AwaitingReview++
+		}
+		if !inPeriod(attempt.FinishedAt) {
+			continue
+		}
+		entry.Submitted++
+		if attempt.Status == "submitted" {
+			entry.Graded++
+			scores = append(scores, float64(attempt.Result))
+			if attempt.Result >= passScore {
+				passed++
+			}
+		}
+	}
+
+	entry.AvgScore = optionalMean(scores)
+	if len(scores) > 0 {
+		rate := float64(passed) / float64(len(scores))
+		entry.PassRate = &rate
+	}
+	return entry
+}
+
+// checkReportOptions проверяет тесты и часовой пояс подписки, вызывается под блокировкой
+func (s *Store) checkReportOptions(opts ReportSubscriptionOptions) (*time.Location, error) {
+	for _, testID := range opts.TestIDs {
+		if _, ok := s.activeTest(testID); !ok {
+			return nil, ErrTestNotFound
+		}
+	}
+	location, err := time.LoadLocation(opts.Timezone)
+	if err != nil {
+		return nil, errors.New("unknown timezone")
+	}
+	return location, nil
+}
+
+// applyReportOptions записывает настройки в подписку и планирует следующее письмо
+func applyReportOptions(subscription *ReportSubscription, opts ReportSubscriptionOptions, location *time.Location, now time.Time) {
+	subscription.TestIDs = slices.Compact(slices.Sorted(slices.Values(opts.TestIDs)))
+	subscription.Weekday = opts.Weekday
+	subscription.Hour = opts.Hour
+	subscription.Timezone = location.String()
+	subscription.Lang = opts.Lang
+	subscription.PassPercent = opts.PassPercent
+	subscription.Paused = opts.Paused
+	subscription.NextSendAt = nextReportTime(opts.Weekday, opts.Hour, location, now)
+}
+
+// nextReportTime возвращает ближайший после now момент, когда в location наступает hour часов дня weekday
+func nextReportTime(weekday time.Weekday, hour int, location *time.Location, now time.Time) time.Time {
+	local := now.In(location)
+	days := (int(weekday) - int(local.Weekday()) + 7) % 7
+	next := time.Date(local.Year(), local.Month(), local.Day()+days, hour, 0, 0, 0, location)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+days+7, hour, 0, 0, 0, location)
+	}
+	return next.UTC()
+}
+
+func copyReportSubscription(subscription *ReportSubscription) *ReportSubscription {
+	result := *subscription
+	result.TestIDs = slices.Clone(subscription.TestIDs)
+	if result.TestIDs == nil {
+		result.TestIDs = []uint64{}
+	}
+	return &result
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrResourceNotFound        = errors.New("resource not found")
+	ErrResourceAlreadyAttached = errors.New("resource already attached to thread")
+)
+
+// TestResource - файл из белого списка теста (например, набор данных, на который ссылается вопрос).
+// Студент может прикрепить его к диалогу с ассистентом. Содержимое лежит в файловом хранилище по ключу Key
+type TestResource struct {
+	ID             uint64    `json:"id"`
+	TestID         uint64    `json:"test_id"`
+	Name           string    `json:"name"`
+	ContentType    string    `json:"content_type"`
+	Size           int       `json:"size"`
+	Key            string    `json:"-"`                          // ключ содержимого в файловом хранилище
+	ProviderFileID string    `json:"provider_file_id,omitempty"` // файл у провайдера, загружается при первом прикреплении
+	CreatedBy      uint64    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AddTestResource добавляет в белый список теста файл, уже сохраненный в файловом хранилище по ключу key
+func (s *Store) AddTestResource(testID uint64, name, contentType string, size int, key string, userID uint64) (*TestResource, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
+	}
+
+	resource := &TestResource{
+		ID:          s.nextResourceID,
+		TestID:      testID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Key:         key,
+		CreatedBy:   userID,
+		CreatedAt:   time.Now().UTC(),
+	}
+	s.testResources[resource.ID] = resource
+	s.nextResourceID++
+
+	return resource, nil
+}
+
+// ListTestResources возвращает белый список файлов теста
+func (s *Store) ListTestResources(testID uint64) []*TestResource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.testResourceList(testID)
+}
+
+// TestResource возвращает файл из белого списка теста
+func (s *Store) TestResource(testID, resourceID uint64) (*TestResource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resource, ok := s.testResources[resourceID]
+	if !ok || resource.TestID != testID {
+		return nil, ErrResourceNotFound
+	}
+
+	return resource, nil
+}
+
+// testResourceList возвращает файлы теста по порядку добавления, вызывается под блокировкой
+func (s *Store) testResourceList(testID uint64) []*TestResource {
+	result := make([]*TestResource, 0)
+	for _, resource := range s.testResources {
+		if resource.TestID == testID {
+			result = append(result, resource)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// DeleteTestResource убирает файл из белого списка и возвращает его, чтобы удалить содержимое
+// из файлового хранилища и файл у провайдера.
+// Уже прикрепленные к диалогам файлы остаются в истории диалогов
+func (s *Store) DeleteTestResource(testID, resourceID uint64) (*TestResource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resource, ok := s.testResources[resourceID]
+	if !ok || resource.TestID != testID {
+		return nil, ErrResourceNotFound
+	}
+	delete(s.testResources, resourceID)
+
+	return resource, nil
+}
+
+// AttemptResources возвращает файлы, которые участник попытки может прикрепить к диалогу
+func (s *Store) AttemptResources(attemptID, userID uint64) ([]*TestResource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+	if !s.canAccessAttempt(attempt, userID) && !s.isStaff(userID) {
+		return nil, ErrAIThreadAccess
+	}
+
+	return s.testResourceList(attempt.TestID), nil
+}
+
+// AttachableResource проверяет, что участник попытки может прикрепить файл из белого списка теста
+// к открытому диалогу, и возвращает копию файла
+func (s *Store) AttachableResource(attemptID uint64, threadID string, userID, resourceID uint64) (TestResource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok || thread.AttemptID != attemptID {
+		return TestResource{}, ErrAIThreadNotFound
+	}
+	if thread.Status == AIThreadClosed {
+		return TestResource{}, ErrAIThreadClosed
+	}
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return TestResource{}, ErrAIThreadNotFound
+	}
+	if !s.canAccessAttempt(attempt, userID) {
+		return TestResource{}, ErrAIThreadAccess
+	}
+
+	resource, ok := s.testResources[resourceID]
+	if !ok || resource.TestID != attempt.TestID {
+		return TestResource{}, ErrResourceNotFound
+	}
+	for _, id := range thread.Attachments {
+		if id == resourceID {
+			return TestResource{}, ErrResourceAlreadyAttached
+		}
+	}
+
+	return *resource, nil
+}
+
+// SetResourceProviderFile запоминает ID файла у провайдера, чтобы не загружать его повторно
+func (s *Store) SetResourceProviderFile(resourceID uint64, fileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resource, ok := s.testResources[resourceID]; ok {
+		resource.ProviderFileID = fileID
+	}
+}
+
+// RecordAIThreadAttachment отмечает файл прикрепленным к диалогу
+func (s *Store) RecordAIThreadAttachment(threadID string, resourceID uint64) (*AIThread, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	thread, ok := s.aiThreadsByID[threadID]
+	if !ok {
+		return nil, ErrAIThreadNotFound
+	}
+	for _, id := range thread.Attachments {
+		if id == resourceID {
+			return nil, ErrResourceAlreadyAttached
+		}
+	}
+	thread.Attachments = append(thread.Attachments, resourceID)
+
+	return thread, nil
+}
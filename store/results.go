@@ -0,0 +1,90 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// ResultRow - сданная попытка в выгрузке результатов
+type ResultRow struct {
+	AttemptID  uint64
+	UserID     uint64
+	Email      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration // время работы без автопаузы
+	Late       bool
+	Penalty    uint64
+	AICost     uint64
+	Result     uint64
+	Correct    map[uint64]bool // ID вопроса -> ответ верный; вопросов, не попавших в попытку, нет
+}
+
+// ResultsExport - результаты теста для журнала оценок
+type ResultsExport struct {
+	TestID    uint64
+	TestName  string
+	MaxScore  uint64
+	Questions []Question // вопросы, попавшие хотя бы в одну попытку, в порядке теста
+	Rows      []*ResultRow
+}
+
+// ExportResults собирает сданные и оцененные попытки теста, от ранних к поздним. Данные копируются,
+// так что выгрузку можно писать клиенту без блокировки хранилища
+func (s *Store) ExportResults(testID uint64) (*ResultsExport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	export := &ResultsExport{TestID: test.ID, TestName: test.Name, MaxScore: test.MaxScore, Rows: make([]*ResultRow, 0)}
+	asked := make(map[uint64]bool)
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status != "submitted" {
+			continue
+		}
+		row := &ResultRow{
+			AttemptID:  attempt.ID,
+			UserID:     attempt.UserID,
+			StartedAt:  attempt.StartedAt,
+			FinishedAt: attempt.FinishedAt,
+			Duration:   attemptDuration(attempt),
+			Late:       attempt.Late,
+			Penalty:    attempt.Penalty,
+			AICost:     attempt.AICost,
+			Result:     attempt.Result,
+			Correct:    make(map[uint64]bool, len(attempt.Answers)),
+		}
+		for _, answer := range attempt.Answers {
+			row.Correct[answer.QuestionID] = answer.RightOrNot
+			asked[answer.QuestionID] = true
+		}
+		export.Rows = append(export.Rows, row)
+	}
+	sort.Slice(export.Rows, func(i, j int) bool {
+		if !export.Rows[i].FinishedAt.Equal(export.Rows[j].FinishedAt) {
+			return export.Rows[i].FinishedAt.Before(export.Rows[j].FinishedAt)
+		}
+		return export.Rows[i].AttemptID < export.Rows[j].AttemptID
+	})
+
+	// Удаленные вопросы остаются в выгрузке, если они были в сданных попытках
+	for _, question := range test.Questions {
+		if asked[question.ID] {
+			export.Questions = append(export.Questions, Question{ID: question.ID, Name: question.Name, MaxScore: question.MaxScore})
+		}
+	}
+
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
+	for _, row := range export.Rows {
+		if user, ok := s.users[row.UserID]; ok {
+			row.Email = user.Email
+		}
+	}
+
+	return export, nil
+}
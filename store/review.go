@@ -0,0 +1,241 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Причины попадания ответа в очередь проверки
+const (
+	ReviewReasonManual        = "manual"         // вопрос проверяется только вручную
+	ReviewReasonLowConfidence = "low_confidence" // автоматическая оценка недостаточно уверенная
+)
+
+// Статусы элемента очереди проверки
+const (
+	ReviewPending = "pending"
+	ReviewClaimed = "claimed"
+	ReviewGraded  = "graded"
+)
+
+// ReviewItem - ответ, ожидающий оценки преподавателя
+type ReviewItem struct {
+	ID               uint64     `json:"id"`
+	AttemptID        uint64     `json:"attempt_id"`
+	TestID           uint64     `json:"test_id"`
+	QuestionID       uint64     `json:"question_id"`
+	QuestionPosition uint64     `json:"question_position"`
+	QuestionText     string     `json:"question_text"`
+	ReferenceAnswer  string     `json:"reference_answer"`
+	AnswerText       string     `json:"answer_text"`
+	MaxScore         uint64     `json:"max_score"`
+	Reason           string     `json:"reason"`
+	Status           string     `json:"status"`
+	ClaimedBy        *uint64    `json:"claimed_by,omitempty"`
+	ClaimedAt        *time.Time `json:"claimed_at,omitempty"`
+	GradedBy         *uint64    `json:"graded_by,omitempty"`
+	GradedAt         *time.Time `json:"graded_at,omitempty"`
+	Score            uint64     `json:"score"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+var (
+	ErrReviewItemNotFound = errors.New("review item not found")
+	ErrReviewItemClaimed  = errors.New("review item is claimed by another teacher")
+	ErrReviewItemGraded   = errors.New("review item already graded")
+)
+
+// enqueueManualReviews ставит в очередь все ответы попытки, ожидающие ручной проверки.
+// Вызывается под блокировкой, возвращает количество добавленных элементов
+func (s *Store) enqueueManualReviews(attempt *Attempt) int {
+	count := 0
+	for i, answer := range attempt.Answers {
+		if answer.Status != "pending_review" {
+			continue
+		}
+		// Ответы на вопросы semantic попадают сюда, только если близость к эталону неуверенная
+		reason := ReviewReasonManual
+		if question, ok := s.findQuestionByID(attempt.TestID, answer.QuestionID); ok && question.GradingMode == GradingSemantic {
+			reason = ReviewReasonLowConfidence
+		}
+		s.enqueueReview(attempt, uint64(i+1), reason)
+		count++
+	}
+
+	return count
+}
+
+// enqueueReview добавляет ответ на позиции questionPos в очередь проверки, вызывается под блокировкой
+func (s *Store) enqueueReview(attempt *Attempt, questionPos uint64, reason string) *ReviewItem {
+	answer := attempt.Answers[questionPos-1]
+
+	item := &ReviewItem{
+		ID:               s.nextReviewID,
+		AttemptID:        attempt.ID,
+		TestID:           attempt.TestID,
+		QuestionID:       answer.QuestionID,
+		QuestionPosition: questionPos,
+		AnswerText:       answer.Text,
+		Reason:           reason,
+		Status:           ReviewPending,
+		CreatedAt:        time.Now().UTC(),
+	}
+	if question, ok := s.findQuestionByID(attempt.TestID, answer.QuestionID); ok {
+		item.QuestionText = question.Text
+		item.ReferenceAnswer = question.TrueAnswer
+		item.MaxScore = question.MaxScore
+	}
+
+	answer.Status = "pending_review"
+	s.reviewItems[item.ID] = item
+	s.nextReviewID++
+
+	return item
+}
+
+// ListReviewQueue возвращает неоцененные элементы очереди по тестам преподавателя (см. teacherTests), старые первыми
+func (s *Store) ListReviewQueue(teacherID uint64) ([]*ReviewItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.lookupUser(teacherID)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	items := make([]*ReviewItem, 0)
+	for _, item := range s.reviewItems {
+		if item.Status != ReviewGraded && s.managesReviewItem(user, item) {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
+
+// reviewItemFor возвращает элемент очереди, если он из тестов преподавателя; чужие элементы
+// не отличаются от несуществующих. Вызывается под блокировкой
+func (s *Store) reviewItemFor(itemID, teacherID uint64) (*ReviewItem, error) {
+	item, ok := s.reviewItems[itemID]
+	if !ok {
+		return nil, ErrReviewItemNotFound
+	}
+	user, ok := s.lookupUser(teacherID)
+	if !ok || !s.managesReviewItem(user, item) {
+		return nil, ErrReviewItemNotFound
+	}
+	return item, nil
+}
+
+// managesReviewItem сообщает, относится ли элемент очереди к тестам пользователя, вызывается под блокировкой
+func (s *Store) managesReviewItem(user *User, item *ReviewItem) bool {
+	test, ok := s.tests[item.TestID]
+	return ok && managesTest(user, test)
+}
+
+// ClaimReviewItem закрепляет элемент очереди за преподавателем
+func (s *Store) ClaimReviewItem(itemID, teacherID uint64) (*ReviewItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.reviewItemFor(itemID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == ReviewGraded {
+		return nil, ErrReviewItemGraded
+	}
+	if item.Status == ReviewClaimed && *item.ClaimedBy != teacherID {
+		return nil, ErrReviewItemClaimed
+	}
+
+	now := time.Now().UTC()
+	item.Status = ReviewClaimed
+	item.ClaimedBy = &teacherID
+	item.ClaimedAt = &now
+
+	return item, nil
+}
+
+// ReleaseReviewItem возвращает закрепленный элемент обратно в очередь
+func (s *Store) ReleaseReviewItem(itemID, teacherID uint64) (*ReviewItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.reviewItemFor(itemID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == ReviewGraded {
+		return nil, ErrReviewItemGraded
+	}
+	if item.Status == ReviewClaimed && *item.ClaimedBy != teacherID {
+		return nil, ErrReviewItemClaimed
+	}
+
+	item.Status = ReviewPending
+	item.ClaimedBy = nil
+	item.ClaimedAt = nil
+
+	return item, nil
+}
+
+// GradeReviewItem выставляет оценку за ответ и завершает попытку, если все ответы проверены
+func (s *Store) GradeReviewItem(itemID, teacherID, score uint64) (*ReviewItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.reviewItemFor(itemID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == ReviewGraded {
+		return nil, ErrReviewItemGraded
+	}
+	if item.Status == ReviewClaimed && *item.ClaimedBy != teacherID {
+		return nil, ErrReviewItemClaimed
+	}
+	if score > item.MaxScore {
+		return nil, errors.New("score exceeds question max score")
+	}
+
+	attempt, ok := s.attempts[item.AttemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	answer := attempt.Answers[item.QuestionPosition-1]
+	attempt.Result -= answer.Score
+	answer.Score = score
+	answer.RightOrNot = score == item.MaxScore
+	answer.Status = "graded"
+	attempt.Result += answer.Score
+
+	now := time.Now().UTC()
+	item.Status = ReviewGraded
+	item.Score = score
+	item.GradedBy = &teacherID
+	item.GradedAt = &now
+
+	if attempt.Status == "grading" && !s.hasPendingReviews(attempt.ID) {
+		s.finalizeAttempt(attempt)
+	}
+
+	return item, nil
+}
+
+// hasPendingReviews проверяет, остались ли у попытки неоцененные ответы, вызывается под блокировкой
+func (s *Store) hasPendingReviews(attemptID uint64) bool {
+	for _, item := range s.reviewItems {
+		if item.AttemptID == attemptID && item.Status != ReviewGraded {
+			return true
+		}
+	}
+
+	return false
+}
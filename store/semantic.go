@@ -0,0 +1,109 @@
+package store
+
+import "errors"
+
+// Пороги близости по умолчанию для режима semantic
+const (
+	DefaultSemanticAccept = 0.9
+	DefaultSemanticReview = 0.75
+)
+
+// SemanticThresholds - пороги косинусной близости ответа к эталону: от Accept ответ засчитывается
+// автоматически, от Review до Accept уходит на проверку преподавателю, ниже Review - не засчитывается
+type SemanticThresholds struct {
+	Accept float64 `json:"accept" validate:"gt=0,lte=1"`
+	Review float64 `json:"review" validate:"gt=0,ltefield=Accept"`
+}
+
+// validate проверяет, что 0 < review <= accept <= 1
+func (t SemanticThresholds) validate() error {
+	if t.Review <= 0 || t.Accept > 1 || t.Review > t.Accept {
+		return errors.New("thresholds must satisfy 0 < review <= accept <= 1")
+	}
+	return nil
+}
+
+// semanticThresholds возвращает пороги вопроса или пороги по умолчанию
+func (q *Question) semanticThresholds() SemanticThresholds {
+	if q.Semantic != nil {
+		return *q.Semantic
+	}
+	return SemanticThresholds{Accept: DefaultSemanticAccept, Review: DefaultSemanticReview}
+}
+
+// SetQuestionGrading задает режим проверки вопроса и пороги близости для режима semantic.
+// Уже выставленные оценки не меняются. version - версия вопроса, которую видел клиент, 0 - без проверки
+func (s *Store) SetQuestionGrading(testID, questionID uint64, mode string, thresholds *SemanticThresholds, version uint64) (*Question, error) {
+	switch mode {
+	case "", GradingAuto, GradingManual, GradingSemantic:
+	default:
+		return nil, errors.New("gradingMode must be one of: auto, manual, semantic")
+	}
+	if thresholds != nil {
+		if mode != GradingSemantic {
+			return nil, errors.New("thresholds are allowed only for semantic grading")
+		}
+		if err := thresholds.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, err := s.findActiveQuestion(testID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQuestionVersion(question, version); err != nil {
+		return nil, err
+	}
+
+	question.GradingMode = mode
+	question.Semantic = thresholds
+	question.Version++
+
+	return question, nil
+}
+
+// ScoreSemanticAnswer оценивает ответ по близости к эталону. Применяется, только если ответ
+// на позиции все еще text и ждет оценки, иначе возвращает текущий ответ без изменений:
+// студент мог успеть ответить заново, а попытка - уйти на проверку
+func (s *Store) ScoreSemanticAnswer(attemptID, questionPos uint64, text string, similarity float64) (*Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+	if questionPos == 0 || questionPos > uint64(len(attempt.Answers)) {
+		return nil, errors.New("question position out of range")
+	}
+	answer := attempt.Answers[questionPos-1]
+
+	question, ok := s.findQuestionByID(attempt.TestID, answer.QuestionID)
+	if !ok {
+		return nil, errors.New("question not found")
+	}
+	if attempt.Status != "started" || question.GradingMode != GradingSemantic ||
+		answer.Status != "pending_review" || answer.Text != text {
+		return answer, nil
+	}
+
+	answer.Similarity = &similarity
+	thresholds := question.semanticThresholds()
+	switch {
+	case similarity >= thresholds.Accept:
+		answer.Status = "graded"
+		answer.Score = question.MaxScore
+		answer.RightOrNot = true
+		attempt.Result += answer.Score
+	case similarity < thresholds.Review:
+		answer.Status = "graded"
+	default:
+		// Неуверенная оценка: ответ останется pending_review и после сдачи попадет в очередь проверки
+	}
+
+	return answer, nil
+}
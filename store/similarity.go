@@ -0,0 +1,284 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Параметры поиска похожих ответов
+const (
+	SimilarityShingleSize   = 3   // слов в шингле
+	MinSimilarityShingles   = 5   // ответы короче не сравниваются: совпадение коротких ответов ничего не значит
+	SimilarityFlagThreshold = 0.5 // доля общих шинглов (коэффициент Жаккара), с которой пара ответов считается подозрительной
+	// Шингл, который встречается больше чем в этой доле ответов на вопрос, - общая формулировка, а не списывание
+	similarityCommonShare = 0.2
+)
+
+// SimilarAnswers - похожие ответы двух попыток на один вопрос
+type SimilarAnswers struct {
+	QuestionID   uint64  `json:"question_id"`
+	QuestionText string  `json:"question_text"`
+	PositionA    uint64  `json:"position_a"` // позиция вопроса в попытке A
+	PositionB    uint64  `json:"position_b"`
+	TextA        string  `json:"text_a"`
+	TextB        string  `json:"text_b"`
+	Similarity   float64 `json:"similarity"` // доля общих шинглов без слов вопроса и эталона
+}
+
+// SimilarPair - пара попыток разных студентов с похожими ответами
+type SimilarPair struct {
+	AttemptA      uint64            `json:"attempt_a"`
+	UserA         uint64            `json:"user_a"`
+	AttemptB      uint64            `json:"attempt_b"`
+	UserB         uint64            `json:"user_b"`
+	MaxSimilarity float64           `json:"max_similarity"`
+	Answers       []*SimilarAnswers `json:"answers"` // самые похожие первыми
+}
+
+// SimilarityReport - результат проверки ответов теста на списывание
+type SimilarityReport struct {
+	TestID          uint64         `json:"test_id"`
+	CheckedAt       time.Time      `json:"checked_at"`
+	AttemptsChecked int            `json:"attempts_checked"` // сданные попытки
+	AnswersCompared int            `json:"answers_compared"` // развернутые ответы достаточной длины
+	Pairs           []*SimilarPair `json:"pairs"`            // больше похожих ответов и выше близость - раньше
+}
+
+// similarityAnswer - развернутый ответ, участвующий в сравнении
+type similarityAnswer struct {
+	attemptID uint64
+	userID    uint64
+	position  uint64
+	text      string
+	shingles  map[string]bool
+}
+
+// similarityQuestion - вопрос с развернутым ответом и ответы на него из сданных попыток
+type similarityQuestion struct {
+	id       uint64
+	text     string
+	excluded map[string]bool // шинглы текста вопроса и эталонного ответа
+	answers  []*similarityAnswer
+}
+
+// CheckAnswerSimilarity сравнивает развернутые ответы (вопросы manual и semantic) сданных попыток теста попарно
+// по шинглам из SimilarityShingleSize слов и сохраняет пары попыток разных студентов, у которых хотя бы
+// один ответ похож не меньше SimilarityFlagThreshold. Формулировки из вопроса и эталона и шинглы, общие
+// для многих ответов, не учитываются, поэтому правильные ответы сами по себе не считаются похожими
+func (s *Store) CheckAnswerSimilarity(testID uint64) (*SimilarityReport, error) {
+	s.mu.RLock()
+	test, ok := s.activeTest(testID)
+	if !ok {
+		s.mu.RUnlock()
+		return nil, ErrTestNotFound
+	}
+
+	report := &SimilarityReport{TestID: testID, CheckedAt: time.Now().UTC(), Pairs: make([]*SimilarPair, 0)}
+	questions := make(map[uint64]*similarityQuestion)
+	for _, question := range test.Questions {
+		if question.GradingMode != GradingManual && question.GradingMode != GradingSemantic {
+			continue
+		}
+		excluded := shingles(question.Text)
+		for shingle := range shingles(question.TrueAnswer) {
+			excluded[shingle] = true
+		}
+		questions[question.ID] = &similarityQuestion{id: question.ID, text: question.Text, excluded: excluded}
+	}
+	// Тексты копируются под блокировкой, сравнение идет без нее
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status == "started" {
+			continue
+		}
+		report.AttemptsChecked++
+		for i, answer := range attempt.Answers {
+			question, ok := questions[answer.QuestionID]
+			if !ok || strings.TrimSpace(answer.Text) == "" {
+				continue
+			}
+			question.answers = append(question.answers, &similarityAnswer{
+				attemptID: attempt.ID,
+				userID:    attempt.UserID,
+				position:  uint64(i + 1),
+				text:      answer.Text,
+			})
+		}
+	}
+	s.mu.RUnlock()
+
+	pairs := make(map[[2]uint64]*SimilarPair)
+	for _, question := range questions {
+		report.AnswersCompared += question.compare(pairs)
+	}
+	for _, pair := range pairs {
+		sort.Slice(pair.Answers, func(i, j int) bool {
+			if pair.Answers[i].Similarity != pair.Answers[j].Similarity {
+				return pair.Answers[i].Similarity > pair.Answers[j].Similarity
+			}
+			return pair.Answers[i].PositionA < pair.Answers[j].PositionA
+		})
+		pair.MaxSimilarity = pair.Answers[0].Similarity
+		report.Pairs = append(report.Pairs, pair)
+	}
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		a, b := report.Pairs[i], report.Pairs[j]
+		if len(a.Answers) != len(b.Answers) {
+			return len(a.Answers) > len(b.Answers)
+		}
+		if a.MaxSimilarity != b.MaxSimilarity {
+			return a.MaxSimilarity > b.MaxSimilarity
+		}
+		if a.AttemptA != b.AttemptA {
+			return a.AttemptA < b.AttemptA
+		}
+		return a.AttemptB < b.AttemptB
+	})
+
+	// Одновременная проверка, начатая позже, не затирается более старым результатом
+	s.mu.Lock()
+	if last, ok := s.similarityReports[testID]; !ok || !last.CheckedAt.After(report.CheckedAt) {
+		s.similarityReports[testID] = report
+	}
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+// LastSimilarityReport возвращает результат последней проверки теста на списывание
+func (s *Store) LastSimilarityReport(testID uint64) (*SimilarityReport, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, false, ErrTestNotFound
+	}
+	report, ok := s.similarityReports[testID]
+	return report, ok, nil
+}
+
+// StaleSimilarityTests возвращает тесты с развернутыми ответами, в которых после последней проверки
+// на списывание сдана хотя бы одна попытка
+func (s *Store) StaleSimilarityTests() []uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]uint64, 0)
+	for _, test := range s.tests {
+		if test.DeletedAt != nil || !hasFreeTextQuestions(test) {
+			continue
+		}
+		var checkedAt time.Time
+		if report, ok := s.similarityReports[test.ID]; ok {
+			checkedAt = report.CheckedAt
+		}
+		for _, attempt := range s.attemptsByTest[test.ID] {
+			if attempt.Status != "started" && attempt.FinishedAt.After(checkedAt) {
+				result = append(result, test.ID)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+	return result
+}
+
+// compare находит похожие ответы на вопрос и добавляет их в пары попыток, возвращает число сравненных ответов.
+// Кандидаты ищутся по общим шинглам, поэтому попарно сравниваются только ответы, у которых они есть
+func (q *similarityQuestion) compare(pairs map[[2]uint64]*SimilarPair) int {
+	answers := make([]*similarityAnswer, 0, len(q.answers))
+	for _, answer := range q.answers {
+		answer.shingles = shingles(answer.text)
+		for shingle := range answer.shingles {
+			if q.excluded[shingle] {
+				delete(answer.shingles, shingle)
+			}
+		}
+		if len(answer.shingles) >= MinSimilarityShingles {
+			answers = append(answers, answer)
+		}
+	}
+	frequency := make(map[string]int)
+	for _, answer := range answers {
+		for shingle := range answer.shingles {
+			frequency[shingle]++
+		}
+	}
+
+	common := max(2, int(similarityCommonShare*float64(len(answers))))
+	postings := make(map[string][]int)
+	for i, answer := range answers {
+		for shingle := range answer.shingles {
+			if frequency[shingle] > common {
+				delete(answer.shingles, shingle)
+				continue
+			}
+			postings[shingle] = append(postings[shingle], i)
+		}
+	}
+
+	shared := make(map[[2]int]int)
+	for _, list := range postings {
+		for x := 0; x < len(list); x++ {
+			for y := x + 1; y < len(list); y++ {
+				if answers[list[x]].userID != answers[list[y]].userID {
+					shared[[2]int{list[x], list[y]}]++
+				}
+			}
+		}
+	}
+
+	for key, count := range shared {
+		a, b := answers[key[0]], answers[key[1]]
+		similarity := float64(count) / float64(len(a.shingles)+len(b.shingles)-count)
+		if similarity < SimilarityFlagThreshold {
+			continue
+		}
+		if a.attemptID > b.attemptID {
+			a, b = b, a
+		}
+		pairKey := [2]uint64{a.attemptID, b.attemptID}
+		pair, ok := pairs[pairKey]
+		if !ok {
+			pair = &SimilarPair{AttemptA: a.attemptID, UserA: a.userID, AttemptB: b.attemptID, UserB: b.userID}
+			pairs[pairKey] = pair
+		}
+		pair.Answers = append(pair.Answers, &SimilarAnswers{
+			QuestionID:   q.id,
+			QuestionText: q.text,
+			PositionA:    a.position,
+			PositionB:    b.position,
+			TextA:        a.text,
+			TextB:        b.text,
+			Similarity:   similarity,
+		})
+	}
+
+	return len(answers)
+}
+
+// shingles разбивает текст на слова без регистра и знаков препинания и возвращает множество
+// последовательностей из SimilarityShingleSize слов
+func shingles(text string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	result := make(map[string]bool)
+	for i := 0; i+SimilarityShingleSize <= len(words); i++ {
+		result[strings.Join(words[i:i+SimilarityShingleSize], " ")] = true
+	}
+	return result
+}
+
+// hasFreeTextQuestions сообщает, есть ли в тесте вопросы с развернутым ответом
+func hasFreeTextQuestions(test *Test) bool {
+	for _, question := range test.Questions {
+		if question.DeletedAt == nil && (question.GradingMode == GradingManual || question.GradingMode == GradingSemantic) {
+			return true
+		}
+	}
+	return false
+}
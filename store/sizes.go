@@ -0,0 +1,31 @@
+package store
+
+// StoreSizes - число записей в хранилище для мониторинга
+type StoreSizes struct {
+	Users     int
+	Tests     int
+	Attempts  map[string]int // по статусу попытки
+	Sessions  int
+	AIThreads int
+}
+
+// Sizes возвращает число пользователей, тестов, попыток, сессий и диалогов с ассистентом
+func (s *Store) Sizes() StoreSizes {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
+
+	sizes := StoreSizes{
+		Users:     len(s.users),
+		Tests:     len(s.tests),
+		Attempts:  make(map[string]int),
+		Sessions:  len(s.sessions),
+		AIThreads: len(s.aiThreads),
+	}
+	for _, attempt := range s.attempts {
+		sizes.Attempts[attempt.Status]++
+	}
+
+	return sizes
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ImportResult - что добавил импорт снимка. Уже существующие записи пропускаются и перечисляются в Skipped
+type ImportResult struct {
+	Users       int      `json:"users"`
+	Tests       int      `json:"tests"`
+	AccessCodes int      `json:"access_codes"`
+	Skipped     []string `json:"skipped"`
+}
+
+// Snapshot возвращает снимок пользователей (с bcrypt-хешами паролей), тестов с вопросами и кодов доступа
+// к тестам в формате фикстуры (JSON), так что снимок можно сразу передать в SEED_FIXTURE.
+// Удаленные записи входят в снимок с отметкой deletedAt, чтобы их можно было восстановить и после переноса.
+// Попытки, сессии, классы, организации и история ассистента в снимок не входят
+func (s *Store) Snapshot() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
+
+	f := fixture{
+		Users:       make([]fixtureUser, 0, len(s.users)),
+		Tests:       make([]fixtureTest, 0, len(s.tests)),
+		AccessCodes: make([]fixtureAccessCode, 0, len(s.accessCodes)),
+	}
+
+	for _, user := range s.users {
+		f.Users = append(f.Users, fixtureUser{Email: user.Email, PasswordHash: user.Password, Role: user.Role, DeletedAt: user.DeletedAt})
+	}
+	sort.Slice(f.Users, func(i, j int) bool {
+		return s.usersByEmail[f.Users[i].Email] < s.usersByEmail[f.Users[j].Email]
+	})
+
+	for _, test := range s.tests {
+		f.Tests = append(f.Tests, fixtureTest{
+			ID:             test.ID,
+			Name:           test.Name,
+			Description:    test.Description,
+			TimeLimit:      fixtureDuration(test.TimeLimit),
+			MaxScore:       test.MaxScore,
+			NumOfQuestions: test.NumOfQuestions,
+			GracePeriod:    fixtureDuration(test.GracePeriod),
+			LatePenalty:    test.LatePenalty,
+			RetakeCooldown: fixtureDuration(test.RetakeCooldown),
+			AutoPauseAfter: fixtureDuration(test.AutoPauseAfter),
+			TeamMode:       test.TeamMode,
+			OpenEnrollment: test.OpenEnrollment,
+			AIMessageLimit: test.AIMessageLimit,
+			AITokenLimit:   test.AITokenLimit,
+			AIConfig:       test.AIConfig,
+			Questions:      test.Questions,
+			DeletedAt:      test.DeletedAt,
+			Version:        test.Version,
+		})
+	}
+	sort.Slice(f.Tests, func(i, j int) bool {
+		return f.Tests[i].ID < f.Tests[j].ID
+	})
+
+	for _, accessCode := range s.accessCodes {
+		// Коды классов зачисляют в класс, а классы в снимок не входят
+		if accessCode.GroupID != 0 {
+			continue
+		}
+		f.AccessCodes = append(f.AccessCodes, fixtureAccessCode{
+			Code:             accessCode.Code,
+			TestID:           accessCode.TestID,
+			MaxUses:          accessCode.MaxUses,
+			ExpiresAt:        accessCode.ExpiresAt,
+			BoundEmail:       accessCode.BoundEmail,
+			ActivationWindow: fixtureDuration(accessCode.ActivationWindow),
+			UsedCount:        accessCode.UsedCount,
+			ActivatedAt:      accessCode.ActivatedAt,
+			ConsumedAt:       accessCode.ConsumedAt,
+			Suspended:        accessCode.Suspended,
+		})
+	}
+	sort.Slice(f.AccessCodes, func(i, j int) bool {
+		return f.AccessCodes[i].Code < f.AccessCodes[j].Code
+	})
+
+	// Вопросы общие с хранилищем, поэтому кодируем под блокировкой
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// ImportSnapshot добавляет в хранилище данные снимка или фикстуры (JSON или YAML по расширению name).
+// Существующие пользователи (по email), тесты (по ID) и коды пропускаются, поэтому импорт можно повторять.
+// Формат снимка проверяется целиком до изменений
+func (s *Store) ImportSnapshot(data []byte, name string) (*ImportResult, error) {
+	f, err := parseFixture(data, name)
+	if err != nil {
+		return nil, fmt.Errorf("import snapshot: %w", err)
+	}
+
+	result, err := s.loadFixture(f, true)
+	if err != nil {
+		return nil, fmt.Errorf("import snapshot: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Пользователи, тесты и вопросы удаляются мягко: запись получает DeletedAt и пропадает из списков
+// и поиска, но остается в хранилище, поэтому ее можно восстановить, а попытки и история, которые
+// на нее ссылаются, не ломаются. Начатые попытки удаленного теста можно закончить
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrTestNotFound     = errors.New("test not found")
+	ErrQuestionNotFound = errors.New("question not found")
+)
+
+// activeUser возвращает неудаленного пользователя, вызывается под блокировкой authMu
+func (s *Store) activeUser(userID uint64) (*User, bool) {
+	user, ok := s.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return nil, false
+	}
+	return user, true
+}
+
+// lookupUser - activeUser для кода под mu: сам берет authMu на чтение и возвращает копию,
+// которую можно читать и после снятия блокировки
+func (s *Store) lookupUser(userID uint64) (*User, bool) {
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
+
+	user, ok := s.activeUser(userID)
+	if !ok {
+		return nil, false
+	}
+	result := *user
+	return &result, true
+}
+
+// activeTest возвращает неудаленный тест, вызывается под блокировкой
+func (s *Store) activeTest(testID uint64) (*Test, bool) {
+	test, ok := s.tests[testID]
+	if !ok || test.DeletedAt != nil {
+		return nil, false
+	}
+	return test, true
+}
+
+// activeQuestions возвращает неудаленные вопросы в исходном порядке
+func activeQuestions(questions []*Question) []*Question {
+	result := make([]*Question, 0, len(questions))
+	for _, question := range questions {
+		if question.DeletedAt == nil {
+			result = append(result, question)
+		}
+	}
+	return result
+}
+
+// findActiveQuestion ищет неудаленный вопрос неудаленного теста для изменения преподавателем,
+// вызывается под блокировкой. Попытки ищут вопросы через findQuestionByID, который видит и удаленные
+func (s *Store) findActiveQuestion(testID, questionID uint64) (*Question, error) {
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
+	}
+	question, ok := s.findQuestionByID(testID, questionID)
+	if !ok || question.DeletedAt != nil {
+		return nil, ErrQuestionNotFound
+	}
+	return question, nil
+}
+
+// DeleteUser помечает пользователя удаленным и завершает его сессии: войти он больше не может,
+// а email остается занятым до восстановления. Возвращает завершенные сессии, чтобы закрыть их соединения
+func (s *Store) DeleteUser(userID uint64) ([]string, error) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	user, ok := s.activeUser(userID)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	now := time.Now().UTC()
+	user.DeletedAt = &now
+
+	sessions := make([]string, 0)
+	for sessionID, session := range s.sessions {
+		if session.userID == userID {
+			delete(s.sessions, sessionID)
+			sessions = append(sessions, sessionID)
+		}
+	}
+
+	return sessions, nil
+}
+
+// RestoreUser снимает отметку удаления с пользователя. Восстановление неудаленного ничего не меняет
+func (s *Store) RestoreUser(userID uint64) (*User, error) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user.DeletedAt = nil
+
+	result := *user
+	return &result, nil
+}
+
+// DeleteTest помечает тест удаленным: он пропадает из списков, новые попытки и коды доступа
+// к нему не создаются, а начатые попытки можно закончить
+func (s *Store) DeleteTest(testID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return ErrTestNotFound
+	}
+	now := time.Now().UTC()
+	test.DeletedAt = &now
+	test.Version++
+
+	return nil
+}
+
+// RestoreTest снимает отметку удаления с теста и возвращает его копию без вопросов
+func (s *Store) RestoreTest(testID uint64) (*Test, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.tests[testID]
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+	if test.DeletedAt != nil {
+		test.DeletedAt = nil
+		test.Version++
+	}
+
+	result := *test
+	result.Questions = nil
+	return &result, nil
+}
+
+// ListDeletedTests возвращает копии удаленных тестов преподавателя (см. teacherTests) без вопросов, по возрастанию ID
+func (s *Store) ListDeletedTests(userID uint64) ([]*Test, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.lookupUser(userID)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	result := make([]*Test, 0)
+	for _, test := range s.tests {
+		if test.DeletedAt == nil || !managesTest(user, test) {
+			continue
+		}
+		t := *test
+		t.Questions = nil
+		result = append(result, &t)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+// DeleteQuestion помечает вопрос теста удаленным: в новые попытки он не попадает,
+// а в начатых и завершенных остается
+func (s *Store) DeleteQuestion(testID, questionID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, err := s.findActiveQuestion(testID, questionID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	question.DeletedAt = &now
+	question.Version++
+
+	return nil
+}
+
+// RestoreQuestion снимает отметку удаления с вопроса. Вопрос удаленного теста восстанавливается
+// только после самого теста. Удаление и восстановление увеличивают версию, но не требуют ее
+func (s *Store) RestoreQuestion(testID, questionID uint64) (*Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
+	}
+	question, ok := s.findQuestionByID(testID, questionID)
+	if !ok {
+		return nil, ErrQuestionNotFound
+	}
+	if question.DeletedAt != nil {
+		question.DeletedAt = nil
+		question.Version++
+	}
+
+	return question, nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultPassPercent - проходной балл по умолчанию, процент от максимального балла теста
+const DefaultPassPercent = 60
+
+// TestStats - сводка по попыткам теста для панели преподавателя
+type TestStats struct {
+	TestID             uint64   `json:"test_id"`
+	MaxScore           uint64   `json:"max_score"`
+	Students           int      `json:"students"`               // разные студенты, начавшие тест
+	AttemptsStarted    int      `json:"attempts_started"`       // все попытки, включая незаконченные
+	AttemptsInProgress int      `json:"attempts_in_progress"`   // еще идут
+	AttemptsGrading    int      `json:"attempts_grading"`       // сданы и ждут ручной проверки
+	AttemptsSubmitted  int      `json:"attempts_submitted"`     // сданы и оценены, по ним считаются баллы
+	AvgScore           *float64 `json:"avg_score,omitempty"`    // средний результат
+	MedianScore        *float64 `json:"median_score,omitempty"` // медиана результата
+	// Среднее время работы над попыткой в секундах, без автопаузы
+	AvgDurationSeconds *float64 `json:"avg_duration_seconds,omitempty"`
+	PassScore          uint64   `json:"pass_score"`          // проходной балл
+	PassRate           *float64 `json:"pass_rate,omitempty"` // доля оцененных попыток с результатом не ниже проходного
+}
+
+// TestStats считает статистику попыток теста. Проходной балл - passPercent процентов от максимального
+// балла теста (округляется вверх). Средние, медиана и доля прошедших есть, только если есть оцененные попытки
+func (s *Store) TestStats(testID uint64, passPercent uint64) (*TestStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	stats := &TestStats{
+		TestID:    testID,
+		MaxScore:  test.MaxScore,
+		PassScore: (test.MaxScore*passPercent + 99) / 100,
+	}
+
+	students := make(map[uint64]bool)
+	var scores, durations []float64
+	passed := 0
+	for _, attempt := range s.attemptsByTest[testID] {
+		students[attempt.UserID] = true
+		stats.AttemptsStarted++
+
+		switch attempt.Status {
+		case "started":
+			stats.AttemptsInProgress++
+		case "grading":
+			stats.AttemptsGrading++
+		case "submitted":
+			stats.AttemptsSubmitted++
+			scores = append(scores, float64(attempt.Result))
+			durations = append(durations, attemptDuration(attempt).Seconds())
+			if attempt.Result >= stats.PassScore {
+				passed++
+			}
+		}
+	}
+	stats.Students = len(students)
+
+	stats.AvgScore = optionalMean(scores)
+	stats.MedianScore = optionalMedian(scores)
+	stats.AvgDurationSeconds = optionalMean(durations)
+	if len(scores) > 0 {
+		rate := float64(passed) / float64(len(scores))
+		stats.PassRate = &rate
+	}
+
+	return stats, nil
+}
+
+// attemptDuration возвращает время работы над законченной попыткой без автопаузы
+func attemptDuration(attempt *Attempt) time.Duration {
+	return max(attempt.FinishedAt.Sub(attempt.StartedAt)-attempt.PausedFor, 0)
+}
+
+// optionalMedian возвращает медиану или nil для пустой выборки. values сортируется
+func optionalMedian(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+	m := values[len(values)/2]
+	if len(values)%2 == 0 {
+		m = (values[len(values)/2-1] + m) / 2
+	}
+	return &m
+}
+
+// DefaultScoreBuckets и MaxScoreBuckets - число интервалов распределения результатов по умолчанию и наибольшее
+const (
+	DefaultScoreBuckets = 10
+	MaxScoreBuckets     = 100
+)
+
+// ScoreBucket - интервал результатов [From, To) и число попыток в нем. У последнего интервала To входит
+type ScoreBucket struct {
+	From  uint64 `json:"from"`
+	To    uint64 `json:"to"`
+	Count int    `json:"count"`
+}
+
+// ScoreDistribution - распределение результатов оцененных попыток теста
+type ScoreDistribution struct {
+	TestID   uint64         `json:"test_id"`
+	MaxScore uint64         `json:"max_score"`
+	Attempts int            `json:"attempts"`
+	Buckets  []*ScoreBucket `json:"buckets"`
+}
+
+// ScoreDistribution делит шкалу от 0 до максимального балла теста на buckets равных интервалов
+// и считает в них оцененные попытки. Интервалов не больше, чем баллов, чтобы не было пустых по построению;
+// результат выше максимального балла попадает в последний интервал
+func (s *Store) ScoreDistribution(testID uint64, buckets int) (*ScoreDistribution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	n := uint64(max(min(buckets, MaxScoreBuckets), 1))
+	if test.MaxScore > 0 {
+		n = min(n, test.MaxScore)
+	} else {
+		n = 1
+	}
+
+	// Границы округляются вверх, чтобы совпадать с номером интервала результата r*n/MaxScore
+	bound := func(i uint64) uint64 {
+		return (i*test.MaxScore + n - 1) / n
+	}
+	result := &ScoreDistribution{TestID: testID, MaxScore: test.MaxScore, Buckets: make([]*ScoreBucket, n)}
+	for i := range result.Buckets {
+		result.Buckets[i] = &ScoreBucket{From: bound(uint64(i)), To: bound(uint64(i) + 1)}
+	}
+
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status != "submitted" {
+			continue
+		}
+		result.Attempts++
+
+		i := n - 1
+		if attempt.Result < test.MaxScore {
+			i = attempt.Result * n / test.MaxScore
+		}
+		result.Buckets[i].Count++
+	}
+
+	return result, nil
+}
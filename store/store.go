@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +18,23 @@ import (
 var (
 	ErrUserExists             = errors.New("user already exists")
 	ErrInvalidEmailOrPassword = errors.New("invalid email or password")
+
+	errAccessCodeExists   = errors.New("access code already exists")
+	errAccessCodeChecksum = errors.New("access code looks like XXXX-XXXX-C but its check character is wrong, use checksum or another format")
+)
+
+// Роли пользователей
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+// Режимы проверки ответа на вопрос
+const (
+	GradingAuto     = "auto"     // точное сравнение с эталонным ответом
+	GradingManual   = "manual"   // ответ проверяет преподаватель
+	GradingSemantic = "semantic" // близость по смыслу к эталонному ответу через эмбеддинги
 )
 
 type AccessCode struct {
@@ -24,32 +44,125 @@ type AccessCode struct {
 	UsedCount uint64     `json:"used_count"` // сколько раз использован
 	ExpiresAt *time.Time `json:"expires_at"` // nil = не истекает
 	CreatedAt time.Time  `json:"created_at"`
+
+	BoundEmail string     `json:"bound_email,omitempty"` // персональный одноразовый код для пользователя с этим email
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"` // когда персональный код был использован
+
+	GroupID uint64 `json:"group_id,omitempty"` // код класса: зачисляет в группу и дает доступ ко всем ее тестам
+	OrgID   uint64 `json:"org_id,omitempty"`   // организация, в пространстве имен которой создан код
+
+	ActivationWindow time.Duration `json:"activation_window" swaggertype:"integer"` // сколько код действует после первого использования, 0 = без ограничения
+	ActivatedAt      *time.Time    `json:"activated_at,omitempty"`                  // когда код был использован впервые
+
+	Suspended   bool       `json:"suspended"`              // код отозван и не принимается
+	SuspendedAt *time.Time `json:"suspended_at,omitempty"` // когда код был отозван
+}
+
+// session - вход пользователя по cookie
+type session struct {
+	userID    uint64
+	createdAt time.Time
 }
 
+// Store хранит все данные в памяти. Данные разделены на части со своими блокировками, чтобы
+// долгий проход по попыткам или журналу вебхуков не задерживал вход и проверку сессии в каждом запросе:
+//   - authMu: пользователи (users, usersByEmail, nextUserID) и сессии;
+//   - webhookMu: вебхуки и журнал их доставок;
+//   - mu: все остальное.
+//
+// Если нужны обе, mu берется раньше authMu; под authMu mu не берется. Код под mu читает
+// пользователей через lookupUser
 type Store struct {
-	mu           sync.RWMutex
+	mu sync.RWMutex
+
+	authMu       sync.RWMutex
 	users        map[uint64]*User
 	usersByEmail map[string]uint64
-	tests        map[uint64]*Test
-	attempts     map[uint64]*Attempt
-	sessions     map[string]uint64
-	aiThreads    map[uint64]*AIThread
-	accessCodes  map[string]*AccessCode // key = код доступа
 	nextUserID   uint64
+	sessions     map[string]*session
+
+	tests         map[uint64]*Test
+	attempts      map[uint64]*Attempt
+	aiThreads     map[uint64]*AIThread
+	aiThreadsByID map[string]*AIThread
+
+	// Попытки по владельцу и по тесту в порядке создания, заполняются в addAttempt.
+	// Участники командной попытки в attemptsByUser не попадают: их состав меняется, см. canAccessAttempt
+	attemptsByUser map[uint64][]*Attempt
+	attemptsByTest map[uint64][]*Attempt
+
+	aiRuns      map[uint64]*AIRun
+	nextAIRunID uint64
+
+	aiImages      map[uint64]*AIImage
+	nextAIImageID uint64
+
+	testResources  map[uint64]*TestResource
+	nextResourceID uint64
+
+	aiUsageByUser    map[uint64]*AIUsage
+	aiUsageByAttempt map[uint64]*AIUsage
+	aiUsageByTest    map[uint64]*AIUsage
+	aiUsageByOrg     map[uint64]*AIUsage
+	aiUsageTotal     AIUsage
+	accessCodes      map[string]*AccessCode  // key = код доступа
+	codeUsages       map[string][]*CodeUsage // key = код доступа
+	reviewItems      map[uint64]*ReviewItem
+	nextReviewID     uint64
+
+	groups      map[uint64]*Group
+	nextGroupID uint64
+
+	organizations      map[uint64]*Organization
+	nextOrganizationID uint64
+
+	notifications      map[uint64]*Notification
+	nextNotificationID uint64
+	nextFeedbackID     uint64
+
+	promptTemplates map[uint64]*PromptTemplate
+	nextPromptID    uint64
+
+	reportSubscriptions      map[uint64]*ReportSubscription
+	nextReportSubscriptionID uint64
+
+	similarityReports map[uint64]*SimilarityReport // key = ID теста, последняя проверка на списывание
+
+	webhookMu             sync.RWMutex
+	webhooks              map[uint64]*Webhook
+	nextWebhookID         uint64
+	webhookDeliveries     map[uint64][]*WebhookDelivery // key = ID вебхука, от старых к новым
+	webhookDeliveryByID   map[uint64]*WebhookDelivery
+	nextWebhookDeliveryID uint64
+
+	aiBudgets    map[uint64]float64 // месячный лимит расходов на ассистента по организациям, 0 = общий
+	aiSpend      map[uint64]float64 // расход за aiSpendMonth по организациям, 0 = общий
+	aiSpendMonth string
 }
 
 type User struct {
-	ID        uint64    `json:"id"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint64     `json:"id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	OrgID     uint64     `json:"org_id,omitempty"`
+	Password  string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // мягкое удаление, см. DeleteUser
 }
 
 type AIThread struct {
-	AttemptID uint64    `json:"attempt_id"`
-	ThreadID  string    `json:"thread_id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	AttemptID        uint64            `json:"attempt_id"`
+	QuestionPosition uint64            `json:"question_position"`
+	ThreadID         string            `json:"thread_id"`
+	Status           string            `json:"status"`
+	CloseReason      string            `json:"close_reason,omitempty"` // attempt_finished или turn_limit
+	Context          string            `json:"-"`                      // вопрос и правила помощи, передаются ассистенту с каждым сообщением
+	Prompt           *PromptVersionRef `json:"prompt,omitempty"`       // версия шаблона промпта, по которой собран контекст
+	Messages         []*AIMessage      `json:"messages"`
+	Attachments      []uint64          `json:"attachments,omitempty"` // файлы из белого списка теста, прикрепленные к диалогу
+	CreatedAt        time.Time         `json:"created_at"`
+	ClosedAt         *time.Time        `json:"closed_at,omitempty"`
+	remoteDeleted    bool              // тред удален у провайдера
 }
 
 type Answer struct {
@@ -57,143 +170,196 @@ type Answer struct {
 	QuestionID uint64    `json:"question_id"`
 	Text       string    `json:"text"`
 	RightOrNot bool      `json:"right_or_no"`
+	Score      uint64    `json:"score"`
+	Status     string    `json:"status"`               // graded или pending_review
+	Similarity *float64  `json:"similarity,omitempty"` // близость к эталонному ответу в режиме semantic
 	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Attempt struct {
-	ID         uint64    `json:"id"`
-	UserID     uint64    `json:"user_id"`
-	TestID     uint64    `json:"test_id"`
-	Status     string    `json:"status"`
-	Answers    []*Answer `json:"answers"`
-	Result     uint64    `json:"result"`
-	StartedAt  time.Time `json:"started_at"`
-	FinishedAt time.Time `json:"finished_at"`
+	ID           uint64              `json:"id"`
+	UserID       uint64              `json:"user_id"`
+	TestID       uint64              `json:"test_id"`
+	Status       string              `json:"status"`
+	Answers      []*Answer           `json:"answers"`
+	Questions    []uint64            `json:"question_ids"` // ID выбранных вопросов в порядке показа студенту
+	Seed         int64               `json:"seed"`         // сид случайного выбора вопросов
+	Result       uint64              `json:"result"`
+	Late         bool                `json:"late"`    // сдана в льготный период после дедлайна
+	Penalty      uint64              `json:"penalty"` // сколько баллов снято за опоздание
+	AICost       uint64              `json:"ai_cost"` // сколько баллов снято за подсказки ассистента
+	StartedAt    time.Time           `json:"started_at"`
+	FinishedAt   time.Time           `json:"finished_at"`
+	LastSeenAt   time.Time           `json:"last_seen_at"`                     // время последнего heartbeat
+	PausedFor    time.Duration       `json:"paused_for" swaggertype:"integer"` // суммарное время автопаузы, сдвигает дедлайн
+	Regrades     []*RegradeEvent     `json:"regrades,omitempty"`
+	Feedback     []*Feedback         `json:"feedback,omitempty"`
+	GroupID      uint64              `json:"group_id,omitempty"` // команда для командной попытки
+	Participants []*ParticipantEntry `json:"participants,omitempty"`
+	// Журнал прокторинга виден только преподавателям через отдельный эндпоинт
+	ProctoringEvents []*ProctoringEvent `json:"-"`
+	// Перефразированные вопросы: студент видит только их текст, исходный - преподаватели
+	Paraphrases []*Paraphrase `json:"-"`
+	// Открытия вопросов и сохранения ответов для хронологии попытки, см. timeline.go
+	Journal []*AttemptAction `json:"-"`
+
+	timeWarning time.Duration // наименьший порог оставшегося времени, о котором уже предупредили
+}
+
+// copyAttempt копирует попытку для ответа API: ответы и журналы копируются, чтобы сериализация после
+// снятия блокировки не читала данные, которые меняют другие запросы. Вызывается под блокировкой
+func copyAttempt(attempt *Attempt) *Attempt {
+	result := *attempt
+	result.Answers = make([]*Answer, len(attempt.Answers))
+	for i, answer := range attempt.Answers {
+		answerCopy := *answer
+		result.Answers[i] = &answerCopy
+	}
+	result.Questions = slices.Clone(attempt.Questions)
+	result.Regrades = slices.Clone(attempt.Regrades)
+	result.Feedback = slices.Clone(attempt.Feedback)
+	result.Participants = slices.Clone(attempt.Participants)
+	result.ProctoringEvents = nil
+	result.Paraphrases = nil
+	result.Journal = nil
+	return &result
 }
 
 type Question struct {
-	ID         uint64 `json:"id"`
-	Name       string `json:"name"`
-	Text       string `json:"text"`
-	TrueAnswer string `json:"answer"`
-	MaxScore   uint64 `json:"maxScore"`
+	ID          uint64              `json:"id"`
+	Name        string              `json:"name"`
+	Text        string              `json:"text"`
+	TrueAnswer  string              `json:"answer"`
+	MaxScore    uint64              `json:"maxScore"`
+	GradingMode string              `json:"gradingMode"`         // auto (по умолчанию), manual или semantic
+	Semantic    *SemanticThresholds `json:"semantic,omitempty"`  // пороги близости для режима semantic, nil = по умолчанию
+	AIConfig    *AIConfig           `json:"aiConfig,omitempty"`  // переопределяет настройки ассистента теста
+	Materials   []string            `json:"materials,omitempty"` // справочные материалы, доступные ассистенту через функцию materials
+	DeletedAt   *time.Time          `json:"deletedAt,omitempty"` // мягкое удаление, см. DeleteQuestion
+	Version     uint64              `json:"version"`             // растет при каждом изменении, см. version.go
 }
 
 type Test struct {
 	ID             uint64        `json:"id"`
 	Name           string        `json:"name"`
 	Description    string        `json:"description"`
-	TimeLimit      time.Duration `json:"timeLimit"`
+	TimeLimit      time.Duration `json:"timeLimit" swaggertype:"integer"`
 	MaxScore       uint64        `json:"maxScore"`
 	Questions      []*Question   `json:"questions,omitempty"`
-	NumOfQuestions uint64        `json:"numOfQuestions"` // Количество вопросов, которые нужно выбрать для попытки
+	NumOfQuestions uint64        `json:"numOfQuestions"`                       // Количество вопросов, которые нужно выбрать для попытки
+	GracePeriod    time.Duration `json:"gracePeriod" swaggertype:"integer"`    // Льготный период после дедлайна, 0 = без льготного периода
+	LatePenalty    uint64        `json:"latePenalty"`                          // Штраф в процентах от результата за сдачу в льготный период
+	RetakeCooldown time.Duration `json:"retakeCooldown" swaggertype:"integer"` // Минимальная пауза между попытками одного пользователя, 0 = без ограничений
+	AutoPauseAfter time.Duration `json:"autoPauseAfter" swaggertype:"integer"` // Через сколько без heartbeat попытка ставится на паузу, 0 = не ставится. Паузы вместе не длиннее TimeLimit
+	TeamMode       bool          `json:"teamMode"`                             // Тест проходится командой (группой) в одной общей попытке
+	OpenEnrollment bool          `json:"openEnrollment"`                       // Попытку можно начать без кода доступа
+	OrgID          uint64        `json:"orgId,omitempty"`                      // Организация-владелец теста
+	AIMessageLimit int           `json:"aiMessageLimit"`                       // Сколько сообщений ассистенту можно отправить за попытку, 0 = без ограничений
+	AITokenLimit   int           `json:"aiTokenLimit"`                         // Сколько токенов ассистента можно израсходовать за попытку, 0 = без ограничений
+	AIConfig       *AIConfig     `json:"aiConfig,omitempty"`                   // Ассистент, модель и температура теста, nil = настройки сервера
+	Prompt         *TestPrompt   `json:"prompt,omitempty"`                     // Шаблон системного промпта, nil = контекст по умолчанию
+	DeletedAt      *time.Time    `json:"deletedAt,omitempty"`                  // Мягкое удаление, см. DeleteTest
+	Version        uint64        `json:"version"`                              // Растет при каждом изменении теста, см. version.go
 }
 
-func (s *Store) InitFillStore() error {
-	_, err := s.CreateUser("user@test.test", "test")
-	if err != nil {
-		return fmt.Errorf("init fill store: %w", err)
-	}
-
-	test := Test{
-		ID:          1,
-		Name:        "test 1",
-		Description: "description for test 1",
-		TimeLimit:   time.Hour * 1,
-		MaxScore:    100,
-		Questions: []*Question{
-			{
-				ID: 1,
-				Text: `Посчитать точное количество гласных букв в гимне Российской федерации
-						за вычетом буквы 'о', ответ вывести по такой формуле
-						X (количество гласных букв) - Y (количество   букв 'о') = Z`,
-				TrueAnswer: "270",
-				MaxScore:   10,
-			},
-			{
-				ID: 2,
-				Text: `Определи что за источник, напиши точную дату публикации и время выхода новости:
-						'С января по сентябрь самая высокая доходность в рублях была у корпоративных облигаций.
-						 Но отдельно по итогам сентября на первое место по доходности вышел другой актив'`,
-				TrueAnswer: "РБК",
-				MaxScore:   10,
-			},
-			{
-				ID: 3,
-				Text: `Рассчитать  beta = Cov (Ra, Rp)/Var(Ra) для невозобновляемых ресурсов в монголии
-						 по 5 разным показателям на основе данных на 2025 год world bank group`,
-				TrueAnswer: "2334",
-				MaxScore:   10,
-			},
-			{
-				ID:         4,
-				Text:       "расставь знаки припинания: научно-технический прогресс не социальный принесёт счастья если не будет дополняться чрезвычайно глубокими изменениями в социальной нравственной и культурной жизни человечества внутреннюю духовную жизнь людей внутренние импульсы их активности трудней всего прогнозировать но именно от этого зависит в конечном итоге и гибель и спасение цивилизации",
-				TrueAnswer: "Научно-технический прогресс не социальный принесёт счастья, если не будет дополняться чрезвычайно глубокими изменениями в социальной, нравственной и культурной жизни человечества. Внутреннюю духовную жизнь людей, внутренние импульсы их активности трудней всего прогнозировать, но именно от этого зависит в конечном итоге и гибель, и спасение цивилизации.",
-				MaxScore:   10,
-			},
-			{
-				ID:         5,
-				Text:       "В комнате находятся Анна, Борис, Василий и Галина. Известно,\n1. Если Анна не брала конфету, то её взял Борис\n2. Если Василий не брал конфету, то Галина тоже её не брала\n3. Ровно один человек взял конфету",
-				TrueAnswer: "анна взяла конфету",
-				MaxScore:   10,
-			},
-			{
-				ID:         6,
-				Text:       "Двойная звезда имеет период Т = 3 года, а расстояние L между ее компонентами равно двум астрономическим единицам. Вырази массу звезды через массу Солнца и сократи до 2 знака после запятой",
-				TrueAnswer: "0,89",
-				MaxScore:   10,
-			},
-			{
-				ID:         7,
-				Text:       "Какая была ключевая ставка ЦБ РФ 22.08.1995",
-				TrueAnswer: "180",
-				MaxScore:   10,
-			},
-		},
-		NumOfQuestions: 7,
-	}
-
-	s.tests[test.ID] = &test
-
-	// Создаем тестовый бесконечный код доступа для test 1
-	_, err = s.CreateAccessCode("TEST-2025-INFINITY", test.ID, nil, nil)
-	if err != nil {
-		return fmt.Errorf("init fill store: failed to create access code: %w", err)
-	}
+// RetakeCooldownError возвращается, если пользователь начинает новую попытку раньше, чем закончилась пауза
+type RetakeCooldownError struct {
+	NextAttemptAt time.Time
+}
 
-	return nil
+func (e *RetakeCooldownError) Error() string {
+	return fmt.Sprintf("next attempt is available at %s", e.NextAttemptAt.Format(time.RFC3339))
 }
 
 func NewStore() *Store {
 	return &Store{
-		users:        make(map[uint64]*User),
-		tests:        make(map[uint64]*Test),
-		attempts:     make(map[uint64]*Attempt),
-		usersByEmail: make(map[string]uint64),
-		sessions:     make(map[string]uint64),
-		aiThreads:    make(map[uint64]*AIThread),
-		accessCodes:  make(map[string]*AccessCode),
-		nextUserID:   1,
+		users:         make(map[uint64]*User),
+		tests:         make(map[uint64]*Test),
+		attempts:      make(map[uint64]*Attempt),
+		usersByEmail:  make(map[string]uint64),
+		sessions:      make(map[string]*session),
+		aiThreads:     make(map[uint64]*AIThread),
+		aiThreadsByID: make(map[string]*AIThread),
+
+		attemptsByUser: make(map[uint64][]*Attempt),
+		attemptsByTest: make(map[uint64][]*Attempt),
+
+		aiRuns:      make(map[uint64]*AIRun),
+		nextAIRunID: 1,
+
+		aiImages:      make(map[uint64]*AIImage),
+		nextAIImageID: 1,
+
+		testResources:  make(map[uint64]*TestResource),
+		nextResourceID: 1,
+
+		aiUsageByUser:    make(map[uint64]*AIUsage),
+		aiUsageByAttempt: make(map[uint64]*AIUsage),
+		aiUsageByTest:    make(map[uint64]*AIUsage),
+		aiUsageByOrg:     make(map[uint64]*AIUsage),
+		accessCodes:      make(map[string]*AccessCode),
+		codeUsages:       make(map[string][]*CodeUsage),
+		nextUserID:       1,
+		reviewItems:      make(map[uint64]*ReviewItem),
+		nextReviewID:     1,
+
+		groups:      make(map[uint64]*Group),
+		nextGroupID: 1,
+
+		organizations:      make(map[uint64]*Organization),
+		nextOrganizationID: 1,
+
+		notifications:      make(map[uint64]*Notification),
+		nextNotificationID: 1,
+		nextFeedbackID:     1,
+
+		promptTemplates: make(map[uint64]*PromptTemplate),
+		nextPromptID:    1,
+
+		reportSubscriptions:      make(map[uint64]*ReportSubscription),
+		nextReportSubscriptionID: 1,
+
+		similarityReports: make(map[uint64]*SimilarityReport),
+
+		webhooks:              make(map[uint64]*Webhook),
+		nextWebhookID:         1,
+		webhookDeliveries:     make(map[uint64][]*WebhookDelivery),
+		webhookDeliveryByID:   make(map[uint64]*WebhookDelivery),
+		nextWebhookDeliveryID: 1,
+
+		aiBudgets: make(map[uint64]float64),
+		aiSpend:   make(map[uint64]float64),
 	}
 }
 
 func (s *Store) CreateUser(email, password string) (*User, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.usersByEmail[email]; ok {
-		return nil, ErrUserExists
-	}
-
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("cannot hash password: %w", err)
 	}
 
+	return s.addUser(email, string(hashedPassword), RoleStudent)
+}
+
+// addUser сохраняет пользователя с готовым bcrypt-хешем пароля, если email еще не занят
+func (s *Store) addUser(email, passwordHash, role string) (*User, error) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	return s.addUserLocked(email, passwordHash, role)
+}
+
+// addUserLocked - addUser под уже взятой блокировкой authMu
+func (s *Store) addUserLocked(email, passwordHash, role string) (*User, error) {
+	if _, ok := s.usersByEmail[email]; ok {
+		return nil, ErrUserExists
+	}
+
 	user := &User{
 		ID:        s.nextUserID,
 		Email:     email,
-		Password:  string(hashedPassword),
+		Role:      role,
+		Password:  passwordHash,
 		CreatedAt: time.Now().UTC(),
 	}
 	s.users[user.ID] = user
@@ -203,27 +369,45 @@ func (s *Store) CreateUser(email, password string) (*User, error) {
 	return user, nil
 }
 
+// CreateAttempt начинает попытку теста. Пауза между попытками проверяется под той же блокировкой,
+// что и создание, иначе одновременные запросы начали бы несколько попыток в обход паузы
 func (s *Store) CreateAttempt(testID, userID uint64) (*Attempt, error) {
-	test, exists := s.tests[testID]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, exists := s.activeTest(testID)
 	if !exists {
-		return nil, fmt.Errorf("test not found")
+		return nil, ErrTestNotFound
 	}
+	if err := s.checkRetakeCooldown(userID, test); err != nil {
+		return nil, err
+	}
+
+	return copyAttempt(s.createAttemptLocked(test, userID)), nil
+}
 
-	// Выбираем случайные вопросы
-	selectedQuestions := s.getRandomQuestions(test.Questions, test.NumOfQuestions)
+// createAttemptLocked создает попытку теста и добавляет ее в индексы, вызывается под блокировкой
+func (s *Store) createAttemptLocked(test *Test, userID uint64) *Attempt {
+	// Выбираем случайные вопросы из неудаленных, сид сохраняется в попытке для воспроизводимости
+	seed := time.Now().UnixNano()
+	selectedQuestions := selectQuestions(activeQuestions(test.Questions), test.NumOfQuestions, seed)
 
 	// Создаем новую попытку
 	attempt := &Attempt{
 		ID:        uint64(len(s.attempts)) + 1,
 		UserID:    userID,
-		TestID:    testID,
+		TestID:    test.ID,
 		Status:    "started", // Статус попытки
 		Answers:   make([]*Answer, len(selectedQuestions)),
+		Questions: make([]uint64, len(selectedQuestions)),
+		Seed:      seed,
 		StartedAt: time.Now().UTC(),
 	}
+	attempt.LastSeenAt = attempt.StartedAt
 
 	// Здесь можно добавить логику для создания ответов для выбранных вопросов
 	for i, question := range selectedQuestions {
+		attempt.Questions[i] = question.ID
 		// Это можно заменить на логику создания ответа на вопрос
 		attempt.Answers[i] = &Answer{
 			ID:         question.ID,
@@ -232,83 +416,187 @@ func (s *Store) CreateAttempt(testID, userID uint64) (*Attempt, error) {
 		}
 	}
 
-	s.mu.Lock()
+	s.addAttempt(attempt)
+
+	return attempt
+}
+
+// addAttempt сохраняет попытку и добавляет ее в индексы, вызывается под блокировкой
+func (s *Store) addAttempt(attempt *Attempt) {
 	s.attempts[attempt.ID] = attempt
-	s.nextUserID++
-	s.mu.Unlock()
+	s.attemptsByUser[attempt.UserID] = append(s.attemptsByUser[attempt.UserID], attempt)
+	s.attemptsByTest[attempt.TestID] = append(s.attemptsByTest[attempt.TestID], attempt)
+}
+
+// CheckRetakeCooldown проверяет, что с последней попытки пользователя прошло достаточно времени
+func (s *Store) CheckRetakeCooldown(userID, testID uint64) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return ErrTestNotFound
+	}
+
+	return s.checkRetakeCooldown(userID, test)
+}
+
+// checkRetakeCooldown возвращает RetakeCooldownError, если пауза после последней попытки еще идет.
+// Вызывается под блокировкой
+func (s *Store) checkRetakeCooldown(userID uint64, test *Test) error {
+	if next := s.retakeAvailableAt(userID, test); time.Now().UTC().Before(next) {
+		return &RetakeCooldownError{NextAttemptAt: next}
+	}
 
-	return attempt, nil
+	return nil
 }
 
-// Функция для получения случайных вопросов
-func (s *Store) getRandomQuestions(allQuestions []*Question, numOfQuestions uint64) []*Question {
-	source := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(source)
+// retakeAvailableAt возвращает, когда пользователь сможет начать новую попытку теста по паузе
+// между попытками, или нулевое время без паузы и без попыток. Вызывается под блокировкой
+func (s *Store) retakeAvailableAt(userID uint64, test *Test) time.Time {
+	if test.RetakeCooldown == 0 {
+		return time.Time{}
+	}
 
-	r.Shuffle(len(allQuestions), func(i, j int) {
-		allQuestions[i], allQuestions[j] = allQuestions[j], allQuestions[i]
+	// Пауза отсчитывается от завершения последней попытки, а для незавершенной — от ее начала
+	var last time.Time
+	for _, attempt := range s.attemptsByUser[userID] {
+		if attempt.TestID != test.ID {
+			continue
+		}
+		at := attempt.StartedAt
+		if !attempt.FinishedAt.IsZero() {
+			at = attempt.FinishedAt
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+
+	if last.IsZero() {
+		return time.Time{}
+	}
+	return last.Add(test.RetakeCooldown)
+}
+
+// selectQuestions детерминированно выбирает numOfQuestions вопросов по сиду.
+// Исходный срез не изменяется, поэтому тест общий для всех попыток остается нетронутым
+func selectQuestions(allQuestions []*Question, numOfQuestions uint64, seed int64) []*Question {
+	r := rand.New(rand.NewSource(seed))
+
+	shuffled := make([]*Question, len(allQuestions))
+	copy(shuffled, allQuestions)
+	r.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
 	})
 
-	if numOfQuestions > uint64(len(allQuestions)) {
-		numOfQuestions = uint64(len(allQuestions))
+	if numOfQuestions > uint64(len(shuffled)) {
+		numOfQuestions = uint64(len(shuffled))
 	}
 
-	return allQuestions[:numOfQuestions]
+	return shuffled[:numOfQuestions]
 }
 
 func (s *Store) AuthenticateUser(email, password string) (*User, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	userID, ok := s.usersByEmail[email]
+	s.authMu.RLock()
+	user, ok := s.activeUser(s.usersByEmail[email])
+	var hash string
+	if ok {
+		hash = user.Password
+	}
+	s.authMu.RUnlock()
 	if !ok {
 		return nil, ErrInvalidEmailOrPassword
 	}
-	user := s.users[userID]
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	// bcrypt считает десятки миллисекунд, под блокировкой он задерживал бы создание сессий
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
 		return nil, ErrInvalidEmailOrPassword
 	}
 
 	return user, nil
 }
 
+// GetUserByID возвращает неудаленного пользователя по ID
+func (s *Store) GetUserByID(userID uint64) (*User, bool) {
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
+
+	return s.activeUser(userID)
+}
+
+// SetUserRole меняет роль пользователя
+func (s *Store) SetUserRole(userID uint64, role string) error {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	switch role {
+	case RoleStudent, RoleTeacher, RoleAdmin:
+	default:
+		return errors.New("unknown role")
+	}
+
+	user, ok := s.activeUser(userID)
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Role = role
+
+	return nil
+}
+
 func (s *Store) CreateSession(userID uint64) string {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
 
 	sessionID := uuid.NewString()
-	s.sessions[sessionID] = userID
+	s.sessions[sessionID] = &session{userID: userID, createdAt: time.Now().UTC()}
 
 	return sessionID
 }
 
 func (s *Store) DeleteSession(sessionID string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
 
 	delete(s.sessions, sessionID)
 }
 
+// DeleteExpiredSessions удаляет сессии старше ttl: их cookie браузер уже не присылает,
+// а без очистки они копились бы в памяти. Возвращает число удаленных сессий
+func (s *Store) DeleteExpiredSessions(ttl time.Duration) int {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	cutoff := time.Now().UTC().Add(-ttl)
+	deleted := 0
+	for sessionID, session := range s.sessions {
+		if session.createdAt.Before(cutoff) {
+			delete(s.sessions, sessionID)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
 func (s *Store) GetUserBySession(sessionID string) (*User, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
 
-	userID, ok := s.sessions[sessionID]
+	session, ok := s.sessions[sessionID]
 	if !ok {
 		log.Info().Str("session_id", sessionID).Msg("session not found")
 		return nil, false
 	}
-	user, ok := s.users[userID]
-
-	return user, ok
+	return s.activeUser(session.userID)
 }
 
 func (s *Store) TestById(testId uint64) (*Test, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	result, ok := s.tests[testId]
+	result, ok := s.activeTest(testId)
 	if !ok {
 		log.Info().Str("testId", fmt.Sprintf("%d", testId)).Msg("test not found")
 	}
@@ -316,6 +604,28 @@ func (s *Store) TestById(testId uint64) (*Test, bool) {
 	return result, ok
 }
 
+// ListTests возвращает копии неудаленных тестов без вопросов, по возрастанию ID
+func (s *Store) ListTests() []*Test {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Test, 0, len(s.tests))
+	for _, test := range s.tests {
+		if test.DeletedAt != nil {
+			continue
+		}
+		t := *test
+		t.Questions = nil
+		result = append(result, &t)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
 func (s *Store) GetAttemptQuestions(attemptId uint64) ([]*Question, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -325,14 +635,20 @@ func (s *Store) GetAttemptQuestions(attemptId uint64) ([]*Question, error) {
 		return nil, errors.New("attempt not found")
 	}
 
-	// Собираем вопросы из попытки
+	// Собираем вопросы из попытки в том порядке, в котором они были выбраны
 	var questions []*Question
-	for _, answer := range attempt.Answers {
+	for i, questionID := range attempt.Questions {
 		// Ищем вопрос по ID
-		question, ok := s.findQuestionByID(attempt.TestID, answer.QuestionID)
+		question, ok := s.findQuestionByID(attempt.TestID, questionID)
 		if !ok {
 			return nil, errors.New("question not found for answer")
 		}
+		// Перефразированный вопрос отдаем копией, исходный текст в тесте не меняется
+		if text := s.questionText(attempt, uint64(i+1), question); text != question.Text {
+			paraphrased := *question
+			paraphrased.Text = text
+			question = &paraphrased
+		}
 		questions = append(questions, question)
 	}
 
@@ -363,33 +679,52 @@ func (s *Store) CheckDeadline(attemptID uint64) error {
 		return errors.New("attempt not found")
 	}
 
+	_, err := s.checkDeadline(attempt)
+	return err
+}
+
+// checkDeadline проверяет дедлайн попытки, вызывается под блокировкой.
+// Возвращает true, если дедлайн прошел, но попытка еще в льготном периоде
+func (s *Store) checkDeadline(attempt *Attempt) (bool, error) {
 	test, ok := s.tests[attempt.TestID]
 	if !ok {
-		return errors.New("test not found")
+		return false, errors.New("test not found")
 	}
 
-	if test.TimeLimit > 0 {
-		deadline := attempt.StartedAt.Add(test.TimeLimit)
-		if time.Now().UTC().After(deadline) {
-			return errors.New("test attempt timeout")
-		}
+	if test.TimeLimit == 0 {
+		return false, nil
 	}
 
-	return nil
+	now := time.Now().UTC()
+	deadline := attemptDeadline(attempt, test, now)
+	if !now.After(deadline) {
+		return false, nil
+	}
+
+	if now.After(deadline.Add(test.GracePeriod)) {
+		return false, errors.New("test attempt timeout")
+	}
+
+	return true, nil
 }
 
-func (s *Store) CreateAnswer(attemptID uint64, questionPos uint64, text string) (*Answer, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// CreateAnswer сохраняет ответ пользователя. Запись под эксклюзивной блокировкой,
+// поэтому одновременные ответы участников команды применяются по очереди
+func (s *Store) CreateAnswer(attemptID, userID, questionPos uint64, text string) (*Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	err := s.CheckDeadline(attemptID)
-	if err != nil {
+	attempt, ok := s.attempts[attemptID]
+	if !ok || !s.canAccessAttempt(attempt, userID) {
+		return nil, errors.New("attempt not found")
+	}
+
+	if _, err := s.checkDeadline(attempt); err != nil {
 		return nil, err
 	}
 
-	attempt, ok := s.attempts[attemptID]
-	if !ok {
-		return nil, errors.New("attempt not found")
+	if attempt.Status != "started" {
+		return nil, errors.New("attempt closed")
 	}
 
 	test, ok := s.tests[attempt.TestID]
@@ -397,49 +732,141 @@ func (s *Store) CreateAnswer(attemptID uint64, questionPos uint64, text string)
 		return nil, errors.New("test not found")
 	}
 
-	if len(attempt.Answers) < int(questionPos-1) {
+	now := time.Now().UTC()
+	answer, err := s.applyAnswer(attempt, userID, questionPos, text, now, now)
+	if err != nil {
+		return nil, err
+	}
+	touchAttempt(attempt, test, now)
+
+	return answer, nil
+}
+
+// applyAnswer записывает и оценивает ответ на вопрос попытки, вызывается под блокировкой.
+// at - время ответа, receivedAt - когда он дошел до сервера; различаются для ответов, сохраненных офлайн
+func (s *Store) applyAnswer(attempt *Attempt, userID, questionPos uint64, text string, at, receivedAt time.Time) (*Answer, error) {
+	if questionPos == 0 || questionPos > uint64(len(attempt.Questions)) {
 		return nil, errors.New("question position out of range")
 	}
 
-	question := test.Questions[questionPos-1]
-	trueAnswer := question.TrueAnswer
+	// Проверяем по снимку вопросов попытки, а не по порядку вопросов в тесте
+	question, ok := s.findQuestionByID(attempt.TestID, attempt.Questions[questionPos-1])
+	if !ok {
+		return nil, errors.New("question not found")
+	}
+	answer := attempt.Answers[questionPos-1]
+
+	// Повторное сохранение того же текста не попадает в журнал
+	if answer.CreatedAt.IsZero() || answer.Text != text {
+		recordAction(attempt, &AttemptAction{
+			Type:             ActionAnswerSaved,
+			UserID:           userID,
+			QuestionPosition: questionPos,
+			Text:             text,
+			At:               at,
+			ReceivedAt:       receivedAt,
+		})
+	}
 
-	if text == trueAnswer {
-		attempt.Result += question.MaxScore
-		attempt.Answers[questionPos-1].RightOrNot = true
-	} else {
-		attempt.Answers[questionPos-1].RightOrNot = false
+	// При повторном ответе сначала убираем баллы за предыдущий
+	attempt.Result -= answer.Score
+	answer.Score = 0
+	answer.RightOrNot = false
+	answer.Similarity = nil
+
+	switch {
+	case question.GradingMode == GradingManual:
+		// Ответ проверит преподаватель после сдачи попытки
+		answer.Status = "pending_review"
+	case question.GradingMode == GradingSemantic && text != question.TrueAnswer:
+		// Ответ ждет оценки близости (ScoreSemanticAnswer), без нее его проверит преподаватель
+		answer.Status = "pending_review"
+	default:
+		answer.Status = "graded"
+		if text == question.TrueAnswer {
+			answer.Score = question.MaxScore
+			answer.RightOrNot = true
+		}
 	}
 
-	attempt.Answers[questionPos-1].Text = text
-	attempt.Answers[questionPos-1].CreatedAt = time.Now().UTC()
+	attempt.Result += answer.Score
+	answer.Text = text
+	answer.CreatedAt = at
+
+	if attempt.GroupID != 0 {
+		attempt.Participants = append(attempt.Participants, &ParticipantEntry{
+			UserID:           userID,
+			QuestionPosition: questionPos,
+			Text:             text,
+			CreatedAt:        at,
+		})
+	}
 
-	return attempt.Answers[questionPos-1], nil
+	return answer, nil
 }
 
 func (s *Store) SubmitAttempt(attemptID uint64) (*Attempt, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	err := s.CheckDeadline(attemptID)
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	late, err := s.checkDeadline(attempt)
 	if err != nil {
 		return nil, err
 	}
 
-	if s.attempts[attemptID].Status != "started" {
+	if attempt.Status != "started" {
 		return nil, errors.New("attempt closed")
 	}
 
-	s.attempts[attemptID].Status = "submitted"
-	s.attempts[attemptID].FinishedAt = time.Now().UTC()
-	attempt, ok := s.attempts[attemptID]
-	if !ok {
-		return nil, errors.New("attempt not found")
+	attempt.Late = late
+	attempt.FinishedAt = time.Now().UTC()
+
+	// Ответы, требующие ручной проверки, уходят в очередь, попытка ждет оценки
+	if s.enqueueManualReviews(attempt) > 0 {
+		attempt.Status = "grading"
+		return copyAttempt(attempt), nil
 	}
-	return attempt, nil
+
+	s.finalizeAttempt(attempt)
+
+	return copyAttempt(attempt), nil
+}
+
+// finalizeAttempt применяет штраф за опоздание и закрывает попытку, вызывается под блокировкой
+func (s *Store) finalizeAttempt(attempt *Attempt) {
+	attempt.Status = "submitted"
+	s.recalculateResult(attempt)
+}
+
+// recalculateResult пересчитывает результат по баллам ответов, вызывается под блокировкой
+func (s *Store) recalculateResult(attempt *Attempt) {
+	var total uint64
+	for _, answer := range attempt.Answers {
+		total += answer.Score
+	}
+
+	// В льготный период принимаем попытку, но снимаем процент от результата
+	attempt.Penalty = 0
+	if attempt.Late && attempt.Status == "submitted" {
+		test := s.tests[attempt.TestID]
+		attempt.Penalty = total * min(test.LatePenalty, 100) / 100
+	}
+
+	attempt.Result = total - attempt.Penalty
+
+	// Подсказки ассистента не уводят результат ниже нуля
+	attempt.Result -= min(attempt.AICost, attempt.Result)
 }
 
 func (s *Store) GetAttemptByID(attemptID uint64) (*Attempt, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	attempt, ok := s.attempts[attemptID]
 	if !ok {
 		return nil, false
@@ -448,6 +875,19 @@ func (s *Store) GetAttemptByID(attemptID uint64) (*Attempt, bool) {
 	return attempt, true
 }
 
+// GetUserAttempt возвращает попытку, если пользователь - ее владелец или участник команды
+func (s *Store) GetUserAttempt(attemptID, userID uint64) (*Attempt, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || !s.canAccessAttempt(attempt, userID) {
+		return nil, false
+	}
+
+	return attempt, true
+}
+
 func (s *Store) CreateAIThread(attemptID, questionPosition uint64, threadID string) (*AIThread, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -460,6 +900,9 @@ func (s *Store) CreateAIThread(attemptID, questionPosition uint64, threadID stri
 
 	// Проверяем, что question position валидна
 	attempt := s.attempts[attemptID]
+	if attempt.Status != "started" {
+		return nil, errors.New("attempt closed")
+	}
 	if questionPosition > uint64(len(attempt.Answers)) || questionPosition == 0 {
 		return nil, errors.New("invalid question position")
 	}
@@ -475,49 +918,98 @@ func (s *Store) CreateAIThread(attemptID, questionPosition uint64, threadID stri
 		}
 	}
 
+	context, prompt := s.aiThreadContext(attempt, questionPosition)
 	thread := &AIThread{
-		AttemptID: attemptID,
-		ThreadID:  threadID,
-		Status:    "active",
-		CreatedAt: time.Now().UTC(),
+		AttemptID:        attemptID,
+		QuestionPosition: questionPosition,
+		ThreadID:         threadID,
+		Status:           AIThreadActive,
+		Context:          context,
+		Prompt:           prompt,
+		Messages:         make([]*AIMessage, 0),
+		CreatedAt:        time.Now().UTC(),
 	}
+	thread.Messages = append(thread.Messages, &AIMessage{
+		Role:      AIRoleSystem,
+		Text:      thread.Context,
+		CreatedAt: thread.CreatedAt,
+	})
 
 	s.aiThreads[key] = thread
+	s.aiThreadsByID[threadID] = thread
 
 	return thread, nil
 }
 
 // CreateAccessCode создает новый код доступа для теста
-func (s *Store) CreateAccessCode(code string, testID uint64, maxUses *uint64, expiresAt *time.Time) (*AccessCode, error) {
+func (s *Store) CreateAccessCode(code string, testID uint64, opts AccessCodeOptions) (*AccessCode, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Проверяем, что тест существует
-	if _, ok := s.tests[testID]; !ok {
-		return nil, errors.New("test not found")
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
 	}
 
-	// Проверяем, что код не существует
-	if _, ok := s.accessCodes[code]; ok {
-		return nil, errors.New("access code already exists")
+	accessCode := newAccessCode(code, opts)
+	accessCode.TestID = testID
+
+	if err := s.addAccessCode(accessCode, opts.Checksum); err != nil {
+		return nil, err
 	}
 
-	accessCode := &AccessCode{
-		Code:      code,
-		TestID:    testID,
-		MaxUses:   maxUses,
-		UsedCount: 0,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now().UTC(),
+	return accessCode, nil
+}
+
+// addAccessCode сохраняет код доступа после проверок, вызывается под блокировкой
+func (s *Store) addAccessCode(accessCode *AccessCode, withChecksum bool) error {
+	// Проверяем, что тест (или класс для кода класса) существует и определяем организацию
+	var orgID uint64
+	if accessCode.GroupID != 0 {
+		group, ok := s.groups[accessCode.GroupID]
+		if !ok {
+			return errors.New("group not found")
+		}
+		orgID = group.OrgID
+	} else {
+		test, ok := s.tests[accessCode.TestID]
+		if !ok {
+			return errors.New("test not found")
+		}
+		orgID = test.OrgID
 	}
 
-	s.accessCodes[code] = accessCode
+	// Коды организации всегда начинаются с ее префикса, пустой код генерируется
+	prefix := s.codePrefix(orgID)
+	if accessCode.Code == "" {
+		code, err := s.generateUniqueCode(prefix, withChecksum)
+		if err != nil {
+			return err
+		}
+		accessCode.Code = code
+	} else {
+		accessCode.Code = namespacedCode(prefix, accessCode.Code)
+		if withChecksum {
+			accessCode.Code = WithChecksum(accessCode.Code)
+		}
+	}
+	// Код такого вида с неверным контрольным символом отклоняется при погашении до обращения к хранилищу
+	if !checksumConsistent(accessCode.Code) {
+		return errAccessCodeChecksum
+	}
+	accessCode.OrgID = orgID
 
-	return accessCode, nil
+	// Проверяем, что код не существует
+	if _, ok := s.accessCodes[accessCode.Code]; ok {
+		return errAccessCodeExists
+	}
+
+	s.accessCodes[accessCode.Code] = accessCode
+
+	return nil
 }
 
-// ValidateAccessCode проверяет код доступа и увеличивает счетчик использования
-func (s *Store) ValidateAccessCode(code string, testID uint64) error {
+// ValidateAccessCode проверяет код доступа для пользователя и увеличивает счетчик использования
+func (s *Store) ValidateAccessCode(code string, testID, userID uint64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -526,23 +1018,75 @@ func (s *Store) ValidateAccessCode(code string, testID uint64) error {
 		return errors.New("invalid access code")
 	}
 
-	// Проверяем, что код для нужного теста
-	if accessCode.TestID != testID {
+	// Проверяем, что код для нужного теста. Код класса подходит для всех тестов, назначенных классу
+	if accessCode.GroupID != 0 {
+		group, ok := s.groups[accessCode.GroupID]
+		if !ok || !group.hasTest(testID) {
+			return errors.New("access code is not valid for this test")
+		}
+	} else if accessCode.TestID != testID {
 		return errors.New("access code is not valid for this test")
 	}
 
+	return s.redeemAccessCode(accessCode, userID)
+}
+
+// redeemAccessCode проверяет ограничения кода и списывает использование, вызывается под блокировкой
+func (s *Store) redeemAccessCode(accessCode *AccessCode, userID uint64) error {
+	if accessCode.Suspended {
+		return errors.New("access code is suspended")
+	}
+
 	// Проверяем срок действия
-	if accessCode.ExpiresAt != nil && time.Now().UTC().After(*accessCode.ExpiresAt) {
+	now := time.Now().UTC()
+	if accessCode.ExpiresAt != nil && now.After(*accessCode.ExpiresAt) {
+		return errors.New("access code has expired")
+	}
+
+	// Код с окном активации действует ограниченное время после первого использования
+	if accessCode.ActivationWindow > 0 && accessCode.ActivatedAt != nil &&
+		now.After(accessCode.ActivatedAt.Add(accessCode.ActivationWindow)) {
 		return errors.New("access code has expired")
 	}
 
+	// Персональный код может использовать только владелец email и только один раз
+	if accessCode.BoundEmail != "" {
+		user, ok := s.lookupUser(userID)
+		if !ok || !strings.EqualFold(user.Email, accessCode.BoundEmail) {
+			return errors.New("access code is bound to another user")
+		}
+		if accessCode.ConsumedAt != nil {
+			return errors.New("access code already used")
+		}
+	}
+
 	// Проверяем лимит использований
 	if accessCode.MaxUses != nil && accessCode.UsedCount >= *accessCode.MaxUses {
 		return errors.New("access code usage limit reached")
 	}
 
-	// Увеличиваем счетчик использования
+	// Увеличиваем счетчик использования и запоминаем, кто использовал код
 	accessCode.UsedCount++
+	if accessCode.ActivationWindow > 0 && accessCode.ActivatedAt == nil {
+		accessCode.ActivatedAt = &now
+	}
+	usage := &CodeUsage{
+		UserID:     userID,
+		RedeemedAt: now,
+	}
+	if user, ok := s.lookupUser(userID); ok {
+		usage.Email = user.Email
+	}
+	if accessCode.BoundEmail != "" {
+		accessCode.ConsumedAt = &now
+	}
+
+	// Код класса зачисляет пользователя в класс
+	if group, ok := s.groups[accessCode.GroupID]; ok && !group.hasMember(userID) {
+		group.MemberIDs = append(group.MemberIDs, userID)
+		usage.enrolled = true
+	}
+	s.codeUsages[accessCode.Code] = append(s.codeUsages[accessCode.Code], usage)
 
 	return nil
 }
@@ -553,27 +1097,26 @@ func (s *Store) GetUserAttemptHistory(userID, testID uint64) ([]*Attempt, error)
 	defer s.mu.RUnlock()
 
 	// Проверяем, что тест существует
-	if _, ok := s.tests[testID]; !ok {
-		return nil, errors.New("test not found")
+	if _, ok := s.activeTest(testID); !ok {
+		return nil, ErrTestNotFound
 	}
 
 	var history []*Attempt
 
-	// Проходим по всем попыткам и фильтруем по userID, testID и статусу
-	for _, attempt := range s.attempts {
-		if attempt.UserID == userID && attempt.TestID == testID && attempt.Status == "submitted" {
+	// Попытки теста фильтруем по userID (с учетом командных попыток) и статусу
+	for _, attempt := range s.attemptsByTest[testID] {
+		if attempt.Status == "submitted" && s.canAccessAttempt(attempt, userID) {
 			history = append(history, attempt)
 		}
 	}
 
-	// Сортируем от новых к старым (по времени завершения)
-	for i := 0; i < len(history); i++ {
-		for j := i + 1; j < len(history); j++ {
-			if history[i].FinishedAt.Before(history[j].FinishedAt) {
-				history[i], history[j] = history[j], history[i]
-			}
+	// Сортируем от новых к старым (по времени завершения), при равном времени новее попытка с большим ID
+	sort.Slice(history, func(i, j int) bool {
+		if !history[i].FinishedAt.Equal(history[j].FinishedAt) {
+			return history[i].FinishedAt.After(history[j].FinishedAt)
 		}
-	}
+		return history[i].ID > history[j].ID
+	})
 
 	return history, nil
 }
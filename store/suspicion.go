@@ -0,0 +1,484 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Уровни риска попытки по сумме баллов признаков
+const (
+	SuspicionLow    = "low"
+	SuspicionMedium = "medium" // от SuspicionMediumScore
+	SuspicionHigh   = "high"   // от SuspicionHighScore
+
+	SuspicionMediumScore = 30
+	SuspicionHighScore   = 60
+	MaxSuspicionScore    = 100
+)
+
+// Признаки риска
+const (
+	SuspicionBlockedAI     = "blocked_ai_messages"   // сообщения ассистенту заблокированы модерацией или отказом
+	SuspicionAIAnswer      = "answer_from_assistant" // ответ повторяет ответы ассистента
+	SuspicionHeavyAI       = "heavy_ai_use"          // сообщений ассистенту намного больше, чем обычно в тесте
+	SuspicionFastAnswers   = "fast_answers"          // ответ появился быстрее, чем его можно набрать
+	SuspicionShortDuration = "short_duration"        // попытка намного быстрее обычной при результате не ниже медианы
+	SuspicionSimilar       = "similar_answers"       // ответы похожи на ответы других студентов, см. CheckAnswerSimilarity
+)
+
+// Пороги и веса признаков
+const (
+	suspicionBlockedPoints   = 10 // за каждое заблокированное сообщение
+	suspicionBlockedMax      = 30
+	suspicionAIAnswerPoints  = 20 // за каждый вопрос
+	suspicionAIAnswerMax     = 40
+	suspicionAIAnswerShare   = 0.6 // доля шинглов ответа, найденных в ответах ассистента по этому вопросу
+	suspicionHeavyAIPoints   = 10
+	suspicionHeavyAIMessages = 10 // меньше сообщений - не признак, даже если в тесте ассистентом почти не пользуются
+	suspicionHeavyAIRatio    = 3  // во сколько раз больше медианы теста
+	suspicionFastPoints      = 10 // за каждый вопрос
+	suspicionFastMax         = 30
+	suspicionFastMinChars    = 20 // короткие ответы набираются за секунды, их скорость ничего не значит
+	suspicionTypingSpeed     = 8  // символов в секунду - быстрее набрать ответ вручную вряд ли получится
+	suspicionShortPoints     = 20
+	suspicionShortRatio      = 0.25 // доля медианного времени теста
+	suspicionSimilarPoints   = 15   // за каждый вопрос
+	suspicionSimilarMax      = 30
+	// Меньше сданных попыток - медианы теста ненадежны, признаки heavy_ai_use и short_duration не считаются
+	suspicionMinBaseline = 5
+)
+
+// SuspicionFactor - признак, повлиявший на оценку риска, с объяснением для преподавателя
+type SuspicionFactor struct {
+	Code              string   `json:"code"`
+	Points            int      `json:"points"`
+	Explanation       string   `json:"explanation"`
+	QuestionPositions []uint64 `json:"question_positions,omitempty"` // вопросы, к которым относится признак
+	RelatedAttempts   []uint64 `json:"related_attempts,omitempty"`   // попытки с похожими ответами
+}
+
+// AttemptSuspicion - оценка риска списывания в попытке
+type AttemptSuspicion struct {
+	AttemptID uint64             `json:"attempt_id"`
+	UserID    uint64             `json:"user_id"`
+	TestID    uint64             `json:"test_id"`
+	Status    string             `json:"status"`
+	Result    uint64             `json:"result"`
+	Score     int                `json:"score"` // от 0 до 100, сумма баллов признаков
+	Level     string             `json:"level"` // low, medium или high
+	Factors   []*SuspicionFactor `json:"factors"`
+}
+
+// TestSuspicion - оценки риска попыток теста
+type TestSuspicion struct {
+	TestID              uint64              `json:"test_id"`
+	AttemptsChecked     int                 `json:"attempts_checked"`
+	SimilarityCheckedAt *time.Time          `json:"similarity_checked_at,omitempty"` // когда искали похожие ответы, пусто = еще не искали
+	Attempts            []*AttemptSuspicion `json:"attempts"`                        // самые подозрительные первыми
+}
+
+// suspicionBaseline - общие для теста данные для оценки попыток
+type suspicionBaseline struct {
+	finished       int      // сданные попытки
+	medianDuration *float64 // секунды работы без автопаузы
+	medianResult   *float64
+	medianMessages *float64                  // сообщения ассистенту в сданной попытке
+	threads        map[uint64][]*AIThread    // key = ID попытки
+	similar        map[uint64][]*SimilarPair // key = ID попытки
+	similarityAt   *time.Time
+}
+
+// AttemptSuspicion оценивает риск списывания в попытке по журналу прокторинга, работе с ассистентом,
+// времени ответов и похожим ответам других студентов из последней проверки теста
+func (s *Store) AttemptSuspicion(attemptID uint64) (*AttemptSuspicion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+	test, ok := s.tests[attempt.TestID]
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	return s.attemptSuspicion(attempt, test, s.suspicionBaseline(test)), nil
+}
+
+// TestSuspicion оценивает риск во всех попытках теста и возвращает попытки с оценкой не ниже minScore
+func (s *Store) TestSuspicion(testID uint64, minScore int) (*TestSuspicion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	baseline := s.suspicionBaseline(test)
+	result := &TestSuspicion{
+		TestID:              testID,
+		AttemptsChecked:     len(s.attemptsByTest[testID]),
+		SimilarityCheckedAt: baseline.similarityAt,
+		Attempts:            make([]*AttemptSuspicion, 0),
+	}
+	for _, attempt := range s.attemptsByTest[testID] {
+		if entry := s.attemptSuspicion(attempt, test, baseline); entry.Score >= minScore {
+			result.Attempts = append(result.Attempts, entry)
+		}
+	}
+	sort.Slice(result.Attempts, func(i, j int) bool {
+		if result.Attempts[i].Score != result.Attempts[j].Score {
+			return result.Attempts[i].Score > result.Attempts[j].Score
+		}
+		return result.Attempts[i].AttemptID < result.Attempts[j].AttemptID
+	})
+
+	return result, nil
+}
+
+// suspicionBaseline собирает медианы теста, диалоги с ассистентом и похожие ответы, вызывается под блокировкой
+func (s *Store) suspicionBaseline(test *Test) *suspicionBaseline {
+	baseline := &suspicionBaseline{
+		threads: make(map[uint64][]*AIThread),
+		similar: make(map[uint64][]*SimilarPair),
+	}
+
+	attempts := make(map[uint64]bool)
+	for _, attempt := range s.attemptsByTest[test.ID] {
+		attempts[attempt.ID] = true
+	}
+	for _, thread := range s.aiThreadsByID {
+		if attempts[thread.AttemptID] {
+			baseline.threads[thread.AttemptID] = append(baseline.threads[thread.AttemptID], thread)
+		}
+	}
+
+	var durations, results, messages []float64
+	for _, attempt := range s.attemptsByTest[test.ID] {
+		if attempt.Status == "started" {
+			continue
+		}
+		baseline.finished++
+		durations = append(durations, attemptDuration(attempt).Seconds())
+		results = append(results, float64(attempt.Result))
+		messages = append(messages, float64(countAIMessages(baseline.threads[attempt.ID])))
+	}
+	if baseline.finished >= suspicionMinBaseline {
+		baseline.medianDuration = optionalMedian(durations)
+		baseline.medianResult = optionalMedian(results)
+		baseline.medianMessages = optionalMedian(messages)
+	}
+
+	if report, ok := s.similarityReports[test.ID]; ok {
+		checkedAt := report.CheckedAt
+		baseline.similarityAt = &checkedAt
+		for _, pair := range report.Pairs {
+			baseline.similar[pair.AttemptA] = append(baseline.similar[pair.AttemptA], pair)
+			baseline.similar[pair.AttemptB] = append(baseline.similar[pair.AttemptB], pair)
+		}
+	}
+
+	return baseline
+}
+
+// attemptSuspicion считает признаки риска попытки, вызывается под блокировкой
+func (s *Store) attemptSuspicion(attempt *Attempt, test *Test, baseline *suspicionBaseline) *AttemptSuspicion {
+	threads := baseline.threads[attempt.ID]
+	factors := make([]*SuspicionFactor, 0)
+	for _, factor := range []*SuspicionFactor{
+		blockedAIFactor(attempt, threads),
+		s.aiAnswerFactor(attempt, threads),
+		heavyAIFactor(threads, baseline),
+		fastAnswersFactor(attempt),
+		shortDurationFactor(attempt, baseline),
+		similarAnswersFactor(attempt, baseline.similar[attempt.ID]),
+	} {
+		if factor != nil {
+			factors = append(factors, factor)
+		}
+	}
+	sort.SliceStable(factors, func(i, j int) bool {
+		return factors[i].Points > factors[j].Points
+	})
+
+	score := 0
+	for _, factor := range factors {
+		score += factor.Points
+	}
+	score = min(score, MaxSuspicionScore)
+
+	level := SuspicionLow
+	switch {
+	case score >= SuspicionHighScore:
+		level = SuspicionHigh
+	case score >= SuspicionMediumScore:
+		level = SuspicionMedium
+	}
+
+	return &AttemptSuspicion{
+		AttemptID: attempt.ID,
+		UserID:    attempt.UserID,
+		TestID:    test.ID,
+		Status:    attempt.Status,
+		Result:    attempt.Result,
+		Score:     score,
+		Level:     level,
+		Factors:   factors,
+	}
+}
+
+// blockedAIFactor - сообщения ассистенту, заблокированные модерацией или отказом решать вопрос
+func blockedAIFactor(attempt *Attempt, threads []*AIThread) *SuspicionFactor {
+	positions := make(map[string]uint64)
+	for _, thread := range threads {
+		positions[thread.ThreadID] = thread.QuestionPosition
+	}
+
+	moderated, refused := 0, 0
+	var questions []uint64
+	for _, event := range attempt.ProctoringEvents {
+		switch event.Type {
+		case ProctoringAIModeration:
+			moderated++
+		case ProctoringAIRefusal:
+			refused++
+		default:
+			continue
+		}
+		if position, ok := positions[event.ThreadID]; ok {
+			questions = append(questions, position)
+		}
+	}
+	if moderated+refused == 0 {
+		return nil
+	}
+
+	return &SuspicionFactor{
+		Code:   SuspicionBlockedAI,
+		Points: min((moderated+refused)*suspicionBlockedPoints, suspicionBlockedMax),
+		Explanation: fmt.Sprintf("%s to the assistant blocked: %d by moderation, %d refused as requests to solve the question",
+			countNoun(moderated+refused, "message"), moderated, refused),
+		QuestionPositions: sortedPositions(questions),
+	}
+}
+
+// aiAnswerFactor - развернутые ответы, большая часть которых взята из ответов ассистента по тому же вопросу
+func (s *Store) aiAnswerFactor(attempt *Attempt, threads []*AIThread) *SuspicionFactor {
+	var questions []uint64
+	maxShare := 0.0
+	for _, thread := range threads {
+		position := thread.QuestionPosition
+		if position == 0 || position > uint64(len(attempt.Answers)) {
+			continue
+		}
+		answerShingles := shingles(attempt.Answers[position-1].Text)
+		// Формулировки из вопроса ассистент повторяет законно
+		if question, ok := s.findQuestionByID(attempt.TestID, attempt.Answers[position-1].QuestionID); ok {
+			for shingle := range shingles(question.Text) {
+				delete(answerShingles, shingle)
+			}
+		}
+		if len(answerShingles) < MinSimilarityShingles {
+			continue
+		}
+
+		replies := make(map[string]bool)
+		for _, message := range thread.Messages {
+			if message.Role == AIRoleAssistant {
+				for shingle := range shingles(message.Text) {
+					replies[shingle] = true
+				}
+			}
+		}
+		found := 0
+		for shingle := range answerShingles {
+			if replies[shingle] {
+				found++
+			}
+		}
+		share := float64(found) / float64(len(answerShingles))
+		if share >= suspicionAIAnswerShare {
+			questions = append(questions, position)
+			maxShare = max(maxShare, share)
+		}
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	questions = sortedPositions(questions)
+	return &SuspicionFactor{
+		Code:   SuspicionAIAnswer,
+		Points: min(len(questions)*suspicionAIAnswerPoints, suspicionAIAnswerMax),
+		Explanation: fmt.Sprintf("answers to %s repeat the assistant's replies: up to %.0f%% of the answer text",
+			listNoun("question", questions), maxShare*100),
+		QuestionPositions: questions,
+	}
+}
+
+// heavyAIFactor - сообщений ассистенту намного больше, чем в обычной сданной попытке теста
+func heavyAIFactor(threads []*AIThread, baseline *suspicionBaseline) *SuspicionFactor {
+	messages := countAIMessages(threads)
+	if messages < suspicionHeavyAIMessages || baseline.medianMessages == nil {
+		return nil
+	}
+	median := *baseline.medianMessages
+	if float64(messages) < median*suspicionHeavyAIRatio {
+		return nil
+	}
+
+	explanation := fmt.Sprintf("%s to the assistant, while most attempts of the test did not use it", countNoun(messages, "message"))
+	if median > 0 {
+		explanation = fmt.Sprintf("%s to the assistant, %.1f times the median of the test (%g)", countNoun(messages, "message"), float64(messages)/median, median)
+	}
+	return &SuspicionFactor{Code: SuspicionHeavyAI, Points: suspicionHeavyAIPoints, Explanation: explanation}
+}
+
+// fastAnswersFactor - ответы, которые появились после открытия вопроса быстрее, чем их можно набрать.
+// Считается по журналу попытки; ответы, сохраненные офлайн, пропускаются: время клиента не сравнить со временем сервера
+func fastAnswersFactor(attempt *Attempt) *SuspicionFactor {
+	var questions []uint64
+	fastest := struct {
+		chars   int
+		elapsed time.Duration
+	}{}
+	previous := make(map[uint64]string)
+	opened := make(map[[2]uint64]time.Time) // key = участник и позиция вопроса
+	for _, action := range attempt.Journal {
+		key := [2]uint64{action.UserID, action.QuestionPosition}
+		if action.Type == ActionQuestionOpened {
+			opened[key] = action.At
+			continue
+		}
+
+		typed := utf8.RuneCountInString(action.Text) - utf8.RuneCountInString(previous[action.QuestionPosition])
+		previous[action.QuestionPosition] = action.Text
+		openedAt, ok := opened[key]
+		if !ok || !action.ReceivedAt.Equal(action.At) || typed < suspicionFastMinChars {
+			continue
+		}
+		elapsed := action.At.Sub(openedAt)
+		if elapsed.Seconds()*suspicionTypingSpeed >= float64(typed) {
+			continue
+		}
+		if !slices.Contains(questions, action.QuestionPosition) {
+			questions = append(questions, action.QuestionPosition)
+		}
+		if typed > fastest.chars {
+			fastest.chars, fastest.elapsed = typed, elapsed
+		}
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	questions = sortedPositions(questions)
+	return &SuspicionFactor{
+		Code:   SuspicionFastAnswers,
+		Points: min(len(questions)*suspicionFastPoints, suspicionFastMax),
+		Explanation: fmt.Sprintf("answers to %s appeared faster than they could be typed: up to %d characters %s after opening the question",
+			listNoun("question", questions), fastest.chars, fastest.elapsed.Round(time.Second)),
+		QuestionPositions: questions,
+	}
+}
+
+// shortDurationFactor - попытка сдана намного быстрее обычного с результатом не ниже медианы теста
+func shortDurationFactor(attempt *Attempt, baseline *suspicionBaseline) *SuspicionFactor {
+	if attempt.Status == "started" || baseline.medianDuration == nil || baseline.medianResult == nil {
+		return nil
+	}
+	duration := attemptDuration(attempt)
+	median := time.Duration(*baseline.medianDuration * float64(time.Second))
+	if float64(duration) >= float64(median)*suspicionShortRatio || float64(attempt.Result) < *baseline.medianResult {
+		return nil
+	}
+
+	return &SuspicionFactor{
+		Code:   SuspicionShortDuration,
+		Points: suspicionShortPoints,
+		Explanation: fmt.Sprintf("finished in %s while the median of the test is %s, with a result of %d not below the median",
+			duration.Round(time.Second), median.Round(time.Second), attempt.Result),
+	}
+}
+
+// similarAnswersFactor - ответы, похожие на ответы других студентов по последней проверке теста
+func similarAnswersFactor(attempt *Attempt, pairs []*SimilarPair) *SuspicionFactor {
+	var questions, related []uint64
+	maxSimilarity := 0.0
+	for _, pair := range pairs {
+		other := pair.AttemptB
+		if pair.AttemptB == attempt.ID {
+			other = pair.AttemptA
+		}
+		related = append(related, other)
+		maxSimilarity = max(maxSimilarity, pair.MaxSimilarity)
+		for _, answers := range pair.Answers {
+			position := answers.PositionA
+			if pair.AttemptB == attempt.ID {
+				position = answers.PositionB
+			}
+			if !slices.Contains(questions, position) {
+				questions = append(questions, position)
+			}
+		}
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	questions = sortedPositions(questions)
+	related = sortedPositions(related)
+	return &SuspicionFactor{
+		Code:   SuspicionSimilar,
+		Points: min(len(questions)*suspicionSimilarPoints, suspicionSimilarMax),
+		Explanation: fmt.Sprintf("answers to %s are similar to answers in %s by other students: up to %.0f%% shared wording",
+			listNoun("question", questions), listNoun("attempt", related), maxSimilarity*100),
+		QuestionPositions: questions,
+		RelatedAttempts:   related,
+	}
+}
+
+// countAIMessages возвращает число сообщений студента ассистенту
+func countAIMessages(threads []*AIThread) int {
+	count := 0
+	for _, thread := range threads {
+		for _, message := range thread.Messages {
+			if message.Role == AIRoleUser {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// sortedPositions сортирует номера и убирает повторы
+func sortedPositions(values []uint64) []uint64 {
+	return slices.Compact(slices.Sorted(slices.Values(values)))
+}
+
+// countNoun возвращает число с существительным в нужном числе: "1 message", "3 messages"
+func countNoun(count int, noun string) string {
+	if count != 1 {
+		noun += "s"
+	}
+	return fmt.Sprintf("%d %s", count, noun)
+}
+
+// listNoun перечисляет номера через запятую после существительного: "question 2", "questions 1, 3"
+func listNoun(noun string, values []uint64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	if len(values) != 1 {
+		noun += "s"
+	}
+	return noun + " " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SyntheticOptions - сколько данных создать для проверки под нагрузкой
+type SyntheticOptions struct {
+	Users           int    `json:"users" validate:"min=1,max=100000"`          // студенты
+	AttemptsPerUser int    `json:"attempts_per_user" validate:"min=0,max=100"` // в среднем, у каждого свое число попыток
+	Days            int    `json:"days" validate:"omitempty,min=1,max=365"`    // за сколько последних дней распределить попытки, по умолчанию 30
+	Password        string `json:"password"`                                   // пароль всех созданных пользователей, по умолчанию test
+	Seed            int64  `json:"seed"`                                       // 0 = случайный
+	EmailDomain     string `json:"email_domain" validate:"omitempty,fqdn"`     // по умолчанию load.test
+}
+
+// SyntheticResult - что создал генератор
+type SyntheticResult struct {
+	Users       int    `json:"users"`
+	Attempts    int    `json:"attempts"`
+	Answers     int    `json:"answers"`
+	FirstUserID uint64 `json:"first_user_id"`
+	LastUserID  uint64 `json:"last_user_id"`
+	Seed        int64  `json:"seed"`
+}
+
+// GenerateSynthetic создает студентов с попытками и ответами по существующим тестам, чтобы проверять
+// хранилище под объемом, близким к настоящему. Распределения правдоподобные, а не равномерные:
+// уровень студента нормальный, число попыток пуассоновское, популярность тестов по Ципфу,
+// попытки чаще днем, длительность логнормальная. С тем же Seed на том же хранилище данные повторяются.
+// Блокировка берется отдельно на каждого пользователя, так что работающий сервер не замирает
+func (s *Store) GenerateSynthetic(opts SyntheticOptions) (*SyntheticResult, error) {
+	if opts.Users <= 0 {
+		return nil, errors.New("users must be positive")
+	}
+	if opts.Days <= 0 {
+		opts.Days = 30
+	}
+	if opts.Password == "" {
+		opts.Password = "test"
+	}
+	if opts.EmailDomain == "" {
+		opts.EmailDomain = "load.test"
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+
+	s.mu.RLock()
+	tests := make([]*Test, 0, len(s.tests))
+	for _, test := range s.tests {
+		if test.DeletedAt == nil && len(activeQuestions(test.Questions)) > 0 {
+			tests = append(tests, test)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].ID < tests[j].ID
+	})
+	if len(tests) == 0 {
+		return nil, errors.New("no tests with questions to generate attempts for")
+	}
+
+	// Один хеш на всех: bcrypt с рабочей стоимостью на тысячи пользователей занял бы минуты
+	hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.MinCost)
+	if err != nil {
+		return nil, fmt.Errorf("cannot hash password: %w", err)
+	}
+
+	// Сложность вопроса - уровень студента, который отвечает на него верно в половине случаев
+	difficulty := make(map[uint64]float64)
+	for _, test := range tests {
+		for _, question := range test.Questions {
+			difficulty[question.ID] = 0.3 + 0.5*r.Float64()
+		}
+	}
+
+	popularity := rand.NewZipf(r, 1.3, 1, uint64(len(tests)-1))
+	now := time.Now().UTC()
+	result := &SyntheticResult{Seed: opts.Seed}
+
+	for n := 0; result.Users < opts.Users; n++ {
+		user, err := s.addUser(fmt.Sprintf("student%d@%s", n+1, opts.EmailDomain), string(hash), RoleStudent)
+		if errors.Is(err, ErrUserExists) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if result.FirstUserID == 0 {
+			result.FirstUserID = user.ID
+		}
+		result.LastUserID = user.ID
+		result.Users++
+
+		ability := clamp(0.65+0.15*r.NormFloat64(), 0.05, 0.98)
+		count := poisson(r, float64(opts.AttemptsPerUser))
+
+		s.mu.Lock()
+		for i := 0; i < count; i++ {
+			test := tests[popularity.Uint64()]
+			attempt := s.syntheticAttempt(r, test, user.ID, ability, difficulty, now, opts.Days)
+			result.Attempts++
+			result.Answers += len(attempt.Answers)
+		}
+		s.mu.Unlock()
+	}
+
+	return result, nil
+}
+
+// syntheticAttempt создает попытку студента уровня ability, вызывается под блокировкой
+func (s *Store) syntheticAttempt(r *rand.Rand, test *Test, userID uint64, ability float64,
+	difficulty map[uint64]float64, now time.Time, days int) *Attempt {
+	seed := r.Int63()
+	questions := selectQuestions(activeQuestions(test.Questions), test.NumOfQuestions, seed)
+
+	// Часть попыток еще идет: начаты только что и без части ответов
+	inProgress := r.Float64() < 0.05
+	var startedAt time.Time
+	if inProgress {
+		startedAt = now.Add(-time.Duration(r.Int63n(int64(10 * time.Minute))))
+	} else {
+		day := now.AddDate(0, 0, -r.Intn(days)).Truncate(24 * time.Hour)
+		hour := clamp(15+3.5*r.NormFloat64(), 0, 23.99)
+		startedAt = day.Add(time.Duration(hour * float64(time.Hour)))
+		if startedAt.After(now) {
+			startedAt = startedAt.AddDate(0, 0, -1)
+		}
+	}
+
+	// Медиана длительности - половина лимита времени, без лимита - 20 минут
+	median := 20 * time.Minute
+	if test.TimeLimit > 0 {
+		median = test.TimeLimit / 2
+	}
+	duration := time.Duration(float64(median) * math.Exp(0.4*r.NormFloat64()))
+	if test.TimeLimit > 0 && duration > test.TimeLimit {
+		duration = test.TimeLimit
+	}
+	if inProgress {
+		duration = now.Sub(startedAt)
+	}
+
+	attempt := &Attempt{
+		ID:        uint64(len(s.attempts)) + 1,
+		UserID:    userID,
+		TestID:    test.ID,
+		Status:    "started",
+		Answers:   make([]*Answer, len(questions)),
+		Questions: make([]uint64, len(questions)),
+		Seed:      seed,
+		StartedAt: startedAt,
+	}
+	attempt.LastSeenAt = startedAt
+
+	for i, question := range questions {
+		attempt.Questions[i] = question.ID
+		answer := &Answer{ID: question.ID, QuestionID: question.ID}
+		attempt.Answers[i] = answer
+
+		// Незаконченная попытка отвечена до середины, законченная иногда с пропусками
+		if (inProgress && i >= len(questions)/2) || r.Float64() < 0.04 {
+			continue
+		}
+		answer.CreatedAt = startedAt.Add(duration * time.Duration(i+1) / time.Duration(len(questions)+1))
+		answer.Status = "graded"
+		if r.Float64() < 1/(1+math.Exp(-8*(ability-difficulty[question.ID]))) {
+			answer.Text = question.TrueAnswer
+			answer.RightOrNot = true
+			answer.Score = question.MaxScore
+		} else {
+			answer.Text = "не знаю"
+		}
+		attempt.LastSeenAt = answer.CreatedAt
+	}
+
+	if !inProgress {
+		attempt.FinishedAt = startedAt.Add(duration)
+		attempt.LastSeenAt = attempt.FinishedAt
+		s.finalizeAttempt(attempt)
+	}
+	s.addAttempt(attempt)
+
+	return attempt
+}
+
+// poisson возвращает случайное число с распределением Пуассона со средним mean (алгоритм Кнута)
+func poisson(r *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	limit := math.Exp(-mean)
+	n, p := 0, r.Float64()
+	for p > limit {
+		n++
+		p *= r.Float64()
+	}
+	return n
+}
+
+func clamp(v, low, high float64) float64 {
+	return math.Max(low, math.Min(high, v))
+}
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Действия студента, которые записываются в журнал попытки
+const (
+	ActionQuestionOpened = "question_opened"
+	ActionAnswerSaved    = "answer_saved"
+)
+
+// MaxAttemptJournal - сколько действий хранится в журнале одной попытки, дальше новые не записываются
+const MaxAttemptJournal = 1000
+
+// Типы событий хронологии попытки
+const (
+	TimelineAttemptStarted   = "attempt_started"
+	TimelineQuestionOpened   = "question_opened"
+	TimelineDraftSaved       = "draft_saved"  // ответ, позже замененный другим
+	TimelineFinalAnswer      = "final_answer" // ответ, который засчитан в попытку
+	TimelineAIMessage        = "ai_message"
+	TimelineProctoring       = "proctoring"
+	TimelineAttemptSubmitted = "attempt_submitted"
+	TimelineAnswerGraded     = "answer_graded" // ручная проверка
+	TimelineFeedback         = "feedback"
+	TimelineRegraded         = "regraded"
+)
+
+// AttemptAction - открытие вопроса или сохранение ответа во время попытки
+type AttemptAction struct {
+	Type             string    // ActionQuestionOpened или ActionAnswerSaved
+	UserID           uint64    // участник, для командной попытки не обязательно владелец
+	QuestionPosition uint64    // позиция вопроса в попытке, с 1
+	Text             string    // текст сохраненного ответа
+	At               time.Time // время действия, для ответов, сохраненных офлайн, - время клиента
+	ReceivedAt       time.Time // когда действие дошло до сервера
+}
+
+// TimelineEvent - событие хронологии попытки
+type TimelineEvent struct {
+	Type             string     `json:"type"`
+	At               time.Time  `json:"at"`
+	UserID           uint64     `json:"user_id,omitempty"` // кто действовал: студент, участник команды или преподаватель
+	QuestionPosition uint64     `json:"question_position,omitempty"`
+	Text             string     `json:"text,omitempty"`        // ответ, сообщение диалога с ассистентом или комментарий
+	Role             string     `json:"role,omitempty"`        // роль в диалоге с ассистентом
+	Detail           string     `json:"detail,omitempty"`      // тип события прокторинга; ai_moderation или ai_refusal - сообщение не дошло до ассистента
+	Categories       []string   `json:"categories,omitempty"`  // сработавшие категории модерации или правило отказа
+	Score            *uint64    `json:"score,omitempty"`       // баллы за ответ или результат попытки
+	ReceivedAt       *time.Time `json:"received_at,omitempty"` // когда сервер получил ответ, сохраненный офлайн
+}
+
+// AttemptTimeline - хронология попытки для разбора спорных случаев
+type AttemptTimeline struct {
+	AttemptID uint64           `json:"attempt_id"`
+	UserID    uint64           `json:"user_id"`
+	TestID    uint64           `json:"test_id"`
+	Status    string           `json:"status"`
+	Truncated bool             `json:"truncated,omitempty"` // журнал переполнен: поздние открытия вопросов и черновики не записаны
+	Events    []*TimelineEvent `json:"events"`              // по времени
+}
+
+// RecordQuestionView записывает, что участник попытки открыл вопрос. Повторное открытие того же вопроса
+// подряд не записывается, закрытые попытки и чужие запросы пропускаются
+func (s *Store) RecordQuestionView(attemptID, userID, questionPos uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok || attempt.Status != "started" || !s.canAccessAttempt(attempt, userID) {
+		return
+	}
+	if questionPos == 0 || questionPos > uint64(len(attempt.Questions)) {
+		return
+	}
+	for i := len(attempt.Journal) - 1; i >= 0; i-- {
+		if last := attempt.Journal[i]; last.Type == ActionQuestionOpened && last.UserID == userID {
+			if last.QuestionPosition == questionPos {
+				return
+			}
+			break
+		}
+	}
+
+	now := time.Now().UTC()
+	recordAction(attempt, &AttemptAction{
+		Type:             ActionQuestionOpened,
+		UserID:           userID,
+		QuestionPosition: questionPos,
+		At:               now,
+		ReceivedAt:       now,
+	})
+}
+
+// recordAction добавляет действие в журнал попытки, вызывается под блокировкой
+func recordAction(attempt *Attempt, action *AttemptAction) {
+	if len(attempt.Journal) < MaxAttemptJournal {
+		attempt.Journal = append(attempt.Journal, action)
+	}
+}
+
+// AttemptTimeline восстанавливает ход попытки по времени: открытия вопросов, черновики и засчитанные ответы,
+// диалоги с ассистентом вместе с заблокированными сообщениями, события прокторинга, сдачу, ручную проверку,
+// комментарии и перепроверки. Ответы, сохраненные офлайн, стоят по времени клиента
+func (s *Store) AttemptTimeline(attemptID uint64) (*AttemptTimeline, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	timeline := &AttemptTimeline{
+		AttemptID: attempt.ID,
+		UserID:    attempt.UserID,
+		TestID:    attempt.TestID,
+		Status:    attempt.Status,
+		Truncated: len(attempt.Journal) >= MaxAttemptJournal,
+		Events:    make([]*TimelineEvent, 0),
+	}
+	add := func(event *TimelineEvent) {
+		timeline.Events = append(timeline.Events, event)
+	}
+
+	add(&TimelineEvent{Type: TimelineAttemptStarted, At: attempt.StartedAt, UserID: attempt.UserID})
+
+	// Последнее сохранение каждого вопроса в журнале - засчитанный ответ, если совпадает с ним
+	lastSave := make(map[uint64]int)
+	for i, action := range attempt.Journal {
+		if action.Type == ActionAnswerSaved {
+			lastSave[action.QuestionPosition] = i
+		}
+	}
+	finalFromJournal := make(map[uint64]bool)
+	for i, action := range attempt.Journal {
+		event := &TimelineEvent{
+			Type:             TimelineQuestionOpened,
+			At:               action.At,
+			UserID:           action.UserID,
+			QuestionPosition: action.QuestionPosition,
+		}
+		if action.Type == ActionAnswerSaved {
+			event.Type = TimelineDraftSaved
+			event.Text = action.Text
+			if !action.ReceivedAt.Equal(action.At) {
+				receivedAt := action.ReceivedAt
+				event.ReceivedAt = &receivedAt
+			}
+			answer := attempt.Answers[action.QuestionPosition-1]
+			if lastSave[action.QuestionPosition] == i && answer.Text == action.Text && answer.CreatedAt.Equal(action.At) {
+				event.Type = TimelineFinalAnswer
+				event.Score = answerScore(answer)
+				finalFromJournal[action.QuestionPosition] = true
+			}
+		}
+		add(event)
+	}
+	// Ответы, сохраненные до появления журнала или не попавшие в переполненный журнал
+	for i, answer := range attempt.Answers {
+		position := uint64(i + 1)
+		if answer.CreatedAt.IsZero() || finalFromJournal[position] {
+			continue
+		}
+		add(&TimelineEvent{
+			Type:             TimelineFinalAnswer,
+			At:               answer.CreatedAt,
+			QuestionPosition: position,
+			Text:             answer.Text,
+			Score:            answerScore(answer),
+		})
+	}
+
+	threadPositions := make(map[string]uint64)
+	for _, thread := range s.aiThreadsByID {
+		if thread.AttemptID != attemptID {
+			continue
+		}
+		threadPositions[thread.ThreadID] = thread.QuestionPosition
+		for _, message := range thread.Messages {
+			if message.Role == AIRoleSystem {
+				continue
+			}
+			add(&TimelineEvent{
+				Type:             TimelineAIMessage,
+				At:               message.CreatedAt,
+				UserID:           message.UserID,
+				QuestionPosition: thread.QuestionPosition,
+				Text:             message.Text,
+				Role:             message.Role,
+			})
+		}
+	}
+
+	for _, proctoring := range attempt.ProctoringEvents {
+		event := &TimelineEvent{
+			Type:             TimelineProctoring,
+			At:               proctoring.CreatedAt,
+			UserID:           proctoring.UserID,
+			QuestionPosition: threadPositions[proctoring.ThreadID],
+			Text:             proctoring.Message,
+			Detail:           proctoring.Type,
+			Categories:       proctoring.Categories,
+		}
+		// Заблокированное сообщение ассистенту показываем в диалоге, как в разборе диалогов
+		if proctoring.Type == ProctoringAIModeration || proctoring.Type == ProctoringAIRefusal {
+			event.Type = TimelineAIMessage
+			event.Role = AIRoleUser
+		}
+		add(event)
+	}
+
+	if attempt.Status != "started" && !attempt.FinishedAt.IsZero() {
+		result := attempt.Result
+		add(&TimelineEvent{Type: TimelineAttemptSubmitted, At: attempt.FinishedAt, UserID: attempt.UserID, Score: &result})
+	}
+
+	for _, item := range s.reviewItems {
+		if item.AttemptID != attemptID || item.GradedAt == nil {
+			continue
+		}
+		score := item.Score
+		event := &TimelineEvent{
+			Type:             TimelineAnswerGraded,
+			At:               *item.GradedAt,
+			QuestionPosition: item.QuestionPosition,
+			Score:            &score,
+		}
+		if item.GradedBy != nil {
+			event.UserID = *item.GradedBy
+		}
+		add(event)
+	}
+
+	for _, feedback := range attempt.Feedback {
+		event := &TimelineEvent{Type: TimelineFeedback, At: feedback.CreatedAt, UserID: feedback.AuthorID, Text: feedback.Text}
+		if feedback.QuestionPosition != nil {
+			event.QuestionPosition = *feedback.QuestionPosition
+		}
+		add(event)
+	}
+
+	for _, regrade := range attempt.Regrades {
+		result := regrade.NewResult
+		add(&TimelineEvent{Type: TimelineRegraded, At: regrade.CreatedAt, UserID: regrade.RegradedBy, Score: &result})
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].At.Before(timeline.Events[j].At)
+	})
+
+	return timeline, nil
+}
+
+// answerScore возвращает баллы за ответ, nil - ответ еще ждет проверки
+func answerScore(answer *Answer) *uint64 {
+	if answer.Status == "pending_review" {
+		return nil
+	}
+	score := answer.Score
+	return &score
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// TranscriptMessage - сообщение диалога с ассистентом в разборе попытки для преподавателя
+type TranscriptMessage struct {
+	Role       string    `json:"role"`
+	UserID     uint64    `json:"user_id"`
+	Text       string    `json:"text"`
+	Images     []uint64  `json:"images,omitempty"`
+	Cached     bool      `json:"cached,omitempty"`
+	HintCost   uint64    `json:"hint_cost,omitempty"`
+	Blocked    string    `json:"blocked,omitempty"`    // ai_moderation или ai_refusal: сообщение не дошло до ассистента
+	Categories []string  `json:"categories,omitempty"` // сработавшие категории модерации или правило отказа
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// QuestionTranscript - диалог с ассистентом по одному вопросу попытки
+type QuestionTranscript struct {
+	QuestionPosition uint64               `json:"question_position"`
+	QuestionID       uint64               `json:"question_id"`
+	QuestionText     string               `json:"question_text"`
+	AnswerText       string               `json:"answer_text"`
+	Score            uint64               `json:"score"`
+	ThreadID         string               `json:"thread_id,omitempty"`
+	MessagesSent     int                  `json:"messages_sent"`    // сообщения, на которые ассистент ответил
+	MessagesBlocked  int                  `json:"messages_blocked"` // сообщения, заблокированные модерацией или отказом
+	HintCost         uint64               `json:"hint_cost"`
+	Messages         []*TranscriptMessage `json:"messages"`
+}
+
+// AttemptTranscript - диалоги с ассистентом по всем вопросам попытки
+type AttemptTranscript struct {
+	AttemptID uint64                `json:"attempt_id"`
+	UserID    uint64                `json:"user_id"`
+	TestID    uint64                `json:"test_id"`
+	AICost    uint64                `json:"ai_cost"`
+	Questions []*QuestionTranscript `json:"questions"`
+}
+
+// AttemptAITranscript собирает диалоги с ассистентом по вопросам попытки вместе с заблокированными сообщениями
+// из журнала прокторинга. Системный контекст диалога не включается
+func (s *Store) AttemptAITranscript(attemptID uint64) (*AttemptTranscript, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return nil, errors.New("attempt not found")
+	}
+
+	transcript := &AttemptTranscript{
+		AttemptID: attempt.ID,
+		UserID:    attempt.UserID,
+		TestID:    attempt.TestID,
+		AICost:    attempt.AICost,
+		Questions: make([]*QuestionTranscript, len(attempt.Answers)),
+	}
+	for i, answer := range attempt.Answers {
+		question := &QuestionTranscript{
+			QuestionPosition: uint64(i + 1),
+			QuestionID:       answer.QuestionID,
+			AnswerText:       answer.Text,
+			Score:            answer.Score,
+			Messages:         make([]*TranscriptMessage, 0),
+		}
+		if q, ok := s.findQuestionByID(attempt.TestID, answer.QuestionID); ok {
+			question.QuestionText = q.Text
+		}
+		transcript.Questions[i] = question
+	}
+
+	byThread := make(map[string]*QuestionTranscript)
+	for _, thread := range s.aiThreadsByID {
+		if thread.AttemptID != attemptID {
+			continue
+		}
+
+		question := transcript.Questions[thread.QuestionPosition-1]
+		question.ThreadID = thread.ThreadID
+		byThread[thread.ThreadID] = question
+
+		for _, message := range thread.Messages {
+			if message.Role == AIRoleSystem {
+				continue
+			}
+			if message.Role == AIRoleAssistant {
+				question.MessagesSent++
+				question.HintCost += message.HintCost
+			}
+			question.Messages = append(question.Messages, &TranscriptMessage{
+				Role:      message.Role,
+				UserID:    message.UserID,
+				Text:      message.Text,
+				Images:    message.Images,
+				Cached:    message.Cached,
+				HintCost:  message.HintCost,
+				CreatedAt: message.CreatedAt,
+			})
+		}
+	}
+
+	for _, event := range attempt.ProctoringEvents {
+		if event.Type != ProctoringAIModeration && event.Type != ProctoringAIRefusal {
+			continue
+		}
+		question, ok := byThread[event.ThreadID]
+		if !ok {
+			continue
+		}
+
+		question.MessagesBlocked++
+		question.Messages = append(question.Messages, &TranscriptMessage{
+			Role:       AIRoleUser,
+			UserID:     event.UserID,
+			Text:       event.Message,
+			Blocked:    event.Type,
+			Categories: event.Categories,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+
+	for _, question := range transcript.Questions {
+		sort.SliceStable(question.Messages, func(i, j int) bool {
+			return question.Messages[i].CreatedAt.Before(question.Messages[j].CreatedAt)
+		})
+	}
+
+	return transcript, nil
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"errors"
+	"sort"
+)
+
+// Разрезы отчета по расходу токенов
+const (
+	UsageByUser    = "user"
+	UsageByAttempt = "attempt"
+	UsageByTest    = "test"
+	UsageByOrg     = "organization"
+)
+
+// AIUsage - накопленный расход токенов ассистента
+type AIUsage struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"` // оценка расхода по тарифам моделей
+}
+
+func (u *AIUsage) add(prompt, completion int, cost float64) {
+	u.Requests++
+	u.PromptTokens += prompt
+	u.CompletionTokens += completion
+	u.TotalTokens += prompt + completion
+	u.CostUSD += cost
+}
+
+// merge добавляет накопленный расход other
+func (u *AIUsage) merge(other AIUsage) {
+	u.Requests += other.Requests
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.CostUSD += other.CostUSD
+}
+
+// AIUsageEntry - строка отчета: расход одного пользователя, попытки или теста
+type AIUsageEntry struct {
+	ID uint64 `json:"id"`
+	AIUsage
+}
+
+// AIUsageReport - отчет по расходу токенов в выбранном разрезе
+type AIUsageReport struct {
+	GroupBy string          `json:"group_by"`
+	Total   AIUsage         `json:"total"`
+	Items   []*AIUsageEntry `json:"items"`
+}
+
+// recordAIUsage учитывает расход токенов и стоимость ответа ассистента, вызывается под блокировкой
+func (s *Store) recordAIUsage(attempt *Attempt, userID uint64, prompt, completion int, cost float64) {
+	accumulate := func(usage map[uint64]*AIUsage, id uint64) {
+		entry, ok := usage[id]
+		if !ok {
+			entry = &AIUsage{}
+			usage[id] = entry
+		}
+		entry.add(prompt, completion, cost)
+	}
+
+	accumulate(s.aiUsageByUser, userID)
+	accumulate(s.aiUsageByAttempt, attempt.ID)
+	accumulate(s.aiUsageByTest, attempt.TestID)
+	orgID := s.attemptOrgID(attempt)
+	if orgID != 0 {
+		accumulate(s.aiUsageByOrg, orgID)
+	}
+	s.aiUsageTotal.add(prompt, completion, cost)
+	s.addAISpend(orgID, cost)
+}
+
+// AIUsageReport возвращает расход токенов по пользователям, попыткам, тестам или организациям, самые затратные первыми
+func (s *Store) AIUsageReport(groupBy string) (*AIUsageReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var usage map[uint64]*AIUsage
+	switch groupBy {
+	case UsageByUser:
+		usage = s.aiUsageByUser
+	case UsageByAttempt:
+		usage = s.aiUsageByAttempt
+	case UsageByTest:
+		usage = s.aiUsageByTest
+	case UsageByOrg:
+		usage = s.aiUsageByOrg
+	default:
+		return nil, errors.New("group_by must be one of: user, attempt, test, organization")
+	}
+
+	report := &AIUsageReport{
+		GroupBy: groupBy,
+		Total:   s.aiUsageTotal,
+		Items:   make([]*AIUsageEntry, 0, len(usage)),
+	}
+	for id, entry := range usage {
+		report.Items = append(report.Items, &AIUsageEntry{ID: id, AIUsage: *entry})
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].CostUSD != report.Items[j].CostUSD {
+			return report.Items[i].CostUSD > report.Items[j].CostUSD
+		}
+		if report.Items[i].TotalTokens != report.Items[j].TotalTokens {
+			return report.Items[i].TotalTokens > report.Items[j].TotalTokens
+		}
+		return report.Items[i].ID < report.Items[j].ID
+	})
+
+	return report, nil
+}
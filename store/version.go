@@ -0,0 +1,67 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Тесты и вопросы версионируются для оптимистичной блокировки: каждое изменение увеличивает Version,
+// а изменяющие методы принимают версию, которую видел клиент, и отказывают, если с тех пор ресурс
+// изменил кто-то другой. Так два преподавателя не перезаписывают правки друг друга молча.
+// Версии теста и его вопросов независимы: правка вопроса не меняет версию теста
+
+// ErrVersionConflict - ресурс изменили после того, как клиент прочитал его версию
+var ErrVersionConflict = errors.New("version conflict")
+
+// VersionConflictError возвращается изменяющими методами при несовпадении версии.
+// Current - текущая версия, с которой клиент может повторить изменение после перечитывания
+type VersionConflictError struct {
+	Resource string // test или question
+	Current  uint64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s was modified by someone else, current version is %d", e.Resource, e.Current)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// checkVersion сравнивает ожидаемую версию с текущей, 0 - без проверки
+func checkVersion(resource string, current, expected uint64) error {
+	if expected != 0 && expected != current {
+		return &VersionConflictError{Resource: resource, Current: current}
+	}
+	return nil
+}
+
+// checkTestVersion проверяет версию теста, вызывается под блокировкой
+func checkTestVersion(test *Test, expected uint64) error {
+	return checkVersion("test", test.Version, expected)
+}
+
+// checkQuestionVersion проверяет версию вопроса, вызывается под блокировкой
+func checkQuestionVersion(question *Question, expected uint64) error {
+	return checkVersion("question", question.Version, expected)
+}
+
+// ListQuestions возвращает копии неудаленных вопросов теста с их версиями в исходном порядке
+func (s *Store) ListQuestions(testID uint64) ([]*Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	test, ok := s.activeTest(testID)
+	if !ok {
+		return nil, ErrTestNotFound
+	}
+
+	questions := activeQuestions(test.Questions)
+	result := make([]*Question, len(questions))
+	for i, question := range questions {
+		q := *question
+		result[i] = &q
+	}
+
+	return result, nil
+}
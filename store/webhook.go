@@ -0,0 +1,195 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// Статусы доставки вебхука
+const (
+	WebhookDeliveryPending   = "pending"   // в очереди или ждет повторной попытки
+	WebhookDeliveryDelivered = "delivered" // получатель ответил 2xx
+	WebhookDeliveryFailed    = "failed"    // получатель отклонил событие или попытки закончились
+)
+
+// maxWebhookDeliveries - сколько последних доставок хранится в журнале каждого вебхука
+const maxWebhookDeliveries = 200
+
+// Webhook - подписка внешней системы на события. Секрет подписывает тело запроса и не возвращается в API
+type Webhook struct {
+	ID        uint64    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"-"`
+	CreatedBy uint64    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery - запись журнала доставок: одно событие, отправленное одному вебхуку
+type WebhookDelivery struct {
+	ID             uint64          `json:"id"`
+	WebhookID      uint64          `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"` // код последнего ответа получателя
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+}
+
+// WebhookTarget - доставка события и секрет, которым подписывается тело
+type WebhookTarget struct {
+	Delivery *WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// CreateWebhook регистрирует вебхук на события events
+func (s *Store) CreateWebhook(url, secret string, events []string, userID uint64) *Webhook {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	webhook := &Webhook{
+		ID:        s.nextWebhookID,
+		URL:       url,
+		Events:    slices.Compact(slices.Sorted(slices.Values(events))),
+		Secret:    secret,
+		CreatedBy: userID,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.webhooks[webhook.ID] = webhook
+	s.nextWebhookID++
+
+	return webhook
+}
+
+// ListWebhooks возвращает все вебхуки
+func (s *Store) ListWebhooks() []*Webhook {
+	s.webhookMu.RLock()
+	defer s.webhookMu.RUnlock()
+
+	webhooks := make([]*Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks
+}
+
+// DeleteWebhook удаляет вебхук вместе с журналом доставок. Уже запущенные доставки завершаются
+func (s *Store) DeleteWebhook(webhookID uint64) error {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(s.webhooks, webhookID)
+	for _, delivery := range s.webhookDeliveries[webhookID] {
+		delete(s.webhookDeliveryByID, delivery.ID)
+	}
+	delete(s.webhookDeliveries, webhookID)
+
+	return nil
+}
+
+// AddWebhookDeliveries создает доставки события всем вебхукам, подписанным на него
+func (s *Store) AddWebhookDeliveries(event, eventID string, payload []byte) []*WebhookTarget {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	now := time.Now().UTC()
+	targets := make([]*WebhookTarget, 0)
+	for _, webhook := range s.webhooks {
+		if !slices.Contains(webhook.Events, event) {
+			continue
+		}
+
+		delivery := &WebhookDelivery{
+			ID:        s.nextWebhookDeliveryID,
+			WebhookID: webhook.ID,
+			EventID:   eventID,
+			Event:     event,
+			Payload:   payload,
+			Status:    WebhookDeliveryPending,
+			CreatedAt: now,
+		}
+		s.nextWebhookDeliveryID++
+		s.addWebhookDelivery(delivery)
+
+		targets = append(targets, &WebhookTarget{Delivery: delivery, URL: webhook.URL, Secret: webhook.Secret})
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Delivery.ID < targets[j].Delivery.ID
+	})
+	return targets
+}
+
+// addWebhookDelivery добавляет доставку в журнал вебхука и удаляет самые старые записи сверх лимита,
+// вызывается под блокировкой webhookMu
+func (s *Store) addWebhookDelivery(delivery *WebhookDelivery) {
+	deliveries := append(s.webhookDeliveries[delivery.WebhookID], delivery)
+	if extra := len(deliveries) - maxWebhookDeliveries; extra > 0 {
+		for _, old := range deliveries[:extra] {
+			delete(s.webhookDeliveryByID, old.ID)
+		}
+		deliveries = slices.Clone(deliveries[extra:])
+	}
+	s.webhookDeliveries[delivery.WebhookID] = deliveries
+	s.webhookDeliveryByID[delivery.ID] = delivery
+}
+
+// RecordWebhookAttempt записывает результат попытки доставки. nextAttemptAt = nil означает,
+// что попыток больше не будет: доставка получает статус delivered или failed
+func (s *Store) RecordWebhookAttempt(deliveryID uint64, attempts, responseStatus int, deliveryErr error, nextAttemptAt *time.Time) {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	delivery, ok := s.webhookDeliveryByID[deliveryID]
+	if !ok {
+		// вебхук удален или запись вытеснена из журнала
+		return
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts = attempts
+	delivery.ResponseStatus = responseStatus
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nextAttemptAt
+	delivery.LastError = ""
+	if deliveryErr != nil {
+		delivery.LastError = deliveryErr.Error()
+	}
+
+	switch {
+	case nextAttemptAt != nil:
+		delivery.Status = WebhookDeliveryPending
+	case deliveryErr != nil:
+		delivery.Status = WebhookDeliveryFailed
+	default:
+		delivery.Status = WebhookDeliveryDelivered
+	}
+}
+
+// ListWebhookDeliveries возвращает журнал доставок вебхука
+func (s *Store) ListWebhookDeliveries(webhookID uint64) ([]*WebhookDelivery, error) {
+	s.webhookMu.RLock()
+	defer s.webhookMu.RUnlock()
+
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+	return slices.Clone(s.webhookDeliveries[webhookID]), nil
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"GEEK_back/client/llm"
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultServiceName - имя сервиса в трейсах, если не задан OTEL_SERVICE_NAME
+const defaultServiceName = "geek-back"
+
+// tracerName - имя инструментации для span, которые сервис создает сам
+const tracerName = "GEEK_back"
+
+// Init включает экспорт трейсов по OTLP/HTTP, если задан OTEL_EXPORTER_OTLP_ENDPOINT
+// или OTEL_EXPORTER_OTLP_TRACES_ENDPOINT. Остальные настройки экспортера (заголовки, TLS, сэмплирование)
+// читаются из стандартных переменных OTEL_*. Без адреса трейсинг выключен и span ничего не стоят.
+// Возвращает функцию, которая отправляет накопленные span при остановке сервера
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	// Входящий traceparent продолжает трейс фронтенда, исходящие запросы к провайдеру его передают
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Middleware открывает span на каждый HTTP-запрос. Имя span - метод и шаблон маршрута mux
+func Middleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if current := mux.CurrentRoute(r); current != nil {
+				if tmpl, err := current.GetPathTemplate(); err == nil {
+					return r.Method + " " + tmpl
+				}
+			}
+			return r.Method
+		}),
+	)
+}
+
+// InstrumentHTTP подключает span и передачу контекста трейса ко всем запросам HTTP-клиента провайдера
+func InstrumentHTTP(client *http.Client, provider string) {
+	if client == nil {
+		return
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = otelhttp.NewTransport(base,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return provider + " " + r.Method + " " + llm.EndpointPath(r.URL.Path)
+		}),
+	)
+}
+
+// Start открывает span с указанным именем
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StoreOp открывает span операции хранилища; вызывающий закрывает его через End
+func StoreOp(ctx context.Context, op string) trace.Span {
+	_, span := Start(ctx, "store."+op, attribute.String("store.operation", op))
+	return span
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"GEEK_back/metrics"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// События, на которые можно подписать вебхук
+const (
+	EventUserRegistered   = "user.registered"   // пользователь зарегистрировался
+	EventAttemptSubmitted = "attempt.submitted" // попытка сдана студентом или автоматически по истечении времени
+)
+
+// Настройки доставки по умолчанию
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 512
+
+	postTimeout  = 10 * time.Second
+	postAttempts = 5
+	postBackoff  = 10 * time.Second
+)
+
+var (
+	// ErrQueueFull возвращается, если очередь доставки переполнена
+	ErrQueueFull = errors.New("webhook queue is full")
+	// ErrClosed возвращается при постановке доставки в очередь после Close
+	ErrClosed = errors.New("webhook dispatcher is closed")
+)
+
+// Delivery - отправка одного события на один адрес
+type Delivery struct {
+	ID     uint64
+	URL    string
+	Secret string
+	Event  string
+	Body   []byte
+
+	attempt int
+}
+
+// Result - итог попытки доставки. Final означает, что повторов больше не будет
+type Result struct {
+	Attempt    int
+	StatusCode int // 0, если ответа не было
+	Err        error
+	Final      bool
+	NextRetry  time.Time // когда будет следующая попытка, если Final = false
+}
+
+// Dispatcher доставляет вебхуки в фоне: Enqueue ставит доставку в очередь, пул воркеров отправляет
+// POST с подписанным телом. При сетевой ошибке, 5xx, 408 и 429 попытка повторяется с растущей паузой.
+// Пауза не занимает воркер, поэтому недоступный получатель не задерживает доставку остальным
+type Dispatcher struct {
+	http   *http.Client
+	report func(d *Delivery, r Result)
+
+	mu     sync.RWMutex
+	queue  chan *Delivery
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New создает диспетчер с workers воркерами и очередью на queueSize доставок.
+// report вызывается после каждой попытки, чтобы записать ее в журнал доставок
+func New(workers, queueSize int, report func(d *Delivery, r Result)) *Dispatcher {
+	d := &Dispatcher{
+		http: &http.Client{
+			Timeout: postTimeout,
+			// Перенаправление вебхука - ошибка настройки получателя, следовать за ним не нужно
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		report: report,
+		queue:  make(chan *Delivery, max(queueSize, 1)),
+	}
+	for range max(workers, 1) {
+		d.wg.Add(1)
+		go d.worker()
+	}
+	return d
+}
+
+// Sign возвращает подпись тела для заголовка X-Webhook-Signature: HMAC-SHA256 с секретом получателя
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue ставит доставку в очередь. Не блокируется: при переполненной очереди возвращает ErrQueueFull
+func (d *Dispatcher) Enqueue(delivery *Delivery) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrClosed
+	}
+	select {
+	case d.queue <- delivery:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close перестает принимать доставки и ждет, пока воркеры отправят уже поставленные в очередь.
+// Повторы, время которых наступит после Close, не выполняются и записываются в журнал как неудачные
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+
+	for delivery := range d.queue {
+		d.deliver(delivery)
+	}
+}
+
+// deliver делает одну попытку доставки и при временной ошибке планирует следующую
+func (d *Dispatcher) deliver(delivery *Delivery) {
+	delivery.attempt++
+	status, retry, err := d.post(delivery)
+	metrics.ObserveWebhook(delivery.Event, err)
+
+	result := Result{Attempt: delivery.attempt, StatusCode: status, Err: err, Final: true}
+	if err != nil && retry && delivery.attempt < postAttempts {
+		backoff := postBackoff << (delivery.attempt - 1)
+		result.Final = false
+		result.NextRetry = time.Now().Add(backoff).UTC()
+		time.AfterFunc(backoff, func() { d.retry(delivery) })
+	}
+	d.report(delivery, result)
+}
+
+// retry возвращает доставку в очередь после паузы
+func (d *Dispatcher) retry(delivery *Delivery) {
+	if err := d.Enqueue(delivery); err != nil {
+		d.report(delivery, Result{Attempt: delivery.attempt, Err: err, Final: true})
+	}
+}
+
+// post отправляет вебхук и сообщает код ответа и имеет ли смысл повторять попытку
+func (d *Dispatcher) post(delivery *Delivery) (int, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GEEK-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(delivery.Secret, delivery.Body))
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, false, nil
+	case resp.StatusCode >= http.StatusInternalServerError,
+		resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests:
+		return resp.StatusCode, true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		// Получатель отклонил событие, повтор не поможет
+		return resp.StatusCode, false, fmt.Errorf("webhook rejected with status %d", resp.StatusCode)
+	}
+}